@@ -0,0 +1,428 @@
+package main
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/lattice-substrate/json-canon/jcserr"
+)
+
+// defaultIncludeGlob selects the files of a walked directory when no
+// --include-glob is given.
+const defaultIncludeGlob = "*.json"
+
+// batch checks or rewrites many files in one run. Explicitly named files are
+// always processed; directories are walked recursively, keeping the regular
+// files that match the include globs.
+type batch struct {
+	verify    bool
+	list      bool
+	write     bool
+	digest    bool
+	jobs      int
+	maxInput  int
+	json      bool
+	include   []string
+	exclude   []string
+	canonical func([]byte) ([]byte, error)
+	// read returns the content of a file to check and a function that
+	// releases it.
+	read func(name string) ([]byte, func(), error)
+}
+
+// batchStatus is the outcome for one file.
+type batchStatus int
+
+const (
+	fileCanonical batchStatus = iota
+	fileNotCanonical
+	fileRewritten
+	fileDigested
+	fileFailed
+)
+
+// batchResult is the outcome for one file. A failure carries its rendered
+// diagnostic and exit code, so that its input need not outlive process; sum
+// is the SHA-256 digest of the canonical form of a digested file.
+type batchResult struct {
+	path       string
+	status     batchStatus
+	diagnostic []byte
+	code       int
+	sum        [sha256.Size]byte
+}
+
+// batchEntry is a file to process, or a path that could not be walked.
+type batchEntry struct {
+	path string
+	err  error
+}
+
+// batchMode reports whether cmd processes its inputs as a batch of files:
+// canonicalize with -l or --write, and verify with -l, a batch option,
+// several inputs, or a directory.
+//
+// CLI-BATCH-001: Batch mode accepts files and directories.
+func batchMode(cmd string, fl *flags, positional []string) (bool, error) {
+	options := fl.jobs != "" || len(fl.includeGlobs) > 0 || len(fl.excludeGlobs) > 0
+	on := fl.list || fl.write
+	if cmd == "verify" && !on {
+		on = options || len(positional) > 1 || (len(positional) == 1 && isDirectory(positional[0]))
+	}
+	if !on {
+		if options {
+			return false, jcserr.New(jcserr.CLIUsage, -1, "--jobs, --include-glob, and --exclude-glob require -l or --write")
+		}
+		return false, nil
+	}
+	if len(positional) == 0 {
+		return true, jcserr.New(jcserr.CLIUsage, -1, "multi-file mode requires file or directory arguments")
+	}
+	for _, p := range positional {
+		if p == "-" {
+			return true, jcserr.New(jcserr.CLIUsage, -1, "standard input cannot be used in multi-file mode")
+		}
+	}
+	return true, nil
+}
+
+func isDirectory(name string) bool {
+	info, err := os.Stat(name)
+	return err == nil && info.IsDir()
+}
+
+// parseJobs returns the positive count given to --jobs, or def when the
+// option is absent.
+func parseJobs(value string, def int) (int, error) {
+	if value == "" {
+		return def, nil
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil || n < 1 {
+		return 0, jcserr.New(jcserr.CLIUsage, -1, fmt.Sprintf("invalid --jobs: %s", value))
+	}
+	return n, nil
+}
+
+// newBatch validates the batch options of fl. Files larger than maxInput
+// bytes fail with BOUND_EXCEEDED; canonical returns the canonical form of a
+// file's bytes. Every command but canonicalize only checks the files.
+func newBatch(cmd string, fl *flags, maxInput int, canonical func([]byte) ([]byte, error)) (*batch, error) {
+	b := &batch{
+		verify:    cmd != "canonicalize",
+		list:      fl.list,
+		write:     fl.write,
+		jobs:      runtime.GOMAXPROCS(0),
+		maxInput:  maxInput,
+		json:      fl.errorFormat == "json",
+		include:   fl.includeGlobs,
+		exclude:   fl.excludeGlobs,
+		canonical: canonical,
+		read: func(name string) ([]byte, func(), error) {
+			return loadInput([]string{name}, nil, maxInput)
+		},
+	}
+	jobs, err := parseJobs(fl.jobs, b.jobs)
+	if err != nil {
+		return nil, err
+	}
+	b.jobs = jobs
+	if len(b.include) == 0 {
+		b.include = []string{defaultIncludeGlob}
+	}
+	for _, pattern := range append(append([]string(nil), b.include...), b.exclude...) {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return nil, jcserr.New(jcserr.CLIUsage, -1, fmt.Sprintf("invalid glob: %s", pattern))
+		}
+	}
+	return b, nil
+}
+
+// collect lists the files named by positional in a deterministic order:
+// arguments in order, each directory walked in lexical order.
+//
+// CLI-BATCH-001: Directories are walked recursively and filtered by globs.
+func (b *batch) collect(positional []string) []batchEntry {
+	var entries []batchEntry
+	for _, root := range positional {
+		if !isDirectory(root) {
+			entries = append(entries, batchEntry{path: root})
+			continue
+		}
+		walkErr := filepath.WalkDir(root, func(p string, d fs.DirEntry, err error) error {
+			if err != nil {
+				entries = append(entries, batchEntry{path: p, err: jcserr.Wrap(jcserr.CLIUsage, -1, fmt.Sprintf("read directory %q", p), err)})
+				return nil
+			}
+			if p == root {
+				return nil
+			}
+			skip := strings.HasPrefix(d.Name(), ".") || b.matches(b.exclude, root, p)
+			switch {
+			case d.IsDir() && skip:
+				return filepath.SkipDir
+			case d.Type().IsRegular() && !skip && b.matches(b.include, root, p):
+				entries = append(entries, batchEntry{path: p})
+			}
+			return nil
+		})
+		if walkErr != nil {
+			entries = append(entries, batchEntry{path: root, err: jcserr.Wrap(jcserr.CLIUsage, -1, fmt.Sprintf("read directory %q", root), walkErr)})
+		}
+	}
+	return entries
+}
+
+// matches reports whether p, found under root, matches any pattern. A
+// pattern containing a slash matches the slash-separated path relative to
+// root; any other pattern matches the base name.
+func (b *batch) matches(patterns []string, root, p string) bool {
+	rel, err := filepath.Rel(root, p)
+	if err != nil {
+		rel = p
+	}
+	rel = filepath.ToSlash(rel)
+	for _, pattern := range patterns {
+		name := path.Base(rel)
+		if strings.Contains(pattern, "/") {
+			name = rel
+		}
+		if ok, _ := path.Match(pattern, name); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// run processes entries with b.jobs workers. Results are in entry order
+// whatever the number of workers.
+//
+// CLI-BATCH-004: Batch output does not depend on the number of workers.
+func (b *batch) run(entries []batchEntry) []batchResult {
+	results := make([]batchResult, len(entries))
+	next := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < b.jobs && w < len(entries); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range next {
+				results[i] = b.guardedProcess(entries[i])
+			}
+		}()
+	}
+	for i := range entries {
+		next <- i
+	}
+	close(next)
+	wg.Wait()
+	return results
+}
+
+// guardedProcess is process, reporting a fault while reading a mapped file
+// as the file's failure.
+func (b *batch) guardedProcess(e batchEntry) (r batchResult) {
+	defer guardMapped(func(err error) { r = b.failure(e.path, err, nil) })()
+	return b.process(e)
+}
+
+// process checks one file and, under --write, rewrites it when it is not
+// canonical. A digest batch records the digest of the canonical form instead.
+func (b *batch) process(e batchEntry) batchResult {
+	if e.err != nil {
+		return b.failure(e.path, e.err, nil)
+	}
+	input, release, err := b.read(e.path)
+	if err != nil {
+		return b.failure(e.path, err, nil)
+	}
+	defer release()
+	canonical, err := b.canonical(input)
+	if err != nil {
+		return b.failure(e.path, err, input)
+	}
+	same := bytes.Equal(input, canonical)
+	if b.digest {
+		return batchResult{path: e.path, status: fileDigested, sum: sha256.Sum256(canonical)}
+	}
+	if same {
+		return batchResult{path: e.path, status: fileCanonical}
+	}
+	if !b.write {
+		return batchResult{path: e.path, status: fileNotCanonical}
+	}
+	if err := writeFileAtomic(e.path, canonical); err != nil {
+		return b.failure(e.path, err, nil)
+	}
+	return batchResult{path: e.path, status: fileRewritten}
+}
+
+// failure renders the diagnostic for err, the failure of file, locating it
+// in input while the caller still holds it.
+func (b *batch) failure(file string, err error, input []byte) batchResult {
+	var buf bytes.Buffer
+	d := diagnostics{stderr: &buf, json: b.json}
+	code := d.report(file, err, input)
+	return batchResult{path: file, status: fileFailed, diagnostic: buf.Bytes(), code: code}
+}
+
+// writeFileAtomic replaces the file at name, or the file a symbolic link at
+// name points to, with data. The data is written to a temporary file in the
+// same directory, given the original mode, and renamed over the original.
+//
+// CLI-BATCH-003: --write replaces files atomically, preserving their mode.
+func writeFileAtomic(name string, data []byte) error {
+	target, err := filepath.EvalSymlinks(name)
+	if err != nil {
+		return jcserr.Wrap(jcserr.InternalIO, -1, fmt.Sprintf("rewrite file %q", name), err)
+	}
+	info, err := os.Stat(target)
+	if err != nil {
+		return jcserr.Wrap(jcserr.InternalIO, -1, fmt.Sprintf("rewrite file %q", name), err)
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(target), "."+filepath.Base(target)+".jcs-canon-*")
+	if err != nil {
+		return jcserr.Wrap(jcserr.InternalIO, -1, fmt.Sprintf("rewrite file %q", name), err)
+	}
+	mode := info.Mode() & (fs.ModePerm | fs.ModeSetuid | fs.ModeSetgid | fs.ModeSticky)
+	err = writeAndClose(tmp, data, mode)
+	if err == nil {
+		err = os.Rename(tmp.Name(), target)
+	}
+	if err != nil {
+		if removeErr := os.Remove(tmp.Name()); removeErr != nil {
+			_ = removeErr
+		}
+		return jcserr.Wrap(jcserr.InternalIO, -1, fmt.Sprintf("rewrite file %q", name), err)
+	}
+	return nil
+}
+
+// writeAndClose writes data to f, sets its mode, flushes it to stable
+// storage, and closes it.
+func writeAndClose(f *os.File, data []byte, mode fs.FileMode) error {
+	_, err := f.Write(data)
+	if err == nil {
+		err = f.Chmod(mode)
+	}
+	if err == nil {
+		err = f.Sync()
+	}
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return fmt.Errorf("write temporary file: %w", err)
+	}
+	return nil
+}
+
+// batchSummary counts the outcomes of a batch.
+type batchSummary struct {
+	files, canonical, notCanonical, rewritten, failed int
+}
+
+// report writes the results in order: under -l the names of files that were
+// not canonical (or were rewritten) to stdout, and a diagnostic for every
+// failure to stderr. verify without -l reports files that are not canonical
+// as NOT_CANONICAL failures. It returns the counts and the aggregate exit
+// code: the highest exit code of any failure, and 2 when verify found a file
+// that is not canonical.
+//
+// CLI-BATCH-002: -l lists files that are not canonical.
+// CLI-BATCH-004: The exit code aggregates the per-file outcomes.
+func (b *batch) report(results []batchResult, stdout io.Writer, diag *diagnostics) (batchSummary, int) {
+	sum := batchSummary{files: len(results)}
+	code := 0
+	for _, r := range results {
+		fileCode := 0
+		switch r.status {
+		case fileCanonical:
+			sum.canonical++
+		case fileNotCanonical, fileRewritten:
+			if r.status == fileRewritten {
+				sum.rewritten++
+			} else {
+				sum.notCanonical++
+			}
+			fileCode = b.reportChanged(r, stdout, diag)
+		default:
+			sum.failed++
+			fileCode = r.reportFailure(diag)
+		}
+		code = max(code, fileCode)
+	}
+	return sum, code
+}
+
+// reportFailure writes the diagnostic rendered for the failure of r and
+// returns its exit code.
+func (r *batchResult) reportFailure(diag *diagnostics) int {
+	if _, err := diag.stderr.Write(r.diagnostic); err != nil {
+		return jcserr.InternalIO.ExitCode()
+	}
+	return r.code
+}
+
+// reportChanged reports a file whose canonical form differs from its bytes.
+func (b *batch) reportChanged(r batchResult, stdout io.Writer, diag *diagnostics) int {
+	if b.list {
+		if err := writeLine(stdout, r.path); err != nil {
+			return diag.fail(jcserr.Wrap(jcserr.InternalIO, -1, "writing file list", err))
+		}
+		if b.verify {
+			return jcserr.NotCanonical.ExitCode()
+		}
+		return 0
+	}
+	if b.verify {
+		return diag.report(r.path, jcserr.New(jcserr.NotCanonical, -1, "input is not canonical"), nil)
+	}
+	return 0
+}
+
+// writeSummary writes the batch counts to stderr: one text line, or under
+// --error-format json one canonical JSON object.
+//
+// CLI-BATCH-004: The summary is deterministic.
+func writeSummary(diag *diagnostics, sum batchSummary) error {
+	if diag.json {
+		return writef(diag.stderr, `{"summary":{"canonical":%d,"failed":%d,"files":%d,"not_canonical":%d,"rewritten":%d}}`+"\n",
+			sum.canonical, sum.failed, sum.files, sum.notCanonical, sum.rewritten)
+	}
+	return writef(diag.stderr, "jcs-canon: files=%d canonical=%d not_canonical=%d rewritten=%d failed=%d\n",
+		sum.files, sum.canonical, sum.notCanonical, sum.rewritten, sum.failed)
+}
+
+// runBatch collects, processes, and reports the files named by positional.
+// notice writes the profile notices that precede the summary; it and the
+// summary are suppressed by --quiet.
+func runBatch(b *batch, positional []string, quiet bool, stdout io.Writer, diag *diagnostics, notice func() error) int {
+	return finishBatch(b, b.run(b.collect(positional)), quiet, stdout, diag, notice)
+}
+
+// finishBatch reports results, then unless quiet the notices and summary.
+func finishBatch(b *batch, results []batchResult, quiet bool, stdout io.Writer, diag *diagnostics, notice func() error) int {
+	sum, code := b.report(results, stdout, diag)
+	if quiet {
+		return code
+	}
+	err := notice()
+	if err == nil {
+		err = writeSummary(diag, sum)
+	}
+	if err != nil {
+		return max(code, diag.fail(jcserr.Wrap(jcserr.InternalIO, -1, "writing summary", err)))
+	}
+	return code
+}
@@ -0,0 +1,193 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/lattice-substrate/json-canon/jcs"
+	"github.com/lattice-substrate/json-canon/jcserr"
+	"github.com/lattice-substrate/json-canon/jcstoken"
+)
+
+// boundOption is a command-line option setting one jcstoken.Options bound.
+// key names the same bound in a --bounds-file profile.
+type boundOption struct {
+	flag  string
+	key   string
+	field func(*jcstoken.Options) *int
+}
+
+// boundOptions lists the bound options in jcstoken.Options field order.
+var boundOptions = []boundOption{
+	{"--max-depth", "max_depth", func(o *jcstoken.Options) *int { return &o.MaxDepth }},
+	{"--max-input-size", "max_input_size", func(o *jcstoken.Options) *int { return &o.MaxInputSize }},
+	{"--max-values", "max_values", func(o *jcstoken.Options) *int { return &o.MaxValues }},
+	{"--max-object-members", "max_object_members", func(o *jcstoken.Options) *int { return &o.MaxObjectMembers }},
+	{"--max-array-elements", "max_array_elements", func(o *jcstoken.Options) *int { return &o.MaxArrayElements }},
+	{"--max-string-bytes", "max_string_bytes", func(o *jcstoken.Options) *int { return &o.MaxStringBytes }},
+	{"--max-number-chars", "max_number_chars", func(o *jcstoken.Options) *int { return &o.MaxNumberChars }},
+	{"--max-output-size", "max_output_size", func(o *jcstoken.Options) *int { return &o.MaxOutputSize }},
+}
+
+// defaultBoundsPreset is the preset applied when --bounds is not given.
+const defaultBoundsPreset = "default"
+
+// boundPresets are the bound sets selectable with --bounds. BOUNDS.md
+// documents their values and memory budgets.
+//
+// CLI-BOUNDS-002: Named presets fix every bound.
+var boundPresets = map[string]jcstoken.Options{
+	"api-small": {
+		MaxDepth:         32,
+		MaxInputSize:     1024 * 1024,
+		MaxValues:        10_000,
+		MaxObjectMembers: 1_000,
+		MaxArrayElements: 10_000,
+		MaxStringBytes:   64 * 1024,
+		MaxNumberChars:   128,
+		MaxOutputSize:    4 * 1024 * 1024,
+	},
+	defaultBoundsPreset: {
+		MaxDepth:         jcstoken.DefaultMaxDepth,
+		MaxInputSize:     jcstoken.DefaultMaxInputSize,
+		MaxValues:        jcstoken.DefaultMaxValues,
+		MaxObjectMembers: jcstoken.DefaultMaxObjectMembers,
+		MaxArrayElements: jcstoken.DefaultMaxArrayElements,
+		MaxStringBytes:   jcstoken.DefaultMaxStringBytes,
+		MaxNumberChars:   jcstoken.DefaultMaxNumberChars,
+		MaxOutputSize:    jcstoken.DefaultMaxOutputSize,
+	},
+	"bulk": {
+		MaxDepth:         jcstoken.DefaultMaxDepth,
+		MaxInputSize:     1024 * 1024 * 1024,
+		MaxValues:        20_000_000,
+		MaxObjectMembers: 5_000_000,
+		MaxArrayElements: 5_000_000,
+		MaxStringBytes:   256 * 1024 * 1024,
+		MaxNumberChars:   jcstoken.DefaultMaxNumberChars,
+		MaxOutputSize:    1536 * 1024 * 1024,
+	},
+}
+
+// boundPresetNames lists the keys of boundPresets from smallest to largest.
+var boundPresetNames = []string{"api-small", defaultBoundsPreset, "bulk"}
+
+// maxBound is the largest accepted bound: 2^53-1, the largest integer a
+// bounds file can hold exactly, or the largest int on 32-bit platforms.
+const maxBound = min(1<<53-1, math.MaxInt)
+
+// maxBoundsFileSize limits the size of a --bounds-file profile.
+const maxBoundsFileSize = 64 * 1024
+
+// boundsUsage is the usage synopsis of the bound options, built from
+// boundPresetNames and boundOptions so that every command's usage line lists
+// the same options.
+var boundsUsage = func() string {
+	parts := []string{"[--bounds " + strings.Join(boundPresetNames, "|") + "]", "[--bounds-file file]"}
+	for _, b := range boundOptions {
+		parts = append(parts, "["+b.flag+" n]")
+	}
+	return strings.Join(parts, " ")
+}()
+
+// boundsHelp describes the bound options in command help.
+var boundsHelp = []string{
+	"  --bounds preset      Start from bound preset api-small, default (default), or bulk",
+	"  --bounds-file file   Override the preset with the bounds in a canonical JSON profile",
+	"  --max-depth n        Override the maximum nesting depth",
+	"  --max-input-size n   Override the maximum input size in bytes",
+	"  --max-values n       Override the maximum number of JSON values",
+	"  --max-object-members n",
+	"                       Override the maximum number of members per object",
+	"  --max-array-elements n",
+	"                       Override the maximum number of elements per array",
+	"  --max-string-bytes n Override the maximum decoded bytes per string",
+	"  --max-number-chars n Override the maximum characters per number token",
+	"  --max-output-size n  Override the maximum output size in bytes",
+}
+
+// resolveBounds returns the parser bounds selected by fl: the --bounds
+// preset, then the bounds in --bounds-file, then each --max-* option.
+//
+// CLI-BOUNDS-001: Every jcstoken.Options bound has a command-line option.
+func resolveBounds(fl *flags) (jcstoken.Options, error) {
+	name := fl.bounds
+	if name == "" {
+		name = defaultBoundsPreset
+	}
+	opts, ok := boundPresets[name]
+	if !ok {
+		return opts, jcserr.New(jcserr.CLIUsage, -1, fmt.Sprintf("unsupported --bounds: %s", name))
+	}
+	if fl.boundsFile != "" {
+		if err := applyBoundsFile(&opts, fl.boundsFile); err != nil {
+			return opts, err
+		}
+	}
+	return opts, applyBoundFlags(&opts, fl.limits)
+}
+
+// applyBoundFlags sets the bounds given as --max-* options in limits.
+func applyBoundFlags(opts *jcstoken.Options, limits map[string]string) error {
+	for _, b := range boundOptions {
+		raw, set := limits[b.flag]
+		if !set {
+			continue
+		}
+		n, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil || n < 1 || n > maxBound {
+			return jcserr.New(jcserr.CLIUsage, -1, fmt.Sprintf("invalid %s: %s", b.flag, raw))
+		}
+		*b.field(opts) = int(n)
+	}
+	return nil
+}
+
+// applyBoundsFile sets the bounds named in the profile at name. The profile
+// is a JSON object in RFC 8785 canonical form whose members are bound keys
+// with integer values from 1 to 2^53-1; bounds it omits keep their value.
+//
+// CLI-BOUNDS-003: --bounds-file reads a canonical JSON bounds profile.
+func applyBoundsFile(opts *jcstoken.Options, name string) error {
+	data, release, err := loadInput([]string{name}, nil, maxBoundsFileSize)
+	if err != nil {
+		return err
+	}
+	defer release()
+	v, err := jcstoken.Parse(data)
+	if err != nil {
+		return jcserr.Wrap(jcserr.CLIUsage, -1, fmt.Sprintf("invalid --bounds-file %q", name), err)
+	}
+	canonical, err := jcs.Serialize(v)
+	if err != nil || !bytes.Equal(data, canonical) {
+		return jcserr.New(jcserr.CLIUsage, -1, fmt.Sprintf("--bounds-file %q is not canonical JSON", name))
+	}
+	if v.Kind != jcstoken.KindObject {
+		return jcserr.New(jcserr.CLIUsage, -1, fmt.Sprintf("--bounds-file %q must hold a JSON object", name))
+	}
+	for _, m := range v.Members {
+		if err := applyBoundMember(opts, name, m); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// applyBoundMember sets the bound named by member m of the profile at name.
+func applyBoundMember(opts *jcstoken.Options, name string, m jcstoken.Member) error {
+	for _, b := range boundOptions {
+		if b.key != m.Key {
+			continue
+		}
+		n := m.Value.Num
+		if m.Value.Kind != jcstoken.KindNumber || n != math.Trunc(n) || n < 1 || n > maxBound {
+			return jcserr.New(jcserr.CLIUsage, -1, fmt.Sprintf("--bounds-file %q: %s must be an integer from 1 to 2^53-1", name, m.Key))
+		}
+		*b.field(opts) = int(n)
+		return nil
+	}
+	return jcserr.New(jcserr.CLIUsage, -1, fmt.Sprintf("--bounds-file %q: unknown bound %q", name, m.Key))
+}
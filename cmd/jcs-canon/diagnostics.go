@@ -0,0 +1,169 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/lattice-substrate/json-canon/jcs"
+	"github.com/lattice-substrate/json-canon/jcserr"
+	"github.com/lattice-substrate/json-canon/jcstoken"
+)
+
+// diagnostics reports a command's failure on stderr in its --error-format.
+// Once the command has read its input, line and column are resolved against
+// it.
+type diagnostics struct {
+	stderr io.Writer
+	json   bool
+	input  []byte
+}
+
+// newDiagnostics selects the error format from the command's raw arguments,
+// so that failures while parsing the remaining flags are reported in it too.
+// Anything but an explicit --error-format json reports text; parseFlags
+// rejects values other than text and json.
+func newDiagnostics(stderr io.Writer, args []string) *diagnostics {
+	format := ""
+	for i := 0; i < len(args); i++ {
+		name, inline, hasInline := splitOption(args[i])
+		if name != "--error-format" {
+			continue
+		}
+		switch {
+		case hasInline:
+			format = inline
+		case i+1 < len(args):
+			i++
+			format = args[i]
+		}
+	}
+	return &diagnostics{stderr: stderr, json: format == "json"}
+}
+
+// fail writes err to stderr and returns the exit code of its class.
+func (d *diagnostics) fail(err error) int {
+	if !d.json {
+		return writeClassifiedError(d.stderr, err)
+	}
+	return writeJSONError(d.stderr, "", err, d.input)
+}
+
+// report writes the failure of one file of a batch, located in its input,
+// and returns the exit code of its class.
+func (d *diagnostics) report(file string, err error, input []byte) int {
+	if !d.json {
+		return writeFileError(d.stderr, file, err)
+	}
+	return writeJSONError(d.stderr, file, err, input)
+}
+
+// writeFileError writes err as text prefixed by the name of the file it
+// concerns.
+//
+// CLI-BATCH-004: Per-file failures name the file and its failure class.
+func writeFileError(stderr io.Writer, file string, err error) int {
+	code := jcserr.InternalError.ExitCode()
+	var je *jcserr.Error
+	if errors.As(err, &je) {
+		err, code = je, je.Class.ExitCode()
+	}
+	return writeErrorAndReturn(stderr, code, "%s: error: %v\n", file, err)
+}
+
+// checkErrorFormat rejects --error-format values other than text and json.
+func checkErrorFormat(format string) error {
+	switch format {
+	case "", "text", "json":
+		return nil
+	default:
+		return jcserr.New(jcserr.CLIUsage, -1, fmt.Sprintf("unsupported --error-format: %s", format))
+	}
+}
+
+// writeJSONError writes err as one canonical JSON object and a newline,
+// naming file when it is not empty. If the object cannot be serialized, the
+// text form is written instead.
+//
+// CLI-ERRFMT-001: JSON error output is one canonical object per failure.
+func writeJSONError(stderr io.Writer, file string, err error, input []byte) int {
+	var je *jcserr.Error
+	if !errors.As(err, &je) {
+		je = jcserr.New(jcserr.InternalError, -1, err.Error())
+	}
+	v := errorReport(err, je, input)
+	if file != "" {
+		v.Members = append(v.Members, jcstoken.Member{Key: "file", Value: reportString(file)})
+	}
+	report, serErr := jcs.Serialize(v)
+	if serErr != nil {
+		if file != "" {
+			return writeFileError(stderr, file, err)
+		}
+		return writeClassifiedError(stderr, err)
+	}
+	if writeErr := writef(stderr, "%s\n", report); writeErr != nil {
+		return jcserr.InternalIO.ExitCode()
+	}
+	return je.Class.ExitCode()
+}
+
+// errorReport builds the JSON error object for je, the classified error in
+// err. An unknown offset and a missing cause are null. pointer names the
+// string holding a failing embedded document and embedded_offset the byte
+// offset of the failure in its decoded value, null when unknown; line and
+// column (1-based, the column in bytes) locate a known offset in input.
+func errorReport(err error, je *jcserr.Error, input []byte) *jcstoken.Value {
+	report := &jcstoken.Value{Kind: jcstoken.KindObject}
+	add := func(key string, v jcstoken.Value) {
+		report.Members = append(report.Members, jcstoken.Member{Key: key, Value: v})
+	}
+	offset := jcstoken.Value{Kind: jcstoken.KindNull}
+	if je.Offset >= 0 {
+		offset = reportNumber(je.Offset)
+	}
+	cause := jcstoken.Value{Kind: jcstoken.KindNull}
+	if je.Cause != nil {
+		cause = reportString(je.Cause.Error())
+	}
+	add("class", reportString(string(je.Class)))
+	add("exit_code", reportNumber(je.Class.ExitCode()))
+	add("offset", offset)
+	add("message", reportString(je.Message))
+	add("cause", cause)
+	var ee *jcs.EmbeddedJSONError
+	if errors.As(err, &ee) {
+		add("pointer", reportString(ee.Pointer))
+		embeddedOffset := jcstoken.Value{Kind: jcstoken.KindNull}
+		if ee.Err.Offset >= 0 {
+			embeddedOffset = reportNumber(ee.Err.Offset)
+		}
+		add("embedded_offset", embeddedOffset)
+	}
+	if input != nil && je.Offset >= 0 && je.Offset <= len(input) {
+		line, column := lineColumn(input, je.Offset)
+		add("line", reportNumber(line))
+		add("column", reportNumber(column))
+	}
+	return report
+}
+
+// lineColumn returns the 1-based line and byte column of offset in input.
+// Lines end at line feeds.
+func lineColumn(input []byte, offset int) (int, int) {
+	before := input[:offset]
+	lineStart := bytes.LastIndexByte(before, '\n') + 1
+	return bytes.Count(before, []byte{'\n'}) + 1, offset - lineStart + 1
+}
+
+// reportString returns a string value, replacing invalid UTF-8 so that
+// diagnostics about malformed input or file names stay serializable.
+func reportString(s string) jcstoken.Value {
+	return jcstoken.Value{Kind: jcstoken.KindString, Str: strings.ToValidUTF8(s, "\uFFFD")}
+}
+
+func reportNumber(n int) jcstoken.Value {
+	return jcstoken.Value{Kind: jcstoken.KindNumber, Num: float64(n)}
+}
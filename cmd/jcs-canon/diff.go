@@ -0,0 +1,178 @@
+package main
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/lattice-substrate/json-canon/jcs"
+	"github.com/lattice-substrate/json-canon/jcserr"
+	"github.com/lattice-substrate/json-canon/jcstoken"
+)
+
+// exitDifferent is the exit code of diff when the documents are not
+// canonically equal.
+const exitDifferent = 1
+
+// cmdDiff compares two JSON documents and writes the RFC 6902 patch from the
+// first to the second, or a summary of it.
+//
+// CLI-DIFF-001: diff exits 0 for canonically equal documents and 1 otherwise.
+func cmdDiff(args []string, stdin io.Reader, stdout io.Writer, stderr io.Writer) int {
+	diag := newDiagnostics(stderr, args)
+	fl, positional, err := parseFlags("diff", args)
+	if err != nil {
+		return diag.fail(err)
+	}
+
+	// CLI-FLAG-003: subcommand --help writes to stdout (frozen stream policy).
+	if fl.help {
+		helpErr := writeDiffHelp(stdout)
+		if helpErr != nil {
+			return diag.fail(jcserr.Wrap(jcserr.InternalIO, -1, "write diff help output", helpErr))
+		}
+		return 0
+	}
+
+	if err := checkDiffArgs(fl.format, positional); err != nil {
+		return diag.fail(err)
+	}
+	bounds, err := resolveBounds(&fl)
+	if err != nil {
+		return diag.fail(err)
+	}
+	var docs [2]*jcstoken.Value
+	for i, name := range positional {
+		var code int
+		docs[i], code = readDocument(name, stdin, &bounds, diag)
+		if docs[i] == nil {
+			return code
+		}
+	}
+	patch, err := jcs.DiffWithOptions(docs[0], docs[1], &bounds)
+	if err != nil {
+		return diag.fail(err)
+	}
+	return reportDiff(patch, &fl, docs[0], &bounds, stdout, diag)
+}
+
+// checkDiffArgs validates --format and requires two inputs, at most one of
+// them standard input.
+func checkDiffArgs(format string, positional []string) error {
+	switch format {
+	case "", "patch", "summary":
+	default:
+		return jcserr.New(jcserr.CLIUsage, -1, fmt.Sprintf("unsupported --format: %s", format))
+	}
+	if len(positional) != 2 {
+		return jcserr.New(jcserr.CLIUsage, -1, "diff requires exactly two inputs")
+	}
+	if positional[0] == "-" && positional[1] == "-" {
+		return jcserr.New(jcserr.CLIUsage, -1, "diff reads standard input at most once")
+	}
+	return nil
+}
+
+// reportDiff writes patch unless --quiet and returns the exit code of the
+// comparison.
+func reportDiff(patch jcs.Patch, fl *flags, a *jcstoken.Value, bounds *jcstoken.Options, stdout io.Writer, diag *diagnostics) int {
+	if !fl.quiet {
+		if err := writeDiff(stdout, fl.format, patch, a, bounds); err != nil {
+			return diag.fail(err)
+		}
+	}
+	if len(patch) > 0 {
+		return exitDifferent
+	}
+	return 0
+}
+
+// writeDiff writes patch in format: the canonical JSON patch, or for
+// "summary" one line per operation.
+//
+// CLI-DIFF-002: The summary names each changed value by JSON Pointer.
+func writeDiff(w io.Writer, format string, patch jcs.Patch, a *jcstoken.Value, bounds *jcstoken.Options) error {
+	if format != "summary" {
+		out, err := patch.SerializeWithOptions(bounds)
+		if err != nil {
+			return err //nolint:wrapcheck // CLI-DIFF-001: serialization errors are already classified.
+		}
+		if _, err := w.Write(out); err != nil {
+			return jcserr.Wrap(jcserr.InternalIO, -1, "writing output", err)
+		}
+		return nil
+	}
+	for _, op := range patch {
+		line, err := summaryLine(op, a, bounds)
+		if err != nil {
+			return err
+		}
+		if err := writeLine(w, line); err != nil {
+			return jcserr.Wrap(jcserr.InternalIO, -1, "writing output", err)
+		}
+	}
+	return nil
+}
+
+// summaryLine describes op as "+", "-", or "~", the quoted pointer, and the
+// values involved. The paths of remove and replace operations produced by
+// jcs.Diff also resolve in a, which holds their old values.
+func summaryLine(op jcs.Operation, a *jcstoken.Value, bounds *jcstoken.Options) (string, error) {
+	path, err := jcs.Serialize(&jcstoken.Value{Kind: jcstoken.KindString, Str: op.Path})
+	if err != nil {
+		return "", err //nolint:wrapcheck // CLI-DIFF-002: serialization errors are already classified.
+	}
+	var value, old []byte
+	if op.Value != nil {
+		if value, err = jcs.SerializeWithOptions(op.Value, bounds); err != nil {
+			return "", err //nolint:wrapcheck // CLI-DIFF-002: serialization errors are already classified.
+		}
+	}
+	if op.Op != "add" {
+		if old, err = oldValue(op.Path, a, bounds); err != nil {
+			return "", err
+		}
+	}
+	switch op.Op {
+	case "add":
+		return fmt.Sprintf("+ %s: %s", path, value), nil
+	case "remove":
+		return fmt.Sprintf("- %s: %s", path, old), nil
+	default:
+		return fmt.Sprintf("~ %s: %s -> %s", path, old, value), nil
+	}
+}
+
+// oldValue returns the canonical bytes of the value at path in a.
+func oldValue(path string, a *jcstoken.Value, bounds *jcstoken.Options) ([]byte, error) {
+	ptr, err := jcstoken.ParsePointer(path)
+	if err != nil {
+		return nil, err //nolint:wrapcheck // CLI-DIFF-002: pointer errors are already classified.
+	}
+	v, err := ptr.Resolve(a)
+	if err != nil {
+		return nil, err //nolint:wrapcheck // CLI-DIFF-002: pointer errors are already classified.
+	}
+	out, err := jcs.SerializeWithOptions(v, bounds)
+	if err != nil {
+		return nil, err //nolint:wrapcheck // CLI-DIFF-002: serialization errors are already classified.
+	}
+	return out, nil
+}
+
+func writeDiffHelp(w io.Writer) error {
+	lines := []string{
+		"usage: jcs-canon diff [--format patch|summary] [--quiet] [--error-format text|json] " + boundsUsage + " a b",
+		"  Compare two JSON documents and write the RFC 6902 JSON Patch turning the first into the second.",
+		"  Exit 0 when the documents are canonically equal and 1 when they differ.",
+		"  --format f           Write the canonical JSON patch (patch, default) or one line per change (summary)",
+		"  --quiet              Write nothing; report the result by exit code only",
+		"  --error-format f     Report errors on stderr as text (default) or as one canonical JSON object",
+	}
+	lines = append(lines, boundsHelp...)
+	for _, line := range lines {
+		if err := writeLine(w, line); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,94 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/lattice-substrate/json-canon/jcs"
+	"github.com/lattice-substrate/json-canon/jcserr"
+)
+
+// maxIndent is the largest number of spaces --indent accepts.
+const maxIndent = 8
+
+// cmdFmt writes the canonical form of the input indented for reading.
+//
+// CLI-FMT-001: fmt writes the jcs.Format view of the input.
+func cmdFmt(args []string, stdin io.Reader, stdout io.Writer, stderr io.Writer) int {
+	diag := newDiagnostics(stderr, args)
+	fl, positional, err := parseFlags("fmt", args)
+	if err != nil {
+		return diag.fail(err)
+	}
+
+	// CLI-FLAG-003: subcommand --help writes to stdout (frozen stream policy).
+	if fl.help {
+		helpErr := writeFmtHelp(stdout)
+		if helpErr != nil {
+			return diag.fail(jcserr.Wrap(jcserr.InternalIO, -1, "write fmt help output", helpErr))
+		}
+		return 0
+	}
+
+	if err := ensureSingleInput(positional); err != nil {
+		return diag.fail(err)
+	}
+	indent, err := parseIndent(fl.indent)
+	if err != nil {
+		return diag.fail(err)
+	}
+	bounds, err := resolveBounds(&fl)
+	if err != nil {
+		return diag.fail(err)
+	}
+	name := "-"
+	if len(positional) == 1 {
+		name = positional[0]
+	}
+	v, code := readDocument(name, stdin, &bounds, diag)
+	if v == nil {
+		return code
+	}
+	output, err := jcs.FormatWithOptions(v, indent, &bounds)
+	if err != nil {
+		return diag.fail(err)
+	}
+	if _, err := stdout.Write(output); err != nil {
+		return diag.fail(jcserr.Wrap(jcserr.InternalIO, -1, "writing output", err))
+	}
+	return 0
+}
+
+// parseIndent returns the indentation --indent selects: "tab" for one tab,
+// or a number of spaces from 0 to maxIndent; the default is two spaces.
+func parseIndent(value string) (string, error) {
+	switch value {
+	case "":
+		return "  ", nil
+	case "tab":
+		return "\t", nil
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil || n < 0 || n > maxIndent || strconv.Itoa(n) != value {
+		return "", jcserr.New(jcserr.CLIUsage, -1, fmt.Sprintf("invalid --indent: %s (want 0 to %d or tab)", value, maxIndent))
+	}
+	return strings.Repeat(" ", n), nil
+}
+
+func writeFmtHelp(w io.Writer) error {
+	lines := []string{
+		"usage: jcs-canon fmt [--indent n|tab] [--error-format text|json] " + boundsUsage + " [file|-]",
+		"  Emit the canonical form of file (or stdin) indented for reading; canonicalizing the output yields the canonical bytes.",
+		fmt.Sprintf("  --indent n|tab       Indent each level by n spaces (0 to %d, default 2) or one tab", maxIndent),
+		"  --error-format f     Report errors on stderr as text (default) or as one canonical JSON object",
+	}
+	lines = append(lines, boundsHelp...)
+	for _, line := range lines {
+		if err := writeLine(w, line); err != nil {
+			return err
+		}
+	}
+	return nil
+}
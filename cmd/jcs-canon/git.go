@@ -0,0 +1,233 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/lattice-substrate/json-canon/jcs"
+	"github.com/lattice-substrate/json-canon/jcserr"
+	"github.com/lattice-substrate/json-canon/jcsgit"
+	"github.com/lattice-substrate/json-canon/jcstoken"
+)
+
+// cmdGitClean is a git clean filter: it writes the canonical bytes of
+// standard input, or fails naming the file git passed as %f.
+//
+// CLI-GIT-001: git-clean canonicalizes standard input and fails on invalid
+// input.
+func cmdGitClean(args []string, stdin io.Reader, stdout io.Writer, stderr io.Writer) int {
+	return runGit("git-clean", args, stdin, stdout, stderr)
+}
+
+// cmdGitFilterProcess serves the git long-running filter process protocol,
+// canonicalizing every file git cleans.
+//
+// CLI-GIT-002: git-filter-process answers each file with its canonical bytes
+// or an error status.
+func cmdGitFilterProcess(args []string, stdin io.Reader, stdout io.Writer, stderr io.Writer) int {
+	return runGit("git-filter-process", args, stdin, stdout, stderr)
+}
+
+// cmdGitTextconv is a git textconv driver: it writes the canonical form of
+// a file indented for reading.
+//
+// CLI-GIT-003: git-textconv writes canonical JSON indented for diffs, as
+// jcs.Format lays it out.
+func cmdGitTextconv(args []string, stdin io.Reader, stdout io.Writer, stderr io.Writer) int {
+	return runGit("git-textconv", args, stdin, stdout, stderr)
+}
+
+// cmdGitPreCommit verifies the staged content of the staged JSON files of
+// the repository in the working directory, as verify does for files.
+//
+// CLI-GIT-004: git-pre-commit verifies only staged files, as staged.
+func cmdGitPreCommit(args []string, stdin io.Reader, stdout io.Writer, stderr io.Writer) int {
+	return runGit("git-pre-commit", args, stdin, stdout, stderr)
+}
+
+// runGit implements cmd, one of the git integration commands.
+func runGit(cmd string, args []string, stdin io.Reader, stdout io.Writer, stderr io.Writer) int {
+	diag := newDiagnostics(stderr, args)
+	fl, positional, err := parseFlags(cmd, args)
+	if err != nil {
+		return diag.fail(err)
+	}
+
+	// CLI-FLAG-003: subcommand --help writes to stdout (frozen stream policy).
+	if fl.help {
+		helpErr := writeGitHelp(stdout, cmd)
+		if helpErr != nil {
+			return diag.fail(jcserr.Wrap(jcserr.InternalIO, -1, fmt.Sprintf("write %s help output", cmd), helpErr))
+		}
+		return 0
+	}
+
+	bounds, err := resolveBounds(&fl)
+	if err != nil {
+		return diag.fail(err)
+	}
+	switch cmd {
+	case "git-clean":
+		return gitClean(positional, &bounds, stdin, stdout, diag)
+	case "git-filter-process":
+		return gitFilterProcess(positional, &bounds, stdin, stdout, diag)
+	case "git-textconv":
+		return gitTextconv(positional, &bounds, stdin, stdout, diag)
+	default:
+		return gitPreCommit(&fl, positional, &bounds, stdout, diag)
+	}
+}
+
+func gitClean(positional []string, bounds *jcstoken.Options, stdin io.Reader, stdout io.Writer, diag *diagnostics) int {
+	if len(positional) > 1 {
+		return diag.fail(jcserr.New(jcserr.CLIUsage, -1, "git-clean takes at most one path"))
+	}
+	name := "-"
+	if len(positional) == 1 {
+		name = positional[0]
+	}
+	input, err := readBounded(stdin, bounds.MaxInputSize)
+	if err != nil {
+		return diag.report(name, err, nil)
+	}
+	canonical, err := jcs.CanonicalizeWithOptions(input, bounds)
+	if err != nil {
+		return diag.report(name, err, input)
+	}
+	if _, err := stdout.Write(canonical); err != nil {
+		return diag.fail(jcserr.Wrap(jcserr.InternalIO, -1, "writing output", err))
+	}
+	return 0
+}
+
+// gitFilterProcess serves git until it closes standard input. Files that
+// fail are reported on stderr and answered with an error status; git
+// decides whether that fails the command.
+func gitFilterProcess(positional []string, bounds *jcstoken.Options, stdin io.Reader, stdout io.Writer, diag *diagnostics) int {
+	if len(positional) > 0 {
+		return diag.fail(jcserr.New(jcserr.CLIUsage, -1, "git-filter-process takes no inputs"))
+	}
+	f := &jcsgit.Filter{
+		Clean: func(_ string, content []byte) ([]byte, error) {
+			return jcs.CanonicalizeWithOptions(content, bounds) //nolint:wrapcheck // CLI-GIT-002: canonicalization errors are already classified.
+		},
+		Report: func(pathname string, err error, content []byte) {
+			diag.report(pathname, err, content)
+		},
+		MaxContentSize: bounds.MaxInputSize,
+	}
+	if err := f.Serve(stdin, stdout); err != nil {
+		return diag.fail(err)
+	}
+	return 0
+}
+
+// gitTextconv writes a file that is not valid JSON unchanged, with its
+// diagnostic on stderr, so that git can still show revisions that predate
+// the filter.
+func gitTextconv(positional []string, bounds *jcstoken.Options, stdin io.Reader, stdout io.Writer, diag *diagnostics) int {
+	if len(positional) != 1 {
+		return diag.fail(jcserr.New(jcserr.CLIUsage, -1, "git-textconv requires exactly one input"))
+	}
+	input, release, err := loadInput(positional, stdin, bounds.MaxInputSize)
+	if err != nil {
+		return diag.fail(err)
+	}
+	defer release()
+	var output []byte
+	v, err := jcstoken.ParseWithOptions(input, bounds)
+	if err == nil {
+		output, err = jcs.FormatWithOptions(v, "  ", bounds)
+	}
+	if err != nil {
+		output = input
+		diag.report(positional[0], err, input)
+	}
+	if _, err := stdout.Write(output); err != nil {
+		return diag.fail(jcserr.Wrap(jcserr.InternalIO, -1, "writing output", err))
+	}
+	return 0
+}
+
+// gitPreCommit reads the staged paths and content from git in the working
+// directory, which is the top of the working tree when git runs a hook.
+func gitPreCommit(fl *flags, positional []string, bounds *jcstoken.Options, stdout io.Writer, diag *diagnostics) int {
+	if len(positional) > 0 {
+		return diag.fail(jcserr.New(jcserr.CLIUsage, -1, "git-pre-commit takes no inputs"))
+	}
+	canonical := func(input []byte) ([]byte, error) { return canonicalBytes(input, bounds) }
+	b, err := newBatch("git-pre-commit", fl, bounds.MaxInputSize, canonical)
+	if err != nil {
+		return diag.fail(err)
+	}
+	ctx := context.Background()
+	paths, err := jcsgit.StagedPaths(ctx, ".")
+	if err != nil {
+		return diag.fail(err)
+	}
+	b.read = func(name string) ([]byte, func(), error) {
+		data, err := jcsgit.ReadStaged(ctx, ".", name, bounds.MaxInputSize)
+		return data, func() {}, err //nolint:wrapcheck // CLI-GIT-004: staged reads are already classified.
+	}
+	var entries []batchEntry
+	for _, p := range paths {
+		if b.selects(p) {
+			entries = append(entries, batchEntry{path: p})
+		}
+	}
+	return finishBatch(b, b.run(entries), fl.quiet, stdout, diag, func() error { return nil })
+}
+
+// selects reports whether a path relative to the top of the working tree
+// is one that verify would check when walking the tree: no component
+// starts with a dot, neither it nor a parent directory matches an exclude
+// glob, and it matches an include glob.
+func (b *batch) selects(p string) bool {
+	parts := strings.Split(p, "/")
+	for i, part := range parts {
+		if strings.HasPrefix(part, ".") || b.matches(b.exclude, ".", strings.Join(parts[:i+1], "/")) {
+			return false
+		}
+	}
+	return b.matches(b.include, ".", p)
+}
+
+func writeGitHelp(w io.Writer, cmd string) error {
+	var lines []string
+	switch cmd {
+	case "git-clean":
+		lines = []string{
+			"usage: jcs-canon git-clean [--error-format text|json] " + boundsUsage + " [path]",
+			"  Git clean filter: emit the canonical bytes of stdin to stdout; failures name path (git's %f).",
+		}
+	case "git-filter-process":
+		lines = []string{
+			"usage: jcs-canon git-filter-process [--error-format text|json] " + boundsUsage,
+			"  Git long-running filter process (filter.<driver>.process): canonicalize each file git cleans.",
+		}
+	case "git-textconv":
+		lines = []string{
+			"usage: jcs-canon git-textconv [--error-format text|json] " + boundsUsage + " file|-",
+			"  Git textconv driver: emit the canonical form of file indented for reading; invalid JSON is emitted unchanged.",
+		}
+	default:
+		lines = []string{
+			"usage: jcs-canon git-pre-commit [--quiet] [--error-format text|json] [--jobs n] [--include-glob glob]... [--exclude-glob glob]... " + boundsUsage,
+			"  Pre-commit hook: verify the staged content of the staged JSON files of the repository in the working directory.",
+			"  -q, --quiet          Suppress the summary line",
+			"  -j, --jobs n         Verify with n parallel workers (default: number of CPUs)",
+			"  --include-glob glob  Verify staged files matching glob (repeatable; default *.json)",
+			"  --exclude-glob glob  Skip staged files and directories matching glob (repeatable)",
+		}
+	}
+	lines = append(lines, "  --error-format f     Report errors on stderr as text (default) or as one canonical JSON object")
+	lines = append(lines, boundsHelp...)
+	for _, line := range lines {
+		if err := writeLine(w, line); err != nil {
+			return err
+		}
+	}
+	return nil
+}
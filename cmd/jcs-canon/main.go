@@ -2,12 +2,55 @@
 //
 // Stable ABI:
 //
-//	jcs-canon canonicalize [--quiet] [file|-]
-//	jcs-canon verify [--quiet] [file|-]
+//	jcs-canon canonicalize [--quiet] [--error-format text|json] [-l] [--write] [--jobs n] [--include-glob glob]... [--exclude-glob glob]... [bound options] [--input-syntax json|jsonc|json5] [--input-encoding utf-8|auto|utf-16le|utf-16be|utf-32le|utf-32be] [--scheme rfc8785|olpc|matrix|decimal] [--exclude ptr]... [--exclude-name name]... [--include ptr]... [--set-pointer ptr]... [--set-path query]... [--set-duplicates keep|remove|reject] [--embedded-json ptr]... [--embedded-json-detect] [file|-|path...]
+//	jcs-canon verify [--quiet] [--error-format text|json] [-l] [--jobs n] [--include-glob glob]... [--exclude-glob glob]... [bound options] [file|-|path...]
+//	jcs-canon convert (--to cbor|--from cbor) [--error-format text|json] [bound options] [file|-]
+//	jcs-canon diff [--format patch|summary] [--quiet] [--error-format text|json] [bound options] a b
+//	jcs-canon patch [--error-format text|json] [bound options] patch [file|-]
+//	jcs-canon merge-patch [--error-format text|json] [bound options] patch [file|-]
+//	jcs-canon fmt [--indent n|tab] [--error-format text|json] [bound options] [file|-]
+//	jcs-canon manifest create [--error-format text|json] [--jobs n] [--include-glob glob]... [--exclude-glob glob]... [bound options] dir
+//	jcs-canon manifest verify [--quiet] [--error-format text|json] [--jobs n] [--include-glob glob]... [--exclude-glob glob]... [bound options] manifest|- dir
+//	jcs-canon serve (--socket path|--listen addr:port) [--jobs n] [--error-format text|json] [bound options]
+//	jcs-canon git-clean [--error-format text|json] [bound options] [path]
+//	jcs-canon git-filter-process [--error-format text|json] [bound options]
+//	jcs-canon git-textconv [--error-format text|json] [bound options] file|-
+//	jcs-canon git-pre-commit [--quiet] [--error-format text|json] [--jobs n] [--include-glob glob]... [--exclude-glob glob]... [bound options]
 //	jcs-canon --help
 //	jcs-canon --version
 //
-// Exit codes: 0 (success), 2 (input/profile/non-canonical/usage), 10 (internal/IO).
+// where [bound options] is
+//
+//	[--bounds api-small|default|bulk] [--bounds-file file] [--max-depth n] [--max-input-size n] [--max-values n] [--max-object-members n] [--max-array-elements n] [--max-string-bytes n] [--max-number-chars n] [--max-output-size n]
+//
+// Exit codes: 0 (success), 1 (diff: documents differ), 2 (input/profile/non-canonical/usage), 10 (internal/IO).
+//
+// With --error-format json, a failing command writes one canonical JSON
+// object describing the error to stderr instead of the "error: " text line.
+//
+// verify with several paths, a directory, or -l, and canonicalize with -l or
+// --write, process files in batch: directories are walked recursively, -l
+// lists files that are not canonical, and --write rewrites them in place.
+//
+// Every command enforces the parser bounds of the --bounds preset (default
+// unless given), overridden by a --bounds-file profile and then by the
+// --max-* options.
+//
+// fmt writes the canonical form indented for reading; canonicalizing its
+// output yields the canonical bytes.
+//
+// manifest create writes a canonical JSON manifest of the SHA-256 digest of
+// the canonical form of each file under a directory, and manifest verify
+// lists the files added, missing, or changed since; reformatting a file
+// does not change its digest.
+//
+// serve answers canonicalize, verify, and digest requests over HTTP on a
+// Unix domain socket or loopback address until SIGINT or SIGTERM; see
+// package jcsserve.
+//
+// git-clean, git-filter-process, and git-textconv are git clean, filter
+// process, and textconv drivers, and git-pre-commit verifies the staged JSON
+// files from a pre-commit hook; see package jcsgit.
 package main
 
 import (
@@ -20,6 +63,7 @@ import (
 	"strings"
 
 	"github.com/lattice-substrate/json-canon/jcs"
+	"github.com/lattice-substrate/json-canon/jcscbor"
 	"github.com/lattice-substrate/json-canon/jcserr"
 	"github.com/lattice-substrate/json-canon/jcstoken"
 )
@@ -29,7 +73,8 @@ func main() {
 }
 
 //nolint:cyclop // REQ:CLI-CMD-001 top-level CLI dispatch is explicit to preserve stable ABI behavior.
-func run(args []string, stdin io.Reader, stdout io.Writer, stderr io.Writer) int {
+func run(args []string, stdin io.Reader, stdout io.Writer, stderr io.Writer) (code int) {
+	defer guardMapped(func(err error) { code = newDiagnostics(stderr, args).fail(err) })()
 	if len(args) == 0 {
 		// CLI-EXIT-001
 		code := writeClassifiedError(stderr, jcserr.New(jcserr.CLIUsage, -1, "no command specified"))
@@ -54,6 +99,28 @@ func run(args []string, stdin io.Reader, stdout io.Writer, stderr io.Writer) int
 		return cmdCanonicalize(args[1:], stdin, stdout, stderr)
 	case "verify":
 		return cmdVerify(args[1:], stdin, stdout, stderr)
+	case "convert":
+		return cmdConvert(args[1:], stdin, stdout, stderr)
+	case "diff":
+		return cmdDiff(args[1:], stdin, stdout, stderr)
+	case "patch":
+		return cmdPatch(args[1:], stdin, stdout, stderr)
+	case "merge-patch":
+		return cmdMergePatch(args[1:], stdin, stdout, stderr)
+	case "fmt":
+		return cmdFmt(args[1:], stdin, stdout, stderr)
+	case "manifest":
+		return cmdManifest(args[1:], stdin, stdout, stderr)
+	case "serve":
+		return cmdServe(args[1:], stdin, stdout, stderr)
+	case "git-clean":
+		return cmdGitClean(args[1:], stdin, stdout, stderr)
+	case "git-filter-process":
+		return cmdGitFilterProcess(args[1:], stdin, stdout, stderr)
+	case "git-textconv":
+		return cmdGitTextconv(args[1:], stdin, stdout, stderr)
+	case "git-pre-commit":
+		return cmdGitPreCommit(args[1:], stdin, stdout, stderr)
 	default:
 		// CLI-EXIT-002
 		code := writeClassifiedError(stderr, jcserr.New(jcserr.CLIUsage, -1, fmt.Sprintf("unknown command: %s", args[0])))
@@ -67,17 +134,77 @@ func run(args []string, stdin io.Reader, stdout io.Writer, stderr io.Writer) int
 type flags struct {
 	quiet bool
 	help  bool
+
+	errorFormat string
+
+	list         bool
+	write        bool
+	jobs         string
+	includeGlobs []string
+	excludeGlobs []string
+
+	bounds     string
+	boundsFile string
+	limits     map[string]string
+
+	projection jcs.Projection
+
+	to   string
+	from string
+
+	format string
+	indent string
+
+	socket string
+	listen string
+
+	inputSyntax   string
+	inputEncoding string
+	scheme        string
+
+	sets          jcs.SetArrays
+	setDuplicates string
+
+	embedded jcs.EmbeddedJSON
+}
+
+// commandFlags lists the options each command accepts. Any option outside a
+// command's list is rejected as unknown.
+var commandFlags = map[string][]string{
+	"canonicalize":       {"--quiet", "-q", "--help", "-h", "--error-format", "--list", "-l", "--write", "-w", "--jobs", "-j", "--include-glob", "--exclude-glob", "--bounds", "--bounds-file", "--max-depth", "--max-input-size", "--max-values", "--max-object-members", "--max-array-elements", "--max-string-bytes", "--max-number-chars", "--max-output-size", "--input-syntax", "--input-encoding", "--scheme", "--exclude", "--exclude-name", "--include", "--set-pointer", "--set-path", "--set-duplicates", "--embedded-json", "--embedded-json-detect"},
+	"verify":             {"--quiet", "-q", "--help", "-h", "--error-format", "--list", "-l", "--jobs", "-j", "--include-glob", "--exclude-glob", "--bounds", "--bounds-file", "--max-depth", "--max-input-size", "--max-values", "--max-object-members", "--max-array-elements", "--max-string-bytes", "--max-number-chars", "--max-output-size"},
+	"convert":            {"--help", "-h", "--error-format", "--bounds", "--bounds-file", "--max-depth", "--max-input-size", "--max-values", "--max-object-members", "--max-array-elements", "--max-string-bytes", "--max-number-chars", "--max-output-size", "--to", "--from"},
+	"diff":               {"--quiet", "-q", "--help", "-h", "--error-format", "--format", "--bounds", "--bounds-file", "--max-depth", "--max-input-size", "--max-values", "--max-object-members", "--max-array-elements", "--max-string-bytes", "--max-number-chars", "--max-output-size"},
+	"patch":              {"--help", "-h", "--error-format", "--bounds", "--bounds-file", "--max-depth", "--max-input-size", "--max-values", "--max-object-members", "--max-array-elements", "--max-string-bytes", "--max-number-chars", "--max-output-size"},
+	"merge-patch":        {"--help", "-h", "--error-format", "--bounds", "--bounds-file", "--max-depth", "--max-input-size", "--max-values", "--max-object-members", "--max-array-elements", "--max-string-bytes", "--max-number-chars", "--max-output-size"},
+	"fmt":                {"--help", "-h", "--error-format", "--indent", "--bounds", "--bounds-file", "--max-depth", "--max-input-size", "--max-values", "--max-object-members", "--max-array-elements", "--max-string-bytes", "--max-number-chars", "--max-output-size"},
+	"manifest":           {"--quiet", "-q", "--help", "-h", "--error-format", "--jobs", "-j", "--include-glob", "--exclude-glob", "--bounds", "--bounds-file", "--max-depth", "--max-input-size", "--max-values", "--max-object-members", "--max-array-elements", "--max-string-bytes", "--max-number-chars", "--max-output-size"},
+	"serve":              {"--help", "-h", "--error-format", "--jobs", "-j", "--socket", "--listen", "--bounds", "--bounds-file", "--max-depth", "--max-input-size", "--max-values", "--max-object-members", "--max-array-elements", "--max-string-bytes", "--max-number-chars", "--max-output-size"},
+	"git-clean":          {"--help", "-h", "--error-format", "--bounds", "--bounds-file", "--max-depth", "--max-input-size", "--max-values", "--max-object-members", "--max-array-elements", "--max-string-bytes", "--max-number-chars", "--max-output-size"},
+	"git-filter-process": {"--help", "-h", "--error-format", "--bounds", "--bounds-file", "--max-depth", "--max-input-size", "--max-values", "--max-object-members", "--max-array-elements", "--max-string-bytes", "--max-number-chars", "--max-output-size"},
+	"git-textconv":       {"--help", "-h", "--error-format", "--bounds", "--bounds-file", "--max-depth", "--max-input-size", "--max-values", "--max-object-members", "--max-array-elements", "--max-string-bytes", "--max-number-chars", "--max-output-size"},
+	"git-pre-commit":     {"--quiet", "-q", "--help", "-h", "--error-format", "--jobs", "-j", "--include-glob", "--exclude-glob", "--bounds", "--bounds-file", "--max-depth", "--max-input-size", "--max-values", "--max-object-members", "--max-array-elements", "--max-string-bytes", "--max-number-chars", "--max-output-size"},
 }
 
-func parseFlags(args []string) (flags, []string, error) {
+func parseFlags(cmd string, args []string) (flags, []string, error) {
 	var f flags
 	var positional []string
-	for _, arg := range args {
+	for i := 0; i < len(args); i++ {
+		arg, inline, hasInline := splitOption(args[i])
+		if err := checkCommandAccepts(cmd, arg); err != nil {
+			return flags{}, nil, err
+		}
 		switch arg {
-		case "--quiet", "-q":
-			f.quiet = true
-		case "--help", "-h":
-			f.help = true
+		case "--quiet", "-q", "--help", "-h", "--list", "-l", "--write", "-w", "--embedded-json-detect":
+			f.setBool(arg)
+		case "--error-format", "--jobs", "-j", "--include-glob", "--exclude-glob", "--bounds", "--bounds-file", "--max-depth", "--max-input-size", "--max-values", "--max-object-members", "--max-array-elements", "--max-string-bytes", "--max-number-chars", "--max-output-size", "--input-syntax", "--input-encoding", "--scheme", "--exclude", "--exclude-name", "--include", "--set-pointer", "--set-path", "--set-duplicates", "--embedded-json", "--to", "--from", "--format", "--indent", "--socket", "--listen":
+			value, next, err := optionValue(args, i, arg, inline, hasInline)
+			if err != nil {
+				return flags{}, nil, err
+			}
+			i = next
+			f.setValue(arg, value)
+			continue
 		case "-":
 			positional = append(positional, arg)
 		default:
@@ -87,100 +214,542 @@ func parseFlags(args []string) (flags, []string, error) {
 			}
 			positional = append(positional, arg)
 		}
+		if hasInline {
+			return flags{}, nil, jcserr.New(jcserr.CLIUsage, -1, fmt.Sprintf("option %s does not take a value", arg))
+		}
+	}
+	if err := checkErrorFormat(f.errorFormat); err != nil {
+		return flags{}, nil, err
 	}
 	return f, positional, nil
 }
 
+// splitOption separates "--name=value" into its name and inline value.
+func splitOption(raw string) (string, string, bool) {
+	if !strings.HasPrefix(raw, "--") {
+		return raw, "", false
+	}
+	return strings.Cut(raw, "=")
+}
+
+// checkCommandAccepts rejects options that exist for other commands only.
+//
+// CLI-FLAG-001: Options outside the command's flag set are unknown.
+func checkCommandAccepts(cmd, arg string) error {
+	if arg == "-" || !strings.HasPrefix(arg, "-") {
+		return nil
+	}
+	for _, name := range commandFlags[cmd] {
+		if name == arg {
+			return nil
+		}
+	}
+	return jcserr.New(jcserr.CLIUsage, -1, fmt.Sprintf("unknown option: %s", arg))
+}
+
+// optionValue returns the value of a value-taking option given either inline
+// ("--name=value") or as the following argument, and the index of the last
+// argument consumed.
+func optionValue(args []string, i int, name, inline string, hasInline bool) (string, int, error) {
+	if hasInline {
+		return inline, i, nil
+	}
+	if i+1 >= len(args) {
+		return "", i, jcserr.New(jcserr.CLIUsage, -1, fmt.Sprintf("option %s requires a value", name))
+	}
+	return args[i+1], i + 1, nil
+}
+
+// setBool records a boolean option.
+func (f *flags) setBool(name string) {
+	switch name {
+	case "--quiet", "-q":
+		f.quiet = true
+	case "--help", "-h":
+		f.help = true
+	case "--list", "-l":
+		f.list = true
+	case "--write", "-w":
+		f.write = true
+	default:
+		f.embedded.Detect = true
+	}
+}
+
+// setValue records the value of a value-taking option.
+func (f *flags) setValue(name, value string) {
+	switch name {
+	case "--error-format":
+		f.errorFormat = value
+	case "--jobs", "-j":
+		f.jobs = value
+	case "--include-glob":
+		f.includeGlobs = append(f.includeGlobs, value)
+	case "--exclude-glob":
+		f.excludeGlobs = append(f.excludeGlobs, value)
+	case "--bounds":
+		f.bounds = value
+	case "--bounds-file":
+		f.boundsFile = value
+	case "--max-depth", "--max-input-size", "--max-values", "--max-object-members", "--max-array-elements", "--max-string-bytes", "--max-number-chars", "--max-output-size":
+		if f.limits == nil {
+			f.limits = map[string]string{}
+		}
+		f.limits[name] = value
+	case "--input-syntax":
+		f.inputSyntax = value
+	case "--input-encoding":
+		f.inputEncoding = value
+	case "--scheme":
+		f.scheme = value
+	case "--set-pointer":
+		f.sets.Pointers = append(f.sets.Pointers, value)
+	case "--set-path":
+		f.sets.Paths = append(f.sets.Paths, value)
+	case "--set-duplicates":
+		f.setDuplicates = value
+	case "--embedded-json":
+		f.embedded.Pointers = append(f.embedded.Pointers, value)
+	case "--to":
+		f.to = value
+	case "--from":
+		f.from = value
+	case "--format":
+		f.format = value
+	case "--indent":
+		f.indent = value
+	case "--socket":
+		f.socket = value
+	case "--listen":
+		f.listen = value
+	case "--exclude":
+		f.projection.Exclude = append(f.projection.Exclude, value)
+	case "--exclude-name":
+		f.projection.ExcludeNames = append(f.projection.ExcludeNames, value)
+	default:
+		f.projection.Include = append(f.projection.Include, value)
+	}
+}
+
 func cmdCanonicalize(args []string, stdin io.Reader, stdout io.Writer, stderr io.Writer) int {
-	fl, positional, err := parseFlags(args)
+	diag := newDiagnostics(stderr, args)
+	fl, positional, err := parseFlags("canonicalize", args)
 	if err != nil {
-		return writeClassifiedError(stderr, err)
+		return diag.fail(err)
 	}
 
 	// CLI-FLAG-003: subcommand --help writes to stdout (frozen stream policy).
 	if fl.help {
 		helpErr := writeCanonicalizeHelp(stdout)
 		if helpErr != nil {
-			return writeClassifiedError(stderr, jcserr.Wrap(jcserr.InternalIO, -1, "write canonicalize help output", helpErr))
+			return diag.fail(jcserr.Wrap(jcserr.InternalIO, -1, "write canonicalize help output", helpErr))
 		}
 		return 0
 	}
 
+	plan, err := newCanonicalizePlan(&fl)
+	if err != nil {
+		return diag.fail(err)
+	}
+
+	// CLI-BATCH-001
+	batched, err := batchMode("canonicalize", &fl, positional)
+	if err != nil {
+		return diag.fail(err)
+	}
+	if batched {
+		b, batchErr := newBatch("canonicalize", &fl, plan.bounds.MaxInputSize, plan.canonicalize)
+		if batchErr != nil {
+			return diag.fail(batchErr)
+		}
+		return runBatch(b, positional, fl.quiet, stdout, diag, func() error {
+			return plan.writeProfileNotice(stderr, false)
+		})
+	}
+	return canonicalizeInput(plan, positional, fl.quiet, stdin, stdout, diag)
+}
+
+// canonicalizeInput canonicalizes a single file or stdin to stdout.
+func canonicalizeInput(plan canonicalizePlan, positional []string, quiet bool, stdin io.Reader, stdout io.Writer, diag *diagnostics) int {
 	// CLI-IO-002
-	ensureErr := ensureSingleInput(positional)
-	if ensureErr != nil {
-		return writeClassifiedError(stderr, ensureErr)
+	if err := ensureSingleInput(positional); err != nil {
+		return diag.fail(err)
 	}
 
-	input, err := readInput(positional, stdin, jcstoken.DefaultMaxInputSize)
+	input, release, err := loadInput(positional, stdin, plan.bounds.MaxInputSize)
 	if err != nil {
-		return writeClassifiedError(stderr, err)
+		return diag.fail(err)
 	}
+	defer release()
+	diag.input = input
 
-	canonical, err := jcs.Canonicalize(input)
+	canonical, err := plan.canonicalize(input)
 	if err != nil {
-		return writeClassifiedError(stderr, err)
+		return diag.fail(err)
 	}
 
 	// CLI-IO-004: output to stdout only
 	if _, err := stdout.Write(canonical); err != nil {
-		return writeClassifiedError(stderr, jcserr.Wrap(jcserr.InternalIO, -1, "writing output", err))
+		return diag.fail(jcserr.Wrap(jcserr.InternalIO, -1, "writing output", err))
+	}
+
+	if err := plan.writeProfileNotice(diag.stderr, quiet); err != nil {
+		return diag.fail(jcserr.Wrap(jcserr.InternalIO, -1, "writing profile notice", err))
 	}
 
 	return 0
 }
 
+// canonicalizePlan holds the resolved canonicalize options.
+type canonicalizePlan struct {
+	syntax     jcstoken.Syntax
+	encoding   jcstoken.Encoding
+	scheme     jcs.Scheme
+	projection *jcs.Projection
+	embedded   jcs.EmbeddedJSON
+	sets       jcs.SetArrays
+	bounds     jcstoken.Options
+}
+
+func newCanonicalizePlan(fl *flags) (canonicalizePlan, error) {
+	plan := canonicalizePlan{projection: &fl.projection}
+	var err error
+	if plan.syntax, err = inputSyntax(fl.inputSyntax); err != nil {
+		return plan, err
+	}
+	if plan.encoding, err = inputEncoding(fl.inputEncoding); err != nil {
+		return plan, err
+	}
+	if plan.scheme, err = canonicalScheme(fl.scheme); err != nil {
+		return plan, err
+	}
+	if plan.bounds, err = resolveBounds(fl); err != nil {
+		return plan, err
+	}
+	plan.embedded = fl.embedded
+	plan.sets = fl.sets
+	if plan.sets.Duplicates, err = duplicatePolicy(fl); err != nil {
+		return plan, err
+	}
+	return plan, nil
+}
+
+// canonicalize parses input in the plan's encoding and syntax, applies the projection, any
+// embedded JSON and any set arrays, and serializes the result under the
+// plan's scheme.
+func (plan canonicalizePlan) canonicalize(input []byte) ([]byte, error) {
+	// CLI-SYNTAX-001, CLI-ENCODING-001
+	parsed, err := jcstoken.ParseEncoded(input, plan.encoding, plan.syntax, plan.parseOptions())
+	if err != nil {
+		return nil, fmt.Errorf("parse canonicalize input: %w", err)
+	}
+
+	// CLI-PROJ-001
+	v, err := jcs.Project(parsed, plan.projection)
+	if err != nil {
+		return nil, fmt.Errorf("project canonicalize input: %w", err)
+	}
+
+	// CLI-EMBED-001
+	if plan.hasEmbedded() {
+		if v, err = jcs.ApplyEmbeddedJSON(v, &plan.embedded, plan.parseOptions()); err != nil {
+			return nil, fmt.Errorf("canonicalize embedded JSON: %w", err)
+		}
+	}
+
+	// CLI-SET-001
+	if plan.hasSets() {
+		if v, err = jcs.ApplySetArrays(v, &plan.sets); err != nil {
+			return nil, fmt.Errorf("apply set arrays: %w", err)
+		}
+	}
+
+	// CLI-SCHEME-001
+	out, err := jcs.SerializeSchemeWithOptions(plan.scheme, v, plan.parseOptions())
+	if err != nil {
+		return nil, fmt.Errorf("serialize %s output: %w", plan.scheme.Name(), err)
+	}
+	return out, nil
+}
+
+// parseOptions returns the plan's bounds with the number mode its scheme
+// requires: the Decimal scheme needs the exact decimal text of every number.
+//
+// CLI-DECIMAL-001: --scheme decimal parses numbers as exact decimals.
+func (plan canonicalizePlan) parseOptions() *jcstoken.Options {
+	opts := plan.bounds
+	if plan.scheme == jcs.Decimal {
+		opts.Numbers = jcstoken.NumberDecimal
+	}
+	return &opts
+}
+
+// writeProfileNotice labels output outside RFC 8785 on stderr, one line per
+// profile, unless quiet.
+//
+// SET-LABEL-001: Set-array output is labeled as a distinct profile.
+// DEC-LABEL-001: Decimal output is labeled as a distinct profile.
+// EMBED-LABEL-001: Embedded-JSON output is labeled as a distinct profile.
+func (plan canonicalizePlan) writeProfileNotice(stderr io.Writer, quiet bool) error {
+	if quiet {
+		return nil
+	}
+	for _, profile := range plan.profiles() {
+		if err := writeLine(stderr, fmt.Sprintf("jcs-canon: profile %s (not RFC 8785)", profile)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// profiles lists the non-RFC 8785 profiles the plan's output belongs to.
+func (plan canonicalizePlan) profiles() []string {
+	var out []string
+	if plan.scheme == jcs.Decimal {
+		out = append(out, jcs.DecimalProfile)
+	}
+	if plan.hasEmbedded() {
+		out = append(out, jcs.EmbeddedJSONProfile)
+	}
+	if plan.hasSets() {
+		out = append(out, jcs.SetArraysProfile)
+	}
+	return out
+}
+
+// hasSets reports whether any set arrays are selected.
+func (plan canonicalizePlan) hasSets() bool {
+	return len(plan.sets.Pointers) > 0 || len(plan.sets.Paths) > 0
+}
+
+// hasEmbedded reports whether any embedded JSON is selected.
+func (plan canonicalizePlan) hasEmbedded() bool {
+	return len(plan.embedded.Pointers) > 0 || plan.embedded.Detect
+}
+
+// duplicatePolicy maps a --set-duplicates value to a jcs.DuplicatePolicy.
+// Keeping duplicates is the default.
+func duplicatePolicy(fl *flags) (jcs.DuplicatePolicy, error) {
+	if fl.setDuplicates != "" && len(fl.sets.Pointers) == 0 && len(fl.sets.Paths) == 0 {
+		return jcs.KeepDuplicates, jcserr.New(jcserr.CLIUsage, -1, "--set-duplicates requires --set-pointer or --set-path")
+	}
+	switch fl.setDuplicates {
+	case "", "keep":
+		return jcs.KeepDuplicates, nil
+	case "remove":
+		return jcs.RemoveDuplicates, nil
+	case "reject":
+		return jcs.RejectDuplicates, nil
+	default:
+		return jcs.KeepDuplicates, jcserr.New(jcserr.CLIUsage, -1, fmt.Sprintf("unsupported --set-duplicates: %s", fl.setDuplicates))
+	}
+}
+
+// inputSyntax maps an --input-syntax value to a jcstoken.Syntax. Strict JSON
+// is the default.
+func inputSyntax(name string) (jcstoken.Syntax, error) {
+	switch name {
+	case "", "json":
+		return jcstoken.SyntaxJSON, nil
+	case "jsonc":
+		return jcstoken.SyntaxJSONC, nil
+	case "json5":
+		return jcstoken.SyntaxJSON5, nil
+	default:
+		return jcstoken.SyntaxJSON, jcserr.New(jcserr.CLIUsage, -1, fmt.Sprintf("unsupported --input-syntax: %s", name))
+	}
+}
+
+// inputEncoding maps an --input-encoding value to a jcstoken.Encoding. Strict
+// UTF-8 is the default.
+func inputEncoding(name string) (jcstoken.Encoding, error) {
+	if name == "" {
+		return jcstoken.EncodingUTF8, nil
+	}
+	enc, ok := jcstoken.LookupEncoding(name)
+	if !ok {
+		return jcstoken.EncodingUTF8, jcserr.New(jcserr.CLIUsage, -1, fmt.Sprintf("unsupported --input-encoding: %s", name))
+	}
+	return enc, nil
+}
+
+// canonicalScheme maps a --scheme value to a jcs.Scheme. RFC 8785 is the
+// default.
+func canonicalScheme(name string) (jcs.Scheme, error) {
+	if name == "" {
+		return jcs.RFC8785, nil
+	}
+	scheme, ok := jcs.LookupScheme(name)
+	if !ok {
+		return nil, jcserr.New(jcserr.CLIUsage, -1, fmt.Sprintf("unsupported --scheme: %s", name))
+	}
+	return scheme, nil
+}
+
 func cmdVerify(args []string, stdin io.Reader, stdout io.Writer, stderr io.Writer) int {
-	fl, positional, err := parseFlags(args)
+	diag := newDiagnostics(stderr, args)
+	fl, positional, err := parseFlags("verify", args)
 	if err != nil {
-		return writeClassifiedError(stderr, err)
+		return diag.fail(err)
 	}
 
 	// CLI-FLAG-003: subcommand --help writes to stdout (frozen stream policy).
 	if fl.help {
 		helpErr := writeVerifyHelp(stdout)
 		if helpErr != nil {
-			return writeClassifiedError(stderr, jcserr.Wrap(jcserr.InternalIO, -1, "write verify help output", helpErr))
+			return diag.fail(jcserr.Wrap(jcserr.InternalIO, -1, "write verify help output", helpErr))
 		}
 		return 0
 	}
 
-	input, canonical, err := parseCanonicalFromInput(positional, stdin)
+	bounds, err := resolveBounds(&fl)
+	if err != nil {
+		return diag.fail(err)
+	}
+	canonical := func(input []byte) ([]byte, error) { return canonicalBytes(input, &bounds) }
+
+	// CLI-BATCH-001
+	batched, err := batchMode("verify", &fl, positional)
+	if err != nil {
+		return diag.fail(err)
+	}
+	if batched {
+		b, batchErr := newBatch("verify", &fl, bounds.MaxInputSize, canonical)
+		if batchErr != nil {
+			return diag.fail(batchErr)
+		}
+		return runBatch(b, positional, fl.quiet, stdout, diag, func() error { return nil })
+	}
+	return verifyInput(positional, fl.quiet, stdin, &bounds, diag)
+}
+
+// verifyInput verifies a single file or stdin under bounds.
+func verifyInput(positional []string, quiet bool, stdin io.Reader, bounds *jcstoken.Options, diag *diagnostics) int {
+	if err := ensureSingleInput(positional); err != nil {
+		return diag.fail(err)
+	}
+	input, release, err := loadInput(positional, stdin, bounds.MaxInputSize)
 	if err != nil {
-		return writeClassifiedError(stderr, err)
+		return diag.fail(err)
+	}
+	defer release()
+	diag.input = input
+
+	canonical, err := canonicalBytes(input, bounds)
+	if err != nil {
+		return diag.fail(err)
 	}
 
 	// VERIFY-ORDER-001, VERIFY-WS-001
 	if !bytes.Equal(input, canonical) {
-		return writeClassifiedError(stderr, jcserr.New(jcserr.NotCanonical, -1, "input is not canonical"))
+		return diag.fail(jcserr.New(jcserr.NotCanonical, -1, "input is not canonical"))
 	}
 
 	// CLI-IO-005, CLI-FLAG-002
-	if !fl.quiet {
-		if err := writeLine(stderr, "ok"); err != nil {
-			return writeClassifiedError(stderr, jcserr.Wrap(jcserr.InternalIO, -1, "writing verify success output", err))
+	if !quiet {
+		if err := writeLine(diag.stderr, "ok"); err != nil {
+			return diag.fail(jcserr.Wrap(jcserr.InternalIO, -1, "writing verify success output", err))
 		}
 	}
 	return 0
 }
 
-func parseCanonicalFromInput(positional []string, stdin io.Reader) ([]byte, []byte, error) {
+func cmdConvert(args []string, stdin io.Reader, stdout io.Writer, stderr io.Writer) int {
+	diag := newDiagnostics(stderr, args)
+	fl, positional, err := parseFlags("convert", args)
+	if err != nil {
+		return diag.fail(err)
+	}
+
+	// CLI-FLAG-003: subcommand --help writes to stdout (frozen stream policy).
+	if fl.help {
+		helpErr := writeConvertHelp(stdout)
+		if helpErr != nil {
+			return diag.fail(jcserr.Wrap(jcserr.InternalIO, -1, "write convert help output", helpErr))
+		}
+		return 0
+	}
+
+	if err := checkConvertFormat(fl); err != nil {
+		return diag.fail(err)
+	}
+	bounds, err := resolveBounds(&fl)
+	if err != nil {
+		return diag.fail(err)
+	}
 	if err := ensureSingleInput(positional); err != nil {
-		return nil, nil, err
+		return diag.fail(err)
+	}
+	input, release, err := loadInput(positional, stdin, bounds.MaxInputSize)
+	if err != nil {
+		return diag.fail(err)
+	}
+	defer release()
+	if fl.to != "" {
+		// CBOR input has no lines.
+		diag.input = input
+	}
+
+	// CLI-CONVERT-001
+	output, err := convert(fl, input, &bounds)
+	if err != nil {
+		return diag.fail(err)
+	}
+	if _, err := stdout.Write(output); err != nil {
+		return diag.fail(jcserr.Wrap(jcserr.InternalIO, -1, "writing output", err))
 	}
-	input, err := readInput(positional, stdin, jcstoken.DefaultMaxInputSize)
+	return 0
+}
+
+// checkConvertFormat requires exactly one of --to and --from, naming cbor.
+func checkConvertFormat(fl flags) error {
+	if (fl.to == "") == (fl.from == "") {
+		return jcserr.New(jcserr.CLIUsage, -1, "convert requires exactly one of --to or --from")
+	}
+	if fl.to != "" && fl.to != "cbor" {
+		return jcserr.New(jcserr.CLIUsage, -1, fmt.Sprintf("unsupported --to format: %s", fl.to))
+	}
+	if fl.from != "" && fl.from != "cbor" {
+		return jcserr.New(jcserr.CLIUsage, -1, fmt.Sprintf("unsupported --from format: %s", fl.from))
+	}
+	return nil
+}
+
+func convert(fl flags, input []byte, bounds *jcstoken.Options) ([]byte, error) {
+	if fl.to != "" {
+		parsed, err := jcstoken.ParseWithOptions(input, bounds)
+		if err != nil {
+			return nil, fmt.Errorf("parse convert input: %w", err)
+		}
+		out, err := jcscbor.Encode(parsed)
+		if err != nil {
+			return nil, fmt.Errorf("encode cbor output: %w", err)
+		}
+		return out, nil
+	}
+	decoded, err := jcscbor.DecodeWithOptions(input, bounds)
+	if err != nil {
+		return nil, fmt.Errorf("decode cbor input: %w", err)
+	}
+	out, err := jcs.SerializeWithOptions(decoded, bounds)
 	if err != nil {
-		return nil, nil, err
+		return nil, fmt.Errorf("serialize converted input: %w", err)
 	}
-	parsed, err := jcstoken.Parse(input)
+	return out, nil
+}
+
+// canonicalBytes parses input as strict JSON under bounds and returns its
+// RFC 8785 serialization.
+func canonicalBytes(input []byte, bounds *jcstoken.Options) ([]byte, error) {
+	parsed, err := jcstoken.ParseWithOptions(input, bounds)
 	if err != nil {
-		return nil, nil, fmt.Errorf("parse canonical input: %w", err)
+		return nil, fmt.Errorf("parse canonical input: %w", err)
 	}
-	canonical, err := jcs.Serialize(parsed)
+	canonical, err := jcs.SerializeWithOptions(parsed, bounds)
 	if err != nil {
-		return nil, nil, fmt.Errorf("serialize canonical input: %w", err)
+		return nil, fmt.Errorf("serialize canonical input: %w", err)
 	}
-	return input, canonical, nil
+	return canonical, nil
 }
 
 // writeClassifiedError extracts jcserr.Error if possible and uses its exit code.
@@ -195,15 +764,26 @@ func writeClassifiedError(stderr io.Writer, err error) int {
 	return writeErrorAndReturn(stderr, jcserr.InternalError.ExitCode(), "error: %v\n", err)
 }
 
-func readInput(positional []string, stdin io.Reader, maxInputSize int) ([]byte, error) {
+// loadInput returns the content of the input named by positional and a
+// function that releases it. A non-empty regular file is memory-mapped
+// read-only once its size is checked against maxInputSize, so its bytes are
+// never copied; stdin, pipes, and special files are read. The content must
+// not be used after release. Reading it after another process truncates the
+// file faults, which run and the batch workers turn into INTERNAL_IO with
+// guardMapped.
+//
+// CLI-IO-006: Regular files are memory-mapped with the failure classes of
+// the read path.
+func loadInput(positional []string, stdin io.Reader, maxInputSize int) ([]byte, func(), error) {
 	// CLI-IO-001
 	if len(positional) == 0 || positional[0] == "-" {
-		return readBounded(stdin, maxInputSize)
+		data, err := readBounded(stdin, maxInputSize)
+		return data, func() {}, err
 	}
 
 	f, err := os.Open(positional[0])
 	if err != nil {
-		return nil, jcserr.Wrap(jcserr.CLIUsage, -1, fmt.Sprintf("read file %q", positional[0]), err)
+		return nil, nil, jcserr.Wrap(jcserr.CLIUsage, -1, fmt.Sprintf("read file %q", positional[0]), err)
 	}
 	defer func() {
 		if closeErr := f.Close(); closeErr != nil {
@@ -211,15 +791,71 @@ func readInput(positional []string, stdin io.Reader, maxInputSize int) ([]byte,
 		}
 	}()
 
-	data, err := readBounded(f, maxInputSize)
+	data, release, err := readFile(f, maxInputSize)
 	if err != nil {
 		var je *jcserr.Error
 		if errors.As(err, &je) && je.Class == jcserr.BoundExceeded {
-			return nil, err
+			return nil, nil, err
 		}
-		return nil, jcserr.Wrap(jcserr.CLIUsage, -1, fmt.Sprintf("read file %q", positional[0]), err)
+		return nil, nil, jcserr.Wrap(jcserr.CLIUsage, -1, fmt.Sprintf("read file %q", positional[0]), err)
 	}
-	return data, nil
+	return data, release, nil
+}
+
+// guardMapped makes a memory fault in the calling goroutine panic instead of
+// crashing the process. Reading a mapped input faults once another process
+// truncates the file. The returned function, deferred by the caller,
+// restores the previous setting and reports such a fault to fail as
+// INTERNAL_IO; other panics continue.
+//
+// CLI-IO-006: A mapped input truncated while it is read fails as
+// INTERNAL_IO.
+func guardMapped(fail func(error)) func() {
+	old := debug.SetPanicOnFault(true)
+	return func() {
+		r := recover()
+		debug.SetPanicOnFault(old)
+		if r == nil {
+			return
+		}
+		if _, fault := r.(interface{ Addr() uintptr }); !fault {
+			panic(r)
+		}
+		fail(jcserr.New(jcserr.InternalIO, -1, "input file was truncated while it was read"))
+	}
+}
+
+// readFile maps f when it is a non-empty regular file no larger than
+// maxInputSize, and reads it otherwise. Files that report a size of zero,
+// such as those under /proc, and files that cannot be mapped are read.
+func readFile(f *os.File, maxInputSize int) ([]byte, func(), error) {
+	info, err := f.Stat()
+	if err == nil && info.Mode().IsRegular() && info.Size() > 0 {
+		if info.Size() > int64(maxInputSize) {
+			return nil, nil, inputTooLarge(maxInputSize)
+		}
+		if data, release, mapErr := mapFile(f, int(info.Size())); mapErr == nil {
+			return data, release, nil
+		}
+	}
+	data, err := readBounded(f, maxInputSize)
+	return data, func() {}, err
+}
+
+// readDocument reads and parses the input name under bounds. On failure
+// it reports the error, naming the input when it is malformed, and returns
+// a nil value and the exit code.
+func readDocument(name string, stdin io.Reader, bounds *jcstoken.Options, diag *diagnostics) (*jcstoken.Value, int) {
+	input, release, err := loadInput([]string{name}, stdin, bounds.MaxInputSize)
+	if err != nil {
+		return nil, diag.fail(err)
+	}
+	defer release()
+	v, err := jcstoken.ParseWithOptions(input, bounds)
+	if err != nil {
+		return nil, diag.report(name, err, input)
+	}
+	return v, 0
 }
 
 func readBounded(r io.Reader, maxInputSize int) ([]byte, error) {
@@ -229,15 +865,20 @@ func readBounded(r io.Reader, maxInputSize int) ([]byte, error) {
 		return nil, jcserr.Wrap(jcserr.InternalIO, -1, "read input stream", err)
 	}
 	if len(data) > maxInputSize {
-		return nil, jcserr.New(
-			jcserr.BoundExceeded,
-			0,
-			fmt.Sprintf("input exceeds maximum size %d bytes", maxInputSize),
-		)
+		return nil, inputTooLarge(maxInputSize)
 	}
 	return data, nil
 }
 
+// inputTooLarge is the failure for an input over maxInputSize bytes.
+func inputTooLarge(maxInputSize int) error {
+	return jcserr.New(
+		jcserr.BoundExceeded,
+		0,
+		fmt.Sprintf("input exceeds maximum size %d bytes", maxInputSize),
+	)
+}
+
 func ensureSingleInput(positional []string) error {
 	if len(positional) <= 1 {
 		return nil
@@ -253,17 +894,41 @@ func writeErrorAndReturn(stderr io.Writer, code int, format string, args ...any)
 }
 
 func writeCanonicalizeHelp(w io.Writer) error {
-	if err := writeLine(w, "usage: jcs-canon canonicalize [--quiet] [file|-]"); err != nil {
-		return err
-	}
-	if err := writeLine(w, "  Read JSON from file (or stdin), emit canonical bytes to stdout."); err != nil {
-		return err
+	lines := []string{
+		"usage: jcs-canon canonicalize [--quiet] [--error-format text|json] [-l] [--write] [--jobs n] [--include-glob glob]... [--exclude-glob glob]... " + boundsUsage + " [--input-syntax json|jsonc|json5] [--input-encoding utf-8|auto|utf-16le|utf-16be|utf-32le|utf-32be] [--scheme rfc8785|olpc|matrix|decimal] [--exclude ptr]... [--exclude-name name]... [--include ptr]... [--set-pointer ptr]... [--set-path query]... [--set-duplicates keep|remove|reject] [--embedded-json ptr]... [--embedded-json-detect] [file|-|path...]",
+		"  Read JSON from file (or stdin), emit canonical bytes to stdout.",
+		"  With -l or --write, process every file and directory argument instead.",
+		"  --quiet              Suppress the profile notices (decimal, embedded JSON, set arrays) on stderr",
+		"  --error-format f     Report errors on stderr as text (default) or as one canonical JSON object",
+		"  -l, --list           List the files whose canonical form differs from their bytes",
+		"  -w, --write          Rewrite files that are not canonical in place",
+		"  -j, --jobs n         Process files with n workers (default: number of CPUs)",
+		"  --include-glob glob  Select walked files matching glob (repeatable; default *.json)",
+		"  --exclude-glob glob  Skip walked files and directories matching glob (repeatable)",
+		"  --input-syntax s     Accept json (default, strict), jsonc (comments, trailing commas), or json5 input",
+		"  --input-encoding e   Read utf-8 (default, strict), auto (detect from BOM), utf-16le, utf-16be, utf-32le, or utf-32be input",
+		"  --scheme s           Emit rfc8785 (default), olpc, matrix, or decimal canonical JSON",
+		"  --exclude ptr        Drop the value at JSON Pointer ptr before emission (repeatable)",
+		"  --exclude-name name  Drop object members named name at any depth (repeatable)",
+		"  --include ptr        Emit only the values at these JSON Pointers and their ancestors (repeatable)",
+		"  --set-pointer ptr    Sort the array at JSON Pointer ptr as an unordered set (repeatable; not RFC 8785)",
+		"  --set-path query     Sort every array selected by JSONPath query as a set (repeatable; not RFC 8785)",
+		"  --set-duplicates p   Keep (default), remove, or reject repeated set elements",
+		"  --embedded-json ptr  Canonicalize the JSON text in the string at JSON Pointer ptr (repeatable; not RFC 8785)",
+		"  --embedded-json-detect",
+		"                       Canonicalize every string holding a JSON object or array (not RFC 8785)",
+	}
+	lines = append(lines, boundsHelp...)
+	for _, line := range lines {
+		if err := writeLine(w, line); err != nil {
+			return err
+		}
 	}
-	return writeLine(w, "  --quiet   Accepted for command symmetry; canonicalize is silent on success")
+	return nil
 }
 
 func writeGlobalHelp(w io.Writer) error {
-	if err := writeLine(w, "usage: jcs-canon <canonicalize|verify> [options] [file|-]"); err != nil {
+	if err := writeLine(w, "usage: jcs-canon <canonicalize|verify|convert|diff|patch|merge-patch|fmt|manifest|serve|git-clean|git-filter-process|git-textconv|git-pre-commit> [options] [file|-]"); err != nil {
 		return err
 	}
 	if err := writeLine(w, "       jcs-canon --help"); err != nil {
@@ -272,7 +937,7 @@ func writeGlobalHelp(w io.Writer) error {
 	if err := writeLine(w, "       jcs-canon --version"); err != nil {
 		return err
 	}
-	if err := writeLine(w, "commands: canonicalize, verify"); err != nil {
+	if err := writeLine(w, "commands: canonicalize, verify, convert, diff, patch, merge-patch, fmt, manifest, serve, git-clean, git-filter-process, git-textconv, git-pre-commit"); err != nil {
 		return err
 	}
 	return writeLine(w, "flags: --help, -h, --version")
@@ -283,13 +948,41 @@ func writeVersion(w io.Writer) error {
 }
 
 func writeVerifyHelp(w io.Writer) error {
-	if err := writeLine(w, "usage: jcs-canon verify [--quiet] [file|-]"); err != nil {
-		return err
+	lines := []string{
+		"usage: jcs-canon verify [--quiet] [--error-format text|json] [-l] [--jobs n] [--include-glob glob]... [--exclude-glob glob]... " + boundsUsage + " [file|-|path...]",
+		"  Parse, canonicalize, and compare bytes to verify canonical form.",
+		"  Several paths, a directory, or -l verify every file and report a summary.",
+		"  --quiet              Suppress success messages and the summary",
+		"  --error-format f     Report errors on stderr as text (default) or as one canonical JSON object",
+		"  -l, --list           List the files that are not canonical instead of reporting them as errors",
+		"  -j, --jobs n         Process files with n workers (default: number of CPUs)",
+		"  --include-glob glob  Select walked files matching glob (repeatable; default *.json)",
+		"  --exclude-glob glob  Skip walked files and directories matching glob (repeatable)",
+	}
+	lines = append(lines, boundsHelp...)
+	for _, line := range lines {
+		if err := writeLine(w, line); err != nil {
+			return err
+		}
 	}
-	if err := writeLine(w, "  Parse, canonicalize, and compare bytes to verify canonical form."); err != nil {
-		return err
+	return nil
+}
+
+func writeConvertHelp(w io.Writer) error {
+	lines := []string{
+		"usage: jcs-canon convert (--to cbor|--from cbor) [--error-format text|json] " + boundsUsage + " [file|-]",
+		"  Transcode between JSON and deterministic CBOR (RFC 8949 section 4.2).",
+		"  --to cbor            Read JSON and emit deterministic CBOR bytes to stdout",
+		"  --from cbor          Read CBOR and emit canonical JSON bytes to stdout",
+		"  --error-format f     Report errors on stderr as text (default) or as one canonical JSON object",
+	}
+	lines = append(lines, boundsHelp...)
+	for _, line := range lines {
+		if err := writeLine(w, line); err != nil {
+			return err
+		}
 	}
-	return writeLine(w, "  --quiet  Suppress success messages")
+	return nil
 }
 
 func writeLine(w io.Writer, msg string) error {
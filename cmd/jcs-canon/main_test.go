@@ -1,16 +1,24 @@
 package main
 
 import (
+	"bufio"
 	"bytes"
+	"context"
 	"errors"
 	"fmt"
 	"io"
+	"net"
+	"net/http"
 	"os"
+	"os/exec"
 	"path/filepath"
 	"strings"
+	"syscall"
 	"testing"
+	"unicode/utf16"
 
 	"github.com/lattice-substrate/json-canon/jcserr"
+	"github.com/lattice-substrate/json-canon/jcstoken"
 )
 
 type failingWriter struct{}
@@ -125,7 +133,7 @@ func TestRunUnknownCommandExitCode(t *testing.T) {
 }
 
 func TestParseFlagsUnknownOption(t *testing.T) {
-	_, _, err := parseFlags([]string{"--nope"})
+	_, _, err := parseFlags("canonicalize", []string{"--nope"})
 	if err == nil {
 		t.Fatal("expected parseFlags error for unknown option")
 	}
@@ -133,13 +141,296 @@ func TestParseFlagsUnknownOption(t *testing.T) {
 }
 
 func TestParseFlagsDoubleDashRejected(t *testing.T) {
-	_, _, err := parseFlags([]string{"--"})
+	_, _, err := parseFlags("canonicalize", []string{"--"})
 	if err == nil {
 		t.Fatal("expected parseFlags error for --")
 	}
 	assertClass(t, err, jcserr.CLIUsage)
 }
 
+func TestParseFlagsCommandScoped(t *testing.T) {
+	if _, _, err := parseFlags("canonicalize", []string{"--exclude", "/proof"}); err != nil {
+		t.Fatalf("canonicalize --exclude: %v", err)
+	}
+	_, _, err := parseFlags("verify", []string{"--exclude", "/proof"})
+	assertClass(t, err, jcserr.CLIUsage)
+}
+
+func TestParseFlagsOptionValues(t *testing.T) {
+	fl, positional, err := parseFlags("canonicalize", []string{
+		"--exclude=/proof", "--exclude", "/sig", "--exclude-name", "signature", "--include=/a", "in.json",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	p := fl.projection
+	if len(p.Exclude) != 2 || p.Exclude[0] != "/proof" || p.Exclude[1] != "/sig" {
+		t.Fatalf("unexpected exclude %q", p.Exclude)
+	}
+	if len(p.ExcludeNames) != 1 || p.ExcludeNames[0] != "signature" {
+		t.Fatalf("unexpected exclude names %q", p.ExcludeNames)
+	}
+	if len(p.Include) != 1 || p.Include[0] != "/a" {
+		t.Fatalf("unexpected include %q", p.Include)
+	}
+	if len(positional) != 1 || positional[0] != "in.json" {
+		t.Fatalf("unexpected positional %q", positional)
+	}
+
+	_, _, err = parseFlags("canonicalize", []string{"--exclude"})
+	assertClass(t, err, jcserr.CLIUsage)
+	_, _, err = parseFlags("canonicalize", []string{"--quiet=yes"})
+	assertClass(t, err, jcserr.CLIUsage)
+}
+
+func TestRunCanonicalizeProjection(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	code := run(
+		[]string{"canonicalize", "--exclude", "/proof", "--exclude-name", "sig", "-"},
+		strings.NewReader(`{"proof":{"v":"z"},"b":{"sig":1,"x":2},"a":1}`),
+		&stdout,
+		&stderr,
+	)
+	if code != 0 {
+		t.Fatalf("expected exit 0, got %d stderr=%q", code, stderr.String())
+	}
+	if got := stdout.String(); got != `{"a":1,"b":{"x":2}}` {
+		t.Fatalf("unexpected output %q", got)
+	}
+
+	stdout.Reset()
+	stderr.Reset()
+	code = run([]string{"canonicalize", "--include", "/missing", "-"}, strings.NewReader(`{"a":1}`), &stdout, &stderr)
+	if code != jcserr.InvalidPointer.ExitCode() || !strings.Contains(stderr.String(), string(jcserr.InvalidPointer)) {
+		t.Fatalf("expected INVALID_POINTER exit, got %d stderr=%q", code, stderr.String())
+	}
+}
+
+func TestRunCanonicalizeInputSyntax(t *testing.T) {
+	in := "{\n  // comment\n  \"b\": [1, 2,],\n  \"a\": 1,\n}\n"
+	var stdout, stderr bytes.Buffer
+	code := run([]string{"canonicalize", "--input-syntax=jsonc", "--exclude", "/a"}, strings.NewReader(in), &stdout, &stderr)
+	if code != 0 || stdout.String() != `{"b":[1,2]}` {
+		t.Fatalf("unexpected jsonc result: exit=%d stdout=%q stderr=%q", code, stdout.String(), stderr.String())
+	}
+
+	stdout.Reset()
+	stderr.Reset()
+	code = run([]string{"canonicalize", "--input-syntax", "json5"}, strings.NewReader(`{b: 0x10, a: 'x',}`), &stdout, &stderr)
+	if code != 0 || stdout.String() != `{"a":"x","b":16}` {
+		t.Fatalf("unexpected json5 result: exit=%d stdout=%q stderr=%q", code, stdout.String(), stderr.String())
+	}
+
+	for _, args := range [][]string{
+		{"canonicalize", "-"},
+		{"canonicalize", "--input-syntax", "json", "-"},
+	} {
+		stdout.Reset()
+		stderr.Reset()
+		code = run(args, strings.NewReader(in), &stdout, &stderr)
+		if code != 2 || !strings.Contains(stderr.String(), string(jcserr.InvalidGrammar)) {
+			t.Fatalf("%q accepted JSONC input: exit=%d stderr=%q", args, code, stderr.String())
+		}
+	}
+
+	stderr.Reset()
+	code = run([]string{"canonicalize", "--input-syntax", "yaml"}, strings.NewReader(`{}`), &stdout, &stderr)
+	if code != 2 || !strings.Contains(stderr.String(), string(jcserr.CLIUsage)) {
+		t.Fatalf("unsupported syntax not rejected: exit=%d stderr=%q", code, stderr.String())
+	}
+}
+
+func TestRunCanonicalizeScheme(t *testing.T) {
+	in := `{"\ud83d\ude00":1,"\uffef":"a\nb","sig":0,"a":10.0}`
+	cases := []struct{ scheme, want string }{
+		{"rfc8785", "{\"a\":10,\"\U0001F600\":1,\"\uffef\":\"a\\nb\"}"},
+		{"olpc", "{\"a\":10,\"\uffef\":\"a\nb\",\"\U0001F600\":1}"},
+		{"matrix", "{\"a\":10,\"\uffef\":\"a\\nb\",\"\U0001F600\":1}"},
+	}
+	for _, tc := range cases {
+		var stdout, stderr bytes.Buffer
+		code := run([]string{"canonicalize", "--scheme=" + tc.scheme, "--exclude", "/sig"}, strings.NewReader(in), &stdout, &stderr)
+		if code != 0 || stdout.String() != tc.want {
+			t.Fatalf("%s: exit=%d stdout=%q stderr=%q", tc.scheme, code, stdout.String(), stderr.String())
+		}
+	}
+
+	var stdout, stderr bytes.Buffer
+	code := run([]string{"canonicalize", "--scheme", "matrix"}, strings.NewReader(`[1.5]`), &stdout, &stderr)
+	if code != 2 || stdout.Len() != 0 || !strings.Contains(stderr.String(), string(jcserr.SchemeDomain)) {
+		t.Fatalf("non-integer not rejected: exit=%d stdout=%q stderr=%q", code, stdout.String(), stderr.String())
+	}
+
+	stderr.Reset()
+	code = run([]string{"canonicalize", "--scheme", "cjson"}, strings.NewReader(`{}`), &stdout, &stderr)
+	if code != 2 || !strings.Contains(stderr.String(), string(jcserr.CLIUsage)) {
+		t.Fatalf("unsupported scheme not rejected: exit=%d stderr=%q", code, stderr.String())
+	}
+}
+
+func TestRunCanonicalizeSetArrays(t *testing.T) {
+	in := `{"roles":[{"perms":["w","r","w"]}],"tags":["b","a"],"order":[2,1]}`
+	var stdout, stderr bytes.Buffer
+	code := run([]string{"canonicalize", "--set-pointer", "/tags", "--set-path=$.roles[*].perms", "--set-duplicates", "remove"}, strings.NewReader(in), &stdout, &stderr)
+	if code != 0 || stdout.String() != `{"order":[2,1],"roles":[{"perms":["r","w"]}],"tags":["a","b"]}` {
+		t.Fatalf("unexpected result: exit=%d stdout=%q stderr=%q", code, stdout.String(), stderr.String())
+	}
+	if stderr.String() != "jcs-canon: profile jcs-set-arrays (not RFC 8785)\n" {
+		t.Fatalf("missing profile notice: %q", stderr.String())
+	}
+
+	stdout.Reset()
+	stderr.Reset()
+	code = run([]string{"canonicalize", "-q", "--set-pointer", "/tags"}, strings.NewReader(in), &stdout, &stderr)
+	if code != 0 || stderr.Len() != 0 {
+		t.Fatalf("--quiet did not suppress notice: exit=%d stderr=%q", code, stderr.String())
+	}
+
+	cases := []struct {
+		args []string
+		want jcserr.FailureClass
+	}{
+		{[]string{"canonicalize", "--set-path", "$.roles[*].perms", "--set-duplicates", "reject"}, jcserr.DuplicateElement},
+		{[]string{"canonicalize", "--set-pointer", "/roles/0"}, jcserr.InvalidPointer},
+		{[]string{"canonicalize", "--set-path", "$.tags[0:1]"}, jcserr.InvalidPointer},
+		{[]string{"canonicalize", "--set-pointer", "/tags", "--set-duplicates", "merge"}, jcserr.CLIUsage},
+		{[]string{"canonicalize", "--set-duplicates", "remove"}, jcserr.CLIUsage},
+		{[]string{"verify", "--set-pointer", "/tags"}, jcserr.CLIUsage},
+	}
+	for _, tc := range cases {
+		stdout.Reset()
+		stderr.Reset()
+		code = run(tc.args, strings.NewReader(in), &stdout, &stderr)
+		if code != 2 || stdout.Len() != 0 || !strings.Contains(stderr.String(), string(tc.want)) {
+			t.Fatalf("%q: expected %s, got exit=%d stdout=%q stderr=%q", tc.args, tc.want, code, stdout.String(), stderr.String())
+		}
+	}
+}
+
+func TestRunCanonicalizeDecimal(t *testing.T) {
+	in := `{"total":12345678901234567.89,"items":[1.10,2.500]}`
+	var stdout, stderr bytes.Buffer
+	code := run([]string{"canonicalize", "--scheme", "decimal"}, strings.NewReader(in), &stdout, &stderr)
+	if code != 0 || stdout.String() != `{"items":[1.1,2.5],"total":12345678901234567.89}` {
+		t.Fatalf("unexpected result: exit=%d stdout=%q stderr=%q", code, stdout.String(), stderr.String())
+	}
+	if stderr.String() != "jcs-canon: profile jcs-decimal (not RFC 8785)\n" {
+		t.Fatalf("missing profile notice: %q", stderr.String())
+	}
+
+	stdout.Reset()
+	stderr.Reset()
+	code = run([]string{"canonicalize", "--scheme=decimal", "--set-pointer", "/items"}, strings.NewReader(in), &stdout, &stderr)
+	if code != 0 || stderr.String() != "jcs-canon: profile jcs-decimal (not RFC 8785)\njcs-canon: profile jcs-set-arrays (not RFC 8785)\n" {
+		t.Fatalf("unexpected notices: exit=%d stderr=%q", code, stderr.String())
+	}
+
+	stdout.Reset()
+	stderr.Reset()
+	code = run([]string{"canonicalize", "-q", "--scheme", "decimal"}, strings.NewReader(`[1e-1000000000]`), &stdout, &stderr)
+	if code != 2 || stdout.Len() != 0 || !strings.Contains(stderr.String(), string(jcserr.UnsupportedDecimal)) {
+		t.Fatalf("expected UNSUPPORTED_DECIMAL, got exit=%d stdout=%q stderr=%q", code, stdout.String(), stderr.String())
+	}
+}
+
+func TestRunCanonicalizeEmbeddedJSON(t *testing.T) {
+	in := `{"tags":["b","a"],"payload":"{ \"b\": 2, \"a\": [ 1.0 ] }","note":"[x"}`
+	var stdout, stderr bytes.Buffer
+	code := run([]string{"canonicalize", "--embedded-json", "/payload", "--set-pointer=/tags"}, strings.NewReader(in), &stdout, &stderr)
+	if code != 0 || stdout.String() != `{"note":"[x","payload":"{\"a\":[1],\"b\":2}","tags":["a","b"]}` {
+		t.Fatalf("unexpected result: exit=%d stdout=%q stderr=%q", code, stdout.String(), stderr.String())
+	}
+	if stderr.String() != "jcs-canon: profile jcs-embedded-json (not RFC 8785)\njcs-canon: profile jcs-set-arrays (not RFC 8785)\n" {
+		t.Fatalf("unexpected notices: %q", stderr.String())
+	}
+
+	stdout.Reset()
+	stderr.Reset()
+	code = run([]string{"canonicalize", "-q", "--embedded-json-detect"}, strings.NewReader(in), &stdout, &stderr)
+	if code != 0 || stderr.Len() != 0 || stdout.String() != `{"note":"[x","payload":"{\"a\":[1],\"b\":2}","tags":["b","a"]}` {
+		t.Fatalf("unexpected detect result: exit=%d stdout=%q stderr=%q", code, stdout.String(), stderr.String())
+	}
+
+	stdout.Reset()
+	stderr.Reset()
+	code = run([]string{"canonicalize", "--embedded-json=/note"}, strings.NewReader(in), &stdout, &stderr)
+	if code != 2 || stdout.Len() != 0 || !strings.Contains(stderr.String(), string(jcserr.InvalidGrammar)) || !strings.Contains(stderr.String(), `"/note"`) {
+		t.Fatalf("expected located INVALID_GRAMMAR, got exit=%d stdout=%q stderr=%q", code, stdout.String(), stderr.String())
+	}
+}
+
+func TestRunCanonicalizeInputEncoding(t *testing.T) {
+	// {"b":1,"a":"é"} as UTF-16LE with a byte order mark.
+	in := []byte{0xFF, 0xFE}
+	for _, u := range utf16.Encode([]rune(`{"b":1,"a":"é"}`)) {
+		in = append(in, byte(u), byte(u>>8))
+	}
+	var stdout, stderr bytes.Buffer
+	code := run([]string{"canonicalize", "--input-encoding", "auto"}, bytes.NewReader(in), &stdout, &stderr)
+	if code != 0 || stdout.String() != `{"a":"é","b":1}` || stderr.Len() != 0 {
+		t.Fatalf("unexpected result: exit=%d stdout=%q stderr=%q", code, stdout.String(), stderr.String())
+	}
+
+	stdout.Reset()
+	stderr.Reset()
+	code = run([]string{"canonicalize", "--input-encoding=utf-16le"}, bytes.NewReader(append(in[:4:4], 0x00, 0xDC)), &stdout, &stderr)
+	if code != 2 || !strings.Contains(stderr.String(), string(jcserr.LoneSurrogate)) {
+		t.Fatalf("expected LONE_SURROGATE, got exit=%d stderr=%q", code, stderr.String())
+	}
+
+	stdout.Reset()
+	stderr.Reset()
+	code = run([]string{"canonicalize", "--input-encoding", "ucs-2"}, strings.NewReader(`{}`), &stdout, &stderr)
+	if code != 2 || !strings.Contains(stderr.String(), string(jcserr.CLIUsage)) {
+		t.Fatalf("expected CLI_USAGE, got exit=%d stderr=%q", code, stderr.String())
+	}
+}
+
+func TestRunConvertRoundTrip(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	code := run([]string{"convert", "--to", "cbor"}, strings.NewReader(`{"b":[1,2.5],"a":true}`), &stdout, &stderr)
+	if code != 0 {
+		t.Fatalf("expected exit 0, got %d stderr=%q", code, stderr.String())
+	}
+	if got := fmt.Sprintf("%x", stdout.Bytes()); got != "a26161f561628201f94100" {
+		t.Fatalf("unexpected cbor %s", got)
+	}
+
+	cbor := stdout.String()
+	stdout.Reset()
+	stderr.Reset()
+	code = run([]string{"convert", "--from=cbor", "-"}, strings.NewReader(cbor), &stdout, &stderr)
+	if code != 0 {
+		t.Fatalf("expected exit 0, got %d stderr=%q", code, stderr.String())
+	}
+	if got := stdout.String(); got != `{"a":true,"b":[1,2.5]}` {
+		t.Fatalf("unexpected output %q", got)
+	}
+
+	stdout.Reset()
+	stderr.Reset()
+	code = run([]string{"convert", "--from", "cbor"}, strings.NewReader("\xc1\x00"), &stdout, &stderr)
+	if code != 2 || !strings.Contains(stderr.String(), string(jcserr.UnsupportedCBOR)) {
+		t.Fatalf("expected UNSUPPORTED_CBOR exit, got %d stderr=%q", code, stderr.String())
+	}
+}
+
+func TestRunConvertUsage(t *testing.T) {
+	for _, args := range [][]string{
+		{"convert"},
+		{"convert", "--to", "cbor", "--from", "cbor"},
+		{"convert", "--to", "yaml"},
+		{"convert", "--from", "json"},
+		{"convert", "--quiet", "--to", "cbor"},
+	} {
+		var stdout, stderr bytes.Buffer
+		code := run(args, strings.NewReader(`{}`), &stdout, &stderr)
+		if code != 2 || !strings.Contains(stderr.String(), string(jcserr.CLIUsage)) {
+			t.Fatalf("%q: expected CLI_USAGE, got %d stderr=%q", args, code, stderr.String())
+		}
+	}
+}
+
 func TestRunCanonicalizeWriteFailure(t *testing.T) {
 	var stderr bytes.Buffer
 	code := run(
@@ -236,11 +527,774 @@ func TestVerifyStderrFormatMatchesCanonicalize(t *testing.T) {
 	}
 }
 
+func TestRunErrorFormatJSON(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	code := run([]string{"verify", "--error-format", "json", "-"}, strings.NewReader("[1,\n\n  tru]"), &stdout, &stderr)
+	want := `{"cause":null,"class":"INVALID_GRAMMAR","column":3,"exit_code":2,"line":3,"message":"invalid literal","offset":7}` + "\n"
+	if code != 2 || stdout.Len() != 0 || stderr.String() != want {
+		t.Fatalf("unexpected result: exit=%d stdout=%q stderr=%q", code, stdout.String(), stderr.String())
+	}
+
+	stderr.Reset()
+	code = run([]string{"canonicalize", "--error-format=json", "--embedded-json=/a"}, strings.NewReader(`{"a":"[01]"}`), &stdout, &stderr)
+	want = `{"cause":"string at \"/a\", embedded byte 2: leading zero in number","class":"INVALID_GRAMMAR","embedded_offset":2,"exit_code":2,"message":"jcs: invalid embedded JSON","offset":null,"pointer":"/a"}` + "\n"
+	if code != 2 || stderr.String() != want {
+		t.Fatalf("unexpected embedded result: exit=%d stderr=%q", code, stderr.String())
+	}
+
+	// Errors raised before --error-format is parsed use the selected format.
+	stderr.Reset()
+	code = run([]string{"convert", "--bad", "--error-format=json"}, strings.NewReader(""), &stdout, &stderr)
+	want = `{"cause":null,"class":"CLI_USAGE","exit_code":2,"message":"unknown option: --bad","offset":null}` + "\n"
+	if code != 2 || stderr.String() != want {
+		t.Fatalf("unexpected usage result: exit=%d stderr=%q", code, stderr.String())
+	}
+
+	stderr.Reset()
+	code = run([]string{"verify", "--error-format=yaml"}, strings.NewReader(""), &stdout, &stderr)
+	if code != 2 || stderr.String() != "error: jcserr: CLI_USAGE: unsupported --error-format: yaml\n" {
+		t.Fatalf("unexpected format result: exit=%d stderr=%q", code, stderr.String())
+	}
+
+	var fallback bytes.Buffer
+	code = writeJSONError(&fallback, "", errors.New("unclassified \xff"), nil)
+	want = `{"cause":null,"class":"INTERNAL_ERROR","exit_code":10,"message":"unclassified ` + "\uFFFD" + `","offset":null}` + "\n"
+	if code != 10 || fallback.String() != want {
+		t.Fatalf("unexpected fallback: exit=%d stderr=%q", code, fallback.String())
+	}
+	if code := writeJSONError(failingWriter{}, "", jcserr.New(jcserr.NotCanonical, -1, "x"), nil); code != jcserr.InternalIO.ExitCode() {
+		t.Fatalf("expected exit %d on write failure, got %d", jcserr.InternalIO.ExitCode(), code)
+	}
+}
+
+// writeTree creates the files in tree, keyed by slash-separated paths, under
+// a new temporary directory and returns it.
+func writeTree(t *testing.T, tree map[string]string) string {
+	t.Helper()
+	dir := t.TempDir()
+	for name, content := range tree {
+		p := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(p), 0o750); err != nil {
+			t.Fatalf("create fixture directory: %v", err)
+		}
+		if err := os.WriteFile(p, []byte(content), 0o600); err != nil {
+			t.Fatalf("write fixture: %v", err)
+		}
+	}
+	return dir
+}
+
+func TestRunVerifyBatch(t *testing.T) {
+	dir := writeTree(t, map[string]string{
+		"a.json":          `{"a":1}`,
+		"sub/b.json":      `{"b":1,"a":2}`,
+		"sub/c.json":      `{"a":01}`,
+		"sub/d.txt":       `[ 1 ]`,
+		".git/e.json":     `[ 1 ]`,
+		"vendor/f.json":   `[ 1 ]`,
+		"sub/deep/g.json": `[1]`,
+	})
+	p := func(name string) string { return filepath.Join(dir, filepath.FromSlash(name)) }
+
+	var stdout, stderr bytes.Buffer
+	code := run([]string{"verify", dir}, strings.NewReader(""), &stdout, &stderr)
+	want := p("sub/b.json") + ": error: jcserr: NOT_CANONICAL: input is not canonical\n" +
+		p("sub/c.json") + ": error: jcserr: INVALID_GRAMMAR at byte 6: leading zero in number\n" +
+		p("vendor/f.json") + ": error: jcserr: NOT_CANONICAL: input is not canonical\n" +
+		"jcs-canon: files=5 canonical=2 not_canonical=2 rewritten=0 failed=1\n"
+	if code != 2 || stdout.Len() != 0 || stderr.String() != want {
+		t.Fatalf("unexpected result: exit=%d stdout=%q stderr=%q", code, stdout.String(), stderr.String())
+	}
+
+	// -l lists files that are not canonical; globs filter walked files only.
+	stdout.Reset()
+	stderr.Reset()
+	code = run([]string{"verify", "-l", "-q", "--exclude-glob=vendor", "--exclude-glob", "sub/c.json", "--include-glob", "*.txt", "--include-glob=*.json", dir, p("sub/c.json")}, strings.NewReader(""), &stdout, &stderr)
+	if code != 2 || stdout.String() != p("sub/b.json")+"\n"+p("sub/d.txt")+"\n" ||
+		stderr.String() != p("sub/c.json")+": error: jcserr: INVALID_GRAMMAR at byte 6: leading zero in number\n" {
+		t.Fatalf("unexpected list result: exit=%d stdout=%q stderr=%q", code, stdout.String(), stderr.String())
+	}
+
+	stdout.Reset()
+	stderr.Reset()
+	code = run([]string{"verify", "--error-format=json", "--include-glob=a.json", dir}, strings.NewReader(""), &stdout, &stderr)
+	if code != 0 || stderr.String() != `{"summary":{"canonical":1,"failed":0,"files":1,"not_canonical":0,"rewritten":0}}`+"\n" {
+		t.Fatalf("unexpected JSON summary: exit=%d stderr=%q", code, stderr.String())
+	}
+}
+
+func TestRunBatchJobsDeterministic(t *testing.T) {
+	tree := make(map[string]string)
+	for i := 0; i < 40; i++ {
+		switch i % 4 {
+		case 0:
+			tree[fmt.Sprintf("d%d/f%02d.json", i%3, i)] = `{"b":1,"a":2}`
+		case 1:
+			tree[fmt.Sprintf("d%d/f%02d.json", i%3, i)] = `[01]`
+		default:
+			tree[fmt.Sprintf("d%d/f%02d.json", i%3, i)] = `[1]`
+		}
+	}
+	dir := writeTree(t, tree)
+	var first string
+	for _, jobs := range []string{"1", "3", "16"} {
+		var stdout, stderr bytes.Buffer
+		code := run([]string{"verify", "-l", "--jobs", jobs, dir}, strings.NewReader(""), &stdout, &stderr)
+		got := fmt.Sprintf("%d\n%s\n%s", code, stdout.String(), stderr.String())
+		if first == "" {
+			first = got
+			if code != 2 || !strings.HasSuffix(stderr.String(), "jcs-canon: files=40 canonical=20 not_canonical=10 rewritten=0 failed=10\n") {
+				t.Fatalf("unexpected result: %s", got)
+			}
+		} else if got != first {
+			t.Fatalf("--jobs %s changed the output:\n%s\nwant:\n%s", jobs, got, first)
+		}
+	}
+}
+
+func TestRunCanonicalizeWrite(t *testing.T) {
+	dir := writeTree(t, map[string]string{
+		"a.json":     `{"a":1}`,
+		"b.json":     `{ "b": 1, "a": [1.0] }`,
+		"c.json":     `{"a":01}`,
+		"sub/d.json": `{"set":[2,1]}`,
+	})
+	p := func(name string) string { return filepath.Join(dir, filepath.FromSlash(name)) }
+	if err := os.Chmod(p("b.json"), 0o640); err != nil {
+		t.Fatalf("chmod fixture: %v", err)
+	}
+	if err := os.Symlink("b.json", p("link.json")); err != nil {
+		t.Fatalf("symlink fixture: %v", err)
+	}
+
+	// -l without --write lists without touching files and exits 0.
+	var stdout, stderr bytes.Buffer
+	code := run([]string{"canonicalize", "-l", "-q", "--set-pointer=/set", p("sub/d.json"), p("link.json")}, strings.NewReader(""), &stdout, &stderr)
+	if code != 0 || stdout.String() != p("sub/d.json")+"\n"+p("link.json")+"\n" || stderr.Len() != 0 {
+		t.Fatalf("unexpected list result: exit=%d stdout=%q stderr=%q", code, stdout.String(), stderr.String())
+	}
+
+	stdout.Reset()
+	stderr.Reset()
+	code = run([]string{"canonicalize", "--write", "--set-pointer=/set", dir}, strings.NewReader(""), &stdout, &stderr)
+	want := p("c.json") + ": error: jcserr: INVALID_GRAMMAR at byte 6: leading zero in number\n" +
+		"jcs-canon: profile jcs-set-arrays (not RFC 8785)\n" +
+		"jcs-canon: files=4 canonical=1 not_canonical=0 rewritten=2 failed=1\n"
+	if code != 2 || stdout.Len() != 0 || stderr.String() != want {
+		t.Fatalf("unexpected write result: exit=%d stdout=%q stderr=%q", code, stdout.String(), stderr.String())
+	}
+	for name, content := range map[string]string{"b.json": `{"a":[1],"b":1}`, "sub/d.json": `{"set":[1,2]}`, "c.json": `{"a":01}`} {
+		got, err := os.ReadFile(p(name))
+		if err != nil || string(got) != content {
+			t.Fatalf("%s = %q, %v; want %q", name, got, err, content)
+		}
+	}
+	info, err := os.Lstat(p("b.json"))
+	if err != nil || info.Mode().Perm() != 0o640 {
+		t.Fatalf("rewrite did not preserve mode: %v, %v", info.Mode(), err)
+	}
+
+	// A named symbolic link is followed: its target is rewritten.
+	if err := os.WriteFile(p("b.json"), []byte(`[ 2 ]`), 0o600); err != nil {
+		t.Fatalf("write fixture: %v", err)
+	}
+	code = run([]string{"canonicalize", "-w", "-q", p("link.json")}, strings.NewReader(""), &stdout, &stderr)
+	if got, err := os.ReadFile(p("b.json")); code != 0 || err != nil || string(got) != `[2]` {
+		t.Fatalf("symbolic link target not rewritten: exit=%d %q, %v", code, got, err)
+	}
+	if info, err := os.Lstat(p("link.json")); err != nil || info.Mode()&os.ModeSymlink == 0 {
+		t.Fatalf("rewrite replaced the symbolic link: %v", err)
+	}
+	if info, err := os.Stat(p("b.json")); err != nil || info.Mode().Perm() != 0o640 {
+		t.Fatalf("rewrite through link did not preserve mode: %v", err)
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil || len(entries) != 5 {
+		t.Fatalf("unexpected directory contents after rewrite: %v, %v", entries, err)
+	}
+}
+
+func TestRunBatchUsage(t *testing.T) {
+	for _, args := range [][]string{
+		{"verify", "-l"},
+		{"verify", ".", "-"},
+		{"canonicalize", "--jobs", "2", "a.json"},
+		{"canonicalize", "--include-glob", "*.json", "a.json", "b.json"},
+		{"verify", "--jobs", "0", "."},
+		{"verify", "--include-glob", "[", "."},
+		{"verify", "--write", "."},
+	} {
+		var stdout, stderr bytes.Buffer
+		code := run(args, strings.NewReader(""), &stdout, &stderr)
+		if code != 2 || !strings.Contains(stderr.String(), string(jcserr.CLIUsage)) {
+			t.Fatalf("%v: expected CLI_USAGE, got exit=%d stderr=%q", args, code, stderr.String())
+		}
+	}
+}
+
+func TestRunBoundOptions(t *testing.T) {
+	limit := strings.Repeat("[", 32) + strings.Repeat("]", 32)
+	deep := "[" + limit + "]"
+	cases := []struct {
+		args  []string
+		input string
+		want  int
+		class jcserr.FailureClass
+	}{
+		{[]string{"canonicalize", "--max-depth", "2"}, `[[1]]`, 0, ""},
+		{[]string{"canonicalize", "--max-depth=1"}, `[[1]]`, 2, jcserr.BoundExceeded},
+		{[]string{"verify", "-q", "--max-input-size", "3"}, `[1]`, 0, ""},
+		{[]string{"verify", "--max-input-size", "3"}, `[10]`, 2, jcserr.BoundExceeded},
+		{[]string{"verify", "--max-values", "3"}, `[1,2,3]`, 2, jcserr.BoundExceeded},
+		{[]string{"verify", "--max-object-members", "1"}, `{"a":1,"b":2}`, 2, jcserr.BoundExceeded},
+		{[]string{"verify", "--max-array-elements", "2"}, `[1,2,3]`, 2, jcserr.BoundExceeded},
+		{[]string{"convert", "--to", "cbor", "--max-string-bytes", "3"}, `["abcd"]`, 2, jcserr.BoundExceeded},
+		{[]string{"convert", "--to", "cbor", "--max-number-chars", "4"}, `[12345]`, 2, jcserr.BoundExceeded},
+		{[]string{"canonicalize", "--max-output-size", "23"}, `[1e20]`, 0, ""},
+		{[]string{"canonicalize", "--max-output-size", "22"}, `[1e20]`, 2, jcserr.BoundExceeded},
+		{[]string{"fmt", "--max-output-size", "7"}, `[1]`, 2, jcserr.BoundExceeded},
+		{[]string{"verify", "-q", "--bounds", "api-small"}, limit, 0, ""},
+		{[]string{"verify", "--bounds", "api-small"}, deep, 2, jcserr.BoundExceeded},
+		{[]string{"verify", "-q", "--bounds", "api-small", "--max-depth", "33"}, deep, 0, ""},
+		{[]string{"verify", "-q", "--bounds=bulk"}, `[1]`, 0, ""},
+		{[]string{"verify", "--bounds", "huge"}, `[1]`, 2, jcserr.CLIUsage},
+		{[]string{"verify", "--max-depth", "0"}, `[1]`, 2, jcserr.CLIUsage},
+		{[]string{"verify", "--max-values", "9007199254740992"}, `[1]`, 2, jcserr.CLIUsage},
+		{[]string{"verify", "--max-values", "1e3"}, `[1]`, 2, jcserr.CLIUsage},
+	}
+	for _, tc := range cases {
+		var stdout, stderr bytes.Buffer
+		code := run(tc.args, strings.NewReader(tc.input), &stdout, &stderr)
+		if code != tc.want || (tc.class != "" && !strings.Contains(stderr.String(), string(tc.class))) {
+			t.Fatalf("%v: exit=%d stderr=%q; want exit %d %s", tc.args, code, stderr.String(), tc.want, tc.class)
+		}
+	}
+
+	// Raised bounds reach serialization under every scheme.
+	over := strings.Repeat("[", jcstoken.DefaultMaxDepth+1) + strings.Repeat("]", jcstoken.DefaultMaxDepth+1)
+	for _, scheme := range []string{"rfc8785", "olpc", "matrix", "decimal"} {
+		var stdout, stderr bytes.Buffer
+		code := run([]string{"canonicalize", "-q", "--scheme", scheme, "--max-depth=1001"}, strings.NewReader(over), &stdout, &stderr)
+		if code != 0 || stdout.String() != over {
+			t.Fatalf("--scheme %s with raised depth: exit=%d stderr=%q", scheme, code, stderr.String())
+		}
+	}
+}
+
+// TestBoundsUsage checks the bound options synopsis shared by every
+// command's usage line against the form documented in README.md and
+// abi_manifest.json.
+func TestBoundsUsage(t *testing.T) {
+	const want = "[--bounds api-small|default|bulk] [--bounds-file file] [--max-depth n] [--max-input-size n] [--max-values n] " +
+		"[--max-object-members n] [--max-array-elements n] [--max-string-bytes n] [--max-number-chars n] [--max-output-size n]"
+	if boundsUsage != want {
+		t.Fatalf("boundsUsage = %q, want %q", boundsUsage, want)
+	}
+	if len(boundPresetNames) != len(boundPresets) {
+		t.Fatalf("boundPresetNames = %v does not list every preset", boundPresetNames)
+	}
+	for _, name := range boundPresetNames {
+		if _, ok := boundPresets[name]; !ok {
+			t.Fatalf("boundPresetNames lists unknown preset %q", name)
+		}
+	}
+	for _, cmd := range []string{
+		"canonicalize", "verify", "convert", "diff", "patch", "merge-patch", "fmt", "manifest", "serve",
+		"git-clean", "git-filter-process", "git-textconv", "git-pre-commit",
+	} {
+		var stdout bytes.Buffer
+		if code := run([]string{cmd, "--help"}, strings.NewReader(""), &stdout, io.Discard); code != 0 ||
+			!strings.Contains(stdout.String(), "jcs-canon "+cmd) || !strings.Contains(stdout.String(), want) {
+			t.Fatalf("%s --help: exit=%d stdout=%q", cmd, code, stdout.String())
+		}
+	}
+}
+
+func TestRunBoundsFile(t *testing.T) {
+	dir := writeTree(t, map[string]string{
+		"depth.json":     `{"max_depth":1}`,
+		"all.json":       `{"max_array_elements":3,"max_depth":2,"max_input_size":64,"max_number_chars":3,"max_object_members":3,"max_output_size":21,"max_string_bytes":3,"max_values":9}`,
+		"spaced.json":    `{ "max_depth": 1 }`,
+		"unknown.json":   `{"max_dept":1}`,
+		"fraction.json":  `{"max_depth":1.5}`,
+		"zero.json":      `{"max_depth":0}`,
+		"string.json":    `{"max_depth":"1"}`,
+		"array.json":     `[1]`,
+		"duplicate.json": `{"max_depth":1,"max_depth":2}`,
+	})
+	p := func(name string) string { return filepath.Join(dir, name) }
+	cases := []struct {
+		args  []string
+		input string
+		want  int
+		class jcserr.FailureClass
+	}{
+		{[]string{"canonicalize", "--bounds-file", p("depth.json")}, `[[1]]`, 2, jcserr.BoundExceeded},
+		{[]string{"canonicalize", "--bounds-file", p("depth.json"), "--max-depth", "2"}, `[[1]]`, 0, ""},
+		{[]string{"canonicalize", "--bounds", "api-small", "--bounds-file", p("depth.json")}, `[1]`, 0, ""},
+		{[]string{"verify", "-q", "--bounds-file", p("all.json")}, `[[123],{"abc":"abc"}]`, 0, ""},
+		{[]string{"verify", "--bounds-file", p("all.json")}, `[1234]`, 2, jcserr.BoundExceeded},
+		{[]string{"verify", "--bounds-file", p("all.json")}, `[[[1]]]`, 2, jcserr.BoundExceeded},
+		{[]string{"canonicalize", "--bounds-file", p("all.json")}, `[[123],{"abc":"ab\u0001"}]`, 2, jcserr.BoundExceeded},
+		{[]string{"verify", "--bounds-file", p("spaced.json")}, `[1]`, 2, jcserr.CLIUsage},
+		{[]string{"verify", "--bounds-file", p("unknown.json")}, `[1]`, 2, jcserr.CLIUsage},
+		{[]string{"verify", "--bounds-file", p("fraction.json")}, `[1]`, 2, jcserr.CLIUsage},
+		{[]string{"verify", "--bounds-file", p("zero.json")}, `[1]`, 2, jcserr.CLIUsage},
+		{[]string{"verify", "--bounds-file", p("string.json")}, `[1]`, 2, jcserr.CLIUsage},
+		{[]string{"verify", "--bounds-file", p("array.json")}, `[1]`, 2, jcserr.CLIUsage},
+		{[]string{"verify", "--bounds-file", p("duplicate.json")}, `[1]`, 2, jcserr.CLIUsage},
+		{[]string{"verify", "--bounds-file", p("missing.json")}, `[1]`, 2, jcserr.CLIUsage},
+	}
+	for _, tc := range cases {
+		var stdout, stderr bytes.Buffer
+		code := run(tc.args, strings.NewReader(tc.input), &stdout, &stderr)
+		if code != tc.want || (tc.class != "" && !strings.Contains(stderr.String(), string(tc.class))) {
+			t.Fatalf("%v: exit=%d stderr=%q; want exit %d %s", tc.args, code, stderr.String(), tc.want, tc.class)
+		}
+	}
+}
+
+func TestRunDiff(t *testing.T) {
+	dir := writeTree(t, map[string]string{
+		"a.json": `{"a":1,"b":[1,2,3],"c":{"d":"x"}}`,
+		"b.json": `{"b":[1,3,4],"c":{"d":"y","e":null},"f":true}`,
+		"c.json": `{ "c": {"d": "x"}, "b": [1, 2, 3.0], "a": 1 }`,
+	})
+	p := func(name string) string { return filepath.Join(dir, name) }
+	cases := []struct {
+		args   []string
+		stdin  string
+		want   int
+		stdout string
+	}{
+		{[]string{"diff", p("a.json"), p("b.json")}, "", 1,
+			`[{"op":"remove","path":"/a"},{"op":"replace","path":"/b/1","value":3},{"op":"replace","path":"/b/2","value":4},` +
+				`{"op":"replace","path":"/c/d","value":"y"},{"op":"add","path":"/c/e","value":null},{"op":"add","path":"/f","value":true}]`},
+		{[]string{"diff", "--format", "summary", p("a.json"), p("b.json")}, "", 1,
+			"- \"/a\": 1\n~ \"/b/1\": 2 -> 3\n~ \"/b/2\": 3 -> 4\n~ \"/c/d\": \"x\" -> \"y\"\n+ \"/c/e\": null\n+ \"/f\": true\n"},
+		{[]string{"diff", p("a.json"), p("c.json")}, "", 0, `[]`},
+		{[]string{"diff", "--format=summary", p("a.json"), p("c.json")}, "", 0, ""},
+		{[]string{"diff", "--format=patch", "-", p("a.json")}, `[1]`, 1,
+			`[{"op":"replace","path":"","value":{"a":1,"b":[1,2,3],"c":{"d":"x"}}}]`},
+		{[]string{"diff", "--format=summary", "-", p("a.json")}, `"a"`, 1,
+			"~ \"\": \"a\" -> {\"a\":1,\"b\":[1,2,3],\"c\":{\"d\":\"x\"}}\n"},
+		{[]string{"diff", "-q", p("a.json"), p("b.json")}, "", 1, ""},
+	}
+	for _, tc := range cases {
+		var stdout, stderr bytes.Buffer
+		code := run(tc.args, strings.NewReader(tc.stdin), &stdout, &stderr)
+		if code != tc.want || stdout.String() != tc.stdout || stderr.Len() != 0 {
+			t.Fatalf("%v: exit=%d stdout=%q stderr=%q; want exit %d stdout %q", tc.args, code, stdout.String(), stderr.String(), tc.want, tc.stdout)
+		}
+	}
+}
+
+func TestRunDiffErrors(t *testing.T) {
+	dir := writeTree(t, map[string]string{"a.json": `[1]`, "deep.json": `[[1]]`})
+	p := func(name string) string { return filepath.Join(dir, name) }
+	cases := []struct {
+		args   []string
+		stdin  string
+		class  jcserr.FailureClass
+		prefix string
+	}{
+		{[]string{"diff", p("a.json")}, "", jcserr.CLIUsage, "error: "},
+		{[]string{"diff", p("a.json"), p("a.json"), p("a.json")}, "", jcserr.CLIUsage, "error: "},
+		{[]string{"diff", "-", "-"}, "", jcserr.CLIUsage, "error: "},
+		{[]string{"diff", "--format", "text", p("a.json"), p("a.json")}, "", jcserr.CLIUsage, "error: "},
+		{[]string{"diff", "--to", "cbor", p("a.json"), p("a.json")}, "", jcserr.CLIUsage, "error: "},
+		{[]string{"diff", p("missing.json"), p("a.json")}, "", jcserr.CLIUsage, "error: "},
+		{[]string{"diff", p("a.json"), "-"}, `[1,]`, jcserr.InvalidGrammar, "-: error: "},
+		{[]string{"diff", "--max-depth", "1", p("a.json"), p("deep.json")}, "", jcserr.BoundExceeded, p("deep.json") + ": error: "},
+	}
+	for _, tc := range cases {
+		var stdout, stderr bytes.Buffer
+		code := run(tc.args, strings.NewReader(tc.stdin), &stdout, &stderr)
+		if code != 2 || stdout.Len() != 0 || !strings.HasPrefix(stderr.String(), tc.prefix+"jcserr: "+string(tc.class)) {
+			t.Fatalf("%v: exit=%d stdout=%q stderr=%q; want %s", tc.args, code, stdout.String(), stderr.String(), tc.class)
+		}
+	}
+	var stdout, stderr bytes.Buffer
+	if code := run([]string{"diff", "--help"}, strings.NewReader(""), &stdout, &stderr); code != 0 ||
+		!strings.HasPrefix(stdout.String(), "usage: jcs-canon diff ") {
+		t.Fatalf("diff --help: exit=%d stdout=%q", code, stdout.String())
+	}
+}
+
+func TestRunPatch(t *testing.T) {
+	dir := writeTree(t, map[string]string{
+		"doc.json":   `{"b":[1,2],"a":{"x":1}}`,
+		"ops.json":   `[{"op":"test","path":"/a/x","value":1.0},{"op":"move","from":"/a/x","path":"/b/-"},{"op":"add","path":"/c","value":"z"}]`,
+		"fail.json":  `[{"op":"replace","path":"/a/x","value":2},{"op":"test","path":"/a/x","value":1}]`,
+		"bad.json":   `[{"op":"add","path":"/c"}]`,
+		"miss.json":  `[{"op":"remove","path":"/q"}]`,
+		"grow.json":  `[{"op":"add","path":"/b/-","value":3}]`,
+		"merge.json": `{"a":{"x":null,"y":[true]},"b":null}`,
+	})
+	p := func(name string) string { return filepath.Join(dir, name) }
+	cases := []struct {
+		args   []string
+		stdin  string
+		want   int
+		stdout string
+		class  jcserr.FailureClass
+	}{
+		{[]string{"patch", p("ops.json"), p("doc.json")}, "", 0, `{"a":{},"b":[1,2,1],"c":"z"}`, ""},
+		{[]string{"patch", p("ops.json")}, `{"a":{"x":1},"b":[]}`, 0, `{"a":{},"b":[1],"c":"z"}`, ""},
+		{[]string{"patch", "-", p("doc.json")}, `[]`, 0, `{"a":{"x":1},"b":[1,2]}`, ""},
+		{[]string{"merge-patch", p("merge.json"), p("doc.json")}, "", 0, `{"a":{"y":[true]}}`, ""},
+		{[]string{"merge-patch", p("merge.json")}, `"s"`, 0, `{"a":{"y":[true]}}`, ""},
+		{[]string{"patch", p("fail.json"), p("doc.json")}, "", 2, "", jcserr.PatchTestFailed},
+		{[]string{"patch", p("bad.json"), p("doc.json")}, "", 2, "", jcserr.InvalidPatch},
+		{[]string{"patch", p("merge.json"), p("doc.json")}, "", 2, "", jcserr.InvalidPatch},
+		{[]string{"patch", p("miss.json"), p("doc.json")}, "", 2, "", jcserr.InvalidPointer},
+		{[]string{"patch", "--max-array-elements", "2", p("grow.json"), p("doc.json")}, "", 2, "", jcserr.BoundExceeded},
+		{[]string{"patch", "-", "-"}, "", 2, "", jcserr.CLIUsage},
+		{[]string{"merge-patch", p("merge.json"), p("doc.json"), p("doc.json")}, "", 2, "", jcserr.CLIUsage},
+		{[]string{"merge-patch"}, "", 2, "", jcserr.CLIUsage},
+		{[]string{"merge-patch", "--quiet", p("merge.json")}, "", 2, "", jcserr.CLIUsage},
+	}
+	for _, tc := range cases {
+		var stdout, stderr bytes.Buffer
+		code := run(tc.args, strings.NewReader(tc.stdin), &stdout, &stderr)
+		wantErr := ""
+		if tc.class != "" {
+			wantErr = "error: jcserr: " + string(tc.class)
+		}
+		if code != tc.want || stdout.String() != tc.stdout || !strings.HasPrefix(stderr.String(), wantErr) || (wantErr == "") != (stderr.Len() == 0) {
+			t.Fatalf("%v: exit=%d stdout=%q stderr=%q; want exit %d stdout %q class %s", tc.args, code, stdout.String(), stderr.String(), tc.want, tc.stdout, tc.class)
+		}
+	}
+	for _, cmd := range []string{"patch", "merge-patch"} {
+		var stdout, stderr bytes.Buffer
+		if code := run([]string{cmd, "-h"}, strings.NewReader(""), &stdout, &stderr); code != 0 ||
+			!strings.HasPrefix(stdout.String(), "usage: jcs-canon "+cmd+" ") {
+			t.Fatalf("%s -h: exit=%d stdout=%q", cmd, code, stdout.String())
+		}
+	}
+}
+
+func TestRunServe(t *testing.T) {
+	cases := [][]string{
+		{"serve"},
+		{"serve", "--socket", filepath.Join(t.TempDir(), "s.sock"), "--listen", "127.0.0.1:0"},
+		{"serve", "--listen", "0.0.0.0:0"},
+		{"serve", "--listen", "localhost:0"},
+		{"serve", "--listen", "127.0.0.1:0", "-j", "0"},
+		{"serve", "--listen", "127.0.0.1:0", "doc.json"},
+		{"serve", "--listen", "127.0.0.1:0", "--quiet"},
+	}
+	for _, args := range cases {
+		var stdout, stderr bytes.Buffer
+		if code := run(args, strings.NewReader(""), &stdout, &stderr); code != 2 ||
+			!strings.HasPrefix(stderr.String(), "error: jcserr: CLI_USAGE") {
+			t.Fatalf("%v: exit=%d stderr=%q", args, code, stderr.String())
+		}
+	}
+	var stdout, stderr bytes.Buffer
+	if code := run([]string{"serve", "-h"}, strings.NewReader(""), &stdout, &stderr); code != 0 ||
+		!strings.HasPrefix(stdout.String(), "usage: jcs-canon serve ") {
+		t.Fatalf("serve -h: exit=%d stdout=%q", code, stdout.String())
+	}
+
+	path := filepath.Join(t.TempDir(), "jcs.sock")
+	notices, notice := io.Pipe()
+	exit := make(chan int, 1)
+	go func() {
+		exit <- run([]string{"serve", "--socket", path, "--max-depth", "1"}, strings.NewReader(""), io.Discard, notice)
+		_ = notice.Close()
+	}()
+	line, err := bufio.NewReader(notices).ReadString('\n')
+	if err != nil || line != "jcs-canon: serving on unix:"+path+"\n" {
+		t.Fatalf("notice %q: %v", line, err)
+	}
+	go func() { _, _ = io.Copy(io.Discard, notices) }()
+	client := &http.Client{Transport: &http.Transport{
+		DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+			return (&net.Dialer{}).DialContext(ctx, "unix", path)
+		},
+	}}
+	for body, want := range map[string]string{
+		`{"b":1,"a":2}`: `{"a":2,"b":1}`,
+		`[[1]]`:         `"class":"BOUND_EXCEEDED"`,
+	} {
+		resp, err := client.Post("http://jcs/v1/canonicalize", "application/json", strings.NewReader(body))
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, err := io.ReadAll(resp.Body)
+		if closeErr := resp.Body.Close(); err != nil || closeErr != nil || !strings.Contains(string(got), want) {
+			t.Fatalf("POST %s: %q, %v, %v", body, got, err, closeErr)
+		}
+	}
+	if err := syscall.Kill(os.Getpid(), syscall.SIGTERM); err != nil {
+		t.Fatal(err)
+	}
+	if code := <-exit; code != 0 {
+		t.Fatalf("serve exit=%d after SIGTERM", code)
+	}
+}
+
+func TestRunFmt(t *testing.T) {
+	dir := writeTree(t, map[string]string{"a.json": `{"b":[1.0,{"d":[],"c":"\u00e9"}],"a":1E-7}`})
+	cases := []struct {
+		args   []string
+		stdin  string
+		want   int
+		stdout string
+		stderr string
+	}{
+		{[]string{"fmt", filepath.Join(dir, "a.json")}, "", 0,
+			"{\n  \"a\": 1e-7,\n  \"b\": [\n    1,\n    {\n      \"c\": \"\u00e9\",\n      \"d\": []\n    }\n  ]\n}\n", ""},
+		{[]string{"fmt", "--indent", "tab"}, `{"b":0,"a":[true]}`, 0, "{\n\t\"a\": [\n\t\ttrue\n\t],\n\t\"b\": 0\n}\n", ""},
+		{[]string{"fmt", "--indent=0", "-"}, `[1,2]`, 0, "[\n1,\n2\n]\n", ""},
+		{[]string{"fmt"}, `"x"`, 0, "\"x\"\n", ""},
+		{[]string{"fmt"}, `{"a":1,"a":2}`, 2, "", "-: error: jcserr: DUPLICATE_KEY"},
+		{[]string{"fmt", "--max-depth", "1"}, `[[1]]`, 2, "", "-: error: jcserr: BOUND_EXCEEDED"},
+		{[]string{"fmt", "--indent", "9"}, `1`, 2, "", "error: jcserr: CLI_USAGE"},
+		{[]string{"fmt", "--indent", "02"}, `1`, 2, "", "error: jcserr: CLI_USAGE"},
+		{[]string{"fmt", "a.json", "b.json"}, ``, 2, "", "error: jcserr: CLI_USAGE"},
+	}
+	for _, tc := range cases {
+		var stdout, stderr bytes.Buffer
+		code := run(tc.args, strings.NewReader(tc.stdin), &stdout, &stderr)
+		if code != tc.want || stdout.String() != tc.stdout || !strings.HasPrefix(stderr.String(), tc.stderr) || (tc.stderr == "") != (stderr.Len() == 0) {
+			t.Fatalf("%v: exit=%d stdout=%q stderr=%q", tc.args, code, stdout.String(), stderr.String())
+		}
+		if code != 0 {
+			continue
+		}
+		input := []byte(tc.stdin)
+		if tc.stdin == "" {
+			input = []byte(`{"b":[1.0,{"d":[],"c":"\u00e9"}],"a":1E-7}`)
+		}
+		want, err := canonicalBytes(input, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got, err := canonicalBytes(stdout.Bytes(), nil); err != nil || !bytes.Equal(got, want) {
+			t.Fatalf("%v: output canonicalizes to %q (%v), want %q", tc.args, got, err, want)
+		}
+	}
+}
+
+func TestRunManifest(t *testing.T) {
+	dir := writeTree(t, map[string]string{"a.json": `{"b":1,"a":[1.0]}`, "sub/b.json": `"x"`, "c.txt": `not json`})
+	var manifest, stderr bytes.Buffer
+	if code := run([]string{"manifest", "create", dir}, strings.NewReader(""), &manifest, &stderr); code != 0 || stderr.Len() != 0 {
+		t.Fatalf("create: exit=%d stderr=%q", code, stderr.String())
+	}
+	if got, err := canonicalBytes(manifest.Bytes(), nil); err != nil || !bytes.Equal(got, manifest.Bytes()) {
+		t.Fatalf("manifest is not canonical: %q", manifest.String())
+	}
+	if !strings.HasPrefix(manifest.String(), `{"algorithm":"sha-256","files":{"a.json":"`) || !strings.Contains(manifest.String(), `"sub/b.json":"`) {
+		t.Fatalf("manifest = %q", manifest.String())
+	}
+
+	writeFile := func(name, content string) {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	verify := func(args ...string) (int, string, string) {
+		var stdout, stderr bytes.Buffer
+		code := run(append([]string{"manifest", "verify"}, args...), bytes.NewReader(manifest.Bytes()), &stdout, &stderr)
+		return code, stdout.String(), stderr.String()
+	}
+	if code, stdout, stderr := verify("-", dir); code != 0 || stdout != "" || stderr != "jcs-canon: files=2 matched=2 added=0 missing=0 changed=0 failed=0\n" {
+		t.Fatalf("verify unchanged: exit=%d stdout=%q stderr=%q", code, stdout, stderr)
+	}
+
+	// Reformatting keeps the digest; a semantic change, a new file, and a
+	// removed file are each reported.
+	writeFile("a.json", "{\n  \"a\": [1],\n  \"b\": 1\n}\n")
+	if code, stdout, _ := verify("--quiet", "-", dir); code != 0 || stdout != "" {
+		t.Fatalf("verify reformatted: exit=%d stdout=%q", code, stdout)
+	}
+	writeFile("a.json", `{"a":[2],"b":1}`)
+	writeFile("new.json", `null`)
+	if err := os.Remove(filepath.Join(dir, "sub", "b.json")); err != nil {
+		t.Fatal(err)
+	}
+	if code, stdout, stderr := verify("-", dir); code != 2 || stdout != "changed a.json\nadded new.json\nmissing sub/b.json\n" ||
+		stderr != "error: jcserr: DIGEST_MISMATCH: directory does not match the manifest\njcs-canon: files=2 matched=0 added=1 missing=1 changed=1 failed=0\n" {
+		t.Fatalf("verify changed: exit=%d stdout=%q stderr=%q", code, stdout, stderr)
+	}
+
+	writeFile("new.json", `{"a":1,"a":2}`)
+	if code, _, stderr := verify("--quiet", "-", dir); code != 2 || !strings.Contains(stderr, "new.json: error: jcserr: DUPLICATE_KEY") {
+		t.Fatalf("verify invalid file: exit=%d stderr=%q", code, stderr)
+	}
+	manifest.Reset()
+	if code := run([]string{"manifest", "create", dir}, strings.NewReader(""), &manifest, &stderr); code != 2 || manifest.Len() != 0 {
+		t.Fatalf("create with invalid file: exit=%d stdout=%q", code, manifest.String())
+	}
+}
+
+func TestRunManifestUsage(t *testing.T) {
+	dir := writeTree(t, map[string]string{"a.json": `1`})
+	cases := []struct {
+		args  []string
+		stdin string
+	}{
+		{[]string{"manifest"}, ""},
+		{[]string{"manifest", "update", dir}, ""},
+		{[]string{"manifest", "create"}, ""},
+		{[]string{"manifest", "create", filepath.Join(dir, "a.json")}, ""},
+		{[]string{"manifest", "verify", "-"}, ""},
+		{[]string{"manifest", "verify", "-", dir}, `[]`},
+		{[]string{"manifest", "verify", "-", dir}, `{"algorithm":"md5","files":{}}`},
+		{[]string{"manifest", "verify", "-", dir}, `{"algorithm":"sha-256","files":{"../a.json":"` + strings.Repeat("0", 64) + `"}}`},
+		{[]string{"manifest", "verify", "-", dir}, `{"algorithm":"sha-256","files":{"a.json":"` + strings.Repeat("A", 64) + `"}}`},
+		{[]string{"manifest", "verify", "-", dir}, `{"algorithm":"sha-256","files":{},"x":1}`},
+		{[]string{"manifest", "verify", "-", dir}, `{"algorithm":"sha-256"`},
+	}
+	for _, tc := range cases {
+		var stdout, stderr bytes.Buffer
+		code := run(tc.args, strings.NewReader(tc.stdin), &stdout, &stderr)
+		if code != 2 || stdout.Len() != 0 || !strings.HasPrefix(stderr.String(), "error: jcserr: CLI_USAGE") {
+			t.Fatalf("%v %q: exit=%d stdout=%q stderr=%q", tc.args, tc.stdin, code, stdout.String(), stderr.String())
+		}
+	}
+}
+
+func TestRunGitClean(t *testing.T) {
+	cases := []struct {
+		args   []string
+		stdin  string
+		want   int
+		stdout string
+		stderr string
+	}{
+		{[]string{"git-clean", "a.json"}, `{"b":1, "a":1E1}`, 0, `{"a":10,"b":1}`, ""},
+		{[]string{"git-clean"}, `[ ]`, 0, `[]`, ""},
+		{[]string{"git-clean", "a.json"}, `{"a":1,}`, 2, "", "a.json: error: jcserr: INVALID_GRAMMAR"},
+		{[]string{"git-clean", "--max-input-size", "2", "a.json"}, `[1]`, 2, "", "a.json: error: jcserr: BOUND_EXCEEDED"},
+		{[]string{"git-clean", "a.json", "b.json"}, `1`, 2, "", "error: jcserr: CLI_USAGE"},
+	}
+	for _, tc := range cases {
+		var stdout, stderr bytes.Buffer
+		code := run(tc.args, strings.NewReader(tc.stdin), &stdout, &stderr)
+		if code != tc.want || stdout.String() != tc.stdout || !strings.HasPrefix(stderr.String(), tc.stderr) || (tc.stderr == "") != (stderr.Len() == 0) {
+			t.Fatalf("%v: exit=%d stdout=%q stderr=%q", tc.args, code, stdout.String(), stderr.String())
+		}
+	}
+}
+
+func TestRunGitFilterProcess(t *testing.T) {
+	pkt := func(lines ...string) string {
+		var b strings.Builder
+		for _, line := range lines {
+			if line == "" {
+				b.WriteString("0000")
+			} else {
+				fmt.Fprintf(&b, "%04x%s", len(line)+4, line)
+			}
+		}
+		return b.String()
+	}
+	stream := pkt("git-filter-client\n", "version=2\n", "", "capability=clean\n", "capability=smudge\n", "") +
+		pkt("command=clean\n", "pathname=a.json\n", "", `{"b":1,"a":2}`, "") +
+		pkt("command=clean\n", "pathname=bad.json\n", "", `[1,2,3]`, "")
+	var stdout, stderr bytes.Buffer
+	code := run([]string{"git-filter-process", "--max-array-elements", "2"}, strings.NewReader(stream), &stdout, &stderr)
+	want := pkt("git-filter-server\n", "version=2\n", "", "capability=clean\n", "") +
+		pkt("status=success\n", "", `{"a":2,"b":1}`, "", "") +
+		pkt("status=error\n", "")
+	if code != 0 || stdout.String() != want || !strings.HasPrefix(stderr.String(), "bad.json: error: jcserr: BOUND_EXCEEDED") {
+		t.Fatalf("exit=%d stdout=%q stderr=%q", code, stdout.String(), stderr.String())
+	}
+	stdout.Reset()
+	stderr.Reset()
+	if code := run([]string{"git-filter-process"}, strings.NewReader("hello"), &stdout, &stderr); code != 2 ||
+		!strings.HasPrefix(stderr.String(), "error: jcserr: CLI_USAGE") {
+		t.Fatalf("non-protocol input: exit=%d stderr=%q", code, stderr.String())
+	}
+}
+
+func TestRunGitTextconv(t *testing.T) {
+	dir := writeTree(t, map[string]string{
+		"a.json":   `{"b":[1,{},[]],"a":{"x":"q\"{,}:[\\"}}`,
+		"bad.json": `{"a":}`,
+	})
+	var stdout, stderr bytes.Buffer
+	if code := run([]string{"git-textconv", filepath.Join(dir, "a.json")}, strings.NewReader(""), &stdout, &stderr); code != 0 || stderr.Len() != 0 {
+		t.Fatalf("exit=%d stderr=%q", code, stderr.String())
+	}
+	want := "{\n  \"a\": {\n    \"x\": \"q\\\"{,}:[\\\\\"\n  },\n  \"b\": [\n    1,\n    {},\n    []\n  ]\n}\n"
+	if stdout.String() != want {
+		t.Fatalf("got %q, want %q", stdout.String(), want)
+	}
+	if canonical, err := canonicalBytes(stdout.Bytes(), nil); err != nil || string(canonical) != `{"a":{"x":"q\"{,}:[\\"},"b":[1,{},[]]}` {
+		t.Fatalf("indented output does not canonicalize back: %q %v", canonical, err)
+	}
+	stdout.Reset()
+	if code := run([]string{"git-textconv", filepath.Join(dir, "bad.json")}, strings.NewReader(""), &stdout, &stderr); code != 0 ||
+		stdout.String() != `{"a":}` || !strings.Contains(stderr.String(), "bad.json: error: jcserr: INVALID_GRAMMAR") {
+		t.Fatalf("invalid input: exit=%d stdout=%q stderr=%q", code, stdout.String(), stderr.String())
+	}
+	for _, args := range [][]string{{"git-textconv"}, {"git-textconv", filepath.Join(dir, "missing.json")}} {
+		stderr.Reset()
+		if code := run(args, strings.NewReader(""), io.Discard, &stderr); code != 2 || !strings.HasPrefix(stderr.String(), "error: jcserr: CLI_USAGE") {
+			t.Fatalf("%v: exit=%d stderr=%q", args, code, stderr.String())
+		}
+	}
+}
+
+func TestRunGitPreCommit(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("GIT_CONFIG_NOSYSTEM", "1")
+	dir := writeTree(t, map[string]string{
+		"ok.json":         `{"a":1}`,
+		"sub/staged.json": `{"b":1,"a":2}`,
+		"vendor/x.json":   `[ ]`,
+		".hidden/y.json":  `[ ]`,
+		"notes.txt":       `not json`,
+		"unstaged.json":   `[ ]`,
+	})
+	t.Chdir(dir)
+	for _, args := range [][]string{{"init", "-q"}, {"add", "ok.json", "sub", "vendor", ".hidden", "notes.txt"}} {
+		if out, err := exec.Command("git", args...).CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, out)
+		}
+	}
+	// The working tree copy is canonical; the staged copy is not.
+	if err := os.WriteFile("sub/staged.json", []byte(`{"a":2,"b":1}`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	var stdout, stderr bytes.Buffer
+	code := run([]string{"git-pre-commit", "--exclude-glob", "vendor"}, strings.NewReader(""), &stdout, &stderr)
+	want := "sub/staged.json: error: jcserr: NOT_CANONICAL: input is not canonical\njcs-canon: files=2 canonical=1 not_canonical=1 rewritten=0 failed=0\n"
+	if code != 2 || stdout.Len() != 0 || stderr.String() != want {
+		t.Fatalf("exit=%d stdout=%q stderr=%q", code, stdout.String(), stderr.String())
+	}
+	if out, err := exec.Command("git", "add", "sub/staged.json").CombinedOutput(); err != nil {
+		t.Fatalf("git add: %v\n%s", err, out)
+	}
+	stderr.Reset()
+	if code := run([]string{"git-pre-commit", "-q", "--exclude-glob", "vendor"}, strings.NewReader(""), &stdout, &stderr); code != 0 || stderr.Len() != 0 {
+		t.Fatalf("after restaging: exit=%d stderr=%q", code, stderr.String())
+	}
+	stderr.Reset()
+	if code := run([]string{"git-pre-commit", "-q"}, strings.NewReader(""), &stdout, &stderr); code != 2 ||
+		!strings.HasPrefix(stderr.String(), "vendor/x.json: error: jcserr: NOT_CANONICAL") {
+		t.Fatalf("without exclusion: exit=%d stderr=%q", code, stderr.String())
+	}
+	t.Chdir(t.TempDir())
+	stderr.Reset()
+	if code := run([]string{"git-pre-commit"}, strings.NewReader(""), &stdout, &stderr); code != 2 || !strings.HasPrefix(stderr.String(), "error: jcserr: CLI_USAGE") {
+		t.Fatalf("outside a repository: exit=%d stderr=%q", code, stderr.String())
+	}
+}
+
 func TestReadInputOversizeClassBoundExceededForStdinAndFile(t *testing.T) {
 	const maxInput = 8
 	oversized := strings.Repeat("x", maxInput+1)
 
-	_, err := readInput(nil, strings.NewReader(oversized), maxInput)
+	_, _, err := loadInput(nil, strings.NewReader(oversized), maxInput)
 	if err == nil {
 		t.Fatal("expected oversize stdin failure")
 	}
@@ -252,7 +1306,7 @@ func TestReadInputOversizeClassBoundExceededForStdinAndFile(t *testing.T) {
 		t.Fatalf("write oversized fixture: %v", writeErr)
 	}
 
-	_, err = readInput([]string{p}, strings.NewReader(""), maxInput)
+	_, _, err = loadInput([]string{p}, strings.NewReader(""), maxInput)
 	if err == nil {
 		t.Fatal("expected oversize file failure")
 	}
@@ -260,7 +1314,7 @@ func TestReadInputOversizeClassBoundExceededForStdinAndFile(t *testing.T) {
 }
 
 func TestReadInputDirectoryPathReturnsCLIUsage(t *testing.T) {
-	_, err := readInput([]string{t.TempDir()}, strings.NewReader(""), 64)
+	_, _, err := loadInput([]string{t.TempDir()}, strings.NewReader(""), 64)
 	if err == nil {
 		t.Fatal("expected directory read failure")
 	}
@@ -268,13 +1322,166 @@ func TestReadInputDirectoryPathReturnsCLIUsage(t *testing.T) {
 }
 
 func TestReadInputMissingFileReturnsCLIUsage(t *testing.T) {
-	_, err := readInput([]string{filepath.Join(t.TempDir(), "missing.json")}, strings.NewReader(""), 64)
+	_, _, err := loadInput([]string{filepath.Join(t.TempDir(), "missing.json")}, strings.NewReader(""), 64)
 	if err == nil {
 		t.Fatal("expected missing file failure")
 	}
 	assertClass(t, err, jcserr.CLIUsage)
 }
 
+func TestLoadInputMapsRegularFile(t *testing.T) {
+	p := filepath.Join(t.TempDir(), "mapped.json")
+	if err := os.WriteFile(p, []byte(`{"b":1,"a":2}`), 0o600); err != nil {
+		t.Fatalf("write fixture: %v", err)
+	}
+	mapped := func() bool {
+		maps, err := os.ReadFile("/proc/self/maps")
+		if err != nil {
+			t.Fatalf("read /proc/self/maps: %v", err)
+		}
+		return strings.Contains(string(maps), p)
+	}
+
+	data, release, err := loadInput([]string{p}, strings.NewReader(""), 64)
+	if err != nil || string(data) != `{"b":1,"a":2}` {
+		t.Fatalf("loadInput = %q, %v", data, err)
+	}
+	if !mapped() {
+		t.Fatal("regular file is not memory-mapped")
+	}
+	release()
+	if mapped() {
+		t.Fatal("mapping not released")
+	}
+
+	_, _, err = loadInput([]string{p}, strings.NewReader(""), 12)
+	assertClass(t, err, jcserr.BoundExceeded)
+}
+
+func TestLoadInputReadsPipesAndSpecialFiles(t *testing.T) {
+	dir := t.TempDir()
+	fifo := filepath.Join(dir, "fifo")
+	if err := syscall.Mkfifo(fifo, 0o600); err != nil {
+		t.Skipf("mkfifo: %v", err)
+	}
+	go func() {
+		if err := os.WriteFile(fifo, []byte(`[1, 2]`), 0o600); err != nil {
+			t.Errorf("write fifo: %v", err)
+		}
+	}()
+	if data, release, err := loadInput([]string{fifo}, strings.NewReader(""), 64); err != nil || string(data) != `[1, 2]` {
+		t.Fatalf("fifo: %q, %v", data, err)
+	} else {
+		release()
+	}
+
+	// /proc files report a size of zero but have content.
+	if data, _, err := loadInput([]string{"/proc/self/status"}, strings.NewReader(""), 1<<20); err != nil || len(data) == 0 {
+		t.Fatalf("/proc/self/status: %d bytes, %v", len(data), err)
+	}
+
+	empty := filepath.Join(dir, "empty.json")
+	if err := os.WriteFile(empty, nil, 0o600); err != nil {
+		t.Fatalf("write fixture: %v", err)
+	}
+	if data, _, err := loadInput([]string{empty}, strings.NewReader(""), 64); err != nil || len(data) != 0 {
+		t.Fatalf("empty file: %q, %v", data, err)
+	}
+
+	go func() {
+		if err := os.WriteFile(fifo, []byte(strings.Repeat("1", 65)), 0o600); err != nil {
+			t.Errorf("write fifo: %v", err)
+		}
+	}()
+	_, _, err := loadInput([]string{fifo}, strings.NewReader(""), 64)
+	assertClass(t, err, jcserr.BoundExceeded)
+}
+
+func TestGuardMappedReportsTruncatedInput(t *testing.T) {
+	p := filepath.Join(t.TempDir(), "truncated.json")
+	content := "[" + strings.Repeat(" ", 3*os.Getpagesize()) + "1]"
+	write := func() {
+		if err := os.WriteFile(p, []byte(content), 0o600); err != nil {
+			t.Fatalf("write fixture: %v", err)
+		}
+	}
+	write()
+	data, release, err := loadInput([]string{p}, strings.NewReader(""), len(content))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer release()
+	maps, err := os.ReadFile("/proc/self/maps")
+	if err != nil || !strings.Contains(string(maps), p) {
+		t.Skip("input is not memory-mapped on this platform")
+	}
+	if err := os.Truncate(p, 0); err != nil {
+		t.Fatal(err)
+	}
+	var got error
+	func() {
+		defer guardMapped(func(err error) { got = err })()
+		_, _ = canonicalBytes(data, nil)
+	}()
+	assertClass(t, got, jcserr.InternalIO)
+
+	// A batch worker reports the fault as the failure of the file.
+	write()
+	b := &batch{
+		jobs:      1,
+		canonical: func(input []byte) ([]byte, error) { return canonicalBytes(input, nil) },
+		read: func(name string) ([]byte, func(), error) {
+			data, release, err := loadInput([]string{name}, nil, len(content))
+			if err == nil {
+				err = os.Truncate(name, 0)
+			}
+			return data, release, err
+		},
+	}
+	results := b.run([]batchEntry{{path: p}})
+	if results[0].status != fileFailed || !strings.Contains(string(results[0].diagnostic), "INTERNAL_IO") {
+		t.Fatalf("truncated file in a batch: %+v", results[0])
+	}
+}
+
+func TestRunReleasesMappedInputs(t *testing.T) {
+	dir := writeTree(t, map[string]string{
+		"tree/good.json": `{"a":1}`,
+		"tree/bad.json":  "{\n  \"a\": tru\n}",
+		"bounds.json":    `{"max_depth":4}`,
+	})
+	p := func(name string) string { return filepath.Join(dir, name) }
+	var manifest, stderr bytes.Buffer
+	if code := run([]string{"manifest", "create", "--exclude-glob", "bad.json", p("tree")}, strings.NewReader(""), &manifest, &stderr); code != 0 {
+		t.Fatalf("manifest create: exit=%d stderr=%q", code, stderr.String())
+	}
+	if err := os.WriteFile(p("manifest.json"), manifest.Bytes(), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	runs := [][]string{
+		{"canonicalize", "--bounds-file", p("bounds.json"), p("tree/good.json")},
+		{"verify", "--error-format", "json", p("tree/good.json"), p("tree/bad.json")},
+		{"verify", "--error-format", "json", "--jobs", "1", p("tree")},
+		{"fmt", p("tree/bad.json")},
+		{"git-textconv", p("tree/bad.json")},
+		{"manifest", "verify", "--exclude-glob", "bad.json", p("manifest.json"), p("tree")},
+	}
+	for _, args := range runs {
+		var stdout, stderr bytes.Buffer
+		run(args, strings.NewReader(""), &stdout, &stderr)
+		maps, err := os.ReadFile("/proc/self/maps")
+		if err != nil {
+			t.Skipf("read /proc/self/maps: %v", err)
+		}
+		if strings.Contains(string(maps), dir) {
+			t.Fatalf("%v: an input is still mapped after the command", args)
+		}
+		if args[0] == "verify" && !strings.Contains(stderr.String(), `"line":2,`) {
+			t.Fatalf("%v: failure not located in its input: %q", args, stderr.String())
+		}
+	}
+}
+
 func assertClass(t *testing.T, err error, class jcserr.FailureClass) {
 	t.Helper()
 	var je *jcserr.Error
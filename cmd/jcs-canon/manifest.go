@@ -0,0 +1,353 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/lattice-substrate/json-canon/jcs"
+	"github.com/lattice-substrate/json-canon/jcserr"
+	"github.com/lattice-substrate/json-canon/jcstoken"
+)
+
+// manifestAlgorithm names the digest a manifest records for each file.
+const manifestAlgorithm = "sha-256"
+
+// maxManifestSize limits the size of a manifest read by manifest verify.
+const maxManifestSize = 1 << 30
+
+// maxManifestPathBytes limits the length of a path in a manifest, the
+// PATH_MAX of Linux.
+const maxManifestPathBytes = 4096
+
+// minManifestEntryBytes is the length of the shortest manifest entry: a
+// quoted one-byte path, a colon, a quoted digest, and a comma.
+const minManifestEntryBytes = 3 + 1 + 66 + 1
+
+// manifestFile is one file of a manifest: its slash-separated path relative
+// to the manifested directory and the hex SHA-256 digest of its canonical
+// form.
+type manifestFile struct {
+	path   string
+	digest string
+}
+
+// manifestScan is the digest of every file under a directory.
+type manifestScan struct {
+	files []manifestFile
+	// failed holds the paths of files that could not be canonicalized.
+	failed map[string]bool
+	// code is the highest exit code of the failures.
+	code int
+}
+
+// manifestChange is a difference between a manifest and a directory.
+type manifestChange struct {
+	kind string
+	path string
+}
+
+// cmdManifest creates or verifies a manifest of the canonical digests of the
+// JSON files under a directory. A digest covers the canonical form of a
+// file, so reformatting the file keeps its digest while any semantic change
+// alters it.
+//
+// CLI-MANIFEST-001: manifest create writes a canonical digest manifest.
+// CLI-MANIFEST-002: manifest verify reports added, missing, and changed files.
+func cmdManifest(args []string, stdin io.Reader, stdout io.Writer, stderr io.Writer) int {
+	diag := newDiagnostics(stderr, args)
+	fl, positional, err := parseFlags("manifest", args)
+	if err != nil {
+		return diag.fail(err)
+	}
+
+	// CLI-FLAG-003: subcommand --help writes to stdout (frozen stream policy).
+	if fl.help {
+		helpErr := writeManifestHelp(stdout)
+		if helpErr != nil {
+			return diag.fail(jcserr.Wrap(jcserr.InternalIO, -1, "write manifest help output", helpErr))
+		}
+		return 0
+	}
+
+	bounds, err := resolveBounds(&fl)
+	if err != nil {
+		return diag.fail(err)
+	}
+	canonical := func(input []byte) ([]byte, error) { return canonicalBytes(input, &bounds) }
+	b, err := newBatch("manifest", &fl, bounds.MaxInputSize, canonical)
+	if err != nil {
+		return diag.fail(err)
+	}
+	b.digest = true
+
+	if len(positional) == 0 {
+		return diag.fail(jcserr.New(jcserr.CLIUsage, -1, "manifest requires an action: create or verify"))
+	}
+	switch positional[0] {
+	case "create":
+		return manifestCreate(b, positional[1:], stdout, diag)
+	case "verify":
+		return manifestVerify(b, positional[1:], fl.quiet, stdin, stdout, diag)
+	}
+	return diag.fail(jcserr.New(jcserr.CLIUsage, -1, fmt.Sprintf("unknown manifest action: %s (want create or verify)", positional[0])))
+}
+
+// manifestCreate writes the canonical manifest of the directory named by
+// positional. No manifest is written when any file fails.
+func manifestCreate(b *batch, positional []string, stdout io.Writer, diag *diagnostics) int {
+	if len(positional) != 1 || !isDirectory(positional[0]) {
+		return diag.fail(jcserr.New(jcserr.CLIUsage, -1, "manifest create requires one directory argument"))
+	}
+	scan := scanManifestDir(b, positional[0], diag)
+	if scan.code != 0 {
+		return scan.code
+	}
+	output, err := jcs.SerializeWithOptions(manifestValue(scan.files), manifestBounds(len(scan.files)))
+	if err != nil {
+		return diag.fail(err)
+	}
+	if _, err := stdout.Write(output); err != nil {
+		return diag.fail(jcserr.Wrap(jcserr.InternalIO, -1, "writing output", err))
+	}
+	return 0
+}
+
+// manifestVerify compares the manifest and directory named by positional.
+// Differences are listed on stdout in path order and fail as
+// DIGEST_MISMATCH; files that cannot be canonicalized are reported as in
+// batch mode. Unless quiet, a summary follows on stderr.
+func manifestVerify(b *batch, positional []string, quiet bool, stdin io.Reader, stdout io.Writer, diag *diagnostics) int {
+	if len(positional) != 2 || !isDirectory(positional[1]) {
+		return diag.fail(jcserr.New(jcserr.CLIUsage, -1, "manifest verify requires a manifest file and a directory"))
+	}
+	expected, err := readManifest(positional[0], stdin)
+	if err != nil {
+		return diag.fail(err)
+	}
+	scan := scanManifestDir(b, positional[1], diag)
+	changes := compareManifest(expected, scan)
+	code := max(scan.code, reportManifestChanges(changes, stdout, diag))
+	if quiet {
+		return code
+	}
+	if err := writeManifestSummary(diag, len(scan.files)+len(scan.failed), changes, len(scan.failed)); err != nil {
+		return max(code, diag.fail(jcserr.Wrap(jcserr.InternalIO, -1, "writing summary", err)))
+	}
+	return code
+}
+
+// reportManifestChanges lists changes on stdout and, when there are any,
+// reports DIGEST_MISMATCH. It returns the resulting exit code.
+func reportManifestChanges(changes []manifestChange, stdout io.Writer, diag *diagnostics) int {
+	for _, c := range changes {
+		if err := writeLine(stdout, c.kind+" "+c.path); err != nil {
+			return diag.fail(jcserr.Wrap(jcserr.InternalIO, -1, "writing manifest changes", err))
+		}
+	}
+	if len(changes) == 0 {
+		return 0
+	}
+	return diag.fail(jcserr.New(jcserr.DigestMismatch, -1, "directory does not match the manifest"))
+}
+
+// scanManifestDir digests the files under root that the batch options
+// select, reporting each file that fails.
+//
+// MANIFEST-DIGEST-001: Each digest covers the canonical form of a file.
+func scanManifestDir(b *batch, root string, diag *diagnostics) manifestScan {
+	scan := manifestScan{failed: make(map[string]bool)}
+	for _, r := range b.run(b.collect([]string{root})) {
+		if r.status == fileFailed {
+			scan.failed[manifestPath(root, r.path)] = true
+			scan.code = max(scan.code, r.reportFailure(diag))
+			continue
+		}
+		scan.files = append(scan.files, manifestFile{path: manifestPath(root, r.path), digest: hex.EncodeToString(r.sum[:])})
+	}
+	return scan
+}
+
+// manifestPath returns the slash-separated path of p relative to root.
+func manifestPath(root, p string) string {
+	rel, err := filepath.Rel(root, p)
+	if err != nil {
+		rel = p
+	}
+	return filepath.ToSlash(rel)
+}
+
+// manifestValue returns the manifest object for files.
+func manifestValue(files []manifestFile) *jcstoken.Value {
+	entries := make([]jcstoken.Member, 0, len(files))
+	for _, f := range files {
+		entries = append(entries, jcstoken.Member{Key: f.path, Value: jcstoken.Value{Kind: jcstoken.KindString, Str: f.digest}})
+	}
+	return &jcstoken.Value{Kind: jcstoken.KindObject, Members: []jcstoken.Member{
+		{Key: "algorithm", Value: jcstoken.Value{Kind: jcstoken.KindString, Str: manifestAlgorithm}},
+		{Key: "files", Value: jcstoken.Value{Kind: jcstoken.KindObject, Members: entries}},
+	}}
+}
+
+// manifestBounds returns the bounds of a manifest of at most files entries,
+// with room for two unexpected members so that a manifest of the wrong shape
+// is reported as such. The command's bounds apply to the files it digests,
+// not to the manifest it writes or reads.
+func manifestBounds(files int) *jcstoken.Options {
+	size := len(`{"algorithm":"sha-256","files":{}}`) + files*(6*maxManifestPathBytes+minManifestEntryBytes)
+	return &jcstoken.Options{
+		MaxDepth:         2,
+		MaxInputSize:     size,
+		MaxValues:        files + 5,
+		MaxObjectMembers: files + 2,
+		MaxStringBytes:   maxManifestPathBytes,
+		MaxOutputSize:    size,
+	}
+}
+
+// compareManifest returns the files added to, missing from, and changed in
+// scan relative to expected, ordered by path. A file that failed is neither
+// added nor missing.
+func compareManifest(expected []manifestFile, scan manifestScan) []manifestChange {
+	want := make(map[string]string, len(expected))
+	for _, f := range expected {
+		want[f.path] = f.digest
+	}
+	present := make(map[string]bool, len(scan.files))
+	var changes []manifestChange
+	for _, f := range scan.files {
+		present[f.path] = true
+		digest, ok := want[f.path]
+		switch {
+		case !ok:
+			changes = append(changes, manifestChange{kind: "added", path: f.path})
+		case digest != f.digest:
+			changes = append(changes, manifestChange{kind: "changed", path: f.path})
+		}
+	}
+	for _, f := range expected {
+		if !present[f.path] && !scan.failed[f.path] {
+			changes = append(changes, manifestChange{kind: "missing", path: f.path})
+		}
+	}
+	sort.Slice(changes, func(i, j int) bool { return changes[i].path < changes[j].path })
+	return changes
+}
+
+// readManifest reads the manifest at name, or stdin for "-": a JSON object
+// whose "algorithm" is "sha-256" and whose "files" object maps relative
+// paths to lowercase hex digests. It is parsed under the bounds of a
+// manifest with as many entries as its size allows. An invalid manifest
+// fails as CLI_USAGE.
+func readManifest(name string, stdin io.Reader) ([]manifestFile, error) {
+	data, release, err := loadInput([]string{name}, stdin, maxManifestSize)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+	v, err := jcstoken.ParseWithOptions(data, manifestBounds(len(data)/minManifestEntryBytes+1))
+	if err != nil {
+		return nil, jcserr.Wrap(jcserr.CLIUsage, -1, fmt.Sprintf("invalid manifest %q", name), err)
+	}
+	invalid := func(reason string) error {
+		return jcserr.New(jcserr.CLIUsage, -1, fmt.Sprintf("invalid manifest %q: %s", name, reason))
+	}
+	if v.Kind != jcstoken.KindObject || len(v.Members) != 2 {
+		return nil, invalid(`want an object with "algorithm" and "files"`)
+	}
+	var files *jcstoken.Value
+	for i := range v.Members {
+		m := &v.Members[i]
+		switch m.Key {
+		case "algorithm":
+			if m.Value.Kind != jcstoken.KindString || m.Value.Str != manifestAlgorithm {
+				return nil, invalid(`"algorithm" must be "` + manifestAlgorithm + `"`)
+			}
+		case "files":
+			files = &m.Value
+		default:
+			return nil, invalid(fmt.Sprintf("unexpected member %q", m.Key))
+		}
+	}
+	if files == nil || files.Kind != jcstoken.KindObject {
+		return nil, invalid(`"files" must be an object`)
+	}
+	return manifestFiles(files, invalid)
+}
+
+// manifestFiles returns the entries of the "files" object of a manifest.
+func manifestFiles(files *jcstoken.Value, invalid func(string) error) ([]manifestFile, error) {
+	out := make([]manifestFile, 0, len(files.Members))
+	for _, m := range files.Members {
+		if !validManifestPath(m.Key) {
+			return nil, invalid(fmt.Sprintf("invalid path %q", m.Key))
+		}
+		if m.Value.Kind != jcstoken.KindString || !validDigest(m.Value.Str) {
+			return nil, invalid(fmt.Sprintf("invalid digest for %q", m.Key))
+		}
+		out = append(out, manifestFile{path: m.Key, digest: m.Value.Str})
+	}
+	return out, nil
+}
+
+// validManifestPath reports whether p is a clean, slash-separated path below
+// the manifested directory.
+func validManifestPath(p string) bool {
+	return p != "" && p != "." && p != ".." && path.Clean(p) == p &&
+		!path.IsAbs(p) && !strings.HasPrefix(p, "../") && !strings.Contains(p, `\`)
+}
+
+// validDigest reports whether s is a lowercase hex SHA-256 digest.
+func validDigest(s string) bool {
+	if len(s) != hex.EncodedLen(sha256.Size) {
+		return false
+	}
+	for i := 0; i < len(s); i++ {
+		if !(('0' <= s[i] && s[i] <= '9') || ('a' <= s[i] && s[i] <= 'f')) {
+			return false
+		}
+	}
+	return true
+}
+
+// writeManifestSummary writes the verify counts to stderr: one text line, or
+// under --error-format json one canonical JSON object.
+func writeManifestSummary(diag *diagnostics, files int, changes []manifestChange, failed int) error {
+	counts := map[string]int{}
+	for _, c := range changes {
+		counts[c.kind]++
+	}
+	matched := files - failed - counts["added"] - counts["changed"]
+	if diag.json {
+		return writef(diag.stderr, `{"summary":{"added":%d,"changed":%d,"failed":%d,"files":%d,"matched":%d,"missing":%d}}`+"\n",
+			counts["added"], counts["changed"], failed, files, matched, counts["missing"])
+	}
+	return writef(diag.stderr, "jcs-canon: files=%d matched=%d added=%d missing=%d changed=%d failed=%d\n",
+		files, matched, counts["added"], counts["missing"], counts["changed"], failed)
+}
+
+func writeManifestHelp(w io.Writer) error {
+	lines := []string{
+		"usage: jcs-canon manifest create [--error-format text|json] [--jobs n] [--include-glob glob]... [--exclude-glob glob]... " + boundsUsage + " dir",
+		"       jcs-canon manifest verify [--quiet] [--error-format text|json] [--jobs n] [--include-glob glob]... [--exclude-glob glob]... " + boundsUsage + " manifest|- dir",
+		"  create writes a canonical JSON manifest of the SHA-256 digest of the canonical form of each file under dir.",
+		"  verify lists the files added to, missing from, or changed in dir relative to manifest.",
+		"  --quiet              Suppress the verify summary",
+		"  --error-format f     Report errors on stderr as text (default) or as one canonical JSON object",
+		"  -j, --jobs n         Process files with n workers (default: number of CPUs)",
+		"  --include-glob glob  Select walked files matching glob (repeatable; default *.json)",
+		"  --exclude-glob glob  Skip walked files and directories matching glob (repeatable)",
+	}
+	lines = append(lines, boundsHelp...)
+	for _, line := range lines {
+		if err := writeLine(w, line); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,22 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"syscall"
+)
+
+// mapFile maps the first size bytes of the regular file f read-only into
+// memory. release unmaps them; the mapping does not depend on f staying
+// open.
+func mapFile(f *os.File, size int) ([]byte, func(), error) {
+	data, err := syscall.Mmap(int(f.Fd()), 0, size, syscall.PROT_READ, syscall.MAP_PRIVATE)
+	if err != nil {
+		return nil, nil, fmt.Errorf("mmap: %w", err)
+	}
+	return data, func() {
+		if unmapErr := syscall.Munmap(data); unmapErr != nil {
+			_ = unmapErr
+		}
+	}, nil
+}
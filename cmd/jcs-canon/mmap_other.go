@@ -0,0 +1,14 @@
+//go:build !linux
+
+package main
+
+import (
+	"errors"
+	"os"
+)
+
+// mapFile is not supported outside Linux, the supported platform; files are
+// read instead.
+func mapFile(*os.File, int) ([]byte, func(), error) {
+	return nil, nil, errors.New("mmap: unsupported platform")
+}
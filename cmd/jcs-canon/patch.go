@@ -0,0 +1,129 @@
+package main
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/lattice-substrate/json-canon/jcs"
+	"github.com/lattice-substrate/json-canon/jcserr"
+	"github.com/lattice-substrate/json-canon/jcstoken"
+)
+
+// cmdPatch applies the RFC 6902 JSON Patch in the first input to the
+// document in the second and writes the canonical result.
+//
+// CLI-PATCH-001: patch emits the canonical bytes of the patched document.
+func cmdPatch(args []string, stdin io.Reader, stdout io.Writer, stderr io.Writer) int {
+	return runPatch("patch", args, stdin, stdout, stderr)
+}
+
+// cmdMergePatch applies the RFC 7396 merge patch in the first input to the
+// document in the second and writes the canonical result.
+//
+// CLI-MERGE-001: merge-patch emits the canonical bytes of the merged document.
+func cmdMergePatch(args []string, stdin io.Reader, stdout io.Writer, stderr io.Writer) int {
+	return runPatch("merge-patch", args, stdin, stdout, stderr)
+}
+
+// runPatch implements cmd, either "patch" or "merge-patch".
+func runPatch(cmd string, args []string, stdin io.Reader, stdout io.Writer, stderr io.Writer) int {
+	diag := newDiagnostics(stderr, args)
+	fl, positional, err := parseFlags(cmd, args)
+	if err != nil {
+		return diag.fail(err)
+	}
+
+	// CLI-FLAG-003: subcommand --help writes to stdout (frozen stream policy).
+	if fl.help {
+		helpErr := writePatchHelp(stdout, cmd)
+		if helpErr != nil {
+			return diag.fail(jcserr.Wrap(jcserr.InternalIO, -1, fmt.Sprintf("write %s help output", cmd), helpErr))
+		}
+		return 0
+	}
+
+	positional, err = checkPatchArgs(cmd, positional)
+	if err != nil {
+		return diag.fail(err)
+	}
+	bounds, err := resolveBounds(&fl)
+	if err != nil {
+		return diag.fail(err)
+	}
+	var docs [2]*jcstoken.Value
+	for i, name := range positional {
+		var code int
+		docs[i], code = readDocument(name, stdin, &bounds, diag)
+		if docs[i] == nil {
+			return code
+		}
+	}
+	output, err := applyPatchDocument(cmd, docs[0], docs[1], &bounds)
+	if err != nil {
+		return diag.fail(err)
+	}
+	if _, err := stdout.Write(output); err != nil {
+		return diag.fail(jcserr.Wrap(jcserr.InternalIO, -1, "writing output", err))
+	}
+	return 0
+}
+
+// checkPatchArgs requires the patch input and at most one document input,
+// which defaults to standard input, and returns both names.
+func checkPatchArgs(cmd string, positional []string) ([]string, error) {
+	switch len(positional) {
+	case 1:
+		positional = append(positional, "-")
+	case 2:
+	default:
+		return nil, jcserr.New(jcserr.CLIUsage, -1, fmt.Sprintf("%s requires a patch and at most one input", cmd))
+	}
+	if positional[0] == "-" && positional[1] == "-" {
+		return nil, jcserr.New(jcserr.CLIUsage, -1, fmt.Sprintf("%s reads standard input at most once", cmd))
+	}
+	return positional, nil
+}
+
+// applyPatchDocument applies patch to doc as cmd prescribes and returns the
+// canonical bytes of the result.
+func applyPatchDocument(cmd string, patch, doc *jcstoken.Value, bounds *jcstoken.Options) ([]byte, error) {
+	var result *jcstoken.Value
+	var err error
+	if cmd == "merge-patch" {
+		result, err = jcs.MergePatchWithOptions(doc, patch, bounds)
+	} else {
+		var p jcs.Patch
+		if p, err = jcs.ParsePatch(patch); err == nil {
+			result, err = jcs.ApplyPatchWithOptions(doc, p, bounds)
+		}
+	}
+	if err != nil {
+		return nil, err //nolint:wrapcheck // CLI-PATCH-001: patch errors are already classified.
+	}
+	out, err := jcs.SerializeWithOptions(result, bounds)
+	if err != nil {
+		return nil, err //nolint:wrapcheck // CLI-PATCH-001: serialization errors are already classified.
+	}
+	return out, nil
+}
+
+func writePatchHelp(w io.Writer, cmd string) error {
+	lines := []string{
+		"usage: jcs-canon patch [--error-format text|json] " + boundsUsage + " patch [file|-]",
+		"  Apply the RFC 6902 JSON Patch in patch to the JSON document in file (or stdin), emit canonical bytes to stdout.",
+	}
+	if cmd == "merge-patch" {
+		lines = []string{
+			"usage: jcs-canon merge-patch [--error-format text|json] " + boundsUsage + " patch [file|-]",
+			"  Apply the RFC 7396 JSON Merge Patch in patch to the JSON document in file (or stdin), emit canonical bytes to stdout.",
+		}
+	}
+	lines = append(lines, "  --error-format f     Report errors on stderr as text (default) or as one canonical JSON object")
+	lines = append(lines, boundsHelp...)
+	for _, line := range lines {
+		if err := writeLine(w, line); err != nil {
+			return err
+		}
+	}
+	return nil
+}
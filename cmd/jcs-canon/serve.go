@@ -0,0 +1,88 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/lattice-substrate/json-canon/jcserr"
+	"github.com/lattice-substrate/json-canon/jcsserve"
+)
+
+// cmdServe answers canonicalize, verify, and digest requests on a Unix
+// domain socket or loopback address until interrupted.
+//
+// CLI-SERVE-001: serve listens on exactly one local address and exits 0
+// after a graceful shutdown.
+func cmdServe(args []string, _ io.Reader, stdout io.Writer, stderr io.Writer) int {
+	diag := newDiagnostics(stderr, args)
+	fl, positional, err := parseFlags("serve", args)
+	if err != nil {
+		return diag.fail(err)
+	}
+
+	// CLI-FLAG-003: subcommand --help writes to stdout (frozen stream policy).
+	if fl.help {
+		helpErr := writeServeHelp(stdout)
+		if helpErr != nil {
+			return diag.fail(jcserr.Wrap(jcserr.InternalIO, -1, "write serve help output", helpErr))
+		}
+		return 0
+	}
+
+	if len(positional) > 0 {
+		return diag.fail(jcserr.New(jcserr.CLIUsage, -1, "serve takes no inputs"))
+	}
+	if (fl.socket == "") == (fl.listen == "") {
+		return diag.fail(jcserr.New(jcserr.CLIUsage, -1, "serve requires exactly one of --socket or --listen"))
+	}
+	bounds, err := resolveBounds(&fl)
+	if err != nil {
+		return diag.fail(err)
+	}
+	jobs, err := parseJobs(fl.jobs, 0)
+	if err != nil {
+		return diag.fail(err)
+	}
+	network, address := "tcp", fl.listen
+	if fl.socket != "" {
+		network, address = "unix", fl.socket
+	}
+	ln, err := jcsserve.Listen(network, address)
+	if err != nil {
+		return diag.fail(err)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	if err := writef(stderr, "jcs-canon: serving on %s:%s\n", ln.Addr().Network(), ln.Addr()); err != nil {
+		return diag.fail(jcserr.Wrap(jcserr.InternalIO, -1, "write serve notice", errors.Join(err, ln.Close())))
+	}
+	srv := jcsserve.New(jcsserve.Config{Options: &bounds, MaxConcurrent: jobs})
+	if err := srv.Serve(ctx, ln); err != nil {
+		return diag.fail(err)
+	}
+	return 0
+}
+
+func writeServeHelp(w io.Writer) error {
+	lines := []string{
+		"usage: jcs-canon serve (--socket path|--listen addr:port) [--jobs n] [--error-format text|json] " + boundsUsage,
+		"  Answer POST requests to /v1/canonicalize, /v1/verify, and /v1/digest over HTTP until SIGINT or SIGTERM.",
+		"  Failures are answered with {\"error\":{...}} naming the failure class.",
+		"  --socket path        Listen on a new Unix domain socket at path (mode 0600)",
+		"  --listen addr:port   Listen on a loopback IP address and port, such as 127.0.0.1:8080",
+		"  -j, --jobs n         Process at most n requests at once (default: number of CPUs)",
+		"  --error-format f     Report errors on stderr as text (default) or as one canonical JSON object",
+	}
+	lines = append(lines, boundsHelp...)
+	for _, line := range lines {
+		if err := writeLine(w, line); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,138 @@
+package conformance_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/lattice-substrate/json-canon/jcserr"
+)
+
+// batchFiles are the fixtures of the batch checks.
+var batchFiles = map[string]string{
+	"a.json":         `{"a":1}`,
+	"b/c.json":       `{"c":1,"b":2}`,
+	"b/d.json":       `[-0]`,
+	"b/e.txt":        `[ 1 ]`,
+	".hidden/f.json": `[ 1 ]`,
+	"skip/g.json":    `[ 1 ]`,
+}
+
+// batchTree writes files, keyed by slash-separated name, under a new
+// directory and returns it with a function that maps names to paths inside
+// it.
+func batchTree(t *testing.T, files map[string]string) (string, func(string) string) {
+	t.Helper()
+	dir := t.TempDir()
+	p := func(name string) string { return filepath.Join(dir, filepath.FromSlash(name)) }
+	for name, content := range files {
+		writeFixture(t, p(name), content)
+	}
+	return dir, p
+}
+
+// writeFixture writes content to path, creating its directory.
+func writeFixture(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0o750); err != nil {
+		t.Fatalf("create fixture directory: %v", err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatalf("write fixture: %v", err)
+	}
+}
+
+// === CLI-BATCH-001: Batch mode walks files and directories ===
+
+func checkCLIBatchInputs(t *testing.T, h *harness) {
+	t.Helper()
+	dir, p := batchTree(t, batchFiles)
+	res := runCLI(t, h, []string{"verify", "--exclude-glob", "skip", dir, p("b/e.txt")}, nil)
+	want := p("b/c.json") + ": error: jcserr: NOT_CANONICAL: input is not canonical\n" +
+		p("b/d.json") + ": error: jcserr: NUMBER_NEGZERO at byte 1: negative zero token is not allowed\n" +
+		p("b/e.txt") + ": error: jcserr: NOT_CANONICAL: input is not canonical\n" +
+		"jcs-canon: files=4 canonical=1 not_canonical=2 rewritten=0 failed=1\n"
+	if res.exitCode != 2 || res.stdout != "" || res.stderr != want {
+		t.Fatalf("unexpected result: %+v", res)
+	}
+	res = runCLI(t, h, []string{"verify", "-q", "--include-glob", "b/*.txt", dir}, nil)
+	if res.exitCode != 2 || !strings.HasPrefix(res.stderr, p("b/e.txt")+": error: jcserr: NOT_CANONICAL") {
+		t.Fatalf("unexpected glob result: %+v", res)
+	}
+	res = runCLI(t, h, []string{"canonicalize", p("a.json"), p("b/c.json")}, nil)
+	if res.exitCode != 2 || !strings.Contains(res.stderr, "multiple input files") {
+		t.Fatalf("canonicalize accepted several inputs without -l or --write: %+v", res)
+	}
+}
+
+// === CLI-BATCH-002: -l lists files that are not canonical ===
+
+func checkCLIBatchList(t *testing.T, h *harness) {
+	t.Helper()
+	dir, p := batchTree(t, batchFiles)
+	res := runCLI(t, h, []string{"verify", "-l", "-q", "--exclude-glob=d.json", dir}, nil)
+	if res.exitCode != 2 || res.stdout != p("b/c.json")+"\n"+p("skip/g.json")+"\n" || res.stderr != "" {
+		t.Fatalf("unexpected verify -l result: %+v", res)
+	}
+	res = runCLI(t, h, []string{"canonicalize", "-l", "-q", "--exclude-glob=d.json", dir}, nil)
+	if res.exitCode != 0 || res.stdout != p("b/c.json")+"\n"+p("skip/g.json")+"\n" || res.stderr != "" {
+		t.Fatalf("unexpected canonicalize -l result: %+v", res)
+	}
+}
+
+// === CLI-BATCH-003: --write rewrites files atomically, preserving mode ===
+
+func checkCLIBatchWrite(t *testing.T, h *harness) {
+	t.Helper()
+	dir, p := batchTree(t, batchFiles)
+	if err := os.Chmod(p("b/c.json"), 0o604); err != nil {
+		t.Fatalf("chmod fixture: %v", err)
+	}
+	res := runCLI(t, h, []string{"canonicalize", "--write", "-l", dir}, nil)
+	if res.exitCode != 2 || res.stdout != p("b/c.json")+"\n"+p("skip/g.json")+"\n" ||
+		!strings.HasSuffix(res.stderr, "jcs-canon: files=4 canonical=1 not_canonical=0 rewritten=2 failed=1\n") {
+		t.Fatalf("unexpected result: %+v", res)
+	}
+	got, err := os.ReadFile(p("b/c.json"))
+	if err != nil || string(got) != `{"b":2,"c":1}` {
+		t.Fatalf("file not rewritten: %q, %v", got, err)
+	}
+	info, err := os.Stat(p("b/c.json"))
+	if err != nil || info.Mode().Perm() != 0o604 {
+		t.Fatalf("mode not preserved: %v", err)
+	}
+	entries, err := os.ReadDir(p("b"))
+	if err != nil || len(entries) != 3 {
+		t.Fatalf("temporary files left behind: %v, %v", entries, err)
+	}
+	res = runCLI(t, h, []string{"verify", "--write", dir}, nil)
+	requireExitClass(t, res, jcserr.CLIUsage)
+}
+
+// === CLI-BATCH-004: Summary and exit code are deterministic ===
+
+func checkCLIBatchSummary(t *testing.T, h *harness) {
+	t.Helper()
+	dir, _ := batchTree(t, batchFiles)
+	first := runCLI(t, h, []string{"verify", "--jobs", "1", dir}, nil)
+	for _, jobs := range []string{"2", "8"} {
+		res := runCLI(t, h, []string{"verify", "--jobs", jobs, dir}, nil)
+		if res != first {
+			t.Fatalf("--jobs %s changed the result: %+v, want %+v", jobs, res, first)
+		}
+	}
+	res := runCLI(t, h, []string{"verify", "--error-format=json", "--include-glob=a.json", dir}, nil)
+	if res.exitCode != 0 || res.stderr != `{"summary":{"canonical":1,"failed":0,"files":1,"not_canonical":0,"rewritten":0}}`+"\n" {
+		t.Fatalf("unexpected JSON summary: %+v", res)
+	}
+	res = runCLI(t, h, []string{"verify", "--jobs=0", dir}, nil)
+	requireExitClass(t, res, jcserr.CLIUsage)
+}
+
+func requireExitClass(t *testing.T, res cliResult, class jcserr.FailureClass) {
+	t.Helper()
+	if res.exitCode != class.ExitCode() || !strings.Contains(res.stderr, string(class)) {
+		t.Fatalf("expected %s, got %+v", class, res)
+	}
+}
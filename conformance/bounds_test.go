@@ -0,0 +1,129 @@
+package conformance_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/lattice-substrate/json-canon/jcserr"
+	"github.com/lattice-substrate/json-canon/jcstoken"
+)
+
+// === CLI-BOUNDS-001: Every jcstoken.Options bound has a command-line option ===
+
+func checkCLIBoundOptions(t *testing.T, h *harness) {
+	t.Helper()
+	cases := []struct {
+		flag      string
+		ok, over  string
+		threshold string
+	}{
+		{"--max-depth", `[[1]]`, `[[[1]]]`, "2"},
+		{"--max-input-size", `[10]`, `[100]`, "4"},
+		{"--max-values", `[1,2]`, `[1,2,3]`, "3"},
+		{"--max-object-members", `{"a":1,"b":2}`, `{"a":1,"b":2,"c":3}`, "2"},
+		{"--max-array-elements", `[1,2]`, `[1,2,3]`, "2"},
+		{"--max-string-bytes", `["abc"]`, `["abcd"]`, "3"},
+		{"--max-number-chars", `[1234]`, `[12345]`, "4"},
+	}
+	for _, cmd := range [][]string{{"canonicalize"}, {"verify"}, {"convert", "--to", "cbor"}} {
+		for _, tc := range cases {
+			args := append(append([]string(nil), cmd...), tc.flag, tc.threshold)
+			if res := runCLI(t, h, args, []byte(tc.ok)); res.exitCode != 0 {
+				t.Fatalf("%v at the bound: %+v", args, res)
+			}
+			requireExitClass(t, runCLI(t, h, args, []byte(tc.over)), jcserr.BoundExceeded)
+		}
+	}
+	// --max-output-size bounds JSON output: convert applies it with --from cbor.
+	for _, tc := range []struct {
+		args     []string
+		ok, over []byte
+	}{
+		{[]string{"canonicalize"}, []byte(`[1e2]`), []byte(`[1e3]`)},
+		{[]string{"verify"}, []byte(`[100]`), []byte(`[1000]`)},
+		{[]string{"convert", "--from", "cbor"}, []byte{0x81, 0x18, 0x64}, []byte{0x81, 0x19, 0x03, 0xe8}},
+	} {
+		args := append(append([]string(nil), tc.args...), "--max-output-size", "5")
+		if res := runCLI(t, h, args, tc.ok); res.exitCode != 0 {
+			t.Fatalf("%v at the bound: %+v", args, res)
+		}
+		requireExitClass(t, runCLI(t, h, args, tc.over), jcserr.BoundExceeded)
+	}
+	for _, value := range []string{"0", "-1", "1e3", "9007199254740992"} {
+		requireExitClass(t, runCLI(t, h, []string{"verify", "--max-depth", value}, []byte(`[1]`)), jcserr.CLIUsage)
+	}
+	over := strings.Repeat("[", jcstoken.DefaultMaxDepth+1) + strings.Repeat("]", jcstoken.DefaultMaxDepth+1)
+	for _, scheme := range []string{"rfc8785", "olpc", "matrix", "decimal"} {
+		res := runCLI(t, h, []string{"canonicalize", "-q", "--scheme", scheme, "--max-depth", "1001"}, []byte(over))
+		if res.exitCode != 0 || res.stdout != over {
+			t.Fatalf("--scheme %s did not honor the raised bound: %+v", scheme, res)
+		}
+	}
+}
+
+// === CLI-BOUNDS-002: Named presets fix every bound ===
+
+func checkCLIBoundPresets(t *testing.T, h *harness) {
+	t.Helper()
+	depth := func(n int) []byte { return []byte(strings.Repeat("[", n) + strings.Repeat("]", n)) }
+	number := func(n int) []byte { return []byte("[" + strings.Repeat("1", n) + "]") }
+	if res := runCLI(t, h, []string{"verify", "--bounds", "api-small"}, depth(32)); res.exitCode != 0 {
+		t.Fatalf("api-small rejected depth 32: %+v", res)
+	}
+	requireExitClass(t, runCLI(t, h, []string{"verify", "--bounds", "api-small"}, depth(33)), jcserr.BoundExceeded)
+	requireExitClass(t, runCLI(t, h, []string{"canonicalize", "--bounds", "api-small"}, number(129)), jcserr.BoundExceeded)
+	for _, preset := range []string{"default", "bulk"} {
+		if res := runCLI(t, h, []string{"canonicalize", "--bounds", preset}, number(129)); res.exitCode != 0 {
+			t.Fatalf("%s rejected a 129-character number: %+v", preset, res)
+		}
+	}
+	requireExitClass(t, runCLI(t, h, []string{"verify", "--bounds", "bulk"}, depth(jcstoken.DefaultMaxDepth+1)), jcserr.BoundExceeded)
+	if res := runCLI(t, h, []string{"verify", "--bounds", "api-small", "--max-depth", "33"}, depth(33)); res.exitCode != 0 {
+		t.Fatalf("--max-depth did not override the preset: %+v", res)
+	}
+	requireExitClass(t, runCLI(t, h, []string{"verify", "--bounds", "unknown"}, depth(1)), jcserr.CLIUsage)
+}
+
+// === CLI-BOUNDS-003: --bounds-file reads a canonical JSON bounds profile ===
+
+func checkCLIBoundsFile(t *testing.T, h *harness) {
+	t.Helper()
+	dir := t.TempDir()
+	profile := func(content string) string {
+		t.Helper()
+		f, err := os.CreateTemp(dir, "bounds-*.json")
+		if err != nil {
+			t.Fatalf("create profile: %v", err)
+		}
+		if _, err := f.WriteString(content); err != nil {
+			t.Fatalf("write profile: %v", err)
+		}
+		if err := f.Close(); err != nil {
+			t.Fatalf("close profile: %v", err)
+		}
+		return f.Name()
+	}
+	depth := profile(`{"max_array_elements":3,"max_depth":2}`)
+	if res := runCLI(t, h, []string{"verify", "--bounds-file", depth}, []byte(`[[1,2,3]]`)); res.exitCode != 0 {
+		t.Fatalf("profile rejected input at its bounds: %+v", res)
+	}
+	requireExitClass(t, runCLI(t, h, []string{"verify", "--bounds-file", depth}, []byte(`[[[1]]]`)), jcserr.BoundExceeded)
+	requireExitClass(t, runCLI(t, h, []string{"verify", "--bounds", "api-small", "--bounds-file", depth}, []byte(`[1,2,3,4]`)), jcserr.BoundExceeded)
+	if res := runCLI(t, h, []string{"verify", "--bounds-file", depth, "--max-depth", "3"}, []byte(`[[[1]]]`)); res.exitCode != 0 {
+		t.Fatalf("--max-depth did not override the profile: %+v", res)
+	}
+	for _, bad := range []string{
+		`{ "max_depth": 2 }`,
+		`{"max_depth":2,"max_depth":3}`,
+		`{"max_width":2}`,
+		`{"max_depth":2.5}`,
+		`{"max_depth":0}`,
+		`{"max_depth":"2"}`,
+		`[2]`,
+	} {
+		requireExitClass(t, runCLI(t, h, []string{"verify", "--bounds-file", profile(bad)}, []byte(`[1]`)), jcserr.CLIUsage)
+	}
+	requireExitClass(t, runCLI(t, h, []string{"verify", "--bounds-file", filepath.Join(dir, "missing.json")}, []byte(`[1]`)), jcserr.CLIUsage)
+}
@@ -0,0 +1,214 @@
+package conformance_test
+
+import (
+	"bytes"
+	"encoding/hex"
+	"strings"
+	"testing"
+
+	"github.com/lattice-substrate/json-canon/jcs"
+	"github.com/lattice-substrate/json-canon/jcscbor"
+	"github.com/lattice-substrate/json-canon/jcserr"
+	"github.com/lattice-substrate/json-canon/jcstoken"
+)
+
+func cborEncodeHex(t *testing.T, in string) string {
+	t.Helper()
+	v, err := jcstoken.Parse([]byte(in))
+	if err != nil {
+		t.Fatalf("parse %s: %v", in, err)
+	}
+	out, err := jcscbor.Encode(v)
+	if err != nil {
+		t.Fatalf("encode %s: %v", in, err)
+	}
+	return hex.EncodeToString(out)
+}
+
+func cborDecodeHex(t *testing.T, h string, opts *jcstoken.Options) (*jcstoken.Value, error) {
+	t.Helper()
+	data, err := hex.DecodeString(h)
+	if err != nil {
+		t.Fatalf("bad hex %s: %v", h, err)
+	}
+	v, err := jcscbor.DecodeWithOptions(data, opts)
+	if err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+func requireCBOREncoding(t *testing.T, cases []struct{ json, cbor string }) {
+	t.Helper()
+	for _, c := range cases {
+		if got := cborEncodeHex(t, c.json); got != c.cbor {
+			t.Fatalf("Encode(%s) = %s, want %s", c.json, got, c.cbor)
+		}
+	}
+}
+
+// === CBOR-ENC-001: Shortest-form arguments and definite lengths ===
+
+func checkCBORShortestArguments(t *testing.T, _ *harness) {
+	t.Helper()
+	requireCBOREncoding(t, []struct{ json, cbor string }{
+		{`23`, "17"},
+		{`24`, "1818"},
+		{`256`, "190100"},
+		{`65536`, "1a00010000"},
+		{`4294967296`, "1b0000000100000000"},
+		{`-25`, "3818"},
+		{`"IETF"`, "6449455446"},
+		{`[1,[2,3],[4,5]]`, "8301820203820405"},
+	})
+}
+
+// === CBOR-ENC-002: Map keys sorted by bytewise order of their encodings ===
+
+func checkCBORMapKeyOrder(t *testing.T, _ *harness) {
+	t.Helper()
+	// RFC 8785 would order "aa" before "b"; deterministic CBOR puts the
+	// shorter encoded key first.
+	requireCBOREncoding(t, []struct{ json, cbor string }{
+		{`{"aa":1,"b":2}`, "a261620262616101"},
+		{`{"b":{"d":1,"c":2},"a":0}`, "a26161006162a2616302616401"},
+	})
+}
+
+// === CBOR-FLOAT-001: Non-integral numbers use the shortest exact float ===
+
+func checkCBORPreferredFloat(t *testing.T, _ *harness) {
+	t.Helper()
+	requireCBOREncoding(t, []struct{ json, cbor string }{
+		{`1.5`, "f93e00"},
+		{`5.960464477539063e-8`, "f90001"},
+		{`100000.5`, "fa47c35040"},
+		{`1.1`, "fb3ff199999999999a"},
+		{`1e+300`, "fb7e37e43c8800759c"},
+	})
+}
+
+// === CBOR-NUM-001: Integral numbers reduce to CBOR integers ===
+
+func checkCBORIntegerReduction(t *testing.T, _ *harness) {
+	t.Helper()
+	requireCBOREncoding(t, []struct{ json, cbor string }{
+		{`1.0e2`, "1864"},
+		{`-1e3`, "3903e7"},
+		{`1e19`, "1b8ac7230489e80000"},
+		{`1.8446744073709552e+19`, "fa5f800000"},
+	})
+}
+
+// === CBOR-DEC-001: Malformed CBOR is rejected as INVALID_CBOR ===
+
+func checkCBORMalformedRejected(t *testing.T, _ *harness) {
+	t.Helper()
+	for _, h := range []string{"", "19ff", "1d", "ff", "0101", "6261", "a161610a01"} {
+		_, err := cborDecodeHex(t, h, nil)
+		requireClass(t, err, jcserr.InvalidCBOR)
+	}
+}
+
+// === CBOR-DEC-002: Data items outside the JSON domain are classified ===
+
+func checkCBORNonJSONClassified(t *testing.T, _ *harness) {
+	t.Helper()
+	cases := []struct {
+		cbor string
+		want jcserr.FailureClass
+	}{
+		{"c074323031332d30332d32315432303a30343a30305a", jcserr.UnsupportedCBOR},
+		{"420102", jcserr.UnsupportedCBOR},
+		{"a10000", jcserr.UnsupportedCBOR},
+		{"f7", jcserr.UnsupportedCBOR},
+		{"bf6161f5ff", jcserr.UnsupportedCBOR},
+		{"f97e00", jcserr.UnsupportedCBOR},
+		{"f97c00", jcserr.NumberOverflow},
+		{"f98000", jcserr.NumberNegZero},
+		{"a2616101616102", jcserr.DuplicateKey},
+		{"62c328", jcserr.InvalidUTF8},
+	}
+	for _, c := range cases {
+		_, err := cborDecodeHex(t, c.cbor, nil)
+		requireClass(t, err, c.want)
+	}
+}
+
+// === CBOR-BOUND-001: Decoder applies jcstoken bounds before allocation ===
+
+func checkCBORBoundsEnforced(t *testing.T, _ *harness) {
+	t.Helper()
+	cases := []struct {
+		cbor string
+		opts *jcstoken.Options
+	}{
+		{"818181818100", &jcstoken.Options{MaxDepth: 4}},
+		{"9bffffffffffffffff", nil},
+		{"6a61616161616161616161", &jcstoken.Options{MaxStringBytes: 8}},
+		{"a2616100616201", &jcstoken.Options{MaxObjectMembers: 1}},
+		{"83010203", &jcstoken.Options{MaxArrayElements: 2}},
+		{"83010203", &jcstoken.Options{MaxInputSize: 3}},
+	}
+	for _, c := range cases {
+		_, err := cborDecodeHex(t, c.cbor, c.opts)
+		requireClass(t, err, jcserr.BoundExceeded)
+	}
+}
+
+// === CBOR-RT-001: JSON → CBOR → JSON reproduces canonical bytes ===
+
+func checkCBORRoundTrip(t *testing.T, _ *harness) {
+	t.Helper()
+	for _, in := range []string{
+		`{"z":[1,2.5,-0.125,1e300,"€"],"a":{"":null,"aa":true,"b":false}}`,
+		`[18446744073709551615,-18446744073709551615,9007199254740993,5e-324]`,
+		`"\u0000\u001f😀"`,
+	} {
+		v, err := jcstoken.Parse([]byte(in))
+		if err != nil {
+			t.Fatalf("parse %s: %v", in, err)
+		}
+		want, err := jcs.Serialize(v)
+		if err != nil {
+			t.Fatal(err)
+		}
+		encoded, err := jcscbor.Encode(v)
+		if err != nil {
+			t.Fatal(err)
+		}
+		decoded, err := jcscbor.Decode(encoded)
+		if err != nil {
+			t.Fatalf("decode %x: %v", encoded, err)
+		}
+		got, err := jcs.Serialize(decoded)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(got, want) {
+			t.Fatalf("round trip %s: got %s want %s", in, got, want)
+		}
+	}
+}
+
+// === CLI-CONVERT-001: convert transcodes between JSON and CBOR ===
+
+func checkCLIConvert(t *testing.T, h *harness) {
+	t.Helper()
+	res := runCLI(t, h, []string{"convert", "--to", "cbor", "-"}, []byte(`{"b":[1,2.5], "a":null}`))
+	if res.exitCode != 0 || hex.EncodeToString([]byte(res.stdout)) != "a26161f661628201f94100" || res.stderr != "" {
+		t.Fatalf("unexpected --to cbor result: %+v", res)
+	}
+	res = runCLI(t, h, []string{"convert", "--from=cbor"}, []byte(res.stdout))
+	if res.exitCode != 0 || res.stdout != `{"a":null,"b":[1,2.5]}` || res.stderr != "" {
+		t.Fatalf("unexpected --from cbor result: %+v", res)
+	}
+	res = runCLI(t, h, []string{"convert", "--from", "cbor"}, []byte{0x41, 0x00})
+	if res.exitCode != 2 || !strings.Contains(res.stderr, string(jcserr.UnsupportedCBOR)) {
+		t.Fatalf("byte string not rejected: %+v", res)
+	}
+	res = runCLI(t, h, []string{"convert", "-"}, []byte(`{}`))
+	if res.exitCode != 2 || !strings.Contains(res.stderr, string(jcserr.CLIUsage)) {
+		t.Fatalf("missing direction not rejected: %+v", res)
+	}
+}
@@ -0,0 +1,131 @@
+package conformance_test
+
+import (
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/lattice-substrate/json-canon/jcs"
+	"github.com/lattice-substrate/json-canon/jcserr"
+	"github.com/lattice-substrate/json-canon/jcsfloat"
+	"github.com/lattice-substrate/json-canon/jcstoken"
+)
+
+var decimalOptions = &jcstoken.Options{Numbers: jcstoken.NumberDecimal}
+
+// === DEC-PARSE-001: Number tokens are kept exactly under NumberDecimal ===
+
+func checkDecimalParse(t *testing.T, _ *harness) {
+	t.Helper()
+	v, err := jcstoken.ParseWithOptions([]byte(`[0.30,1e309,1e-400]`), decimalOptions)
+	if err != nil {
+		t.Fatalf("decimal parse: %v", err)
+	}
+	if v.Elems[0].Str != "0.3" || v.Elems[0].Num != 0.3 || v.Elems[1].Str != "1e+309" || !math.IsInf(v.Elems[1].Num, 1) || v.Elems[2].Str != "1e-400" {
+		t.Fatalf("unexpected decimal values: %+v", v.Elems)
+	}
+	_, err = jcstoken.ParseWithOptions([]byte(`-0.00`), decimalOptions)
+	requireClass(t, err, jcserr.NumberNegZero)
+	_, err = jcstoken.Parse([]byte(`1e309`))
+	requireClass(t, err, jcserr.NumberOverflow)
+}
+
+// === DEC-CANON-001: Exact decimals have a single canonical form ===
+
+func checkDecimalCanonicalForm(t *testing.T, _ *harness) {
+	t.Helper()
+	for in, want := range map[string]string{
+		"1.10":                  "1.1",
+		"110e-2":                "1.1",
+		"0.0":                   "0",
+		"12345678901234567.89":  "12345678901234567.89",
+		"1e20":                  "100000000000000000000",
+		"10e20":                 "1e+21",
+		"0.000001":              "0.000001",
+		"0.00000010":            "1e-7",
+		"-98765.4321000e+30":    "-9.87654321e+34",
+		"0.1000000000000000055": "0.1000000000000000055",
+	} {
+		got, err := jcsfloat.FormatDecimal(in)
+		if err != nil || got != want {
+			t.Fatalf("FormatDecimal(%q) = %q, %v; want %q", in, got, err, want)
+		}
+	}
+}
+
+// === DEC-RANGE-001: Exponents beyond MaxDecimalExponent are UNSUPPORTED_DECIMAL ===
+
+func checkDecimalRange(t *testing.T, _ *harness) {
+	t.Helper()
+	if jcsfloat.MaxDecimalExponent != 999_999_999 {
+		t.Fatalf("unexpected exponent bound %d", jcsfloat.MaxDecimalExponent)
+	}
+	if got, err := jcsfloat.FormatDecimal("9.9e999999999"); err != nil || got != "9.9e+999999999" {
+		t.Fatalf("largest exponent rejected: %q, %v", got, err)
+	}
+	for _, in := range []string{"99e999999999", "0.1e-999999999", "1e123456789012345678901234567890"} {
+		if _, err := jcsfloat.FormatDecimal(in); err == nil || err.Class != jcserr.UnsupportedDecimal {
+			t.Fatalf("FormatDecimal(%q): expected UNSUPPORTED_DECIMAL, got %v", in, err)
+		}
+	}
+}
+
+// === DEC-SCHEME-001: Decimal output is RFC 8785 output with exact numbers ===
+
+func checkDecimalScheme(t *testing.T, _ *harness) {
+	t.Helper()
+	out, err := jcs.CanonicalizeDecimal([]byte(`{"€":1.50,"b":[9007199254740993,"x"],"a":0.1}`), nil)
+	if err != nil || string(out) != `{"a":0.1,"b":[9007199254740993,"x"],"€":1.5}` {
+		t.Fatalf("CanonicalizeDecimal = %s, %v", out, err)
+	}
+	in := []byte(`{"n":[1e21,0.000001,-4.35,5e-324],"s":"\u0001"}`)
+	want, err := jcs.Canonicalize(in)
+	if err != nil {
+		t.Fatal(err)
+	}
+	v, err := jcstoken.Parse(in)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, err := jcs.Decimal.Serialize(v); err != nil || string(got) != string(want) {
+		t.Fatalf("binary64 value: decimal output %s, %v differs from RFC 8785 %s", got, err, want)
+	}
+	if got, err := jcs.CanonicalizeDecimal(in, nil); err != nil || string(got) != string(want) {
+		t.Fatalf("decimal parse: output %s, %v differs from RFC 8785 %s", got, err, want)
+	}
+}
+
+// === DEC-LABEL-001: Decimal output is labeled as a distinct profile ===
+
+func checkDecimalLabeled(t *testing.T, h *harness) {
+	t.Helper()
+	if jcs.DecimalProfile != "jcs-decimal" {
+		t.Fatalf("unexpected profile label %q", jcs.DecimalProfile)
+	}
+	res := runCLI(t, h, []string{"canonicalize", "--scheme", "decimal", "-"}, []byte(`[2.50]`))
+	if res.exitCode != 0 || res.stdout != `[2.5]` || res.stderr != "jcs-canon: profile jcs-decimal (not RFC 8785)\n" {
+		t.Fatalf("unexpected labeled result: %+v", res)
+	}
+	res = runCLI(t, h, []string{"canonicalize", "--quiet", "--scheme", "decimal", "-"}, []byte(`[2.50]`))
+	if res.exitCode != 0 || res.stderr != "" {
+		t.Fatalf("--quiet did not suppress the label: %+v", res)
+	}
+}
+
+// === CLI-DECIMAL-001: canonicalize --scheme decimal ===
+
+func checkCLIDecimal(t *testing.T, h *harness) {
+	t.Helper()
+	res := runCLI(t, h, []string{"canonicalize", "-q", "--scheme=decimal", "-"}, []byte(`{"v":1e400,"w":12345678901234567.89}`))
+	if res.exitCode != 0 || res.stdout != `{"v":1e+400,"w":12345678901234567.89}` {
+		t.Fatalf("unexpected result: %+v", res)
+	}
+	res = runCLI(t, h, []string{"canonicalize", "-"}, []byte(`{"v":1e400}`))
+	if res.exitCode != 2 || !strings.Contains(res.stderr, string(jcserr.NumberOverflow)) {
+		t.Fatalf("RFC 8785 accepted an overflowing number: %+v", res)
+	}
+	res = runCLI(t, h, []string{"canonicalize", "--scheme", "decimal", "-"}, []byte(`[1e-1000000000]`))
+	if res.exitCode != 2 || res.stdout != "" || !strings.Contains(res.stderr, string(jcserr.UnsupportedDecimal)) {
+		t.Fatalf("expected UNSUPPORTED_DECIMAL, got %+v", res)
+	}
+}
@@ -0,0 +1,258 @@
+package conformance_test
+
+import (
+	"encoding/json"
+	"path/filepath"
+	"testing"
+
+	"github.com/lattice-substrate/json-canon/jcs"
+	"github.com/lattice-substrate/json-canon/jcsdi"
+	"github.com/lattice-substrate/json-canon/jcserr"
+	"github.com/lattice-substrate/json-canon/jcstoken"
+)
+
+type diVectorFile struct {
+	ProofOptions struct {
+		ProofPurpose string `json:"proofPurpose"`
+		Created      string `json:"created"`
+	} `json:"proof_options"`
+	Unsecured string         `json:"unsecured"`
+	Cases     []diVectorCase `json:"cases"`
+}
+
+type diVectorCase struct {
+	ID          string `json:"id"`
+	Cryptosuite string `json:"cryptosuite"`
+	Key         string `json:"key"`
+	Secured     string `json:"secured"`
+}
+
+func diDir(h *harness) string {
+	return filepath.Join(h.root, "conformance", "official", "w3c-di")
+}
+
+func loadDIVectors(t *testing.T, h *harness) diVectorFile {
+	t.Helper()
+	var vf diVectorFile
+	if err := json.Unmarshal(mustReadBinaryFile(t, filepath.Join(diDir(h), "vectors.json")), &vf); err != nil {
+		t.Fatalf("decode w3c-di vectors: %v", err)
+	}
+	if len(vf.Cases) == 0 {
+		t.Fatal("w3c-di vectors.json has no cases")
+	}
+	return vf
+}
+
+func diParseFile(t *testing.T, h *harness, rel string) *jcstoken.Value {
+	t.Helper()
+	v, err := jcstoken.Parse(mustReadBinaryFile(t, filepath.Join(diDir(h), filepath.FromSlash(rel))))
+	if err != nil {
+		t.Fatalf("parse %s: %v", rel, err)
+	}
+	return v
+}
+
+func diKeyRing(t *testing.T, h *harness, vf diVectorFile) *jcsdi.KeyRing {
+	t.Helper()
+	paths := make([]string, 0, len(vf.Cases))
+	for _, c := range vf.Cases {
+		paths = append(paths, filepath.Join(diDir(h), filepath.FromSlash(c.Key)))
+	}
+	r, err := jcsdi.LoadKeyRing(paths...)
+	if err != nil {
+		t.Fatalf("load w3c-di keys: %v", err)
+	}
+	return r
+}
+
+func diKey(t *testing.T, h *harness, rel string) *jcsdi.Multikey {
+	t.Helper()
+	k, err := jcsdi.ReadMultikeyFile(filepath.Join(diDir(h), filepath.FromSlash(rel)))
+	if err != nil {
+		t.Fatalf("read key %s: %v", rel, err)
+	}
+	return k
+}
+
+func diOptions(vf diVectorFile, c diVectorCase, k *jcsdi.Multikey) jcsdi.ProofOptions {
+	return jcsdi.ProofOptions{
+		Cryptosuite:        c.Cryptosuite,
+		VerificationMethod: k.ID,
+		ProofPurpose:       vf.ProofOptions.ProofPurpose,
+		Created:            vf.ProofOptions.Created,
+	}
+}
+
+func diMember(t *testing.T, v *jcstoken.Value, name string) *jcstoken.Value {
+	t.Helper()
+	for i := range v.Members {
+		if v.Members[i].Key == name {
+			return &v.Members[i].Value
+		}
+	}
+	t.Fatalf("member %q not found", name)
+	return nil
+}
+
+// === DI-KEY-001: Multikey decoding ===
+
+func checkDIMultikeyDecoding(t *testing.T, h *harness) {
+	t.Helper()
+	vf := loadDIVectors(t, h)
+	for _, c := range vf.Cases {
+		k := diKey(t, h, c.Key)
+		if !k.CanSign() {
+			t.Fatalf("%s: fixture key has no secret key material", c.Key)
+		}
+		enc, err := jcsdi.EncodePublicKeyMultibase(k.Public())
+		if err != nil || enc != k.PublicKeyMultibase {
+			t.Fatalf("%s: public key re-encodes to %q, %v", c.Key, enc, err)
+		}
+	}
+	_, err := jcsdi.NewMultikey("k", "", "mAAAA", "")
+	requireClass(t, err, jcserr.InvalidKey)
+	ed := diKey(t, h, "keys/ed25519.json")
+	p256 := diKey(t, h, "keys/p256.json")
+	_, err = jcsdi.NewMultikey("k", "", ed.PublicKeyMultibase, p256.SecretKeyMultibase)
+	requireClass(t, err, jcserr.InvalidKey)
+}
+
+// === DI-SUITE-001: Cryptosuite key and hash binding ===
+
+func checkDISuiteKeyBinding(t *testing.T, h *harness) {
+	t.Helper()
+	vf := loadDIVectors(t, h)
+	doc := diParseFile(t, h, vf.Unsecured)
+	for _, c := range vf.Cases {
+		k := diKey(t, h, c.Key)
+		other := jcsdi.EdDSAJCS2022
+		if c.Cryptosuite == jcsdi.EdDSAJCS2022 {
+			other = jcsdi.ECDSAJCS2019
+		}
+		o := diOptions(vf, c, k)
+		o.Cryptosuite = other
+		_, err := jcsdi.CreateProof(doc, o, k)
+		requireClass(t, err, jcserr.InvalidKey)
+	}
+}
+
+// === DI-CREATE-001: proofValue signs proofConfigHash || documentHash ===
+
+func checkDICreateHashData(t *testing.T, h *harness) {
+	t.Helper()
+	vf := loadDIVectors(t, h)
+	c := vf.Cases[0]
+	k := diKey(t, h, c.Key)
+	pretty := diParseFile(t, h, vf.Unsecured)
+	canonical, err := jcs.Serialize(pretty)
+	if err != nil {
+		t.Fatal(err)
+	}
+	compact, err := jcstoken.Parse(canonical)
+	if err != nil {
+		t.Fatal(err)
+	}
+	a, err := jcsdi.CreateProof(pretty, diOptions(vf, c, k), k)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := jcsdi.CreateProof(compact, diOptions(vf, c, k), k)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sa, sb := mustCanonical(t, a), mustCanonical(t, b); sa != sb {
+		t.Fatalf("proof depends on source formatting:\n%s\n%s", sa, sb)
+	}
+	// An existing proof member is not covered by a new proof.
+	secured := diParseFile(t, h, c.Secured)
+	again, err := jcsdi.CreateProof(secured, diOptions(vf, c, k), k)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sa, sb := mustCanonical(t, a), mustCanonical(t, again); sa != sb {
+		t.Fatalf("existing proof was signed over:\n%s\n%s", sa, sb)
+	}
+}
+
+func mustCanonical(t *testing.T, v *jcstoken.Value) string {
+	t.Helper()
+	out, err := jcs.Serialize(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(out)
+}
+
+// === DI-CREATE-002: Proof options are validated ===
+
+func checkDICreateValidatesOptions(t *testing.T, h *harness) {
+	t.Helper()
+	vf := loadDIVectors(t, h)
+	c := vf.Cases[0]
+	k := diKey(t, h, c.Key)
+	doc := diParseFile(t, h, vf.Unsecured)
+	for _, mutate := range []func(*jcsdi.ProofOptions){
+		func(o *jcsdi.ProofOptions) { o.Cryptosuite = "eddsa-rdfc-2022" },
+		func(o *jcsdi.ProofOptions) { o.ProofPurpose = "" },
+		func(o *jcsdi.ProofOptions) { o.VerificationMethod = "" },
+		func(o *jcsdi.ProofOptions) { o.Created = "2023-02-24 23:36:38" },
+	} {
+		o := diOptions(vf, c, k)
+		mutate(&o)
+		_, err := jcsdi.CreateProof(doc, o, k)
+		requireClass(t, err, jcserr.InvalidProof)
+	}
+}
+
+// === DI-VERIFY-001: Verification recomputes hashData ===
+
+func checkDIVerifySignature(t *testing.T, h *harness) {
+	t.Helper()
+	vf := loadDIVectors(t, h)
+	r := diKeyRing(t, h, vf)
+	for _, c := range vf.Cases {
+		secured := diParseFile(t, h, c.Secured)
+		if err := jcsdi.VerifyProof(secured, r); err != nil {
+			t.Fatalf("%s: %v", c.ID, err)
+		}
+		diMember(t, diMember(t, secured, "credentialSubject"), "alumniOf").Str = "Another School"
+		requireClass(t, jcsdi.VerifyProof(secured, r), jcserr.SignatureInvalid)
+
+		secured = diParseFile(t, h, c.Secured)
+		diMember(t, diMember(t, secured, "proof"), "created").Str = "2024-01-01T00:00:00Z"
+		requireClass(t, jcsdi.VerifyProof(secured, r), jcserr.SignatureInvalid)
+	}
+}
+
+// === DI-VERIFY-002: Proof @context must prefix the document @context ===
+
+func checkDIVerifyContextPrefix(t *testing.T, h *harness) {
+	t.Helper()
+	vf := loadDIVectors(t, h)
+	r := diKeyRing(t, h, vf)
+	for _, c := range vf.Cases {
+		secured := diParseFile(t, h, c.Secured)
+		ctx := diMember(t, secured, "@context")
+		ctx.Elems = ctx.Elems[:1]
+		requireClass(t, jcsdi.VerifyProof(secured, r), jcserr.InvalidProof)
+	}
+}
+
+// === DI-VEC-001: W3C Data Integrity fixtures reproduce exactly ===
+
+func checkDIOfficialVectors(t *testing.T, h *harness) {
+	t.Helper()
+	vf := loadDIVectors(t, h)
+	doc := diParseFile(t, h, vf.Unsecured)
+	for _, c := range vf.Cases {
+		k := diKey(t, h, c.Key)
+		out, err := jcsdi.AddProof(doc, diOptions(vf, c, k), k)
+		if err != nil {
+			t.Fatalf("%s: %v", c.ID, err)
+		}
+		want := string(mustReadBinaryFile(t, filepath.Join(diDir(h), filepath.FromSlash(c.Secured))))
+		if got := mustCanonical(t, out); got != want {
+			t.Fatalf("%s mismatch:\n got %s\nwant %s", c.ID, got, want)
+		}
+	}
+}
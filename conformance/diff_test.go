@@ -0,0 +1,130 @@
+package conformance_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/lattice-substrate/json-canon/jcs"
+	"github.com/lattice-substrate/json-canon/jcserr"
+	"github.com/lattice-substrate/json-canon/jcstoken"
+)
+
+func diffPatch(t *testing.T, a, b string, opts *jcstoken.Options) (string, error) {
+	t.Helper()
+	va, err := jcstoken.Parse([]byte(a))
+	if err != nil {
+		t.Fatalf("parse %s: %v", a, err)
+	}
+	vb, err := jcstoken.Parse([]byte(b))
+	if err != nil {
+		t.Fatalf("parse %s: %v", b, err)
+	}
+	patch, err := jcs.DiffWithOptions(va, vb, opts)
+	if err != nil {
+		return "", err
+	}
+	out, err := patch.Serialize()
+	if err != nil {
+		t.Fatalf("serialize patch: %v", err)
+	}
+	return string(out), nil
+}
+
+func requireDiff(t *testing.T, cases []struct{ a, b, want string }) {
+	t.Helper()
+	for _, tc := range cases {
+		got, err := diffPatch(t, tc.a, tc.b, nil)
+		if err != nil {
+			t.Fatalf("Diff(%s, %s): %v", tc.a, tc.b, err)
+		}
+		if got != tc.want {
+			t.Fatalf("Diff(%s, %s) = %s, want %s", tc.a, tc.b, got, tc.want)
+		}
+	}
+}
+
+// diffFiles writes a and b to a temporary directory and returns their paths.
+func diffFiles(t *testing.T, a, b string) (string, string) {
+	t.Helper()
+	dir := t.TempDir()
+	pa, pb := filepath.Join(dir, "a.json"), filepath.Join(dir, "b.json")
+	for path, content := range map[string]string{pa: a, pb: b} {
+		if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+			t.Fatalf("write fixture: %v", err)
+		}
+	}
+	return pa, pb
+}
+
+// === DIFF-API-001: Diff emits an RFC 6902 patch transforming a into b ===
+
+func checkDiffApplies(t *testing.T, _ *harness) {
+	t.Helper()
+	requireDiff(t, []struct{ a, b, want string }{
+		{`{"b":[1,2.0],"a":"x"}`, `{"a":"x","b":[1.0,2]}`, `[]`},
+		{`{"a":1}`, `[1]`, `[{"op":"replace","path":"","value":[1]}]`},
+		{`{"a":{"b":null}}`, `{"a":{"b":false}}`, `[{"op":"replace","path":"/a/b","value":false}]`},
+		{`{"a/b":1,"c~d":2}`, `{"a/b":1}`, `[{"op":"remove","path":"/c~0d"}]`},
+		{`[1,2,3]`, `[1,3]`, `[{"op":"remove","path":"/1"}]`},
+		{`[1,3]`, `[1,2,3]`, `[{"op":"add","path":"/1","value":2}]`},
+	})
+	_, err := diffPatch(t, `[[1]]`, `[]`, &jcstoken.Options{MaxDepth: 1})
+	requireClass(t, err, jcserr.BoundExceeded)
+}
+
+// === DIFF-ORDER-001: Patch operations follow a deterministic order ===
+
+func checkDiffOrder(t *testing.T, _ *harness) {
+	t.Helper()
+	want := `[{"op":"remove","path":"/a"},{"op":"replace","path":"/b/0","value":0},{"op":"remove","path":"/b/3"},{"op":"remove","path":"/b/2"},{"op":"add","path":"/c","value":{"d":1}},{"op":"add","path":"/ﬁ","value":1}]`
+	requireDiff(t, []struct{ a, b, want string }{
+		{`{"a":1,"b":[1,2,3,4,5]}`, `{"ﬁ":1,"c":{"d":1},"b":[0,2,5]}`, want},
+		{`{"b":[1,2,3,4,5],"a":1}`, `{"b":[0,2,5],"c":{"d":1},"ﬁ":1}`, want},
+		{`[0,0]`, `[0,1,2,0]`, `[{"op":"add","path":"/1","value":1},{"op":"add","path":"/2","value":2}]`},
+	})
+}
+
+// === CLI-DIFF-001: diff exits 0 for canonically equal documents and 1 otherwise ===
+
+func checkCLIDiff(t *testing.T, h *harness) {
+	t.Helper()
+	a, b := diffFiles(t, `{"b":2,"a":1}`, `{"a":1,"b":3}`)
+	res := runCLI(t, h, []string{"diff", a, b}, nil)
+	if res.exitCode != 1 || res.stdout != `[{"op":"replace","path":"/b","value":3}]` || res.stderr != "" {
+		t.Fatalf("unexpected result: %+v", res)
+	}
+	res = runCLI(t, h, []string{"diff", a, "-"}, []byte(`{ "a": 1.0, "b": 2 }`))
+	if res.exitCode != 0 || res.stdout != `[]` || res.stderr != "" {
+		t.Fatalf("equal documents: %+v", res)
+	}
+	res = runCLI(t, h, []string{"diff", "--quiet", a, b}, nil)
+	if res.exitCode != 1 || res.stdout != "" || res.stderr != "" {
+		t.Fatalf("--quiet: %+v", res)
+	}
+	res = runCLI(t, h, []string{"diff", a, "-"}, []byte(`[1,]`))
+	requireExitClass(t, res, jcserr.InvalidGrammar)
+	if !strings.HasPrefix(res.stderr, "-: ") {
+		t.Fatalf("failure does not name the input: %+v", res)
+	}
+	for _, args := range [][]string{{"diff", a}, {"diff", a, b, b}, {"diff", "-", "-"}, {"diff", "--format", "yaml", a, b}} {
+		requireExitClass(t, runCLI(t, h, args, nil), jcserr.CLIUsage)
+	}
+}
+
+// === CLI-DIFF-002: The summary names each changed value by JSON Pointer ===
+
+func checkCLIDiffSummary(t *testing.T, h *harness) {
+	t.Helper()
+	a, b := diffFiles(t, `{"a":[1,2],"b":"x","c":{}}`, `{"a":[1],"b":"y","c":{"d e":null}}`)
+	res := runCLI(t, h, []string{"diff", "--format=summary", a, b}, nil)
+	want := "- \"/a/1\": 2\n~ \"/b\": \"x\" -> \"y\"\n+ \"/c/d e\": null\n"
+	if res.exitCode != 1 || res.stdout != want || res.stderr != "" {
+		t.Fatalf("unexpected summary: %+v", res)
+	}
+	res = runCLI(t, h, []string{"diff", "--format", "summary", a, a}, nil)
+	if res.exitCode != 0 || res.stdout != "" {
+		t.Fatalf("equal documents: %+v", res)
+	}
+}
@@ -0,0 +1,107 @@
+package conformance_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/lattice-substrate/json-canon/jcs"
+	"github.com/lattice-substrate/json-canon/jcserr"
+)
+
+// === EMBED-CANON-001: Embedded JSON is replaced by its canonical form ===
+
+func checkEmbeddedCanonical(t *testing.T, _ *harness) {
+	t.Helper()
+	sel := &jcs.EmbeddedJSON{Pointers: []string{"/p", "/absent"}}
+	a, err := jcs.CanonicalizeEmbeddedJSON([]byte(`{"p":"{\"b\":1,\"a\":[1.0,\"\\u00e9\"]}"}`), sel, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := jcs.CanonicalizeEmbeddedJSON([]byte("{\"p\":\"{ \\\"a\\\" : [ 1e0 , \\\"é\\\" ],\\n \\\"b\\\" : 1 }\"}"), sel, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(a) != string(b) || string(a) != `{"p":"{\"a\":[1,\"é\"],\"b\":1}"}` {
+		t.Fatalf("embedded forms differ: %s vs %s", a, b)
+	}
+}
+
+// === EMBED-SELECT-001: Selected strings must hold strict JSON text ===
+
+func checkEmbeddedSelect(t *testing.T, _ *harness) {
+	t.Helper()
+	_, err := jcs.CanonicalizeEmbeddedJSON([]byte(`{"p":[1]}`), &jcs.EmbeddedJSON{Pointers: []string{"/p"}}, nil)
+	requireClass(t, err, jcserr.InvalidPointer)
+	_, err = jcs.CanonicalizeEmbeddedJSON([]byte(`{"p":"{\"a\":1,\"a\":2}"}`), &jcs.EmbeddedJSON{Pointers: []string{"/p"}}, nil)
+	requireClass(t, err, jcserr.DuplicateKey)
+	_, err = jcs.CanonicalizeEmbeddedJSON([]byte(`{"p":"// c\n{}"}`), &jcs.EmbeddedJSON{Pointers: []string{"/p"}}, nil)
+	requireClass(t, err, jcserr.InvalidGrammar)
+}
+
+// === EMBED-DETECT-001: Detection rewrites only strings holding objects or arrays ===
+
+func checkEmbeddedDetect(t *testing.T, _ *harness) {
+	t.Helper()
+	in := `{"a":"[ \"{ \\\"z\\\":0 }\" ]","b":"7","c":"{nope","{ \"k\":1 }":null}`
+	got, err := jcs.CanonicalizeEmbeddedJSON([]byte(in), &jcs.EmbeddedJSON{Detect: true}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `{"a":"[\"{\\\"z\\\":0}\"]","b":"7","c":"{nope","{ \"k\":1 }":null}`
+	if string(got) != want {
+		t.Fatalf("got %s want %s", got, want)
+	}
+}
+
+// === EMBED-ERROR-001: Embedded failures carry the outer pointer and inner offset ===
+
+func checkEmbeddedError(t *testing.T, _ *harness) {
+	t.Helper()
+	_, err := jcs.CanonicalizeEmbeddedJSON([]byte(`{"x":["ok","{\"a\":tru}"]}`), &jcs.EmbeddedJSON{Pointers: []string{"/x/1"}}, nil)
+	requireClass(t, err, jcserr.InvalidGrammar)
+	var ee *jcs.EmbeddedJSONError
+	if !errors.As(err, &ee) || ee.Pointer != "/x/1" || ee.Err.Offset != 5 {
+		t.Fatalf("unexpected embedded error location: %v", err)
+	}
+}
+
+// === EMBED-LABEL-001: Embedded-JSON output is labeled as a distinct profile ===
+
+func checkEmbeddedLabeled(t *testing.T, h *harness) {
+	t.Helper()
+	if jcs.EmbeddedJSONProfile != "jcs-embedded-json" {
+		t.Fatalf("unexpected profile label %q", jcs.EmbeddedJSONProfile)
+	}
+	res := runCLI(t, h, []string{"canonicalize", "--embedded-json-detect", "-"}, []byte(`["{ }"]`))
+	if res.exitCode != 0 || res.stdout != `["{}"]` || res.stderr != "jcs-canon: profile jcs-embedded-json (not RFC 8785)\n" {
+		t.Fatalf("unexpected labeled result: %+v", res)
+	}
+	res = runCLI(t, h, []string{"canonicalize", "-q", "--embedded-json-detect", "-"}, []byte(`["{ }"]`))
+	if res.exitCode != 0 || res.stderr != "" {
+		t.Fatalf("--quiet did not suppress the label: %+v", res)
+	}
+	res = runCLI(t, h, []string{"canonicalize", "-"}, []byte(`["{ }"]`))
+	if res.exitCode != 0 || res.stdout != `["{ }"]` || res.stderr != "" {
+		t.Fatalf("default output changed: %+v", res)
+	}
+}
+
+// === CLI-EMBED-001: canonicalize --embedded-json and --embedded-json-detect ===
+
+func checkCLIEmbedded(t *testing.T, h *harness) {
+	t.Helper()
+	in := []byte(`{"drop":1,"p":"{\"b\":1,\"drop\":2,\"a\":0}"}`)
+	res := runCLI(t, h, []string{"canonicalize", "-q", "--exclude", "/drop", "--embedded-json=/p", "-"}, in)
+	if res.exitCode != 0 || res.stdout != `{"p":"{\"a\":0,\"b\":1,\"drop\":2}"}` {
+		t.Fatalf("unexpected result: %+v", res)
+	}
+	res = runCLI(t, h, []string{"canonicalize", "--embedded-json", "/drop", "-"}, in)
+	if res.exitCode != 2 || !strings.Contains(res.stderr, string(jcserr.InvalidPointer)) {
+		t.Fatalf("expected INVALID_POINTER, got %+v", res)
+	}
+	res = runCLI(t, h, []string{"verify", "--embedded-json-detect", "-"}, []byte(`{}`))
+	if res.exitCode != 2 || !strings.Contains(res.stderr, string(jcserr.CLIUsage)) {
+		t.Fatalf("verify accepted --embedded-json-detect: %+v", res)
+	}
+}
@@ -0,0 +1,134 @@
+package conformance_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"unicode/utf16"
+
+	"github.com/lattice-substrate/json-canon/jcserr"
+	"github.com/lattice-substrate/json-canon/jcstoken"
+)
+
+// utf16LE encodes s as UTF-16LE, with a byte order mark if bom is set.
+func utf16LE(s string, bom bool) []byte {
+	var out []byte
+	if bom {
+		out = append(out, 0xFF, 0xFE)
+	}
+	for _, u := range utf16.Encode([]rune(s)) {
+		out = append(out, byte(u), byte(u>>8))
+	}
+	return out
+}
+
+// utf32BE encodes s as UTF-32BE without a byte order mark.
+func utf32BE(s string) []byte {
+	var out []byte
+	for _, r := range s {
+		out = append(out, byte(r>>24), byte(r>>16), byte(r>>8), byte(r))
+	}
+	return out
+}
+
+func encodedClass(t *testing.T, data []byte, enc jcstoken.Encoding) *jcserr.Error {
+	t.Helper()
+	_, err := jcstoken.ParseEncoded(data, enc, jcstoken.SyntaxJSON, nil)
+	var je *jcserr.Error
+	if !errors.As(err, &je) {
+		t.Fatalf("expected *jcserr.Error, got %v", err)
+	}
+	return je
+}
+
+// === ENC-DETECT-001: Encodings are detected from the BOM or zero-byte pattern ===
+
+func checkEncodingDetect(t *testing.T, _ *harness) {
+	t.Helper()
+	for data, want := range map[string]jcstoken.Encoding{
+		"\xEF\xBB\xBF{}":     jcstoken.EncodingUTF8,
+		"\xFF\xFE{\x00}\x00": jcstoken.EncodingUTF16LE,
+		"\xFE\xFF\x00{\x00}": jcstoken.EncodingUTF16BE,
+		"\x00{\x00}":         jcstoken.EncodingUTF16BE,
+		"\xFF\xFE\x00\x00[\x00\x00\x00]\x00\x00\x00": jcstoken.EncodingUTF32LE,
+		"\x00\x00\x00[\x00\x00\x00]":                 jcstoken.EncodingUTF32BE,
+		"[]":                                         jcstoken.EncodingUTF8,
+	} {
+		if got, _ := jcstoken.DetectEncoding([]byte(data)); got != want {
+			t.Fatalf("DetectEncoding(% x) = %s, want %s", data, got, want)
+		}
+		if _, err := jcstoken.ParseEncoded([]byte(data), jcstoken.EncodingAuto, jcstoken.SyntaxJSON, nil); err != nil {
+			t.Fatalf("ParseEncoded(% x): %v", data, err)
+		}
+	}
+	if je := encodedClass(t, []byte("\xEF\xBB\xBF{}"), jcstoken.EncodingUTF8); je.Class != jcserr.InvalidGrammar {
+		t.Fatalf("default encoding accepted a BOM: %s", je.Class)
+	}
+}
+
+// === ENC-TRANSCODE-001: UTF-16 and UTF-32 input is transcoded to UTF-8 ===
+
+func checkEncodingTranscode(t *testing.T, _ *harness) {
+	t.Helper()
+	const doc = "{\"k\":\"é\U0001D11E\"}"
+	for _, tc := range []struct {
+		data []byte
+		enc  jcstoken.Encoding
+	}{
+		{utf16LE(doc, true), jcstoken.EncodingAuto},
+		{utf16LE(doc, false), jcstoken.EncodingUTF16LE},
+		{utf32BE(doc), jcstoken.EncodingUTF32BE},
+	} {
+		tr, err := jcstoken.Transcode(tc.data, tc.enc)
+		if err != nil || string(tr.Data) != doc {
+			t.Fatalf("Transcode(%s) = %q, %v", tc.enc, tr.Data, err)
+		}
+	}
+	if je := encodedClass(t, utf16LE("[1]", false)[:5], jcstoken.EncodingUTF16LE); je.Class != jcserr.InvalidUTF8 {
+		t.Fatalf("truncated UTF-16: %s", je.Class)
+	}
+}
+
+// === ENC-SURROGATE-001: Unpaired surrogates in the source are LONE_SURROGATE ===
+
+func checkEncodingSurrogate(t *testing.T, _ *harness) {
+	t.Helper()
+	data := append(utf16LE(`["`, false), 0x00, 0xD8, '"', 0x00, ']', 0x00)
+	if je := encodedClass(t, data, jcstoken.EncodingUTF16LE); je.Class != jcserr.LoneSurrogate || je.Offset != 4 {
+		t.Fatalf("unpaired UTF-16 surrogate: %s at %d", je.Class, je.Offset)
+	}
+	data = append(utf32BE(`["`), 0x00, 0x00, 0xDF, 0xFF)
+	if je := encodedClass(t, data, jcstoken.EncodingUTF32BE); je.Class != jcserr.LoneSurrogate || je.Offset != 8 {
+		t.Fatalf("UTF-32 surrogate: %s at %d", je.Class, je.Offset)
+	}
+}
+
+// === ENC-OFFSET-001: Error offsets refer to the original input bytes ===
+
+func checkEncodingOffset(t *testing.T, _ *harness) {
+	t.Helper()
+	// The duplicate key starts at character 9 (after the BOM).
+	je := encodedClass(t, utf16LE(`{"a":1,"a":2}`, true), jcstoken.EncodingAuto)
+	if je.Class != jcserr.DuplicateKey || je.Offset != 2+7*2 {
+		t.Fatalf("unexpected error %s at %d", je.Class, je.Offset)
+	}
+}
+
+// === CLI-ENCODING-001: canonicalize --input-encoding ===
+
+func checkCLIEncoding(t *testing.T, h *harness) {
+	t.Helper()
+	in := utf16LE(`{ "b": 1, "a": "é" }`, true)
+	res := runCLI(t, h, []string{"canonicalize", "--input-encoding", "auto", "-"}, in)
+	if res.exitCode != 0 || res.stdout != `{"a":"é","b":1}` || res.stderr != "" {
+		t.Fatalf("unexpected result: %+v", res)
+	}
+	res = runCLI(t, h, []string{"canonicalize", "-"}, in)
+	if res.exitCode != 2 || !strings.Contains(res.stderr, string(jcserr.InvalidUTF8)) {
+		t.Fatalf("default encoding accepted UTF-16: %+v", res)
+	}
+	res = runCLI(t, h, []string{"canonicalize", "--input-encoding=latin1", "-"}, []byte(`{}`))
+	if res.exitCode != 2 || !strings.Contains(res.stderr, string(jcserr.CLIUsage)) {
+		t.Fatalf("expected CLI_USAGE, got %+v", res)
+	}
+}
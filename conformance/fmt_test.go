@@ -0,0 +1,153 @@
+package conformance_test
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/lattice-substrate/json-canon/jcs"
+	"github.com/lattice-substrate/json-canon/jcserr"
+	"github.com/lattice-substrate/json-canon/jcstoken"
+)
+
+// loadVectorFile returns the vectors in conformance/vectors/name.
+func loadVectorFile(t *testing.T, h *harness, name string) []vectorCase {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join(h.root, "conformance", "vectors", name))
+	if err != nil {
+		t.Fatalf("read vectors %s: %v", name, err)
+	}
+	var vectors []vectorCase
+	for _, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		var v vectorCase
+		if err := json.Unmarshal([]byte(line), &v); err != nil {
+			t.Fatalf("decode vector in %s: %v", name, err)
+		}
+		vectors = append(vectors, v)
+	}
+	return vectors
+}
+
+func vectorInput(t *testing.T, v *vectorCase) []byte {
+	t.Helper()
+	if v.InputHex == "" {
+		return []byte(v.Input)
+	}
+	b, err := hex.DecodeString(v.InputHex)
+	if err != nil {
+		t.Fatalf("%s: decode input_hex: %v", v.ID, err)
+	}
+	return b
+}
+
+// === FMT-VIEW-001: Format lays out the canonical form one member or element per line ===
+
+func checkFormatView(t *testing.T, _ *harness) {
+	t.Helper()
+	v, err := jcstoken.Parse([]byte(`{"z":[1.0,"é",{}],"a":{"":null,"😀":[]}}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := jcs.Format(v, "  ")
+	want := "{\n  \"a\": {\n    \"\U0001F600\": [],\n    \"\": null\n  },\n  \"z\": [\n    1,\n    \"é\",\n    {}\n  ]\n}\n"
+	if err != nil || string(got) != want {
+		t.Fatalf("Format = %q, %v; want %q", got, err, want)
+	}
+	_, err = jcs.Format(v, "--")
+	requireClass(t, err, jcserr.InternalError)
+}
+
+// === FMT-ROUNDTRIP-001: Canonicalizing the Format output yields the canonical bytes ===
+
+// checkFormatRoundTrip formats the input of every plain canonicalize vector
+// and every fmt vector, and requires the result to canonicalize to the
+// canonical bytes of the input.
+func checkFormatRoundTrip(t *testing.T, h *harness) {
+	t.Helper()
+	checked := 0
+	for _, name := range []string{"core.jsonl", "fmt.jsonl"} {
+		for _, vec := range loadVectorFile(t, h, name) {
+			plain := len(vec.Args) == 0 && vec.Mode == "canonicalize" || isPlainCommand(vec.Args, "canonicalize", "fmt")
+			if vec.WantExit != 0 || !plain {
+				continue
+			}
+			input := vectorInput(t, &vec)
+			v, err := jcstoken.Parse(input)
+			if err != nil {
+				t.Fatalf("%s: parse input: %v", vec.ID, err)
+			}
+			want, err := jcs.Serialize(v)
+			if err != nil {
+				t.Fatalf("%s: serialize: %v", vec.ID, err)
+			}
+			for _, indent := range []string{"", "  ", "\t"} {
+				pretty, err := jcs.Format(v, indent)
+				if err != nil {
+					t.Fatalf("%s: Format: %v", vec.ID, err)
+				}
+				if got, err := jcs.Canonicalize(pretty); err != nil || string(got) != string(want) {
+					t.Fatalf("%s: Format(%q) canonicalizes to %q (%v), want %q", vec.ID, indent, got, err, want)
+				}
+			}
+			if vec.WantStdout != nil && isPlainCommand(vec.Args, "fmt") {
+				if got, err := jcs.Canonicalize([]byte(*vec.WantStdout)); err != nil || string(got) != string(want) {
+					t.Fatalf("%s: fmt output canonicalizes to %q (%v), want %q", vec.ID, got, err, want)
+				}
+			}
+			checked++
+		}
+	}
+	if checked < 20 {
+		t.Fatalf("only %d vectors round-tripped", checked)
+	}
+}
+
+// isPlainCommand reports whether args run one of cmds on a single input
+// with at most an --indent option.
+func isPlainCommand(args []string, cmds ...string) bool {
+	if len(args) == 0 {
+		return false
+	}
+	found := false
+	for _, c := range cmds {
+		found = found || args[0] == c
+	}
+	if !found {
+		return false
+	}
+	for _, a := range args[1:] {
+		if strings.HasPrefix(a, "-") && a != "-" && !strings.HasPrefix(a, "--indent") {
+			return false
+		}
+	}
+	return true
+}
+
+// === CLI-FMT-001: fmt writes the jcs.Format view of the input ===
+
+func checkCLIFmt(t *testing.T, h *harness) {
+	t.Helper()
+	input := []byte(`{"b":[2,1],"a":{"c":-1.5E-3}}`)
+	res := runCLI(t, h, []string{"fmt"}, input)
+	want := "{\n  \"a\": {\n    \"c\": -0.0015\n  },\n  \"b\": [\n    2,\n    1\n  ]\n}\n"
+	if res.exitCode != 0 || res.stdout != want || res.stderr != "" {
+		t.Fatalf("unexpected result: %+v", res)
+	}
+	res = runCLI(t, h, []string{"fmt", "--indent", "tab", "-"}, input)
+	if res.exitCode != 0 || res.stdout != strings.ReplaceAll(want, "  ", "\t") {
+		t.Fatalf("--indent tab: %+v", res)
+	}
+	canon := runCLI(t, h, []string{"canonicalize"}, []byte(res.stdout))
+	if canon.exitCode != 0 || canon.stdout != `{"a":{"c":-0.0015},"b":[2,1]}` {
+		t.Fatalf("fmt output does not canonicalize back: %+v", canon)
+	}
+	requireExitClass(t, runCLI(t, h, []string{"fmt"}, []byte(`{"a":1,"a":1}`)), jcserr.DuplicateKey)
+	requireExitClass(t, runCLI(t, h, []string{"fmt", "--indent", "x"}, input), jcserr.CLIUsage)
+}
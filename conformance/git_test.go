@@ -0,0 +1,354 @@
+package conformance_test
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/lattice-substrate/json-canon/jcs"
+	"github.com/lattice-substrate/json-canon/jcserr"
+	"github.com/lattice-substrate/json-canon/jcsgit"
+)
+
+// pktLines encodes text lines as git pkt-lines, "" standing for a flush
+// packet.
+func pktLines(lines ...string) string {
+	var b strings.Builder
+	for _, line := range lines {
+		if line == "" {
+			b.WriteString("0000")
+			continue
+		}
+		fmt.Fprintf(&b, "%04x%s", len(line)+4, line)
+	}
+	return b.String()
+}
+
+var (
+	filterHandshake = pktLines("git-filter-client\n", "version=2\n", "", "capability=clean\n", "capability=smudge\n", "")
+	filterWelcome   = pktLines("git-filter-server\n", "version=2\n", "", "capability=clean\n", "")
+)
+
+// gitRepo is a scratch repository whose git commands ignore the user's and
+// the system's configuration.
+type gitRepo struct {
+	dir string
+	env []string
+}
+
+func newGitRepo(t *testing.T) *gitRepo {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	r := &gitRepo{
+		dir: t.TempDir(),
+		env: append(os.Environ(), "HOME="+t.TempDir(), "GIT_CONFIG_NOSYSTEM=1",
+			"GIT_AUTHOR_NAME=jcs", "GIT_AUTHOR_EMAIL=jcs@example.invalid",
+			"GIT_COMMITTER_NAME=jcs", "GIT_COMMITTER_EMAIL=jcs@example.invalid"),
+	}
+	r.mustRun(t, "init", "-q")
+	return r
+}
+
+// run runs git in the repository and returns its combined output.
+func (r *gitRepo) run(t *testing.T, args ...string) (string, error) {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
+	defer cancel()
+	cmd := exec.CommandContext(ctx, "git", args...)
+	cmd.Dir = r.dir
+	cmd.Env = r.env
+	out, err := cmd.CombinedOutput()
+	return string(out), err
+}
+
+func (r *gitRepo) mustRun(t *testing.T, args ...string) string {
+	t.Helper()
+	out, err := r.run(t, args...)
+	if err != nil {
+		t.Fatalf("git %v: %v\n%s", args, err, out)
+	}
+	return out
+}
+
+func (r *gitRepo) write(t *testing.T, name, content string, perm os.FileMode) {
+	t.Helper()
+	path := filepath.Join(r.dir, filepath.FromSlash(name))
+	if err := os.MkdirAll(filepath.Dir(path), 0o750); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), perm); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// === GIT-FILTER-001: The filter process canonicalizes each file and answers a failure with an error status ===
+
+func checkGitFilter(t *testing.T, _ *harness) {
+	t.Helper()
+	var reported []string
+	f := &jcsgit.Filter{
+		Clean: func(_ string, content []byte) ([]byte, error) { return jcs.Canonicalize(content) },
+		Report: func(pathname string, _ error, _ []byte) {
+			reported = append(reported, pathname)
+		},
+	}
+	stream := filterHandshake +
+		pktLines("command=clean\n", "pathname=a.json\n", "", `{"z":1,"a":[1.0E2]}`, "") +
+		pktLines("command=clean\n", "pathname=b.json\n", "", `{"a":1,"a":1}`, "")
+	var out bytes.Buffer
+	if err := f.Serve(strings.NewReader(stream), &out); err != nil {
+		t.Fatal(err)
+	}
+	want := filterWelcome + pktLines("status=success\n", "", `{"a":[100],"z":1}`, "", "") + pktLines("status=error\n", "")
+	if out.String() != want || len(reported) != 1 || reported[0] != "b.json" {
+		t.Fatalf("got %q (reported %q), want %q", out.String(), reported, want)
+	}
+	err := f.Serve(strings.NewReader(pktLines("git-filter-client\n", "version=3\n", "")), &out)
+	requireClass(t, err, jcserr.CLIUsage)
+}
+
+// === GIT-FILTER-002: Content over the size bound fails with BOUND_EXCEEDED ===
+
+func checkGitFilterBound(t *testing.T, _ *harness) {
+	t.Helper()
+	var reported error
+	f := &jcsgit.Filter{
+		Clean:          func(_ string, content []byte) ([]byte, error) { return jcs.Canonicalize(content) },
+		Report:         func(_ string, err error, _ []byte) { reported = err },
+		MaxContentSize: 4,
+	}
+	stream := filterHandshake + pktLines("command=clean\n", "pathname=a.json\n", "", `[1,`, `2]`, "")
+	var out bytes.Buffer
+	if err := f.Serve(strings.NewReader(stream), &out); err != nil {
+		t.Fatal(err)
+	}
+	if out.String() != filterWelcome+pktLines("status=error\n", "") {
+		t.Fatalf("got %q", out.String())
+	}
+	requireClass(t, reported, jcserr.BoundExceeded)
+}
+
+// === GIT-STAGED-001: Pre-commit checks read the staged content of the staged files ===
+
+func checkGitStaged(t *testing.T, _ *harness) {
+	t.Helper()
+	r := newGitRepo(t)
+	r.write(t, "a.json", `{"b":1,"a":2}`, 0o600)
+	r.write(t, "gone.json", `[]`, 0o600)
+	r.mustRun(t, "add", ".")
+	r.mustRun(t, "commit", "-q", "-m", "initial")
+	r.write(t, "a.json", `{"a":2,"b":1}`, 0o600)
+	r.write(t, "new.json", `[ ]`, 0o600)
+	r.mustRun(t, "add", "new.json")
+	r.mustRun(t, "rm", "-q", "gone.json")
+
+	ctx := context.Background()
+	paths, err := jcsgit.StagedPaths(ctx, r.dir)
+	if err != nil || len(paths) != 1 || paths[0] != "new.json" {
+		t.Fatalf("staged paths %q: %v", paths, err)
+	}
+	r.mustRun(t, "add", "a.json")
+	r.write(t, "a.json", `{"b":1,"a":2}`, 0o600)
+	got, err := jcsgit.ReadStaged(ctx, r.dir, "a.json", 0)
+	if err != nil || string(got) != `{"a":2,"b":1}` {
+		t.Fatalf("staged content %q: %v", got, err)
+	}
+	_, err = jcsgit.ReadStaged(ctx, r.dir, "a.json", 4)
+	requireClass(t, err, jcserr.BoundExceeded)
+	_, err = jcsgit.StagedPaths(ctx, t.TempDir())
+	requireClass(t, err, jcserr.CLIUsage)
+}
+
+// === GIT-EXEC-001: The package runs only the git executable, without a shell ===
+
+func checkGitExec(t *testing.T, h *harness) {
+	t.Helper()
+	banned := []string{"net", "net/http", "net/url", "net/netip", "net/rpc", "net/smtp", "os/signal", "syscall"}
+	dir := filepath.Join(h.root, "jcsgit")
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatalf("read dir %s: %v", dir, err)
+	}
+	runs := 0
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".go") || strings.HasSuffix(entry.Name(), "_test.go") {
+			continue
+		}
+		path := filepath.Join(dir, entry.Name())
+		f, err := parser.ParseFile(token.NewFileSet(), path, nil, 0)
+		if err != nil {
+			t.Fatalf("parse file %s: %v", path, err)
+		}
+		for _, is := range f.Imports {
+			imp := strings.Trim(is.Path.Value, "\"")
+			for _, b := range banned {
+				if imp == b {
+					t.Fatalf("file %s imports %q", path, imp)
+				}
+			}
+		}
+		ast.Inspect(f, func(n ast.Node) bool {
+			call, ok := n.(*ast.CallExpr)
+			if !ok {
+				return true
+			}
+			program, isExec := execProgram(call)
+			if isExec && program != "git" {
+				t.Fatalf("file %s runs %s, want the literal program \"git\"", path, program)
+			}
+			if isExec {
+				runs++
+			}
+			return true
+		})
+	}
+	if runs == 0 {
+		t.Fatal("no exec.Command or exec.CommandContext call found in jcsgit")
+	}
+}
+
+// execProgram reports whether call is exec.Command, exec.CommandContext, or
+// exec.LookPath and, if so, the literal program it names.
+func execProgram(call *ast.CallExpr) (string, bool) {
+	sel, ok := call.Fun.(*ast.SelectorExpr)
+	if !ok {
+		return "", false
+	}
+	if pkg, ok := sel.X.(*ast.Ident); !ok || pkg.Name != "exec" {
+		return "", false
+	}
+	arg := map[string]int{"Command": 0, "CommandContext": 1, "LookPath": 0}
+	i, ok := arg[sel.Sel.Name]
+	if !ok {
+		return "", false
+	}
+	if i < len(call.Args) {
+		if lit, ok := call.Args[i].(*ast.BasicLit); ok && lit.Kind == token.STRING {
+			return strings.Trim(lit.Value, "\"`"), true
+		}
+	}
+	return "a non-literal program", true
+}
+
+// shellQuote quotes s for the shell git runs filter and hook commands with.
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// === CLI-GIT-001: git-clean canonicalizes standard input and fails on invalid input ===
+
+func checkCLIGitClean(t *testing.T, h *harness) {
+	t.Helper()
+	res := runCLI(t, h, []string{"git-clean", "a.json"}, []byte(`{"b":false, "a":1e-7}`))
+	if res.exitCode != 0 || res.stdout != `{"a":1e-7,"b":false}` || res.stderr != "" {
+		t.Fatalf("unexpected result: %+v", res)
+	}
+	res = runCLI(t, h, []string{"git-clean", "a.json"}, []byte(`{"a":1,"a":2}`))
+	requireExitClass(t, res, jcserr.DuplicateKey)
+	if res.stdout != "" || !strings.HasPrefix(res.stderr, "a.json: ") {
+		t.Fatalf("failure does not name the file: %+v", res)
+	}
+
+	r := newGitRepo(t)
+	r.mustRun(t, "config", "filter.jcs.clean", shellQuote(h.bin)+" git-clean %f")
+	r.mustRun(t, "config", "filter.jcs.required", "true")
+	r.write(t, ".gitattributes", "*.json filter=jcs\n", 0o600)
+	r.write(t, "a.json", `{ "b": [1.0], "a": "x" }`, 0o600)
+	r.mustRun(t, "add", ".gitattributes", "a.json")
+	if got := r.mustRun(t, "cat-file", "blob", ":a.json"); got != `{"a":"x","b":[1]}` {
+		t.Fatalf("staged blob %q", got)
+	}
+	r.write(t, "bad.json", `{"a":}`, 0o600)
+	if out, err := r.run(t, "add", "bad.json"); err == nil || !strings.Contains(out, "bad.json: error: jcserr: INVALID_GRAMMAR") {
+		t.Fatalf("git add of invalid JSON: %v\n%s", err, out)
+	}
+}
+
+// === CLI-GIT-002: git-filter-process answers each file with its canonical bytes or an error status ===
+
+func checkCLIGitFilterProcess(t *testing.T, h *harness) {
+	t.Helper()
+	stream := filterHandshake + pktLines("command=clean\n", "pathname=a.json\n", "", `[3,2,"A"]`, "")
+	res := runCLI(t, h, []string{"git-filter-process"}, []byte(stream))
+	if res.exitCode != 0 || res.stdout != filterWelcome+pktLines("status=success\n", "", `[3,2,"A"]`, "", "") || res.stderr != "" {
+		t.Fatalf("unexpected result: %+v", res)
+	}
+	requireExitClass(t, runCLI(t, h, []string{"git-filter-process"}, []byte("{}")), jcserr.CLIUsage)
+	requireExitClass(t, runCLI(t, h, []string{"git-filter-process", "a.json"}, nil), jcserr.CLIUsage)
+
+	r := newGitRepo(t)
+	r.mustRun(t, "config", "filter.jcs.process", shellQuote(h.bin)+" git-filter-process")
+	r.mustRun(t, "config", "filter.jcs.required", "true")
+	r.write(t, ".gitattributes", "*.json filter=jcs\n", 0o600)
+	r.write(t, "a.json", `{"z":{}, "a":[ ]}`, 0o600)
+	r.write(t, "b.json", `1E400`, 0o600)
+	r.mustRun(t, "add", ".gitattributes", "a.json")
+	if got := r.mustRun(t, "cat-file", "blob", ":a.json"); got != `{"a":[],"z":{}}` {
+		t.Fatalf("staged blob %q", got)
+	}
+	if out, err := r.run(t, "add", "b.json"); err == nil || !strings.Contains(out, "b.json: error: jcserr: NUMBER_OVERFLOW") {
+		t.Fatalf("git add of invalid JSON: %v\n%s", err, out)
+	}
+}
+
+// === CLI-GIT-003: git-textconv writes canonical JSON indented for diffs ===
+
+func checkCLIGitTextconv(t *testing.T, h *harness) {
+	t.Helper()
+	res := runCLI(t, h, []string{"git-textconv", "-"}, []byte(`{"b":[true,null],"a":{}}`))
+	want := "{\n  \"a\": {},\n  \"b\": [\n    true,\n    null\n  ]\n}\n"
+	if res.exitCode != 0 || res.stdout != want || res.stderr != "" {
+		t.Fatalf("unexpected result: %+v", res)
+	}
+	res = runCLI(t, h, []string{"git-textconv", "-"}, []byte(`not json`))
+	if res.exitCode != 0 || res.stdout != "not json" || !strings.Contains(res.stderr, "INVALID_GRAMMAR") {
+		t.Fatalf("invalid input not passed through: %+v", res)
+	}
+	requireExitClass(t, runCLI(t, h, []string{"git-textconv"}, nil), jcserr.CLIUsage)
+
+	r := newGitRepo(t)
+	r.mustRun(t, "config", "diff.jcs.textconv", shellQuote(h.bin)+" git-textconv")
+	r.write(t, ".gitattributes", "*.json diff=jcs\n", 0o600)
+	r.write(t, "a.json", `{"a":1,"b":2}`, 0o600)
+	r.mustRun(t, "add", ".")
+	r.mustRun(t, "commit", "-q", "-m", "initial")
+	r.write(t, "a.json", `{"a":1,"b":3}`, 0o600)
+	out := r.mustRun(t, "diff", "--no-color", "-U0", "a.json")
+	if !strings.Contains(out, "\n-  \"b\": 2\n+  \"b\": 3\n") {
+		t.Fatalf("diff is not line oriented:\n%s", out)
+	}
+}
+
+// === CLI-GIT-004: git-pre-commit verifies only staged files, as staged ===
+
+func checkCLIGitPreCommit(t *testing.T, h *harness) {
+	t.Helper()
+	r := newGitRepo(t)
+	r.write(t, ".git/hooks/pre-commit", "#!/bin/sh\nexec "+shellQuote(h.bin)+" git-pre-commit --quiet\n", 0o700)
+	r.write(t, "a.json", `{"b":1,"a":2}`, 0o600)
+	r.write(t, "untracked.json", `{"b":1,"a":2}`, 0o600)
+	r.mustRun(t, "add", "a.json")
+	out, err := r.run(t, "commit", "-q", "-m", "not canonical")
+	if err == nil || !strings.Contains(out, "a.json: error: jcserr: NOT_CANONICAL") || strings.Contains(out, "untracked.json") {
+		t.Fatalf("commit of a non-canonical file: %v\n%s", err, out)
+	}
+	r.write(t, "a.json", `{"a":2,"b":1}`, 0o600)
+	r.mustRun(t, "add", "a.json")
+	// The working tree copy is not canonical; only the staged copy counts.
+	r.write(t, "a.json", `{ "a": 2, "b": 1 }`, 0o600)
+	r.mustRun(t, "commit", "-q", "-m", "canonical")
+
+	res := runCLI(t, h, []string{"git-pre-commit", "extra"}, nil)
+	requireExitClass(t, res, jcserr.CLIUsage)
+}
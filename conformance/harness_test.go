@@ -6,6 +6,7 @@ import (
 	"context"
 	"crypto/sha256"
 	"debug/elf"
+	"encoding/hex"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -24,6 +25,7 @@ import (
 	"strconv"
 	"strings"
 	"sync"
+	"syscall"
 	"testing"
 	"time"
 
@@ -50,7 +52,9 @@ type vectorCase struct {
 	Mode               string   `json:"mode,omitempty"`
 	Args               []string `json:"args,omitempty"`
 	Input              string   `json:"input"`
+	InputHex           string   `json:"input_hex,omitempty"`
 	WantStdout         *string  `json:"want_stdout,omitempty"`
+	WantStdoutHex      *string  `json:"want_stdout_hex,omitempty"`
 	WantStderr         *string  `json:"want_stderr,omitempty"`
 	WantStderrContains *string  `json:"want_stderr_contains,omitempty"`
 	WantExit           int      `json:"want_exit"`
@@ -136,13 +140,22 @@ func TestConformanceVectors(t *testing.T) {
 					args = []string{v.Mode, "-"}
 				}
 
-				res := runCLI(t, h, args, []byte(v.Input))
+				input := []byte(v.Input)
+				if v.InputHex != "" {
+					if input, err = hex.DecodeString(v.InputHex); err != nil {
+						t.Fatalf("%s:%d id=%s decode input_hex: %v", file, lineNo, v.ID, err)
+					}
+				}
+				res := runCLI(t, h, args, input)
 				if res.exitCode != v.WantExit {
 					t.Fatalf("%s:%d id=%s exit mismatch got=%d want=%d stdout=%q stderr=%q", file, lineNo, v.ID, res.exitCode, v.WantExit, res.stdout, res.stderr)
 				}
 				if v.WantStdout != nil && res.stdout != *v.WantStdout {
 					t.Fatalf("%s:%d id=%s stdout mismatch got=%q want=%q", file, lineNo, v.ID, res.stdout, *v.WantStdout)
 				}
+				if v.WantStdoutHex != nil && hex.EncodeToString([]byte(res.stdout)) != *v.WantStdoutHex {
+					t.Fatalf("%s:%d id=%s stdout mismatch got=%x want=%s", file, lineNo, v.ID, res.stdout, *v.WantStdoutHex)
+				}
 				if v.WantStderr != nil && res.stderr != *v.WantStderr {
 					t.Fatalf("%s:%d id=%s stderr mismatch got=%q want=%q", file, lineNo, v.ID, res.stderr, *v.WantStderr)
 				}
@@ -227,10 +240,25 @@ func requirementChecks() map[string]func(*testing.T, *harness) {
 		"ECMA-FMT-010": checkECMANegativeSign,
 		"ECMA-FMT-011": checkECMAMinimalK,
 		"ECMA-FMT-012": checkECMAScientificK1,
+		// ECMA-PARSE
+		"ECMA-PARSE-001": checkECMAParseRounding,
+		"ECMA-PARSE-002": checkECMAParseClassification,
+		// ECMA-OPS
+		"ECMA-OPS-001": checkECMAOpsFixed,
+		"ECMA-OPS-002": checkECMAOpsExponential,
+		"ECMA-OPS-003": checkECMAOpsPrecision,
+		"ECMA-OPS-004": checkECMAOpsRadix,
+		"ECMA-OPS-005": checkECMAOpsRange,
+		"ECMA-OPS-006": checkECMAOpsRadixV8,
+		// NUMTYPE
+		"NUMTYPE-F32-001": checkNumTypeFloat32,
+		"NUMTYPE-INT-001": checkNumTypeInteger,
 		// ECMA-VEC
 		"ECMA-VEC-001": checkBaseGoldenOracle,
 		"ECMA-VEC-002": checkStressGoldenOracle,
 		"ECMA-VEC-003": checkECMABoundaryConstants,
+		"ECMA-VEC-004": checkES6CorpusFastMatchesExact,
+		"ECMA-VEC-005": checkMethodsGoldenOracle,
 		// OFFICIAL-VEC
 		"OFFICIAL-VEC-001": checkOfficialCyberphoneVectors,
 		"OFFICIAL-VEC-002": checkOfficialRFC8785Vectors,
@@ -248,23 +276,35 @@ func requirementChecks() map[string]func(*testing.T, *harness) {
 		"BOUND-ELEMS-001":    checkArrayElementLimitEnforced,
 		"BOUND-STRBYTES-001": checkStringByteLimitEnforced,
 		"BOUND-NUMCHARS-001": checkNumberTokenLengthLimitEnforced,
+		"BOUND-OUTPUT-001":   checkOutputSizeLimitEnforced,
 		// CLI
-		"CLI-CMD-001":   checkCanonicalizeFunctional,
-		"CLI-CMD-002":   checkVerifyFunctional,
-		"CLI-EXIT-001":  checkNoCommandExitCode,
-		"CLI-EXIT-002":  checkUnknownCommandExitCode,
-		"CLI-EXIT-003":  checkInputViolationExitCode,
-		"CLI-EXIT-004":  checkInternalWriteFailureExitCode,
-		"CLI-FLAG-001":  checkUnknownOptionRejected,
-		"CLI-FLAG-002":  checkVerifyQuietSuppressesOk,
-		"CLI-FLAG-003":  checkHelpExitsZero,
-		"CLI-FLAG-004":  checkVersionExitsZero,
-		"CLI-IO-001":    checkStdinReading,
-		"CLI-IO-002":    checkMultipleInputRejected,
-		"CLI-IO-003":    checkFileAndStdinParity,
-		"CLI-IO-004":    checkCanonicalizeStdoutOnly,
-		"CLI-IO-005":    checkVerifyOkEmission,
-		"CLI-CLASS-001": checkErrorDiagnosticsIncludeFailureClass,
+		"CLI-CMD-001":    checkCanonicalizeFunctional,
+		"CLI-CMD-002":    checkVerifyFunctional,
+		"CLI-EXIT-001":   checkNoCommandExitCode,
+		"CLI-EXIT-002":   checkUnknownCommandExitCode,
+		"CLI-EXIT-003":   checkInputViolationExitCode,
+		"CLI-EXIT-004":   checkInternalWriteFailureExitCode,
+		"CLI-FLAG-001":   checkUnknownOptionRejected,
+		"CLI-FLAG-002":   checkVerifyQuietSuppressesOk,
+		"CLI-FLAG-003":   checkHelpExitsZero,
+		"CLI-FLAG-004":   checkVersionExitsZero,
+		"CLI-FLAG-005":   checkCommandScopedFlags,
+		"CLI-IO-001":     checkStdinReading,
+		"CLI-IO-002":     checkMultipleInputRejected,
+		"CLI-IO-003":     checkFileAndStdinParity,
+		"CLI-IO-004":     checkCanonicalizeStdoutOnly,
+		"CLI-IO-005":     checkVerifyOkEmission,
+		"CLI-IO-006":     checkMappedFileParity,
+		"CLI-CLASS-001":  checkErrorDiagnosticsIncludeFailureClass,
+		"CLI-ERRFMT-001": checkJSONErrorFormat,
+		"CLI-BATCH-001":  checkCLIBatchInputs,
+		"CLI-BATCH-002":  checkCLIBatchList,
+		"CLI-BATCH-003":  checkCLIBatchWrite,
+		"CLI-BATCH-004":  checkCLIBatchSummary,
+		"CLI-BOUNDS-001": checkCLIBoundOptions,
+		"CLI-BOUNDS-002": checkCLIBoundPresets,
+		"CLI-BOUNDS-003": checkCLIBoundsFile,
+		"CLI-PROJ-001":   checkCLICanonicalizeProjection,
 		// ABI/Supply/Governance/Traceability policy
 		"ABI-PARITY-001":       checkABIManifestBehaviorParity,
 		"SUPPLY-PIN-001":       checkGitHubActionsPinnedBySHA,
@@ -292,6 +332,121 @@ func requirementChecks() map[string]func(*testing.T, *harness) {
 		// API
 		"API-CANON-001": checkCanonicalizeEquivalence,
 		"API-CANON-002": checkCanonicalizeWithOptionsEquivalence,
+		"API-LEN-001":   checkCanonicalLengthMatchesSerialize,
+		// PTR
+		"PTR-SYNTAX-001": checkPointerRequiresLeadingSlash,
+		"PTR-SYNTAX-002": checkPointerEscapesDecodeInOrder,
+		"PTR-EVAL-001":   checkPointerEvaluation,
+		"PTR-EVAL-002":   checkPointerArrayIndexGrammar,
+		// REDACT
+		"REDACT-DIGEST-001": checkRedactDigestCanonicalOnly,
+		"REDACT-APPLY-001":  checkRedactDeterministic,
+		"REDACT-APPLY-002":  checkRedactRejectsInvalidTargets,
+		"REDACT-VERIFY-001": checkRedactDisclosuresReferencedOnce,
+		"REDACT-VERIFY-002": checkRedactVerifyDigest,
+		// PROJ
+		"PROJ-APPLY-001":   checkProjectionPrecedesEmission,
+		"PROJ-EXCLUDE-001": checkProjectionExclude,
+		"PROJ-SELECT-001":  checkProjectionInclude,
+		// DI
+		"DI-KEY-001":    checkDIMultikeyDecoding,
+		"DI-SUITE-001":  checkDISuiteKeyBinding,
+		"DI-CREATE-001": checkDICreateHashData,
+		"DI-CREATE-002": checkDICreateValidatesOptions,
+		"DI-VERIFY-001": checkDIVerifySignature,
+		"DI-VERIFY-002": checkDIVerifyContextPrefix,
+		"DI-VEC-001":    checkDIOfficialVectors,
+		// CBOR
+		"CBOR-ENC-001":    checkCBORShortestArguments,
+		"CBOR-ENC-002":    checkCBORMapKeyOrder,
+		"CBOR-FLOAT-001":  checkCBORPreferredFloat,
+		"CBOR-NUM-001":    checkCBORIntegerReduction,
+		"CBOR-DEC-001":    checkCBORMalformedRejected,
+		"CBOR-DEC-002":    checkCBORNonJSONClassified,
+		"CBOR-BOUND-001":  checkCBORBoundsEnforced,
+		"CBOR-RT-001":     checkCBORRoundTrip,
+		"CLI-CONVERT-001": checkCLIConvert,
+		// LENIENT
+		"LENIENT-SYNTAX-001":  checkLenientCommentsAndTrailingCommas,
+		"LENIENT-JSON5-001":   checkLenientJSON5Literals,
+		"LENIENT-PROFILE-001": checkLenientProfileEnforced,
+		"LENIENT-OFFSET-001":  checkLenientOffsets,
+		"CLI-SYNTAX-001":      checkCLIInputSyntax,
+		// SCHEME
+		"SCHEME-API-001":    checkSchemeInterface,
+		"SCHEME-API-002":    checkSchemeBounds,
+		"SCHEME-OLPC-001":   checkSchemeOLPCEncoding,
+		"SCHEME-OLPC-002":   checkSchemeOLPCIntegers,
+		"SCHEME-MATRIX-001": checkSchemeMatrixEncoding,
+		"SCHEME-MATRIX-002": checkSchemeMatrixIntegers,
+		"CLI-SCHEME-001":    checkCLIScheme,
+		// SET
+		"JPATH-SYNTAX-001": checkJSONPathSyntax,
+		"JPATH-EVAL-001":   checkJSONPathSelection,
+		"SET-SORT-001":     checkSetArraysSorted,
+		"SET-DUP-001":      checkSetArraysDuplicates,
+		"SET-TARGET-001":   checkSetArraysTargets,
+		"SET-LABEL-001":    checkSetArraysLabeled,
+		"CLI-SET-001":      checkCLISetArrays,
+		// DECIMAL
+		"DEC-PARSE-001":   checkDecimalParse,
+		"DEC-CANON-001":   checkDecimalCanonicalForm,
+		"DEC-RANGE-001":   checkDecimalRange,
+		"DEC-SCHEME-001":  checkDecimalScheme,
+		"DEC-LABEL-001":   checkDecimalLabeled,
+		"CLI-DECIMAL-001": checkCLIDecimal,
+		// EMBED
+		"EMBED-CANON-001":  checkEmbeddedCanonical,
+		"EMBED-SELECT-001": checkEmbeddedSelect,
+		"EMBED-DETECT-001": checkEmbeddedDetect,
+		"EMBED-ERROR-001":  checkEmbeddedError,
+		"EMBED-LABEL-001":  checkEmbeddedLabeled,
+		"CLI-EMBED-001":    checkCLIEmbedded,
+		// ENC
+		"ENC-DETECT-001":    checkEncodingDetect,
+		"ENC-TRANSCODE-001": checkEncodingTranscode,
+		"ENC-SURROGATE-001": checkEncodingSurrogate,
+		"ENC-OFFSET-001":    checkEncodingOffset,
+		"CLI-ENCODING-001":  checkCLIEncoding,
+		// DIFF
+		"DIFF-API-001":   checkDiffApplies,
+		"DIFF-ORDER-001": checkDiffOrder,
+		"CLI-DIFF-001":   checkCLIDiff,
+		"CLI-DIFF-002":   checkCLIDiffSummary,
+
+		// PATCH
+		"PATCH-PARSE-001":  checkPatchParse,
+		"PATCH-APPLY-001":  checkPatchApply,
+		"PATCH-TEST-001":   checkPatchTest,
+		"PATCH-DOMAIN-001": checkPatchDomain,
+		"MERGE-APPLY-001":  checkMergeApply,
+		"CLI-PATCH-001":    checkCLIPatch,
+		"CLI-MERGE-001":    checkCLIMergePatch,
+		// SERVE
+		"SERVE-API-001":      checkServeAPI,
+		"SERVE-BOUND-001":    checkServeBound,
+		"SERVE-ERR-001":      checkServeError,
+		"SERVE-NET-001":      checkServeNetwork,
+		"SERVE-SHUTDOWN-001": checkServeShutdown,
+		"CLI-SERVE-001":      checkCLIServe,
+		// GIT
+		"GIT-FILTER-001": checkGitFilter,
+		"GIT-FILTER-002": checkGitFilterBound,
+		"GIT-STAGED-001": checkGitStaged,
+		"GIT-EXEC-001":   checkGitExec,
+		"CLI-GIT-001":    checkCLIGitClean,
+		"CLI-GIT-002":    checkCLIGitFilterProcess,
+		"CLI-GIT-003":    checkCLIGitTextconv,
+		"CLI-GIT-004":    checkCLIGitPreCommit,
+		// FMT
+		"FMT-VIEW-001":      checkFormatView,
+		"FMT-ROUNDTRIP-001": checkFormatRoundTrip,
+		"CLI-FMT-001":       checkCLIFmt,
+
+		// MANIFEST
+		"MANIFEST-DIGEST-001": checkManifestDigest,
+		"CLI-MANIFEST-001":    checkCLIManifestCreate,
+		"CLI-MANIFEST-002":    checkCLIManifestVerify,
 	}
 }
 
@@ -1193,6 +1348,40 @@ func checkNumberTokenLengthLimitEnforced(t *testing.T, _ *harness) {
 	}
 }
 
+func checkOutputSizeLimitEnforced(t *testing.T, _ *harness) {
+	t.Helper()
+	// 1e20 expands to 21 digits, so the 6-byte input has a 23-byte canonical form.
+	input := []byte(`[1e20]`)
+	if _, err := jcs.CanonicalizeWithOptions(input, &jcstoken.Options{MaxOutputSize: 23}); err != nil {
+		t.Fatalf("expected exact output size limit to pass, got %v", err)
+	}
+
+	v, err := jcstoken.Parse(input)
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	over := &jcstoken.Options{MaxOutputSize: 22}
+	for name, serialize := range map[string]func() ([]byte, error){
+		"CanonicalizeWithOptions": func() ([]byte, error) { return jcs.CanonicalizeWithOptions(input, over) },
+		"SerializeWithOptions":    func() ([]byte, error) { return jcs.SerializeWithOptions(v, over) },
+		"Decimal":                 func() ([]byte, error) { return jcs.SerializeSchemeWithOptions(jcs.Decimal, v, over) },
+		"FormatWithOptions":       func() ([]byte, error) { return jcs.FormatWithOptions(v, "", over) },
+		"Patch": func() ([]byte, error) {
+			patch, err := jcs.Diff(&jcstoken.Value{Kind: jcstoken.KindNull}, v)
+			if err != nil {
+				return nil, err
+			}
+			return patch.SerializeWithOptions(over)
+		},
+	} {
+		out, err := serialize()
+		var je *jcserr.Error
+		if out != nil || !errors.As(err, &je) || je.Class != jcserr.BoundExceeded || !strings.Contains(je.Message, "output size exceeds maximum 22 bytes") {
+			t.Fatalf("%s: expected BOUND_EXCEEDED output size error, got %q %v", name, out, err)
+		}
+	}
+}
+
 // ==================== CLI ====================
 
 func checkCanonicalizeFunctional(t *testing.T, h *harness) {
@@ -1376,6 +1565,39 @@ func checkFileAndStdinParity(t *testing.T, h *harness) {
 	}
 }
 
+// checkMappedFileParity runs inputs from a regular file, which the CLI maps
+// into memory, from a FIFO, which it reads, and from stdin, and requires the
+// same result from each, including for an input over --max-input-size.
+func checkMappedFileParity(t *testing.T, h *harness) {
+	t.Helper()
+	dir := t.TempDir()
+	fifo := filepath.Join(dir, "fifo")
+	if err := syscall.Mkfifo(fifo, 0o600); err != nil {
+		t.Fatalf("mkfifo: %v", err)
+	}
+	for i, input := range []string{`{"b":2,"a":1}`, `{"a":1,}`, `[1,2,3,4,5]`, ``} {
+		args := []string{"canonicalize", "--max-input-size", "10"}
+		p := filepath.Join(dir, fmt.Sprintf("in%d.json", i))
+		if err := os.WriteFile(p, []byte(input), 0o600); err != nil {
+			t.Fatalf("write temp file: %v", err)
+		}
+		fromStdin := runCLI(t, h, append(args, "-"), []byte(input))
+		if fromFile := runCLI(t, h, append(args, p), nil); fromFile != fromStdin {
+			t.Fatalf("mapped file %q: %+v, stdin: %+v", input, fromFile, fromStdin)
+		}
+		go func() {
+			if err := os.WriteFile(fifo, []byte(input), 0o600); err != nil {
+				t.Errorf("write fifo: %v", err)
+			}
+		}()
+		if fromFIFO := runCLI(t, h, append(args, fifo), nil); fromFIFO != fromStdin {
+			t.Fatalf("fifo %q: %+v, stdin: %+v", input, fromFIFO, fromStdin)
+		}
+	}
+	res := runCLI(t, h, []string{"verify", "--max-input-size", "10", filepath.Join(dir, "in2.json")}, nil)
+	requireExitClass(t, res, jcserr.BoundExceeded)
+}
+
 func checkCanonicalizeStdoutOnly(t *testing.T, h *harness) {
 	t.Helper()
 	res := runCLI(t, h, []string{"canonicalize", "-"}, []byte(canonicalObjectA1))
@@ -1410,6 +1632,45 @@ func checkErrorDiagnosticsIncludeFailureClass(t *testing.T, h *harness) {
 	}
 }
 
+// === CLI-ERRFMT-001: --error-format json writes one canonical error object ===
+
+func checkJSONErrorFormat(t *testing.T, h *harness) {
+	t.Helper()
+	res := runCLI(t, h, []string{"canonicalize", "--error-format=json", "-"}, []byte("{\"a\":\n 01}"))
+	want := `{"cause":null,"class":"INVALID_GRAMMAR","column":3,"exit_code":2,"line":2,"message":"leading zero in number","offset":8}` + "\n"
+	if res.exitCode != 2 || res.stdout != "" || res.stderr != want {
+		t.Fatalf("unexpected JSON error: %+v", res)
+	}
+	report := strings.TrimSuffix(res.stderr, "\n")
+	canonical, err := jcs.Canonicalize([]byte(report))
+	if err != nil || string(canonical) != report {
+		t.Fatalf("error object is not canonical: %q (%v)", report, err)
+	}
+
+	// Flag errors before --error-format is parsed are still JSON.
+	res = runCLI(t, h, []string{"verify", "--nope", "--error-format", "json"}, nil)
+	want = `{"cause":null,"class":"CLI_USAGE","exit_code":2,"message":"unknown option: --nope","offset":null}` + "\n"
+	if res.exitCode != 2 || res.stderr != want {
+		t.Fatalf("unexpected JSON usage error: %+v", res)
+	}
+
+	res = runCLI(t, h, []string{"canonicalize", "--error-format=json", "--embedded-json", "/a", "-"}, []byte(`{"a":"[1,]"}`))
+	if res.exitCode != 2 || !strings.Contains(res.stderr, `"pointer":"/a"`) ||
+		!strings.Contains(res.stderr, `"embedded_offset":3`) || !strings.Contains(res.stderr, `"class":"INVALID_GRAMMAR"`) {
+		t.Fatalf("expected embedded JSON pointer and offset, got %+v", res)
+	}
+
+	// The text format is the default and is unchanged.
+	res = runCLI(t, h, []string{"verify", "--error-format=text", "-"}, []byte(`{"b":1,"a":2}`))
+	if res.exitCode != 2 || res.stderr != "error: jcserr: NOT_CANONICAL: input is not canonical\n" {
+		t.Fatalf("unexpected text error: %+v", res)
+	}
+	res = runCLI(t, h, []string{"convert", "--error-format=xml", "--to", "cbor"}, nil)
+	if res.exitCode != 2 || !strings.HasPrefix(res.stderr, "error: jcserr: CLI_USAGE") {
+		t.Fatalf("expected CLI_USAGE for unknown format, got %+v", res)
+	}
+}
+
 // ==================== VERIFY ====================
 
 func checkVerifyRejectsNonCanonicalOrder(t *testing.T, h *harness) {
@@ -1541,7 +1802,7 @@ func checkNoNondeterminismSources(t *testing.T, h *harness) {
 		"net/netip":   {},
 		"os/exec":     {},
 	}
-	srcDirs := []string{"jcserr", "jcsfloat", "jcstoken", "jcs", "cmd/jcs-canon"}
+	srcDirs := []string{"jcserr", "jcsfloat", "jcstoken", "jcs", "jcsredact", "jcsdi", "jcscbor", "cmd/jcs-canon"}
 	for _, dir := range srcDirs {
 		entries, err := os.ReadDir(filepath.Join(h.root, dir))
 		if err != nil {
@@ -1803,9 +2064,26 @@ func checkBehaviorTestsLinkedToRequirements(t *testing.T, h *harness) {
 		"cmd/jcs-canon/main_test.go",
 		"cmd/jcs-canon/blackbox_cli_test.go",
 		"jcs/serialize_test.go",
+		"jcs/project_test.go",
+		"jcs/scheme_test.go",
+		"jcs/sets_test.go",
+		"jcs/decimal_test.go",
+		"jcs/embedded_test.go",
 		"jcserr/errors_test.go",
 		"jcsfloat/jcsfloat_test.go",
+		"jcsfloat/decimal_test.go",
+		"jcsfloat/parse_test.go",
+		"jcsfloat/shortest_test.go",
+		"jcsfloat/methods_test.go",
+		"jcsfloat/integer_test.go",
 		"jcstoken/token_test.go",
+		"jcstoken/pointer_test.go",
+		"jcstoken/lenient_test.go",
+		"jcstoken/encoding_test.go",
+		"jcstoken/jsonpath_test.go",
+		"jcsredact/redact_test.go",
+		"jcsdi/proof_test.go",
+		"jcscbor/cbor_test.go",
 	}
 
 	for _, rel := range behaviorTestFiles {
@@ -1951,19 +2229,33 @@ func TestABIManifestValid(t *testing.T) {
 	}
 
 	expectedClasses := map[string]int{
-		"INVALID_UTF8":     2,
-		"INVALID_GRAMMAR":  2,
-		"DUPLICATE_KEY":    2,
-		"LONE_SURROGATE":   2,
-		"NONCHARACTER":     2,
-		"NUMBER_OVERFLOW":  2,
-		"NUMBER_NEGZERO":   2,
-		"NUMBER_UNDERFLOW": 2,
-		"BOUND_EXCEEDED":   2,
-		"NOT_CANONICAL":    2,
-		"CLI_USAGE":        2,
-		"INTERNAL_IO":      10,
-		"INTERNAL_ERROR":   10,
+		"INVALID_UTF8":        2,
+		"INVALID_GRAMMAR":     2,
+		"DUPLICATE_KEY":       2,
+		"LONE_SURROGATE":      2,
+		"NONCHARACTER":        2,
+		"NUMBER_OVERFLOW":     2,
+		"NUMBER_NEGZERO":      2,
+		"NUMBER_UNDERFLOW":    2,
+		"NUMBER_INEXACT":      2,
+		"BOUND_EXCEEDED":      2,
+		"NOT_CANONICAL":       2,
+		"INVALID_POINTER":     2,
+		"DIGEST_MISMATCH":     2,
+		"INVALID_KEY":         2,
+		"INVALID_PROOF":       2,
+		"SIGNATURE_INVALID":   2,
+		"INVALID_CBOR":        2,
+		"UNSUPPORTED_CBOR":    2,
+		"SCHEME_DOMAIN":       2,
+		"DUPLICATE_ELEMENT":   2,
+		"UNSUPPORTED_DECIMAL": 2,
+		"INVALID_PATCH":       2,
+		"PATCH_TEST_FAILED":   2,
+		"INVALID_SALT":        2,
+		"CLI_USAGE":           2,
+		"INTERNAL_IO":         10,
+		"INTERNAL_ERROR":      10,
 	}
 
 	for _, c := range classes {
@@ -2002,10 +2294,17 @@ func checkABIManifestBehaviorParity(t *testing.T, h *harness) {
 	wantGlobalFlags := decodeManifestFlagSet(t, manifest.GlobalFlags)
 	assertSetEqual(t, "ABI global flags", srcGlobalFlags, wantGlobalFlags)
 
+	srcPerCommand := loadCommandFlagTable(t, filepath.Join(h.root, "cmd", "jcs-canon", "main.go"))
+	assertSetEqual(t, "ABI command flag table commands", mapKeys(srcPerCommand), mapKeys(manifest.Commands))
+	union := make(map[string]struct{})
 	for cmdName, cmd := range manifest.Commands {
 		wantCmdFlags := decodeManifestFlagSet(t, cmd.Flags)
-		assertSetEqual(t, "ABI command flags "+cmdName, srcCommandFlags, wantCmdFlags)
+		assertSetEqual(t, "ABI command flags "+cmdName, srcPerCommand[cmdName], wantCmdFlags)
+		for f := range srcPerCommand[cmdName] {
+			union[f] = struct{}{}
+		}
 	}
+	assertSetEqual(t, "ABI parsed command flags", srcCommandFlags, union)
 	for cmdName := range manifest.Commands {
 		res := runCLI(t, h, []string{cmdName, "--help"}, nil)
 		if res.exitCode != 0 {
@@ -2907,6 +3206,56 @@ func loadCLISurfaceFromSource(t *testing.T, path string) (map[string]struct{}, m
 	return commands, globalFlags, commandFlags
 }
 
+// loadCommandFlagTable extracts the per-command option lists from the
+// commandFlags composite literal in the CLI source.
+//
+//nolint:gocognit // REQ:ABI-PARITY-001 CLI AST extraction keeps explicit literal traversal for stable ABI parity checks.
+func loadCommandFlagTable(t *testing.T, path string) map[string]map[string]struct{} {
+	t.Helper()
+
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, path, nil, 0)
+	if err != nil {
+		t.Fatalf("parse CLI source %s: %v", path, err)
+	}
+
+	table := make(map[string]map[string]struct{})
+	ast.Inspect(f, func(n ast.Node) bool {
+		vs, ok := n.(*ast.ValueSpec)
+		if !ok || len(vs.Names) != 1 || vs.Names[0].Name != "commandFlags" || len(vs.Values) != 1 {
+			return true
+		}
+		lit, ok := vs.Values[0].(*ast.CompositeLit)
+		if !ok {
+			t.Fatalf("commandFlags in %s is not a composite literal", path)
+		}
+		for _, elt := range lit.Elts {
+			kv, ok := elt.(*ast.KeyValueExpr)
+			if !ok {
+				continue
+			}
+			cmdName, ok := stringLiteralValue(kv.Key)
+			if !ok {
+				continue
+			}
+			set := make(map[string]struct{})
+			if list, ok := kv.Value.(*ast.CompositeLit); ok {
+				for _, e := range list.Elts {
+					if flag, ok := stringLiteralValue(e); ok {
+						set[flag] = struct{}{}
+					}
+				}
+			}
+			table[cmdName] = set
+		}
+		return false
+	})
+	if len(table) == 0 {
+		t.Fatalf("commandFlags table not found in %s", path)
+	}
+	return table
+}
+
 func isIndexZeroExpr(expr ast.Expr, identName string) bool {
 	idx, ok := expr.(*ast.IndexExpr)
 	if !ok {
@@ -2996,6 +3345,10 @@ func verifyFloatOracle(t *testing.T, path string, expectedRows int, expectedSHA2
 		if got != parts[1] {
 			t.Fatalf("line %d bits=%016x got=%q want=%q", rows, bits, got, parts[1])
 		}
+		exact, exactErr := jcsfloat.FormatDoubleExact(math.Float64frombits(bits))
+		if exactErr != nil || exact != parts[1] {
+			t.Fatalf("line %d bits=%016x FormatDoubleExact=%q (%v) want=%q", rows, bits, exact, exactErr, parts[1])
+		}
 	}
 	if err := sc.Err(); err != nil {
 		t.Fatalf("scan oracle: %v", err)
@@ -3079,3 +3432,25 @@ func checkCanonicalizeWithOptionsEquivalence(t *testing.T, _ *harness) {
 		t.Fatalf("got %q, want %q", got, want)
 	}
 }
+
+// === API-LEN-001: CanonicalLength equals the length of the serialized bytes ===
+
+func checkCanonicalLengthMatchesSerialize(t *testing.T, h *harness) {
+	t.Helper()
+	for _, vec := range loadVectorFile(t, h, "core.jsonl") {
+		v, err := jcstoken.Parse(vectorInput(t, &vec))
+		if err != nil {
+			continue
+		}
+		want, err := jcs.Serialize(v)
+		if err != nil {
+			t.Fatalf("%s: Serialize: %v", vec.ID, err)
+		}
+		if n, err := jcs.CanonicalLength(v); err != nil || n != len(want) {
+			t.Fatalf("%s: CanonicalLength = %d, %v; want %d", vec.ID, n, err, len(want))
+		}
+		if _, err := jcs.CanonicalLengthWithOptions(v, &jcstoken.Options{MaxOutputSize: len(want)}); err != nil {
+			t.Fatalf("%s: CanonicalLengthWithOptions at the bound: %v", vec.ID, err)
+		}
+	}
+}
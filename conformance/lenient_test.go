@@ -0,0 +1,125 @@
+package conformance_test
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/lattice-substrate/json-canon/jcserr"
+	"github.com/lattice-substrate/json-canon/jcstoken"
+)
+
+func requireLenientValue(t *testing.T, in, strict string, syntax jcstoken.Syntax) {
+	t.Helper()
+	got, err := jcstoken.ParseLenient([]byte(in), syntax, nil)
+	if err != nil {
+		t.Fatalf("ParseLenient(%q): %v", in, err)
+	}
+	want, err := jcstoken.Parse([]byte(strict))
+	if err != nil {
+		t.Fatalf("Parse(%q): %v", strict, err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("ParseLenient(%q) differs from Parse(%q)", in, strict)
+	}
+}
+
+func lenientClass(t *testing.T, in string, syntax jcstoken.Syntax, opts *jcstoken.Options) *jcserr.Error {
+	t.Helper()
+	_, err := jcstoken.ParseLenient([]byte(in), syntax, opts)
+	var je *jcserr.Error
+	if !errors.As(err, &je) {
+		t.Fatalf("ParseLenient(%q): expected *jcserr.Error, got %v", in, err)
+	}
+	return je
+}
+
+// === LENIENT-SYNTAX-001: Comments and trailing commas only under opt-in syntax ===
+
+func checkLenientCommentsAndTrailingCommas(t *testing.T, _ *harness) {
+	t.Helper()
+	in := "/* header */ {\"a\": [1, 2,], // note\n \"b\": \"/* kept */\",}"
+	requireLenientValue(t, in, `{"a":[1,2],"b":"/* kept */"}`, jcstoken.SyntaxJSONC)
+	if _, err := jcstoken.Parse([]byte(in)); err == nil {
+		t.Fatal("strict Parse accepted comments and trailing commas")
+	}
+	for _, bad := range []string{`[1,,]`, `{,}`, `{'a':1}`} {
+		requireClass(t, lenientClass(t, bad, jcstoken.SyntaxJSONC, nil), jcserr.InvalidGrammar)
+	}
+}
+
+// === LENIENT-JSON5-001: JSON5 literal forms map to their JSON equivalents ===
+
+func checkLenientJSON5Literals(t *testing.T, _ *harness) {
+	t.Helper()
+	requireLenientValue(t,
+		`{key: 'it\'s', hex: 0xFF, pos: +1, lead: .5, trail: 2., esc: '\x41\
+B',}`,
+		`{"key":"it's","hex":255,"pos":1,"lead":0.5,"trail":2,"esc":"AB"}`,
+		jcstoken.SyntaxJSON5)
+	requireClass(t, lenientClass(t, `{key: 1}`, jcstoken.SyntaxJSONC, nil), jcserr.InvalidGrammar)
+}
+
+// === LENIENT-PROFILE-001: Strict profile checks apply to lenient input ===
+
+func checkLenientProfileEnforced(t *testing.T, _ *harness) {
+	t.Helper()
+	cases := []struct {
+		in   string
+		want jcserr.FailureClass
+	}{
+		{`{a: 1, 'a': 2}`, jcserr.DuplicateKey},
+		{`['\uDC00']`, jcserr.LoneSurrogate},
+		{`[-0x0]`, jcserr.NumberNegZero},
+		{`[1e-400]`, jcserr.NumberUnderflow},
+		{`[+Infinity]`, jcserr.NumberOverflow},
+		{`[NaN]`, jcserr.InvalidGrammar},
+	}
+	for _, c := range cases {
+		requireClass(t, lenientClass(t, c.in, jcstoken.SyntaxJSON5, nil), c.want)
+	}
+	je := lenientClass(t, `[[1,],]`, jcstoken.SyntaxJSONC, &jcstoken.Options{MaxDepth: 1})
+	requireClass(t, je, jcserr.BoundExceeded)
+}
+
+// === LENIENT-OFFSET-001: Error offsets refer to the original input ===
+
+func checkLenientOffsets(t *testing.T, _ *harness) {
+	t.Helper()
+	je := lenientClass(t, "// c\n{a: 1, /* c */ 'a': 2}", jcstoken.SyntaxJSON5, nil)
+	if je.Class != jcserr.DuplicateKey || je.Offset != 20 {
+		t.Fatalf("expected DUPLICATE_KEY at 20, got %v", je)
+	}
+	je = lenientClass(t, "[1, /* c */ 01]", jcstoken.SyntaxJSONC, nil)
+	if je.Offset != 13 {
+		t.Fatalf("expected offset 13, got %v", je)
+	}
+}
+
+// === CLI-SYNTAX-001: canonicalize --input-syntax selects the front end ===
+
+func checkCLIInputSyntax(t *testing.T, h *harness) {
+	t.Helper()
+	in := []byte("{\n  \"b\": 2, // two\n  \"a\": 1,\n}\n")
+	res := runCLI(t, h, []string{"canonicalize", "--input-syntax=jsonc", "-"}, in)
+	if res.exitCode != 0 || res.stdout != `{"a":1,"b":2}` || res.stderr != "" {
+		t.Fatalf("unexpected jsonc result: %+v", res)
+	}
+	res = runCLI(t, h, []string{"canonicalize", "-"}, in)
+	if res.exitCode != 2 || !strings.Contains(res.stderr, string(jcserr.InvalidGrammar)) {
+		t.Fatalf("default syntax accepted JSONC: %+v", res)
+	}
+	res = runCLI(t, h, []string{"canonicalize", "--input-syntax", "json5", "-"}, []byte(`{b: 2, a: 0x1,}`))
+	if res.exitCode != 0 || res.stdout != `{"a":1,"b":2}` {
+		t.Fatalf("unexpected json5 result: %+v", res)
+	}
+	res = runCLI(t, h, []string{"canonicalize", "--input-syntax", "toml", "-"}, in)
+	if res.exitCode != 2 || !strings.Contains(res.stderr, string(jcserr.CLIUsage)) {
+		t.Fatalf("unsupported syntax not rejected: %+v", res)
+	}
+	res = runCLI(t, h, []string{"verify", "--input-syntax", "jsonc", "-"}, in)
+	if res.exitCode != 2 || !strings.Contains(res.stderr, "unknown option") {
+		t.Fatalf("verify accepted --input-syntax: %+v", res)
+	}
+}
@@ -0,0 +1,127 @@
+package conformance_test
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/lattice-substrate/json-canon/jcserr"
+)
+
+// sha256Hex returns the lowercase hex SHA-256 digest of s.
+func sha256Hex(s string) string {
+	sum := sha256.Sum256([]byte(s))
+	return hex.EncodeToString(sum[:])
+}
+
+// === MANIFEST-DIGEST-001: Digests cover the canonical form ===
+
+func checkManifestDigest(t *testing.T, h *harness) {
+	t.Helper()
+	dir, p := batchTree(t, map[string]string{"a.json": `{"b":"x","a":[1.0]}`})
+	res := runCLI(t, h, []string{"manifest", "create", dir}, nil)
+	want := `{"algorithm":"sha-256","files":{"a.json":"` + sha256Hex(`{"a":[1],"b":"x"}`) + `"}}`
+	if res.exitCode != 0 || res.stdout != want {
+		t.Fatalf("unexpected manifest: %+v", res)
+	}
+	manifest := []byte(res.stdout)
+	for _, same := range []string{`{"a":[1],"b":"x"}`, "{\n  \"a\": [ 10E-1 ],\n  \"\\u0062\": \"\\u0078\"\n}\n"} {
+		writeFixture(t, p("a.json"), same)
+		if res := runCLI(t, h, []string{"manifest", "verify", "-q", "-", dir}, manifest); res.exitCode != 0 || res.stdout != "" {
+			t.Fatalf("reformatted %q changed the digest: %+v", same, res)
+		}
+	}
+	for _, changed := range []string{`{"a":[1],"b":"y"}`, `{"a":[1,1],"b":"x"}`, `{"a":[1],"b":"x","c":null}`} {
+		writeFixture(t, p("a.json"), changed)
+		res := runCLI(t, h, []string{"manifest", "verify", "-q", "-", dir}, manifest)
+		if res.stdout != "changed a.json\n" {
+			t.Fatalf("semantic change %q kept the digest: %+v", changed, res)
+		}
+		requireExitClass(t, res, jcserr.DigestMismatch)
+	}
+}
+
+// === CLI-MANIFEST-001: manifest create writes a canonical digest manifest ===
+
+func checkCLIManifestCreate(t *testing.T, h *harness) {
+	t.Helper()
+	dir, p := batchTree(t, map[string]string{
+		"z.json":         `[ 2 ]`,
+		"sub/a.json":     `{"k":"\u00e9"}`,
+		"sub/b.txt":      `1`,
+		".hidden/c.json": `1`,
+		"skip/d.json":    `1`,
+	})
+	res := runCLI(t, h, []string{"manifest", "create", "--exclude-glob", "skip", dir}, nil)
+	want := `{"algorithm":"sha-256","files":{"sub/a.json":"` + sha256Hex(`{"k":"é"}`) + `","z.json":"` + sha256Hex(`[2]`) + `"}}`
+	if res.exitCode != 0 || res.stdout != want || res.stderr != "" {
+		t.Fatalf("unexpected result: %+v", res)
+	}
+	for _, jobs := range []string{"1", "8"} {
+		if again := runCLI(t, h, []string{"manifest", "create", "--jobs", jobs, "--exclude-glob", "skip", dir}, nil); again != res {
+			t.Fatalf("--jobs %s changed the manifest: %+v", jobs, again)
+		}
+	}
+	if canon := runCLI(t, h, []string{"verify", "-q"}, []byte(res.stdout)); canon.exitCode != 0 {
+		t.Fatalf("manifest is not canonical: %+v", canon)
+	}
+
+	writeFixture(t, p("sub/bad.json"), `{"a":1,"a":2}`)
+	res = runCLI(t, h, []string{"manifest", "create", dir}, nil)
+	if res.stdout != "" || !strings.HasPrefix(res.stderr, p("sub/bad.json")+": error: jcserr: DUPLICATE_KEY") {
+		t.Fatalf("manifest written despite a failing file: %+v", res)
+	}
+	requireExitClass(t, res, jcserr.DuplicateKey)
+	requireExitClass(t, runCLI(t, h, []string{"manifest", "create", p("z.json")}, nil), jcserr.CLIUsage)
+	requireExitClass(t, runCLI(t, h, []string{"manifest", "hash", dir}, nil), jcserr.CLIUsage)
+}
+
+// === CLI-MANIFEST-002: manifest verify reports added, missing, and changed files ===
+
+func checkCLIManifestVerify(t *testing.T, h *harness) {
+	t.Helper()
+	dir, p := batchTree(t, map[string]string{"a.json": `1`, "b.json": `2`, "c/d.json": `3`})
+	created := runCLI(t, h, []string{"manifest", "create", dir}, nil)
+	manifest := filepath.Join(t.TempDir(), "manifest.json")
+	writeFixture(t, manifest, created.stdout)
+	res := runCLI(t, h, []string{"manifest", "verify", manifest, dir}, nil)
+	if res.exitCode != 0 || res.stdout != "" || res.stderr != "jcs-canon: files=3 matched=3 added=0 missing=0 changed=0 failed=0\n" {
+		t.Fatalf("unexpected result for an unchanged directory: %+v", res)
+	}
+	// The bound options apply to the files, not to the manifest of them.
+	bounded := runCLI(t, h, []string{"manifest", "create", "--max-object-members", "2", "--max-output-size", "16", dir}, nil)
+	if bounded.exitCode != 0 || bounded.stdout != created.stdout {
+		t.Fatalf("bounds applied to the written manifest: %+v", bounded)
+	}
+	res = runCLI(t, h, []string{"manifest", "verify", "-q", "--max-object-members", "2", "--max-input-size", "16", manifest, dir}, nil)
+	if res.exitCode != 0 {
+		t.Fatalf("bounds applied to the read manifest: %+v", res)
+	}
+
+	writeFixture(t, p("b.json"), `20`)
+	writeFixture(t, p("c/e.json"), `4`)
+	writeFixture(t, p("f.json"), `[`)
+	if err := os.Remove(p("a.json")); err != nil {
+		t.Fatalf("remove fixture: %v", err)
+	}
+	res = runCLI(t, h, []string{"manifest", "verify", manifest, dir}, nil)
+	if res.stdout != "missing a.json\nchanged b.json\nadded c/e.json\n" ||
+		res.stderr != p("f.json")+": error: jcserr: INVALID_GRAMMAR at byte 1: unexpected end of input in array\n"+
+			"error: jcserr: DIGEST_MISMATCH: directory does not match the manifest\n"+
+			"jcs-canon: files=4 matched=1 added=1 missing=1 changed=1 failed=1\n" {
+		t.Fatalf("unexpected result for a changed directory: %+v", res)
+	}
+	requireExitClass(t, res, jcserr.DigestMismatch)
+	res = runCLI(t, h, []string{"manifest", "verify", "--error-format", "json", "--exclude-glob", "f.json", "-", dir}, []byte(created.stdout))
+	if res.exitCode != 2 || !strings.HasSuffix(res.stderr, `{"summary":{"added":1,"changed":1,"failed":0,"files":3,"matched":1,"missing":1}}`+"\n") {
+		t.Fatalf("unexpected JSON summary: %+v", res)
+	}
+
+	for _, bad := range []string{`{}`, `{"algorithm":"sha-512","files":{}}`, `{"algorithm":"sha-256","files":{"../a.json":"` + sha256Hex("1") + `"}}`} {
+		requireExitClass(t, runCLI(t, h, []string{"manifest", "verify", "-", dir}, []byte(bad)), jcserr.CLIUsage)
+	}
+	requireExitClass(t, runCLI(t, h, []string{"manifest", "verify", manifest}, nil), jcserr.CLIUsage)
+}
@@ -0,0 +1,194 @@
+package conformance_test
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/lattice-substrate/json-canon/jcserr"
+	"github.com/lattice-substrate/json-canon/jcsfloat"
+)
+
+type numberMethod func(float64, int) (string, *jcserr.Error)
+
+func requireMethodOutputs(t *testing.T, name string, format numberMethod, cases map[float64]map[int]string) {
+	t.Helper()
+	for v, byArg := range cases {
+		for arg, want := range byArg {
+			got, err := format(v, arg)
+			if err != nil || got != want {
+				t.Fatalf("%s(%v, %d) = %q, %v; want %q", name, v, arg, got, err, want)
+			}
+		}
+	}
+}
+
+// === ECMA-OPS-001: Number.prototype.toFixed ===
+
+func checkECMAOpsFixed(t *testing.T, _ *harness) {
+	t.Helper()
+	requireMethodOutputs(t, "FormatFixed", jcsfloat.FormatFixed, map[float64]map[int]string{
+		1.005:   {2: "1.00"},
+		1.25:    {1: "1.3"},
+		-0.0001: {2: "-0.00"},
+		1e21:    {2: "1e+21"},
+		0.1:     {20: "0.10000000000000000555"},
+	})
+}
+
+// === ECMA-OPS-002: Number.prototype.toExponential ===
+
+func checkECMAOpsExponential(t *testing.T, _ *harness) {
+	t.Helper()
+	requireMethodOutputs(t, "FormatExponential", jcsfloat.FormatExponential, map[float64]map[int]string{
+		0:       {-1: "0e+0", 2: "0.00e+0"},
+		123.456: {-1: "1.23456e+2", 2: "1.23e+2"},
+		5e-324:  {-1: "5e-324", 3: "4.941e-324"},
+		99.99:   {1: "1.0e+2"},
+	})
+}
+
+// === ECMA-OPS-003: Number.prototype.toPrecision ===
+
+func checkECMAOpsPrecision(t *testing.T, _ *harness) {
+	t.Helper()
+	requireMethodOutputs(t, "FormatPrecision", jcsfloat.FormatPrecision, map[float64]map[int]string{
+		0:            {3: "0.00"},
+		123.456:      {2: "1.2e+2", 3: "123", 5: "123.46"},
+		0.000123:     {2: "0.00012"},
+		0.0000001234: {2: "1.2e-7"},
+	})
+}
+
+// === ECMA-OPS-004: Number.prototype.toString(radix) ===
+
+func checkECMAOpsRadix(t *testing.T, _ *harness) {
+	t.Helper()
+	requireMethodOutputs(t, "FormatRadix", jcsfloat.FormatRadix, map[float64]map[int]string{
+		255:    {2: "11111111", 16: "ff", 36: "73"},
+		-255.5: {16: "-ff.8"},
+		1e21:   {10: "1e+21", 36: "5v1j4f4ds7c000"},
+		0.1:    {10: "0.1"},
+	})
+}
+
+// === ECMA-OPS-005: RangeError arguments are BOUND_EXCEEDED ===
+
+func checkECMAOpsRange(t *testing.T, _ *harness) {
+	t.Helper()
+	for _, call := range []struct {
+		format numberMethod
+		arg    int
+	}{
+		{jcsfloat.FormatFixed, -1},
+		{jcsfloat.FormatFixed, 101},
+		{jcsfloat.FormatExponential, 101},
+		{jcsfloat.FormatPrecision, 0},
+		{jcsfloat.FormatPrecision, 101},
+		{jcsfloat.FormatRadix, 1},
+		{jcsfloat.FormatRadix, 37},
+	} {
+		_, err := call.format(math.NaN(), call.arg)
+		requireClass(t, err, jcserr.BoundExceeded)
+	}
+	requireMethodOutputs(t, "FormatPrecision", jcsfloat.FormatPrecision, map[float64]map[int]string{
+		math.NaN():   {3: "NaN"},
+		math.Inf(1):  {3: "Infinity"},
+		math.Inf(-1): {3: "-Infinity"},
+	})
+}
+
+// === ECMA-OPS-006: Non-decimal radix digits match V8 ===
+
+func checkECMAOpsRadixV8(t *testing.T, h *harness) {
+	t.Helper()
+	rows := 0
+	verifyMethodOracle(t, h, func(method, arg string) bool {
+		if method != "toString" || arg == "10" {
+			return false
+		}
+		rows++
+		return true
+	})
+	if rows == 0 {
+		t.Fatal("method oracle has no non-decimal toString rows")
+	}
+}
+
+// === ECMA-VEC-005: method golden oracle ===
+
+func checkMethodsGoldenOracle(t *testing.T, h *harness) {
+	t.Helper()
+	rows, sum := verifyMethodOracle(t, h, func(string, string) bool { return true })
+	if rows != 61440 {
+		t.Fatalf("oracle row count mismatch: got %d want 61440", rows)
+	}
+	if want := "ddda7577b62aed4c0612b4478157c1fdeab6e7ec7bf280c8a47edff289bfbfb3"; sum != want {
+		t.Fatalf("oracle checksum mismatch: got %s want %s", sum, want)
+	}
+}
+
+// verifyMethodOracle checks every selected row of the method oracle and
+// returns the total row count and the file's SHA-256.
+func verifyMethodOracle(t *testing.T, h *harness, selected func(method, arg string) bool) (int, string) {
+	t.Helper()
+	methods := map[string]numberMethod{
+		"toFixed":       jcsfloat.FormatFixed,
+		"toExponential": jcsfloat.FormatExponential,
+		"toPrecision":   jcsfloat.FormatPrecision,
+		"toString":      jcsfloat.FormatRadix,
+	}
+	path := filepath.Join(h.root, "jcsfloat", "testdata", "golden_methods_vectors.csv")
+	f, err := os.Open(path) //nolint:gosec // REQ:ECMA-VEC-005 oracle verifier reads repository oracle fixtures by path.
+	if err != nil {
+		t.Fatalf("open oracle: %v", err)
+	}
+	t.Cleanup(func() {
+		if closeErr := f.Close(); closeErr != nil {
+			t.Errorf("close oracle %s: %v", path, closeErr)
+		}
+	})
+	sum := sha256.New()
+	sc := bufio.NewScanner(io.TeeReader(f, sum))
+	sc.Buffer(make([]byte, 0, 128*1024), 2*1024*1024)
+	rows := 0
+	for sc.Scan() {
+		rows++
+		parts := strings.Split(sc.Text(), ",")
+		if len(parts) != 4 {
+			t.Fatalf("malformed oracle line %d: %q", rows, sc.Text())
+		}
+		format, ok := methods[parts[1]]
+		if !ok {
+			t.Fatalf("line %d unknown method %q", rows, parts[1])
+		}
+		if !selected(parts[1], parts[2]) {
+			continue
+		}
+		bits, err := strconv.ParseUint(parts[0], 16, 64)
+		if err != nil {
+			t.Fatalf("line %d parse bits: %v", rows, err)
+		}
+		arg := -1
+		if parts[2] != "" {
+			if arg, err = strconv.Atoi(parts[2]); err != nil {
+				t.Fatalf("line %d parse argument: %v", rows, err)
+			}
+		}
+		got, fmtErr := format(math.Float64frombits(bits), arg)
+		if fmtErr != nil || got != parts[3] {
+			t.Fatalf("line %d %s(bits=%016x, %s) = %q, %v; want %q", rows, parts[1], bits, parts[2], got, fmtErr, parts[3])
+		}
+	}
+	if err := sc.Err(); err != nil {
+		t.Fatalf("scan oracle: %v", err)
+	}
+	return rows, fmt.Sprintf("%x", sum.Sum(nil))
+}
@@ -0,0 +1,56 @@
+package conformance_test
+
+import (
+	"math"
+	"testing"
+
+	"github.com/lattice-substrate/json-canon/jcserr"
+	"github.com/lattice-substrate/json-canon/jcsfloat"
+)
+
+// === NUMTYPE-F32-001: float32 values are formatted by exact widening ===
+
+func checkNumTypeFloat32(t *testing.T, _ *harness) {
+	t.Helper()
+	for f, want := range map[float32]string{
+		0.1:                           "0.10000000149011612",
+		16777216:                      "16777216",
+		math.MaxFloat32:               "3.4028234663852886e+38",
+		math.SmallestNonzeroFloat32:   "1.401298464324817e-45",
+		float32(math.Copysign(0, -1)): "0",
+	} {
+		got, err := jcsfloat.FormatFloat32(f)
+		if err != nil || got != want {
+			t.Fatalf("FormatFloat32(%v) = %q, %v; want %q", f, got, err, want)
+		}
+	}
+}
+
+// === NUMTYPE-INT-001: Integers are emitted only when the output equals them ===
+
+func checkNumTypeInteger(t *testing.T, _ *harness) {
+	t.Helper()
+	for i, want := range map[int64]string{
+		1 << 53:    "9007199254740992",
+		-(1 << 53): "-9007199254740992",
+		1<<53 + 2:  "9007199254740994",
+		1e18:       "1000000000000000000",
+		-123456789: "-123456789",
+	} {
+		got, err := jcsfloat.FormatInt64(i)
+		if err != nil || got != want {
+			t.Fatalf("FormatInt64(%d) = %q, %v; want %q", i, got, err, want)
+		}
+	}
+	for _, i := range []int64{1<<53 + 1, 1 << 60, math.MaxInt64, math.MinInt64} {
+		_, err := jcsfloat.FormatInt64(i)
+		requireClass(t, err, jcserr.NumberInexact)
+	}
+	if got, err := jcsfloat.FormatUint64(1e19); err != nil || got != "10000000000000000000" {
+		t.Fatalf("FormatUint64(1e19) = %q, %v", got, err)
+	}
+	for _, u := range []uint64{1 << 63, math.MaxUint64} {
+		_, err := jcsfloat.FormatUint64(u)
+		requireClass(t, err, jcserr.NumberInexact)
+	}
+}
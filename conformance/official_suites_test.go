@@ -15,12 +15,17 @@ import (
 	"strings"
 	"testing"
 
+	"github.com/lattice-substrate/json-canon/jcserr"
 	"github.com/lattice-substrate/json-canon/jcsfloat"
 )
 
 const (
 	officialES6Checksum10K  = "b9f7a8e75ef22a835685a52ccba7f7d6bdc99e34b010992cbc5864cd12be6892"
 	officialES6Checksum100M = "0f7dda6b0837dde083c5d6b896f7d62340c8a2415b0c7121d83145e08a755272"
+
+	// es6DifferentialLines is the prefix of the official ES6 corpus on which
+	// the fixed-width and multiprecision digit paths are compared.
+	es6DifferentialLines = 100_000
 )
 
 type officialES6Target struct {
@@ -40,6 +45,11 @@ func TestOfficialCyberphoneCanonicalPairs(t *testing.T) {
 	checkOfficialCyberphoneVectors(t, h)
 }
 
+func TestOfficialW3CDataIntegrityVectors(t *testing.T) {
+	h := testHarness(t)
+	checkDIOfficialVectors(t, h)
+}
+
 func TestOfficialCyberphoneFixtureProvenance(t *testing.T) {
 	h := testHarness(t)
 	manifestPath := filepath.Join(h.root, "conformance", "official", "cyberphone", "UPSTREAM.json")
@@ -84,14 +94,21 @@ func TestOfficialRFC8785Vectors(t *testing.T) {
 }
 
 func TestOfficialES6CorpusChecksums10K(t *testing.T) {
-	verifyOfficialES6Checksums(t, []officialES6Target{{lines: 10_000, sum: officialES6Checksum10K}})
+	verifyOfficialES6Checksums(t, []officialES6Target{{lines: 10_000, sum: officialES6Checksum10K}}, jcsfloat.FormatDouble)
 }
 
+// TestOfficialES6CorpusChecksums100M formats the full corpus with
+// FormatDouble, whose digits come from the fixed-width path. The offline
+// cross-arch harness runs it on both x86_64 and arm64.
 func TestOfficialES6CorpusChecksums100M(t *testing.T) {
 	if lookupEnvTrimmed("JCS_OFFICIAL_ES6_ENABLE_100M") != "1" {
 		t.Skip("set JCS_OFFICIAL_ES6_ENABLE_100M=1 to run 100M official ES6 checksum gate")
 	}
-	verifyOfficialES6Checksums(t, []officialES6Target{{lines: 100_000_000, sum: officialES6Checksum100M}})
+	verifyOfficialES6Checksums(t, []officialES6Target{{lines: 100_000_000, sum: officialES6Checksum100M}}, jcsfloat.FormatDouble)
+}
+
+func TestES6CorpusFastMatchesExact(t *testing.T) {
+	checkES6CorpusFastMatchesExact(t, nil)
 }
 
 func TestOfficialES6100MReleaseGatePolicy(t *testing.T) {
@@ -159,7 +176,26 @@ func checkOfficialRFC8785Vectors(t *testing.T, h *harness) {
 
 func checkOfficialES6Corpus10K(t *testing.T, _ *harness) {
 	t.Helper()
-	verifyOfficialES6Checksums(t, []officialES6Target{{lines: 10_000, sum: officialES6Checksum10K}})
+	verifyOfficialES6Checksums(t, []officialES6Target{{lines: 10_000, sum: officialES6Checksum10K}}, jcsfloat.FormatDouble)
+}
+
+// checkES6CorpusFastMatchesExact reproduces the official 10K checksum with
+// the multiprecision reference alone, then compares the fixed-width and
+// multiprecision paths on a longer prefix of the same corpus.
+func checkES6CorpusFastMatchesExact(t *testing.T, _ *harness) {
+	t.Helper()
+	verifyOfficialES6Checksums(t, []officialES6Target{{lines: 10_000, sum: officialES6Checksum10K}}, jcsfloat.FormatDoubleExact)
+
+	next := newOfficialES6Generator()
+	for i := 1; i <= es6DifferentialLines; i++ {
+		f := next()
+		got, err := jcsfloat.FormatDouble(f)
+		want, wantErr := jcsfloat.FormatDoubleExact(f)
+		if err != nil || wantErr != nil || got != want {
+			t.Fatalf("line %d bits=%016x FormatDouble=%q (%v) FormatDoubleExact=%q (%v)",
+				i, math.Float64bits(f), got, err, want, wantErr)
+		}
+	}
 }
 
 func checkOfficialES6100MReleaseGatePolicy(t *testing.T, h *harness) {
@@ -215,7 +251,7 @@ func verifyRFC8785AppendixB(t *testing.T, path string) {
 	}
 }
 
-func verifyOfficialES6Checksums(t *testing.T, targets []officialES6Target) {
+func verifyOfficialES6Checksums(t *testing.T, targets []officialES6Target, format func(float64) (string, *jcserr.Error)) {
 	t.Helper()
 	if len(targets) == 0 {
 		t.Fatal("at least one ES6 checksum target is required")
@@ -237,7 +273,7 @@ func verifyOfficialES6Checksums(t *testing.T, targets []officialES6Target) {
 
 	for i := 1; i <= maxLines; i++ {
 		f := next()
-		formatted, fmtErr := jcsfloat.FormatDouble(f)
+		formatted, fmtErr := format(f)
 		if fmtErr != nil {
 			t.Fatalf("line %d unexpected format error: %v", i, fmtErr)
 		}
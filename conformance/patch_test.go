@@ -0,0 +1,207 @@
+package conformance_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/lattice-substrate/json-canon/jcs"
+	"github.com/lattice-substrate/json-canon/jcserr"
+	"github.com/lattice-substrate/json-canon/jcstoken"
+)
+
+func parseValue(t *testing.T, in string) *jcstoken.Value {
+	t.Helper()
+	v, err := jcstoken.Parse([]byte(in))
+	if err != nil {
+		t.Fatalf("parse %s: %v", in, err)
+	}
+	return v
+}
+
+// applyJSONPatch applies the patch text ops to doc and returns the
+// canonical result.
+func applyJSONPatch(t *testing.T, doc, ops string, opts *jcstoken.Options) (string, error) {
+	t.Helper()
+	p, err := jcs.ParsePatch(parseValue(t, ops))
+	if err != nil {
+		return "", err
+	}
+	out, err := jcs.ApplyPatchWithOptions(parseValue(t, doc), p, opts)
+	if err != nil {
+		return "", err
+	}
+	canonical, err := jcs.Serialize(out)
+	if err != nil {
+		t.Fatalf("serialize result: %v", err)
+	}
+	return string(canonical), nil
+}
+
+func requirePatch(t *testing.T, cases [][3]string) {
+	t.Helper()
+	for _, tc := range cases {
+		got, err := applyJSONPatch(t, tc[0], tc[1], nil)
+		if err != nil {
+			t.Fatalf("ApplyPatch(%s, %s): %v", tc[0], tc[1], err)
+		}
+		if got != tc[2] {
+			t.Fatalf("ApplyPatch(%s, %s) = %s, want %s", tc[0], tc[1], got, tc[2])
+		}
+	}
+}
+
+// === PATCH-PARSE-001: Malformed patch documents are rejected as INVALID_PATCH ===
+
+func checkPatchParse(t *testing.T, _ *harness) {
+	t.Helper()
+	for _, ops := range []string{
+		`{"op":"add","path":"/a","value":1}`,
+		`["add"]`,
+		`[{"op":"add","value":1}]`,
+		`[{"op":"replace","path":"/a"}]`,
+		`[{"op":"move","path":"/a"}]`,
+		`[{"op":"delete","path":"/a"}]`,
+		`[{"op":"remove","path":0}]`,
+	} {
+		_, err := jcs.ParsePatch(parseValue(t, ops))
+		requireClass(t, err, jcserr.InvalidPatch)
+	}
+	_, err := jcs.ParsePatch(parseValue(t, `[{"op":"remove","path":"a"}]`))
+	requireClass(t, err, jcserr.InvalidPointer)
+	if _, err := jcs.ParsePatch(parseValue(t, `[{"op":"test","path":"","value":null,"comment":"x"}]`)); err != nil {
+		t.Fatalf("unknown members must be ignored: %v", err)
+	}
+}
+
+// === PATCH-APPLY-001: Operations apply in order with RFC 6902 semantics ===
+
+func checkPatchApply(t *testing.T, _ *harness) {
+	t.Helper()
+	requirePatch(t, [][3]string{
+		{`{"foo":"bar"}`, `[{"op":"add","path":"/baz","value":"qux"}]`, `{"baz":"qux","foo":"bar"}`},
+		{`{"foo":["bar","baz"]}`, `[{"op":"add","path":"/foo/1","value":"qux"}]`, `{"foo":["bar","qux","baz"]}`},
+		{`{"foo":["bar"]}`, `[{"op":"add","path":"/foo/-","value":1}]`, `{"foo":["bar",1]}`},
+		{`{"a":1,"b":2}`, `[{"op":"remove","path":"/a"},{"op":"replace","path":"/b","value":3}]`, `{"b":3}`},
+		{`{"a":{"b":1},"c":{}}`, `[{"op":"move","from":"/a/b","path":"/c/d"}]`, `{"a":{},"c":{"d":1}}`},
+		{`{"a":[1,2,3]}`, `[{"op":"move","from":"/a/0","path":"/a/2"}]`, `{"a":[2,3,1]}`},
+		{`{"a":[1]}`, `[{"op":"copy","from":"/a","path":"/b"},{"op":"add","path":"/b/-","value":2}]`, `{"a":[1],"b":[1,2]}`},
+		{`{"a":1}`, `[{"op":"replace","path":"","value":[null]}]`, `[null]`},
+	})
+	for _, tc := range []struct {
+		doc, ops string
+		class    jcserr.FailureClass
+	}{
+		{`{"a":1}`, `[{"op":"add","path":"/b/c","value":1}]`, jcserr.InvalidPointer},
+		{`{"a":1}`, `[{"op":"remove","path":"/b"}]`, jcserr.InvalidPointer},
+		{`[1]`, `[{"op":"add","path":"/2","value":1}]`, jcserr.InvalidPointer},
+		{`{"a":{}}`, `[{"op":"move","from":"/a","path":"/a/b"}]`, jcserr.InvalidPatch},
+	} {
+		_, err := applyJSONPatch(t, tc.doc, tc.ops, nil)
+		requireClass(t, err, tc.class)
+	}
+}
+
+// === PATCH-TEST-001: "test" compares values by their canonical serialization ===
+
+func checkPatchTest(t *testing.T, _ *harness) {
+	t.Helper()
+	requirePatch(t, [][3]string{
+		{`{"a":{"x":[1e0],"y":"é"}}`, `[{"op":"test","path":"/a","value":{"y":"é","x":[1.0]}}]`, `{"a":{"x":[1],"y":"é"}}`},
+	})
+	for _, ops := range []string{
+		`[{"op":"test","path":"/a","value":{"x":[1]}}]`,
+		`[{"op":"test","path":"/a/x","value":["1"]}]`,
+		`[{"op":"test","path":"/a/y","value":"e"}]`,
+	} {
+		_, err := applyJSONPatch(t, `{"a":{"x":[1],"y":"é"}}`, ops, nil)
+		requireClass(t, err, jcserr.PatchTestFailed)
+	}
+}
+
+// === PATCH-DOMAIN-001: Patch results are validated against the input domain ===
+
+func checkPatchDomain(t *testing.T, _ *harness) {
+	t.Helper()
+	dup := jcs.Patch{{Op: "add", Path: "/b", Value: &jcstoken.Value{Kind: jcstoken.KindObject, Members: []jcstoken.Member{
+		{Key: "k", Value: jcstoken.Value{Kind: jcstoken.KindBool, Str: "true"}},
+		{Key: "k", Value: jcstoken.Value{Kind: jcstoken.KindBool, Str: "false"}},
+	}}}}
+	_, err := jcs.ApplyPatch(parseValue(t, `{"a":1}`), dup)
+	requireClass(t, err, jcserr.DuplicateKey)
+	_, err = applyJSONPatch(t, `[1,2]`, `[{"op":"add","path":"/-","value":3}]`, &jcstoken.Options{MaxArrayElements: 2})
+	requireClass(t, err, jcserr.BoundExceeded)
+	_, err = jcs.MergePatchWithOptions(parseValue(t, `{}`), parseValue(t, `{"a":{"b":{"c":{}}}}`), &jcstoken.Options{MaxDepth: 2})
+	requireClass(t, err, jcserr.BoundExceeded)
+}
+
+// === MERGE-APPLY-001: Merge patches apply with RFC 7396 semantics ===
+
+func checkMergeApply(t *testing.T, _ *harness) {
+	t.Helper()
+	for _, tc := range [][3]string{
+		{`{"a":"b","c":{"d":"e","f":"g"}}`, `{"a":"z","c":{"f":null}}`, `{"a":"z","c":{"d":"e"}}`},
+		{`{"title":"Goodbye!","tags":["example","sample"]}`, `{"title":"Hello!","phoneNumber":"+01-123-456-7890","tags":["example"]}`,
+			`{"phoneNumber":"+01-123-456-7890","tags":["example"],"title":"Hello!"}`},
+		{`{"a":[{"b":"c"}]}`, `{"a":[1]}`, `{"a":[1]}`},
+		{`["a","b"]`, `{"a":"b","c":null}`, `{"a":"b"}`},
+		{`{"a":"foo"}`, `null`, `null`},
+		{`{}`, `{"a":{"bb":{"ccc":null}}}`, `{"a":{"bb":{}}}`},
+	} {
+		out, err := jcs.MergePatch(parseValue(t, tc[0]), parseValue(t, tc[1]))
+		if err != nil {
+			t.Fatalf("MergePatch(%s, %s): %v", tc[0], tc[1], err)
+		}
+		got, err := jcs.Serialize(out)
+		if err != nil || string(got) != tc[2] {
+			t.Fatalf("MergePatch(%s, %s) = %s, %v, want %s", tc[0], tc[1], got, err, tc[2])
+		}
+	}
+}
+
+// === CLI-PATCH-001: patch emits the canonical bytes of the patched document ===
+
+func checkCLIPatch(t *testing.T, h *harness) {
+	t.Helper()
+	ops, doc := diffFiles(t, `[{"op":"test","path":"/n","value":1.0},{"op":"replace","path":"/n","value":2}]`, `{"s":"x","n":1}`)
+	res := runCLI(t, h, []string{"patch", ops, doc}, nil)
+	if res.exitCode != 0 || res.stdout != `{"n":2,"s":"x"}` || res.stderr != "" {
+		t.Fatalf("unexpected result: %+v", res)
+	}
+	res = runCLI(t, h, []string{"patch", ops}, []byte(`{ "n": 1e0 }`))
+	if res.exitCode != 0 || res.stdout != `{"n":2}` || res.stderr != "" {
+		t.Fatalf("document from stdin: %+v", res)
+	}
+	res = runCLI(t, h, []string{"patch", ops}, []byte(`{"n":3}`))
+	requireExitClass(t, res, jcserr.PatchTestFailed)
+	if res.stdout != "" {
+		t.Fatalf("failed patch wrote output: %+v", res)
+	}
+	res = runCLI(t, h, []string{"patch", "-", doc}, []byte(`[{"op":"copy","path":"/t"}]`))
+	requireExitClass(t, res, jcserr.InvalidPatch)
+	res = runCLI(t, h, []string{"patch", ops, "-"}, []byte(`{"n":1,"n":1}`))
+	requireExitClass(t, res, jcserr.DuplicateKey)
+	if !strings.HasPrefix(res.stderr, "-: ") {
+		t.Fatalf("failure does not name the input: %+v", res)
+	}
+	for _, args := range [][]string{{"patch"}, {"patch", ops, doc, doc}, {"patch", "-", "-"}, {"patch", "--quiet", ops, doc}} {
+		requireExitClass(t, runCLI(t, h, args, nil), jcserr.CLIUsage)
+	}
+}
+
+// === CLI-MERGE-001: merge-patch emits the canonical bytes of the merged document ===
+
+func checkCLIMergePatch(t *testing.T, h *harness) {
+	t.Helper()
+	patch, doc := diffFiles(t, `{"b":{"c":null,"d":[2]},"e":null}`, `{"e":0,"b":{"c":1},"a":true}`)
+	res := runCLI(t, h, []string{"merge-patch", patch, doc}, nil)
+	if res.exitCode != 0 || res.stdout != `{"a":true,"b":{"d":[2]}}` || res.stderr != "" {
+		t.Fatalf("unexpected result: %+v", res)
+	}
+	res = runCLI(t, h, []string{"merge-patch", patch, "-"}, []byte(`[1]`))
+	if res.exitCode != 0 || res.stdout != `{"b":{"d":[2]}}` || res.stderr != "" {
+		t.Fatalf("document from stdin: %+v", res)
+	}
+	res = runCLI(t, h, []string{"merge-patch", "--max-depth", "1", patch, doc}, nil)
+	requireExitClass(t, res, jcserr.BoundExceeded)
+	requireExitClass(t, runCLI(t, h, []string{"merge-patch", "-", "-"}, nil), jcserr.CLIUsage)
+}
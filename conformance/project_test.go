@@ -0,0 +1,113 @@
+package conformance_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/lattice-substrate/json-canon/jcs"
+	"github.com/lattice-substrate/json-canon/jcserr"
+	"github.com/lattice-substrate/json-canon/jcstoken"
+)
+
+const projectionDoc = `{"@context":["https://www.w3.org/ns/credentials/v2"],"type":"VerifiableCredential","proof":{"type":"DataIntegrityProof","proofValue":"z58"},"subject":{"id":"did:x","signature":"s"}}`
+
+func mustProject(t *testing.T, p *jcs.Projection) string {
+	t.Helper()
+	out, err := jcs.CanonicalizeProjected([]byte(projectionDoc), p, nil)
+	if err != nil {
+		t.Fatalf("CanonicalizeProjected(%+v): %v", p, err)
+	}
+	return string(out)
+}
+
+// === PROJ-APPLY-001: Projection precedes canonical emission ===
+
+func checkProjectionPrecedesEmission(t *testing.T, _ *harness) {
+	t.Helper()
+	want, err := jcs.Canonicalize([]byte(projectionDoc))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := mustProject(t, nil); got != string(want) {
+		t.Fatalf("nil projection changed output: %s", got)
+	}
+	v, err := jcstoken.Parse([]byte(projectionDoc))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := jcs.SerializeProjected(v, &jcs.Projection{Exclude: []string{"/proof"}}, nil); err != nil {
+		t.Fatal(err)
+	}
+	after, err := jcs.Serialize(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(after) != string(want) {
+		t.Fatal("projection modified the caller's value tree")
+	}
+}
+
+// === PROJ-EXCLUDE-001: Exclusion by pointer and member name ===
+
+func checkProjectionExclude(t *testing.T, _ *harness) {
+	t.Helper()
+	got := mustProject(t, &jcs.Projection{Exclude: []string{"/proof", "/absent"}, ExcludeNames: []string{"signature"}})
+	want := `{"@context":["https://www.w3.org/ns/credentials/v2"],"subject":{"id":"did:x"},"type":"VerifiableCredential"}`
+	if got != want {
+		t.Fatalf("got %s want %s", got, want)
+	}
+	for _, bad := range []string{"", "proof"} {
+		_, err := jcs.CanonicalizeProjected([]byte(projectionDoc), &jcs.Projection{Exclude: []string{bad}}, nil)
+		requireClass(t, err, jcserr.InvalidPointer)
+	}
+}
+
+// === PROJ-SELECT-001: Include keeps selected values and ancestors ===
+
+func checkProjectionInclude(t *testing.T, _ *harness) {
+	t.Helper()
+	got := mustProject(t, &jcs.Projection{Include: []string{"/proof", "/subject/id"}, Exclude: []string{"/proof/proofValue"}})
+	want := `{"proof":{"type":"DataIntegrityProof"},"subject":{"id":"did:x"}}`
+	if got != want {
+		t.Fatalf("got %s want %s", got, want)
+	}
+	_, err := jcs.CanonicalizeProjected([]byte(projectionDoc), &jcs.Projection{Include: []string{"/nope"}}, nil)
+	requireClass(t, err, jcserr.InvalidPointer)
+}
+
+// === CLI-PROJ-001: canonicalize projection flags ===
+
+func checkCLICanonicalizeProjection(t *testing.T, h *harness) {
+	t.Helper()
+	res := runCLI(t, h, []string{"canonicalize", "--exclude", "/proof", "--exclude-name=signature", "-"}, []byte(projectionDoc))
+	want := `{"@context":["https://www.w3.org/ns/credentials/v2"],"subject":{"id":"did:x"},"type":"VerifiableCredential"}`
+	if res.exitCode != 0 || res.stdout != want || res.stderr != "" {
+		t.Fatalf("unexpected result: %+v", res)
+	}
+	res = runCLI(t, h, []string{"canonicalize", "--include=/type", "-"}, []byte(projectionDoc))
+	if res.exitCode != 0 || res.stdout != `{"type":"VerifiableCredential"}` {
+		t.Fatalf("unexpected include result: %+v", res)
+	}
+	res = runCLI(t, h, []string{"canonicalize", "--include", "/nope", "-"}, []byte(projectionDoc))
+	if res.exitCode != 2 || !strings.Contains(res.stderr, string(jcserr.InvalidPointer)) {
+		t.Fatalf("unexpected unresolved include result: %+v", res)
+	}
+}
+
+// === CLI-FLAG-005: Flags are command-scoped and take values uniformly ===
+
+func checkCommandScopedFlags(t *testing.T, h *harness) {
+	t.Helper()
+	res := runCLI(t, h, []string{"verify", "--exclude", "/proof", "-"}, []byte(`{}`))
+	if res.exitCode != 2 || !strings.Contains(res.stderr, "unknown option") {
+		t.Fatalf("verify accepted canonicalize-only flag: %+v", res)
+	}
+	res = runCLI(t, h, []string{"canonicalize", "--exclude"}, nil)
+	if res.exitCode != 2 || !strings.Contains(res.stderr, string(jcserr.CLIUsage)) {
+		t.Fatalf("missing flag value not rejected: %+v", res)
+	}
+	res = runCLI(t, h, []string{"verify", "--quiet=1", "-"}, []byte(`{}`))
+	if res.exitCode != 2 || !strings.Contains(res.stderr, string(jcserr.CLIUsage)) {
+		t.Fatalf("boolean flag accepted a value: %+v", res)
+	}
+}
@@ -0,0 +1,238 @@
+package conformance_test
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/lattice-substrate/json-canon/jcs"
+	"github.com/lattice-substrate/json-canon/jcserr"
+	"github.com/lattice-substrate/json-canon/jcsredact"
+	"github.com/lattice-substrate/json-canon/jcstoken"
+)
+
+const redactSample = `{"a":{"b":"secret","c":[1,2,3]},"d":"public"}`
+
+func requireClass(t *testing.T, err error, want jcserr.FailureClass) {
+	t.Helper()
+	var je *jcserr.Error
+	if !errors.As(err, &je) {
+		t.Fatalf("expected *jcserr.Error with class %s, got %T: %v", want, err, err)
+	}
+	if je.Class != want {
+		t.Fatalf("expected %s, got %s: %v", want, je.Class, err)
+	}
+}
+
+func redactSalt(b byte) []byte {
+	return bytes.Repeat([]byte{b}, jcsredact.MinSaltBytes)
+}
+
+func mustRedact(t *testing.T, doc string, targets []jcsredact.Target) *jcsredact.Result {
+	t.Helper()
+	v, err := jcstoken.Parse([]byte(doc))
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	res, err := jcsredact.Redact(v, targets)
+	if err != nil {
+		t.Fatalf("redact: %v", err)
+	}
+	return res
+}
+
+// === PTR-SYNTAX-001: Non-empty pointer begins with '/' ===
+
+func checkPointerRequiresLeadingSlash(t *testing.T, _ *harness) {
+	t.Helper()
+	if p, err := jcstoken.ParsePointer(""); err != nil || len(p) != 0 {
+		t.Fatalf("empty pointer must reference the root, got %q, %v", p, err)
+	}
+	for _, bad := range []string{"a", "#/a", " /a"} {
+		_, err := jcstoken.ParsePointer(bad)
+		requireClass(t, err, jcserr.InvalidPointer)
+	}
+}
+
+// === PTR-SYNTAX-002: '~1' decodes before '~0' ===
+
+func checkPointerEscapesDecodeInOrder(t *testing.T, _ *harness) {
+	t.Helper()
+	p, err := jcstoken.ParsePointer("/~01")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p[0] != "~1" {
+		t.Fatalf(`"~01" must decode to "~1", got %q`, p[0])
+	}
+	for _, bad := range []string{"/~", "/~2", "/a~b"} {
+		_, err := jcstoken.ParsePointer(bad)
+		requireClass(t, err, jcserr.InvalidPointer)
+	}
+}
+
+// === PTR-EVAL-001: RFC 6901 §5 example evaluation ===
+
+func checkPointerEvaluation(t *testing.T, _ *harness) {
+	t.Helper()
+	doc := `{"foo":["bar","baz"],"":0,"a/b":1,"c%d":2,"e^f":3,"g|h":4,"i\\j":5,"k\"l":6," ":7,"m~n":8}`
+	v, err := jcstoken.Parse([]byte(doc))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]string{
+		"/foo/0": `"bar"`, "/": `0`, "/a~1b": `1`, "/c%d": `2`, "/e^f": `3`,
+		"/g|h": `4`, "/i\\j": `5`, "/k\"l": `6`, "/ ": `7`, "/m~0n": `8`,
+	}
+	for ptr, w := range want {
+		p, err := jcstoken.ParsePointer(ptr)
+		if err != nil {
+			t.Fatalf("%s: %v", ptr, err)
+		}
+		got, err := p.Resolve(v)
+		if err != nil {
+			t.Fatalf("%s: %v", ptr, err)
+		}
+		out, err := jcs.Serialize(got)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(out) != w {
+			t.Fatalf("%s: got %s want %s", ptr, out, w)
+		}
+	}
+	p, _ := jcstoken.ParsePointer("/foo/bar")
+	_, err = p.Resolve(v)
+	requireClass(t, err, jcserr.InvalidPointer)
+}
+
+// === PTR-EVAL-002: Array index grammar ===
+
+func checkPointerArrayIndexGrammar(t *testing.T, _ *harness) {
+	t.Helper()
+	for _, bad := range []string{"-", "00", "01", "-0", "1e0"} {
+		_, err := jcstoken.ArrayIndex(bad)
+		requireClass(t, err, jcserr.InvalidPointer)
+	}
+	if n, err := jcstoken.ArrayIndex("10"); err != nil || n != 10 {
+		t.Fatalf(`"10": got %d, %v`, n, err)
+	}
+}
+
+// === REDACT-DIGEST-001: Digests cover canonical bytes only ===
+
+func checkRedactDigestCanonicalOnly(t *testing.T, _ *harness) {
+	t.Helper()
+	a, err := jcstoken.Parse([]byte(redactSample))
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := jcstoken.Parse([]byte("{ \"d\" : \"public\",\n \"a\" : { \"c\" : [ 1, 2, 3 ], \"b\" : \"secret\" } }"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	da, err := jcsredact.Digest(a)
+	if err != nil {
+		t.Fatal(err)
+	}
+	db, err := jcsredact.Digest(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if da != db {
+		t.Fatalf("digest depends on formatting: %s vs %s", da, db)
+	}
+}
+
+// === REDACT-APPLY-001: Redaction is deterministic given salts ===
+
+func checkRedactDeterministic(t *testing.T, _ *harness) {
+	t.Helper()
+	targets := []jcsredact.Target{
+		{Pointer: "/a/b", Salt: redactSalt(1)},
+		{Pointer: "/a/c/1", Salt: redactSalt(2)},
+		{Pointer: "/a", Salt: redactSalt(3)},
+	}
+	var first []byte
+	for i := 0; i < 20; i++ {
+		ordered := []jcsredact.Target{targets[i%3], targets[(i+1)%3], targets[(i+2)%3]}
+		res := mustRedact(t, redactSample, ordered)
+		out, err := jcs.Serialize(res.Document)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, d := range res.Disclosures {
+			enc, err := d.Encode()
+			if err != nil {
+				t.Fatal(err)
+			}
+			out = append(out, enc...)
+		}
+		if first == nil {
+			first = out
+			continue
+		}
+		if !bytes.Equal(first, out) {
+			t.Fatalf("run %d differs:\n%s\n%s", i, first, out)
+		}
+	}
+}
+
+// === REDACT-APPLY-002: Invalid targets and reserved collisions rejected ===
+
+func checkRedactRejectsInvalidTargets(t *testing.T, _ *harness) {
+	t.Helper()
+	v, err := jcstoken.Parse([]byte(redactSample))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, ptr := range []string{"", "/zz", "/a/c/-", "/a/c/3", "/d/x"} {
+		_, err := jcsredact.Redact(v, []jcsredact.Target{{Pointer: ptr, Salt: redactSalt(1)}})
+		requireClass(t, err, jcserr.InvalidPointer)
+	}
+	_, err = jcsredact.Redact(v, []jcsredact.Target{{Pointer: "/d", Salt: []byte{1}}})
+	requireClass(t, err, jcserr.InvalidSalt)
+	_, err = jcsredact.Redact(v, []jcsredact.Target{{Pointer: "/a/b", Salt: redactSalt(1)}, {Pointer: "/d", Salt: redactSalt(1)}})
+	requireClass(t, err, jcserr.InvalidSalt)
+
+	reserved, err := jcstoken.Parse([]byte(`{"_sd":["x"],"k":1}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = jcsredact.Redact(reserved, []jcsredact.Target{{Pointer: "/k", Salt: redactSalt(1)}})
+	requireClass(t, err, jcserr.DuplicateKey)
+}
+
+// === REDACT-VERIFY-001: Disclosures referenced exactly once ===
+
+func checkRedactDisclosuresReferencedOnce(t *testing.T, _ *harness) {
+	t.Helper()
+	res := mustRedact(t, redactSample, []jcsredact.Target{{Pointer: "/a/b", Salt: redactSalt(1)}})
+	stray := mustRedact(t, redactSample, []jcsredact.Target{{Pointer: "/d", Salt: redactSalt(2)}})
+	_, err := jcsredact.Reassemble(res.Document, append(res.Disclosures, stray.Disclosures...))
+	requireClass(t, err, jcserr.DigestMismatch)
+
+	// A member disclosure must not be accepted in an array-element position.
+	wrongKind, err := jcstoken.Parse([]byte(`[{"...":"` + res.Disclosures[0].Digest + `"}]`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = jcsredact.Reassemble(wrongKind, res.Disclosures)
+	requireClass(t, err, jcserr.DigestMismatch)
+}
+
+// === REDACT-VERIFY-002: Reassembled digest equals the original ===
+
+func checkRedactVerifyDigest(t *testing.T, _ *harness) {
+	t.Helper()
+	res := mustRedact(t, redactSample, []jcsredact.Target{
+		{Pointer: "/a", Salt: redactSalt(1)},
+		{Pointer: "/a/c/0", Salt: redactSalt(2)},
+	})
+	if err := jcsredact.Verify(res.Document, res.Disclosures, res.Digest); err != nil {
+		t.Fatalf("verify: %v", err)
+	}
+	other := mustRedact(t, `{"a":1}`, nil)
+	err := jcsredact.Verify(res.Document, res.Disclosures, other.Digest)
+	requireClass(t, err, jcserr.DigestMismatch)
+}
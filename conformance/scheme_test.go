@@ -0,0 +1,166 @@
+package conformance_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/lattice-substrate/json-canon/jcs"
+	"github.com/lattice-substrate/json-canon/jcserr"
+	"github.com/lattice-substrate/json-canon/jcstoken"
+)
+
+func schemeSerialize(t *testing.T, s jcs.Scheme, in string) ([]byte, error) {
+	t.Helper()
+	v, err := jcstoken.Parse([]byte(in))
+	if err != nil {
+		t.Fatalf("parse %s: %v", in, err)
+	}
+	return s.Serialize(v)
+}
+
+func requireSchemeOutput(t *testing.T, s jcs.Scheme, cases []struct{ in, want string }) {
+	t.Helper()
+	for _, c := range cases {
+		out, err := schemeSerialize(t, s, c.in)
+		if err != nil {
+			t.Fatalf("%s.Serialize(%s): %v", s.Name(), c.in, err)
+		}
+		if string(out) != c.want {
+			t.Fatalf("%s.Serialize(%s) = %q, want %q", s.Name(), c.in, out, c.want)
+		}
+	}
+}
+
+func requireSchemeRejects(t *testing.T, s jcs.Scheme, inputs ...string) {
+	t.Helper()
+	for _, in := range inputs {
+		_, err := schemeSerialize(t, s, in)
+		requireClass(t, err, jcserr.SchemeDomain)
+	}
+}
+
+// === SCHEME-API-001: Schemes share the parsed Value tree ===
+
+func checkSchemeInterface(t *testing.T, _ *harness) {
+	t.Helper()
+	if got := jcs.Schemes(); len(got) == 0 || got[0] != jcs.RFC8785 {
+		t.Fatalf("RFC 8785 must be the first (default) scheme, got %v", got)
+	}
+	in := `{"b":[1e21,0.1],"a":"é"}`
+	out, err := schemeSerialize(t, jcs.RFC8785, in)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want, err := jcs.Canonicalize([]byte(in))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(out) != string(want) {
+		t.Fatalf("RFC8785 scheme = %s, want %s", out, want)
+	}
+	for _, name := range []string{"rfc8785", "olpc", "matrix"} {
+		if s, ok := jcs.LookupScheme(name); !ok || s.Name() != name {
+			t.Fatalf("LookupScheme(%q) = %v, %v", name, s, ok)
+		}
+	}
+}
+
+// === SCHEME-API-002: Built-in schemes validate against caller-supplied bounds ===
+
+func checkSchemeBounds(t *testing.T, _ *harness) {
+	t.Helper()
+	v, err := jcstoken.Parse([]byte(`{"a":[1,2,3]}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, s := range jcs.Schemes() {
+		_, err := jcs.SerializeSchemeWithOptions(s, v, &jcstoken.Options{MaxArrayElements: 2})
+		requireClass(t, err, jcserr.BoundExceeded)
+		if _, err := jcs.SerializeSchemeWithOptions(s, v, &jcstoken.Options{MaxArrayElements: 3}); err != nil {
+			t.Fatalf("%s: %v", s.Name(), err)
+		}
+	}
+}
+
+// === SCHEME-OLPC-001: OLPC key order, whitespace, and string escaping ===
+
+func checkSchemeOLPCEncoding(t *testing.T, _ *harness) {
+	t.Helper()
+	requireSchemeOutput(t, jcs.OLPC, []struct{ in, want string }{
+		{`{"signed":{"version":1,"_type":"root"},"signatures":[]}`, `{"signatures":[],"signed":{"_type":"root","version":1}}`},
+		{`["a\"b\\c", "line\nbreak\u0000"]`, "[\"a\\\"b\\\\c\",\"line\nbreak\x00\"]"},
+		{`{"😀":1,"￯":2}`, "{\"￯\":2,\"😀\":1}"},
+	})
+}
+
+// === SCHEME-OLPC-002: OLPC numbers are integers ===
+
+func checkSchemeOLPCIntegers(t *testing.T, _ *harness) {
+	t.Helper()
+	requireSchemeOutput(t, jcs.OLPC, []struct{ in, want string }{
+		{`[1.0, -2e2, 9007199254740991]`, `[1,-200,9007199254740991]`},
+	})
+	requireSchemeRejects(t, jcs.OLPC, `[0.5]`, `{"expires":1e-3}`, `[9007199254740992]`)
+}
+
+// === SCHEME-MATRIX-001: Matrix key order, whitespace, and string escaping ===
+
+func checkSchemeMatrixEncoding(t *testing.T, _ *harness) {
+	t.Helper()
+	requireSchemeOutput(t, jcs.Matrix, []struct{ in, want string }{
+		// Examples from the Matrix specification appendix on canonical JSON.
+		{`{"one": 1, "two": "Two"}`, `{"one":1,"two":"Two"}`},
+		{`{"b": "2", "a": "1"}`, `{"a":"1","b":"2"}`},
+		{`{"auth":{"success":true,"mxid":"@john.doe:example.com","profile":{"display_name":"John Doe","three_pids":[{"medium":"email","address":"john.doe@example.org"},{"medium":"msisdn","address":"123456789"}]}}}`,
+			`{"auth":{"mxid":"@john.doe:example.com","profile":{"display_name":"John Doe","three_pids":[{"address":"john.doe@example.org","medium":"email"},{"address":"123456789","medium":"msisdn"}]},"success":true}}`},
+		{`{"a": "日本語"}`, "{\"a\":\"日本語\"}"},
+		{`{"本": 2, "日": 1}`, "{\"日\":1,\"本\":2}"},
+		{`{"a": "\u65E5"}`, "{\"a\":\"日\"}"},
+		{`{"a": "\u0000"}`, `{"a":"\u0000"}`},
+		{`{"😀":1,"￯":2}`, "{\"￯\":2,\"😀\":1}"},
+	})
+}
+
+// === SCHEME-MATRIX-002: Matrix numbers are integers in [-(2^53)+1, 2^53-1] ===
+
+func checkSchemeMatrixIntegers(t *testing.T, _ *harness) {
+	t.Helper()
+	requireSchemeOutput(t, jcs.Matrix, []struct{ in, want string }{
+		{`{"a": null}`, `{"a":null}`},
+		{`[-9007199254740991, 9007199254740991, 1e2]`, `[-9007199254740991,9007199254740991,100]`},
+	})
+	requireSchemeRejects(t, jcs.Matrix, `[1.5]`, `[-9007199254740992]`, `[1e300]`)
+}
+
+// === CLI-SCHEME-001: canonicalize --scheme selects the canonical scheme ===
+
+func checkCLIScheme(t *testing.T, h *harness) {
+	t.Helper()
+	in := []byte(`{"b":"x\ny","a":1}`)
+	for _, c := range []struct {
+		args []string
+		want string
+	}{
+		{[]string{"canonicalize", "-"}, `{"a":1,"b":"x\ny"}`},
+		{[]string{"canonicalize", "--scheme", "rfc8785", "-"}, `{"a":1,"b":"x\ny"}`},
+		{[]string{"canonicalize", "--scheme=olpc", "-"}, "{\"a\":1,\"b\":\"x\ny\"}"},
+		{[]string{"canonicalize", "--scheme", "matrix", "--exclude", "/a", "-"}, `{"b":"x\ny"}`},
+	} {
+		res := runCLI(t, h, c.args, in)
+		if res.exitCode != 0 || res.stdout != c.want || res.stderr != "" {
+			t.Fatalf("%q: unexpected result %+v", c.args, res)
+		}
+	}
+	res := runCLI(t, h, []string{"canonicalize", "--scheme", "olpc", "-"}, []byte(`[0.1]`))
+	if res.exitCode != 2 || res.stdout != "" || !strings.Contains(res.stderr, string(jcserr.SchemeDomain)) {
+		t.Fatalf("non-integer not rejected: %+v", res)
+	}
+	res = runCLI(t, h, []string{"canonicalize", "--scheme", "gibson", "-"}, in)
+	if res.exitCode != 2 || !strings.Contains(res.stderr, string(jcserr.CLIUsage)) {
+		t.Fatalf("unsupported scheme not rejected: %+v", res)
+	}
+	res = runCLI(t, h, []string{"verify", "--scheme", "olpc", "-"}, in)
+	if res.exitCode != 2 || !strings.Contains(res.stderr, "unknown option") {
+		t.Fatalf("verify accepted --scheme: %+v", res)
+	}
+}
@@ -0,0 +1,275 @@
+package conformance_test
+
+import (
+	"bufio"
+	"context"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/lattice-substrate/json-canon/jcserr"
+	"github.com/lattice-substrate/json-canon/jcsserve"
+	"github.com/lattice-substrate/json-canon/jcstoken"
+)
+
+// serveRequest posts body to path on h and returns the status and body of
+// the response.
+func serveRequest(t *testing.T, h http.Handler, path, body string) (int, string) {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, path, strings.NewReader(body)))
+	if ct := rec.Header().Get("Content-Type"); ct != "application/json" {
+		t.Fatalf("POST %s: Content-Type %q", path, ct)
+	}
+	return rec.Code, rec.Body.String()
+}
+
+// unixClient returns an HTTP client whose connections all go to the Unix
+// domain socket at path.
+func unixClient(path string) *http.Client {
+	return &http.Client{Transport: &http.Transport{
+		DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+			return (&net.Dialer{}).DialContext(ctx, "unix", path)
+		},
+	}}
+}
+
+// === SERVE-API-001: canonicalize, verify, and digest follow the CLI semantics ===
+
+func checkServeAPI(t *testing.T, _ *harness) {
+	t.Helper()
+	s := jcsserve.New(jcsserve.Config{})
+	cases := []struct {
+		path, body string
+		status     int
+		want       string
+	}{
+		{jcsserve.PathCanonicalize, `{"z":[1.50,true],"a":"é"}`, 200, `{"a":"é","z":[1.5,true]}`},
+		{jcsserve.PathVerify, `{"a":"é","z":[1.5,true]}`, 200, `{"canonical":true}`},
+		{jcsserve.PathVerify, `{"a": 1}`, 422, `{"error":{"cause":null,"class":"NOT_CANONICAL","exit_code":2,"message":"input is not canonical","offset":null}}`},
+		{jcsserve.PathDigest, `{"a":1}`, 200, `{"algorithm":"sha-256","digest":"015abd7f5cc57a2dd94b7590f04ad8084273905ee33ec5cebeae62276a97f862"}`},
+	}
+	for _, tc := range cases {
+		status, body := serveRequest(t, s, tc.path, tc.body)
+		if status != tc.status || body != tc.want {
+			t.Fatalf("POST %s %s = %d %s, want %d %s", tc.path, tc.body, status, body, tc.status, tc.want)
+		}
+	}
+	if status, body := serveRequest(t, s, "/v1/unknown", `1`); status != http.StatusNotFound || !strings.Contains(body, `"class":"CLI_USAGE"`) {
+		t.Fatalf("unknown path: %d %s", status, body)
+	}
+	rec := httptest.NewRecorder()
+	s.ServeHTTP(rec, httptest.NewRequest(http.MethodPut, jcsserve.PathCanonicalize, strings.NewReader(`1`)))
+	if rec.Code != http.StatusMethodNotAllowed || rec.Header().Get("Allow") != http.MethodPost {
+		t.Fatalf("PUT: %d %v", rec.Code, rec.Header())
+	}
+}
+
+// === SERVE-BOUND-001: Requests are bounded by jcstoken.Options and a concurrency limit ===
+
+func checkServeBound(t *testing.T, _ *harness) {
+	t.Helper()
+	s := jcsserve.New(jcsserve.Config{Options: &jcstoken.Options{MaxInputSize: 16, MaxArrayElements: 2}, MaxConcurrent: 1})
+	for _, body := range []string{`"` + strings.Repeat("x", 16) + `"`, `[1,2,3]`} {
+		status, got := serveRequest(t, s, jcsserve.PathCanonicalize, body)
+		if status != http.StatusRequestEntityTooLarge || !strings.Contains(got, `"class":"BOUND_EXCEEDED"`) {
+			t.Fatalf("POST %s = %d %s, want 413 BOUND_EXCEEDED", body, status, got)
+		}
+	}
+	if status, got := serveRequest(t, s, jcsserve.PathCanonicalize, `[2,1]`); status != http.StatusOK || got != `[2,1]` {
+		t.Fatalf("within bounds: %d %s", status, got)
+	}
+}
+
+// === SERVE-ERR-001: Failures are answered with a stable JSON error envelope ===
+
+func checkServeError(t *testing.T, _ *harness) {
+	t.Helper()
+	s := jcsserve.New(jcsserve.Config{})
+	status, body := serveRequest(t, s, jcsserve.PathCanonicalize, "[1,\n\"\xff\"]")
+	want := `{"error":{"cause":null,"class":"INVALID_UTF8","column":2,"exit_code":2,"line":2,"message":"input is not valid UTF-8","offset":5}}`
+	if status != http.StatusUnprocessableEntity || body != want {
+		t.Fatalf("got %d %s, want 422 %s", status, body, want)
+	}
+	v, err := jcstoken.Parse([]byte(body))
+	if err != nil || len(v.Members) != 1 || v.Members[0].Key != "error" {
+		t.Fatalf("envelope is not one error object: %s (%v)", body, err)
+	}
+	for _, name := range []string{"class", "exit_code", "offset", "message", "cause"} {
+		if !strings.Contains(body, `"`+name+`":`) {
+			t.Fatalf("envelope lacks %q: %s", name, body)
+		}
+	}
+}
+
+// === SERVE-NET-001: The service listens only on a Unix socket or loopback address ===
+
+func checkServeNetwork(t *testing.T, h *harness) {
+	t.Helper()
+	for _, addr := range []string{"0.0.0.0:0", "[::]:0", "203.0.113.7:80", "localhost:0"} {
+		_, err := jcsserve.Listen("tcp", addr)
+		requireClass(t, err, jcserr.CLIUsage)
+	}
+	_, err := jcsserve.Listen("udp", "127.0.0.1:0")
+	requireClass(t, err, jcserr.CLIUsage)
+	// The package accepts connections only: no dialing, HTTP clients, or
+	// name resolution.
+	outbound := map[string]struct{}{
+		"Dial": {}, "DialContext": {}, "DialTCP": {}, "DialUDP": {}, "DialUnix": {}, "DialIP": {}, "DialTimeout": {}, "Dialer": {},
+		"Client": {}, "Transport": {}, "DefaultClient": {}, "DefaultTransport": {}, "Get": {}, "Post": {}, "Head": {}, "PostForm": {},
+		"LookupHost": {}, "LookupIP": {}, "LookupAddr": {}, "LookupCNAME": {}, "LookupMX": {}, "LookupTXT": {}, "LookupSRV": {}, "LookupNS": {}, "Resolver": {},
+	}
+	banned := []string{"math/rand", "crypto/rand", "os/exec", "net/url", "net/rpc", "net/smtp"}
+	dir := filepath.Join(h.root, "jcsserve")
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatalf("read dir %s: %v", dir, err)
+	}
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".go") || strings.HasSuffix(entry.Name(), "_test.go") {
+			continue
+		}
+		path := filepath.Join(dir, entry.Name())
+		f, err := parser.ParseFile(token.NewFileSet(), path, nil, 0)
+		if err != nil {
+			t.Fatalf("parse file %s: %v", path, err)
+		}
+		for _, is := range f.Imports {
+			imp := strings.Trim(is.Path.Value, "\"")
+			for _, b := range banned {
+				if imp == b {
+					t.Fatalf("file %s imports %q", path, imp)
+				}
+			}
+		}
+		ast.Inspect(f, func(n ast.Node) bool {
+			sel, ok := n.(*ast.SelectorExpr)
+			if !ok {
+				return true
+			}
+			pkg, ok := sel.X.(*ast.Ident)
+			if !ok || (pkg.Name != "net" && pkg.Name != "http") {
+				return true
+			}
+			if _, bad := outbound[sel.Sel.Name]; bad {
+				t.Fatalf("file %s uses outbound network API %s.%s", path, pkg.Name, sel.Sel.Name)
+			}
+			return true
+		})
+	}
+}
+
+// === SERVE-SHUTDOWN-001: Shutdown drains in-flight requests ===
+
+func checkServeShutdown(t *testing.T, _ *harness) {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "jcs.sock")
+	ln, err := jcsserve.Listen("unix", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	served := make(chan error, 1)
+	go func() { served <- jcsserve.New(jcsserve.Config{}).Serve(ctx, ln) }()
+
+	body, release := io.Pipe()
+	type result struct {
+		body string
+		err  error
+	}
+	done := make(chan result, 1)
+	go func() {
+		resp, err := unixClient(path).Post("http://jcs"+jcsserve.PathDigest, "application/json", body)
+		if err != nil {
+			done <- result{err: err}
+			return
+		}
+		defer resp.Body.Close()
+		b, err := io.ReadAll(resp.Body)
+		done <- result{string(b), err}
+	}()
+	// The padding exceeds the socket buffers, so the handler is reading the
+	// body by the time the write returns.
+	if _, err := release.Write([]byte(strings.Repeat("\n", 4<<20) + `{"a"`)); err != nil {
+		t.Fatal(err)
+	}
+	cancel()
+	if _, err := release.Write([]byte(`:1}`)); err != nil {
+		t.Fatal(err)
+	}
+	if err := release.Close(); err != nil {
+		t.Fatal(err)
+	}
+	got := <-done
+	want := `{"algorithm":"sha-256","digest":"015abd7f5cc57a2dd94b7590f04ad8084273905ee33ec5cebeae62276a97f862"}`
+	if got.err != nil || got.body != want {
+		t.Fatalf("in-flight request: %q, %v", got.body, got.err)
+	}
+	if err := <-served; err != nil {
+		t.Fatalf("Serve: %v", err)
+	}
+	if _, err := unixClient(path).Post("http://jcs"+jcsserve.PathDigest, "application/json", strings.NewReader(`1`)); err == nil {
+		t.Fatal("request accepted after shutdown")
+	}
+}
+
+// === CLI-SERVE-001: serve listens on exactly one local address and exits 0 after a graceful shutdown ===
+
+//nolint:gosec // REQ:CLI-SERVE-001 conformance harness executes the just-built local CLI binary.
+func checkCLIServe(t *testing.T, h *harness) {
+	t.Helper()
+	for _, args := range [][]string{
+		{"serve"},
+		{"serve", "--socket", "a.sock", "--listen", "127.0.0.1:0"},
+		{"serve", "--listen", "0.0.0.0:0"},
+		{"serve", "--listen", "127.0.0.1:0", "input.json"},
+	} {
+		requireExitClass(t, runCLI(t, h, args, nil), jcserr.CLIUsage)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
+	t.Cleanup(cancel)
+	path := filepath.Join(t.TempDir(), "jcs.sock")
+	cmd := exec.CommandContext(ctx, h.bin, "serve", "--socket", path)
+	stderr, err := cmd.StderrPipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := cmd.Start(); err != nil {
+		t.Fatal(err)
+	}
+	notices := bufio.NewReader(stderr)
+	line, err := notices.ReadString('\n')
+	if err != nil || line != "jcs-canon: serving on unix:"+path+"\n" {
+		t.Fatalf("notice %q: %v", line, err)
+	}
+	resp, err := unixClient(path).Post("http://jcs"+jcsserve.PathCanonicalize, "application/json", strings.NewReader(`{"b":[],"a":1E1}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := io.ReadAll(resp.Body)
+	if closeErr := resp.Body.Close(); err != nil || closeErr != nil || string(got) != `{"a":10,"b":[]}` {
+		t.Fatalf("canonicalize: %q, %v, %v", got, err, closeErr)
+	}
+	if err := cmd.Process.Signal(syscall.SIGTERM); err != nil {
+		t.Fatal(err)
+	}
+	rest, err := io.ReadAll(notices)
+	if err != nil || len(rest) != 0 {
+		t.Fatalf("stderr after shutdown: %q, %v", rest, err)
+	}
+	if err := cmd.Wait(); err != nil {
+		t.Fatalf("serve after SIGTERM: %v", err)
+	}
+}
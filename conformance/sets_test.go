@@ -0,0 +1,163 @@
+package conformance_test
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/lattice-substrate/json-canon/jcs"
+	"github.com/lattice-substrate/json-canon/jcserr"
+	"github.com/lattice-substrate/json-canon/jcstoken"
+)
+
+func pathSelection(t *testing.T, doc, query string) []string {
+	t.Helper()
+	v, err := jcstoken.Parse([]byte(doc))
+	if err != nil {
+		t.Fatalf("parse %s: %v", doc, err)
+	}
+	p, err := jcstoken.ParsePath(query)
+	if err != nil {
+		t.Fatalf("ParsePath(%q): %v", query, err)
+	}
+	out := []string{}
+	for _, ptr := range p.Select(v) {
+		out = append(out, ptr.String())
+	}
+	return out
+}
+
+func requireSetOutput(t *testing.T, in string, s *jcs.SetArrays, want string) {
+	t.Helper()
+	out, err := jcs.CanonicalizeSetArrays([]byte(in), s, nil)
+	if err != nil {
+		t.Fatalf("CanonicalizeSetArrays(%s): %v", in, err)
+	}
+	if string(out) != want {
+		t.Fatalf("CanonicalizeSetArrays(%s) = %s, want %s", in, out, want)
+	}
+}
+
+// === JPATH-SYNTAX-001: RFC 9535 query syntax subset ===
+
+func checkJSONPathSyntax(t *testing.T, _ *harness) {
+	t.Helper()
+	for _, q := range []string{`$`, `$.a[0]`, `$..b`, `$.*`, `$['a','b'][-1]`, `$["é"]`} {
+		if _, err := jcstoken.ParsePath(q); err != nil {
+			t.Fatalf("ParsePath(%q): %v", q, err)
+		}
+	}
+	for _, q := range []string{`a`, `$.`, `$[01]`, `$[-0]`, `$[0:1]`, `$[?@]`, `$['\x']`} {
+		_, err := jcstoken.ParsePath(q)
+		requireClass(t, err, jcserr.InvalidPointer)
+	}
+}
+
+// === JPATH-EVAL-001: Selection follows RFC 9535 ===
+
+func checkJSONPathSelection(t *testing.T, _ *harness) {
+	t.Helper()
+	doc := `{"o":{"j":1,"k":2},"a":[5,3,[{"j":4},{"k":6}]]}`
+	cases := []struct {
+		query string
+		want  []string
+	}{
+		// Examples from RFC 9535 Tables 5, 6, and 8, restricted to supported selectors.
+		{`$.o['j j']`, []string{}},
+		{`$.o.*`, []string{"/o/j", "/o/k"}},
+		{`$.a[1]`, []string{"/a/1"}},
+		{`$.a[-2]`, []string{"/a/1"}},
+		{`$.a[*]`, []string{"/a/0", "/a/1", "/a/2"}},
+		{`$..j`, []string{"/o/j", "/a/2/0/j"}},
+		{`$..[0]`, []string{"/a/0", "/a/2/0"}},
+	}
+	for _, c := range cases {
+		if got := pathSelection(t, doc, c.query); !reflect.DeepEqual(got, c.want) {
+			t.Fatalf("%s: got %v want %v", c.query, got, c.want)
+		}
+	}
+}
+
+// === SET-SORT-001: Set arrays are sorted by canonical element bytes ===
+
+func checkSetArraysSorted(t *testing.T, _ *harness) {
+	t.Helper()
+	s := &jcs.SetArrays{Pointers: []string{"/tags"}}
+	requireSetOutput(t, `{"tags":["z","10",9,true,{"b":1,"a":0}]}`, s, `{"tags":["10","z",9,true,{"a":0,"b":1}]}`)
+	requireSetOutput(t, `{"tags":["x"],"other":[2,1]}`, s, `{"other":[2,1],"tags":["x"]}`)
+	// An enclosing set sorts on the bytes of its already sorted members.
+	requireSetOutput(t, `[[2,1],[1,0]]`, &jcs.SetArrays{Paths: []string{`$`, `$.*`}}, `[[0,1],[1,2]]`)
+}
+
+// === SET-DUP-001: Repeated elements are kept, removed, or rejected ===
+
+func checkSetArraysDuplicates(t *testing.T, _ *harness) {
+	t.Helper()
+	in := `{"p":["r",1e0,"r",1]}`
+	requireSetOutput(t, in, &jcs.SetArrays{Pointers: []string{"/p"}}, `{"p":["r","r",1,1]}`)
+	requireSetOutput(t, in, &jcs.SetArrays{Pointers: []string{"/p"}, Duplicates: jcs.RemoveDuplicates}, `{"p":["r",1]}`)
+	_, err := jcs.CanonicalizeSetArrays([]byte(in), &jcs.SetArrays{Pointers: []string{"/p"}, Duplicates: jcs.RejectDuplicates}, nil)
+	requireClass(t, err, jcserr.DuplicateElement)
+}
+
+// === SET-TARGET-001: Selectors address arrays of the input document ===
+
+func checkSetArraysTargets(t *testing.T, _ *harness) {
+	t.Helper()
+	requireSetOutput(t, `{"a":[2,1]}`, &jcs.SetArrays{Pointers: []string{"/b", "/a/5"}}, `{"a":[2,1]}`)
+	for _, s := range []*jcs.SetArrays{
+		{Pointers: []string{"/a/0"}},
+		{Pointers: []string{"a"}},
+		{Paths: []string{`$.a[0]`}},
+		{Paths: []string{`$.a[0:1]`}},
+	} {
+		_, err := jcs.CanonicalizeSetArrays([]byte(`{"a":[2,1]}`), s, nil)
+		requireClass(t, err, jcserr.InvalidPointer)
+	}
+}
+
+// === SET-LABEL-001: Set-array output is labeled as a distinct profile ===
+
+func checkSetArraysLabeled(t *testing.T, h *harness) {
+	t.Helper()
+	if jcs.SetArraysProfile != "jcs-set-arrays" {
+		t.Fatalf("unexpected profile label %q", jcs.SetArraysProfile)
+	}
+	res := runCLI(t, h, []string{"canonicalize", "--set-pointer", "/s", "-"}, []byte(`{"s":[2,1]}`))
+	if res.exitCode != 0 || res.stdout != `{"s":[1,2]}` || res.stderr != "jcs-canon: profile jcs-set-arrays (not RFC 8785)\n" {
+		t.Fatalf("unexpected labeled result: %+v", res)
+	}
+	res = runCLI(t, h, []string{"canonicalize", "--quiet", "--set-pointer", "/s", "-"}, []byte(`{"s":[2,1]}`))
+	if res.exitCode != 0 || res.stderr != "" {
+		t.Fatalf("--quiet did not suppress the label: %+v", res)
+	}
+	res = runCLI(t, h, []string{"canonicalize", "-"}, []byte(`{"s":[2,1]}`))
+	if res.exitCode != 0 || res.stdout != `{"s":[2,1]}` || res.stderr != "" {
+		t.Fatalf("RFC 8785 output changed or labeled: %+v", res)
+	}
+}
+
+// === CLI-SET-001: canonicalize set-array options ===
+
+func checkCLISetArrays(t *testing.T, h *harness) {
+	t.Helper()
+	in := []byte(`{"g":[{"m":["b","a","b"]}],"t":["y","x"]}`)
+	res := runCLI(t, h, []string{"canonicalize", "-q", "--set-pointer=/t", "--set-path", "$.g[*].m", "--set-duplicates", "remove", "-"}, in)
+	if res.exitCode != 0 || res.stdout != `{"g":[{"m":["a","b"]}],"t":["x","y"]}` {
+		t.Fatalf("unexpected result: %+v", res)
+	}
+	for _, c := range []struct {
+		args []string
+		want jcserr.FailureClass
+	}{
+		{[]string{"canonicalize", "--set-path", "$..m", "--set-duplicates", "reject", "-"}, jcserr.DuplicateElement},
+		{[]string{"canonicalize", "--set-duplicates", "keep", "-"}, jcserr.CLIUsage},
+		{[]string{"canonicalize", "--set-pointer", "/t", "--set-duplicates", "ignore", "-"}, jcserr.CLIUsage},
+		{[]string{"verify", "--set-path", "$.t", "-"}, jcserr.CLIUsage},
+	} {
+		res = runCLI(t, h, c.args, in)
+		if res.exitCode != 2 || res.stdout != "" || !strings.Contains(res.stderr, string(c.want)) {
+			t.Fatalf("%q: expected %s, got %+v", c.args, c.want, res)
+		}
+	}
+}
@@ -0,0 +1,109 @@
+package jcs
+
+import (
+	"github.com/lattice-substrate/json-canon/jcserr"
+	"github.com/lattice-substrate/json-canon/jcsfloat"
+	"github.com/lattice-substrate/json-canon/jcstoken"
+)
+
+// DecimalProfile names the output of the Decimal scheme. It is an extension,
+// not RFC 8785: numbers keep their exact decimal value instead of being
+// rounded to binary64, so "12345678901234567.89" is emitted as written rather
+// than as 12345678901234568. Consumers that record or compare digests should
+// record this label alongside them.
+const DecimalProfile = "jcs-decimal"
+
+// decimalScheme is RFC 8785 in every respect except numbers, which are
+// emitted from the exact decimal text kept by jcstoken.NumberDecimal.
+// Numbers without decimal text are emitted as RFC 8785 emits them, so input
+// parsed as binary64 serializes identically under both schemes.
+//
+// DEC-SCHEME-001: Decimal output is RFC 8785 output with exact numbers.
+type decimalScheme struct{}
+
+func (decimalScheme) Name() string { return "decimal" }
+
+func (decimalScheme) Serialize(v *jcstoken.Value) ([]byte, error) {
+	return serializeDecimal(nil, v, nil)
+}
+
+func (decimalScheme) serializeWithOptions(v *jcstoken.Value, opts *jcstoken.Options) ([]byte, error) {
+	return serializeDecimal(nil, v, opts)
+}
+
+// CanonicalizeDecimal parses input with opts under jcstoken.NumberDecimal,
+// whatever opts.Numbers says, and returns its Decimal scheme bytes.
+func CanonicalizeDecimal(input []byte, opts *jcstoken.Options) ([]byte, error) {
+	var o jcstoken.Options
+	if opts != nil {
+		o = *opts
+	}
+	o.Numbers = jcstoken.NumberDecimal
+	v, err := jcstoken.ParseWithOptions(input, &o)
+	if err != nil {
+		return nil, err //nolint:wrapcheck // DEC-SCHEME-001: pass through jcstoken parse errors unchanged.
+	}
+	return serializeDecimal(make([]byte, 0, len(input)), v, &o)
+}
+
+func serializeDecimal(buf []byte, v *jcstoken.Value, opts *jcstoken.Options) ([]byte, error) {
+	if v == nil {
+		return nil, jcserr.New(jcserr.InternalError, -1, "jcs: nil value")
+	}
+	limits := resolveSerializeLimits(opts)
+	state := &serializeValidationState{exactDecimal: true}
+	if err := validateValueTree(v, 0, state, limits); err != nil {
+		return nil, err
+	}
+	return appendDecimalValue(buf, v, limits.maxOutputSize)
+}
+
+// appendDecimalValue emits v as serializeValue does, except that numbers
+// carrying decimal text are written exactly.
+func appendDecimalValue(buf []byte, v *jcstoken.Value, limit int) ([]byte, error) {
+	var err error
+	switch v.Kind {
+	case jcstoken.KindNumber:
+		if buf, err = appendDecimalNumber(buf, v); err != nil {
+			return nil, err
+		}
+		return boundOutput(buf, limit)
+	case jcstoken.KindArray:
+		buf = append(buf, '[')
+		for i := range v.Elems {
+			if i > 0 {
+				buf = append(buf, ',')
+			}
+			if buf, err = appendDecimalValue(buf, &v.Elems[i], limit); err != nil {
+				return nil, err
+			}
+		}
+		return boundOutput(append(buf, ']'), limit)
+	case jcstoken.KindObject:
+		buf = append(buf, '{')
+		for i, m := range sortMembers(v) {
+			if i > 0 {
+				buf = append(buf, ',')
+			}
+			buf = serializeString(buf, m.member.Key)
+			buf = append(buf, ':')
+			if buf, err = appendDecimalValue(buf, &m.member.Value, limit); err != nil {
+				return nil, err
+			}
+		}
+		return boundOutput(append(buf, '}'), limit)
+	default:
+		return serializeValue(buf, v, limit)
+	}
+}
+
+func appendDecimalNumber(buf []byte, v *jcstoken.Value) ([]byte, error) {
+	if v.Str == "" {
+		return serializeNumber(buf, v.Num)
+	}
+	s, err := jcsfloat.FormatDecimal(v.Str)
+	if err != nil {
+		return nil, jcserr.Wrap(err.Class, -1, "jcs: decimal serialization error", err)
+	}
+	return append(buf, s...), nil
+}
@@ -0,0 +1,88 @@
+package jcs_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/lattice-substrate/json-canon/jcs"
+	"github.com/lattice-substrate/json-canon/jcserr"
+	"github.com/lattice-substrate/json-canon/jcstoken"
+)
+
+// === DEC-SCHEME-001: Decimal output is RFC 8785 output with exact numbers ===
+
+func TestDecimalScheme_DEC_SCHEME_001(t *testing.T) {
+	in := `{"amount":12345678901234567.89,"fee":1.10,"b":"é\n","a":[1e400,-2.50E-3]}`
+	got, err := jcs.CanonicalizeDecimal([]byte(in), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "{\"a\":[1e+400,-0.0025],\"amount\":12345678901234567.89,\"b\":\"é\\n\",\"fee\":1.1}"
+	if string(got) != want {
+		t.Fatalf("got %s want %s", got, want)
+	}
+	// Numbers without decimal text serialize exactly as under RFC 8785.
+	plain := `{"z":[0.1,1e21,5e-324,100],"a":{"c":true,"b":null}}`
+	v, err := jcstoken.Parse([]byte(plain))
+	if err != nil {
+		t.Fatal(err)
+	}
+	rfc, err := jcs.Serialize(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dec, err := jcs.Decimal.Serialize(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(dec) != string(rfc) {
+		t.Fatalf("decimal %s differs from RFC 8785 %s", dec, rfc)
+	}
+	// Shortest round-trip input has the same bytes under both profiles.
+	if out, err := jcs.CanonicalizeDecimal([]byte(plain), nil); err != nil || string(out) != string(rfc) {
+		t.Fatalf("CanonicalizeDecimal = %s, %v; want %s", out, err, rfc)
+	}
+	if s, ok := jcs.LookupScheme("decimal"); !ok || s != jcs.Decimal {
+		t.Fatal("decimal scheme not registered")
+	}
+}
+
+func TestDecimalSchemeRejectsInvalidDecimalText(t *testing.T) {
+	v := &jcstoken.Value{Kind: jcstoken.KindNumber, Str: "1.5.5"}
+	_, err := jcs.Decimal.Serialize(v)
+	var je *jcserr.Error
+	if !errors.As(err, &je) || je.Class != jcserr.InvalidGrammar {
+		t.Fatalf("expected INVALID_GRAMMAR, got %v", err)
+	}
+	// RFC 8785 cannot represent a decimal that overflows binary64.
+	parsed, err := jcstoken.ParseWithOptions([]byte(`1e400`), &jcstoken.Options{Numbers: jcstoken.NumberDecimal})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := jcs.Serialize(parsed); err == nil {
+		t.Fatal("RFC 8785 serialized an infinite number")
+	}
+	_, err = jcs.CanonicalizeDecimal([]byte(`-1e-1000000000`), nil)
+	if !errors.As(err, &je) || je.Class != jcserr.UnsupportedDecimal {
+		t.Fatalf("expected UNSUPPORTED_DECIMAL, got %v", err)
+	}
+}
+
+func TestApplySetArraysOrdersExactDecimals(t *testing.T) {
+	opts := &jcstoken.Options{Numbers: jcstoken.NumberDecimal}
+	v, err := jcstoken.ParseWithOptions([]byte(`[0.10000000000000000001,0.1,0.100]`), opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sorted, err := jcs.ApplySetArrays(v, &jcs.SetArrays{Pointers: []string{""}, Duplicates: jcs.RemoveDuplicates})
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := jcs.Decimal.Serialize(sorted)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := `[0.1,0.10000000000000000001]`; string(got) != want {
+		t.Fatalf("got %s want %s", got, want)
+	}
+}
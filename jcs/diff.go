@@ -0,0 +1,232 @@
+package jcs
+
+import (
+	"math"
+	"strconv"
+
+	"github.com/lattice-substrate/json-canon/jcserr"
+	"github.com/lattice-substrate/json-canon/jcstoken"
+)
+
+// Operation is one RFC 6902 JSON Patch operation. Path and From are RFC 6901
+// JSON Pointers; From is used only when Op is "move" or "copy", and Value is
+// nil unless Op is "add", "replace", or "test".
+type Operation struct {
+	Op    string
+	Path  string
+	From  string
+	Value *jcstoken.Value
+}
+
+// Patch is an RFC 6902 JSON Patch document: operations applied in order.
+type Patch []Operation
+
+// Diff returns the patch that transforms a into b, validating both against
+// the default bounds. Two values are equal when their RFC 8785
+// serializations are, so an empty patch means a and b are canonically
+// equal.
+//
+// DIFF-API-001: Diff emits an RFC 6902 patch transforming a into b.
+func Diff(a, b *jcstoken.Value) (Patch, error) {
+	return DiffWithOptions(a, b, nil)
+}
+
+// DiffWithOptions is like Diff but validates a and b against caller-supplied
+// bounds.
+//
+// The patch contains only "add", "remove", and "replace" operations, in a
+// fixed order: object members are visited in RFC 8785 key order, and the
+// elements of an array are compared after its common leading and trailing
+// elements, pairwise from the lowest index, with surplus elements of a
+// removed from the highest index down and surplus elements of b added from
+// the lowest index up. A value whose kind changes, or a scalar that
+// differs, is replaced whole. Every path therefore resolves against the
+// document as transformed by the operations before it. The patch shares no
+// storage with a or b.
+//
+// DIFF-ORDER-001: Patch operations follow a deterministic order.
+func DiffWithOptions(a, b *jcstoken.Value, opts *jcstoken.Options) (Patch, error) {
+	if a == nil || b == nil {
+		return nil, jcserr.New(jcserr.InternalError, -1, "jcs: nil value")
+	}
+	limits := resolveSerializeLimits(opts)
+	for _, v := range []*jcstoken.Value{a, b} {
+		if err := validateValueTree(v, 0, &serializeValidationState{}, limits); err != nil {
+			return nil, err
+		}
+	}
+	patch := Patch{}
+	diffValue(&patch, "", a, b)
+	return patch, nil
+}
+
+// Serialize returns the RFC 8785 canonical JSON text of p: an array of
+// operation objects with members "from", "op", "path", and "value" as
+// present.
+func (p Patch) Serialize() ([]byte, error) {
+	return p.serialize(math.MaxInt)
+}
+
+// SerializeWithOptions is like Serialize but fails with BOUND_EXCEEDED as
+// soon as the text exceeds MaxOutputSize. The other bounds of opts are
+// applied by DiffWithOptions, not here.
+func (p Patch) SerializeWithOptions(opts *jcstoken.Options) ([]byte, error) {
+	return p.serialize(resolveSerializeLimits(opts).maxOutputSize)
+}
+
+func (p Patch) serialize(limit int) ([]byte, error) {
+	buf := []byte{'['}
+	for i := range p {
+		if i > 0 {
+			buf = append(buf, ',')
+		}
+		var err error
+		buf, err = p[i].appendJSON(buf, limit)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return boundOutput(append(buf, ']'), limit)
+}
+
+// appendJSON appends the canonical JSON object of op to buf, failing once
+// buf grows past limit bytes. Its member names are already in RFC 8785
+// order.
+func (op *Operation) appendJSON(buf []byte, limit int) ([]byte, error) {
+	buf = append(buf, '{')
+	if usesFrom(op.Op) {
+		buf = append(serializeString(append(buf, `"from":`...), op.From), ',')
+	}
+	buf = serializeString(append(buf, `"op":`...), op.Op)
+	buf = serializeString(append(buf, `,"path":`...), op.Path)
+	if op.Value != nil {
+		var err error
+		buf, err = serializeValue(append(buf, `,"value":`...), op.Value, limit)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return boundOutput(append(buf, '}'), limit)
+}
+
+// diffValue appends to patch the operations turning a into b at path.
+func diffValue(patch *Patch, path string, a, b *jcstoken.Value) {
+	switch {
+	case a.Kind != b.Kind:
+		patch.add("replace", path, b)
+	case a.Kind == jcstoken.KindObject:
+		diffObject(patch, path, a, b)
+	case a.Kind == jcstoken.KindArray:
+		diffArray(patch, path, a, b)
+	case !equalValues(a, b):
+		patch.add("replace", path, b)
+	}
+}
+
+// diffObject compares the members of a and b in RFC 8785 key order.
+func diffObject(patch *Patch, path string, a, b *jcstoken.Value) {
+	am, bm := sortMembers(a), sortMembers(b)
+	i, j := 0, 0
+	for i < len(am) || j < len(bm) {
+		var c int
+		switch {
+		case i == len(am):
+			c = 1
+		case j == len(bm):
+			c = -1
+		default:
+			c = compareSortKeys(&am[i], &bm[j])
+		}
+		switch {
+		case c < 0:
+			patch.add("remove", childPath(path, am[i].member.Key), nil)
+			i++
+		case c > 0:
+			patch.add("add", childPath(path, bm[j].member.Key), &bm[j].member.Value)
+			j++
+		default:
+			diffValue(patch, childPath(path, am[i].member.Key), &am[i].member.Value, &bm[j].member.Value)
+			i++
+			j++
+		}
+	}
+}
+
+// diffArray compares the elements of a and b between their common leading
+// and trailing elements.
+func diffArray(patch *Patch, path string, a, b *jcstoken.Value) {
+	ae, be := a.Elems, b.Elems
+	start := 0
+	for start < len(ae) && start < len(be) && equalValues(&ae[start], &be[start]) {
+		start++
+	}
+	endA, endB := len(ae), len(be)
+	for endA > start && endB > start && equalValues(&ae[endA-1], &be[endB-1]) {
+		endA--
+		endB--
+	}
+	paired := min(endA, endB)
+	for i := start; i < paired; i++ {
+		diffValue(patch, childPath(path, strconv.Itoa(i)), &ae[i], &be[i])
+	}
+	for i := endA - 1; i >= paired; i-- {
+		patch.add("remove", childPath(path, strconv.Itoa(i)), nil)
+	}
+	for i := paired; i < endB; i++ {
+		patch.add("add", childPath(path, strconv.Itoa(i)), &be[i])
+	}
+}
+
+// add appends an operation, copying v so the patch owns its values.
+func (p *Patch) add(op, path string, v *jcstoken.Value) {
+	if v != nil {
+		v = v.Clone()
+	}
+	*p = append(*p, Operation{Op: op, Path: path, Value: v})
+}
+
+// childPath returns the pointer to member or element tok of the value at
+// path.
+func childPath(path, tok string) string {
+	return path + jcstoken.Pointer{tok}.String()
+}
+
+// equalValues reports whether a and b have the same RFC 8785 serialization.
+// Numbers compare by binary64 value, which fixes their serialization.
+func equalValues(a, b *jcstoken.Value) bool {
+	if a.Kind != b.Kind {
+		return false
+	}
+	switch a.Kind {
+	case jcstoken.KindNumber:
+		return a.Num == b.Num
+	case jcstoken.KindArray:
+		if len(a.Elems) != len(b.Elems) {
+			return false
+		}
+		for i := range a.Elems {
+			if !equalValues(&a.Elems[i], &b.Elems[i]) {
+				return false
+			}
+		}
+		return true
+	case jcstoken.KindObject:
+		return equalObjects(a, b)
+	default:
+		return a.Str == b.Str
+	}
+}
+
+// equalObjects reports whether objects a and b have equal members.
+func equalObjects(a, b *jcstoken.Value) bool {
+	if len(a.Members) != len(b.Members) {
+		return false
+	}
+	am, bm := sortMembers(a), sortMembers(b)
+	for i := range am {
+		if am[i].member.Key != bm[i].member.Key || !equalValues(&am[i].member.Value, &bm[i].member.Value) {
+			return false
+		}
+	}
+	return true
+}
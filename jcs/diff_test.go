@@ -0,0 +1,177 @@
+package jcs_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/lattice-substrate/json-canon/jcs"
+	"github.com/lattice-substrate/json-canon/jcserr"
+	"github.com/lattice-substrate/json-canon/jcstoken"
+)
+
+func diffJSON(t *testing.T, a, b string) string {
+	t.Helper()
+	va, err := jcstoken.Parse([]byte(a))
+	if err != nil {
+		t.Fatalf("parse %q: %v", a, err)
+	}
+	vb, err := jcstoken.Parse([]byte(b))
+	if err != nil {
+		t.Fatalf("parse %q: %v", b, err)
+	}
+	patch, err := jcs.Diff(va, vb)
+	if err != nil {
+		t.Fatalf("Diff(%s, %s): %v", a, b, err)
+	}
+	// The patch is checked with the package's own applier and with
+	// applyTestPatch, which shares no code with it.
+	applied, err := jcs.ApplyPatch(va, patch)
+	if err != nil {
+		t.Fatalf("apply Diff(%s, %s): %v", a, b, err)
+	}
+	if got, want := canonValue(t, applied), canon(t, b); got != want {
+		t.Fatalf("Diff(%s, %s) patch yields %s with ApplyPatch", a, b, got)
+	}
+	applyTestPatch(t, va, patch)
+	if got, want := canonValue(t, va), canon(t, b); got != want {
+		t.Fatalf("Diff(%s, %s) patch yields %s", a, b, got)
+	}
+	out, err := patch.Serialize()
+	if err != nil {
+		t.Fatalf("serialize patch: %v", err)
+	}
+	return string(out)
+}
+
+// applyTestPatch applies the add, remove, and replace operations of patch
+// to v in place.
+func applyTestPatch(t *testing.T, v *jcstoken.Value, patch jcs.Patch) {
+	t.Helper()
+	for _, op := range patch {
+		ptr, err := jcstoken.ParsePointer(op.Path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(ptr) == 0 {
+			*v = *op.Value.Clone()
+			continue
+		}
+		parent, err := ptr[:len(ptr)-1].Resolve(v)
+		if err != nil {
+			t.Fatalf("%s %s: %v", op.Op, op.Path, err)
+		}
+		applyTestOp(t, parent, ptr[len(ptr)-1], op)
+	}
+}
+
+func applyTestOp(t *testing.T, parent *jcstoken.Value, tok string, op jcs.Operation) {
+	t.Helper()
+	if parent.Kind == jcstoken.KindObject {
+		members := parent.Members[:0:0]
+		for _, m := range parent.Members {
+			if m.Key != tok {
+				members = append(members, m)
+			}
+		}
+		if op.Op != "remove" {
+			members = append(members, jcstoken.Member{Key: tok, Value: *op.Value.Clone()})
+		}
+		parent.Members = members
+		return
+	}
+	i, jerr := jcstoken.ArrayIndex(tok)
+	if jerr != nil {
+		t.Fatalf("%s %s: %v", op.Op, op.Path, jerr)
+	}
+	elems := append([]jcstoken.Value(nil), parent.Elems[:i]...)
+	switch op.Op {
+	case "add":
+		elems = append(append(elems, *op.Value.Clone()), parent.Elems[i:]...)
+	case "remove":
+		elems = append(elems, parent.Elems[i+1:]...)
+	default:
+		elems = append(append(elems, *op.Value.Clone()), parent.Elems[i+1:]...)
+	}
+	parent.Elems = elems
+}
+
+// === DIFF-API-001: Diff emits an RFC 6902 patch transforming a into b ===
+
+func TestDiff_DIFF_API_001(t *testing.T) {
+	cases := []struct {
+		a, b string
+		want string
+	}{
+		{`{"a":1,"b":[1,2]}`, `{"b":[1,2],"a":1.0}`, `[]`},
+		{`1`, `"1"`, `[{"op":"replace","path":"","value":"1"}]`},
+		{`{"a":{"b":1}}`, `{"a":[1]}`, `[{"op":"replace","path":"/a","value":[1]}]`},
+		{`{"a":1,"c":3}`, `{"b":2,"c":4}`,
+			`[{"op":"remove","path":"/a"},{"op":"add","path":"/b","value":2},{"op":"replace","path":"/c","value":4}]`},
+		{`{"a/b":1,"m~n":2}`, `{"a/b":2}`,
+			`[{"op":"replace","path":"/a~1b","value":2},{"op":"remove","path":"/m~0n"}]`},
+		{`[1,2,3,4]`, `[1,4]`, `[{"op":"remove","path":"/2"},{"op":"remove","path":"/1"}]`},
+		{`[1,4]`, `[1,2,3,4]`, `[{"op":"add","path":"/1","value":2},{"op":"add","path":"/2","value":3}]`},
+		{`[1,2,9]`, `[1,3,4,9]`, `[{"op":"replace","path":"/1","value":3},{"op":"add","path":"/2","value":4}]`},
+		{`[{"x":1},{"x":2}]`, `[{"x":1,"y":0},{"x":3}]`,
+			`[{"op":"add","path":"/0/y","value":0},{"op":"replace","path":"/1/x","value":3}]`},
+		{`{"":[]}`, `{"":[null]}`, `[{"op":"add","path":"//0","value":null}]`},
+		{`[true]`, `[false]`, `[{"op":"replace","path":"/0","value":false}]`},
+	}
+	for _, tc := range cases {
+		if got := diffJSON(t, tc.a, tc.b); got != tc.want {
+			t.Errorf("Diff(%s, %s) = %s, want %s", tc.a, tc.b, got, tc.want)
+		}
+	}
+}
+
+func TestDiffValidatesBounds(t *testing.T) {
+	a, err := jcstoken.Parse([]byte(`[[1]]`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := jcstoken.Parse([]byte(`[1]`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = jcs.DiffWithOptions(b, a, &jcstoken.Options{MaxDepth: 1})
+	var je *jcserr.Error
+	if !errors.As(err, &je) || je.Class != jcserr.BoundExceeded {
+		t.Fatalf("expected BOUND_EXCEEDED, got %v", err)
+	}
+	if _, err := jcs.Diff(nil, b); err == nil {
+		t.Fatal("expected error for nil value")
+	}
+}
+
+// === DIFF-ORDER-001: Patch operations follow a deterministic order ===
+
+func TestDiff_DIFF_ORDER_001(t *testing.T) {
+	// Member order of the inputs does not affect the patch; keys follow
+	// RFC 8785 order (UTF-16 code units: U+FB01 sorts after U+1F600).
+	want := `[{"op":"remove","path":"/a"},{"op":"add","path":"/b","value":1},{"op":"replace","path":"/😀","value":2},{"op":"add","path":"/ﬁ","value":3}]`
+	for _, pair := range [][2]string{
+		{`{"a":0,"😀":1}`, `{"ﬁ":3,"😀":2,"b":1}`},
+		{`{"😀":1,"a":0}`, `{"b":1,"😀":2,"ﬁ":3}`},
+	} {
+		if got := diffJSON(t, pair[0], pair[1]); got != want {
+			t.Errorf("Diff(%s, %s) = %s, want %s", pair[0], pair[1], got, want)
+		}
+	}
+	// The patch does not alias b.
+	a, err := jcstoken.Parse([]byte(`{}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := jcstoken.Parse([]byte(`{"a":[1]}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	patch, err := jcs.Diff(a, b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b.Members[0].Value.Elems[0].Num = 2
+	if out, err := patch.Serialize(); err != nil || string(out) != `[{"op":"add","path":"/a","value":[1]}]` {
+		t.Fatalf("patch changed with b: %s, %v", out, err)
+	}
+}
@@ -0,0 +1,193 @@
+package jcs
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/lattice-substrate/json-canon/jcserr"
+	"github.com/lattice-substrate/json-canon/jcstoken"
+)
+
+// EmbeddedJSONProfile names the output of embedded-JSON canonicalization. It
+// is an extension, not RFC 8785: string values holding JSON text are
+// replaced by the canonical form of that text, so documents whose embedded
+// JSON differs only in formatting produce identical bytes. Consumers that
+// record or compare digests should record this label alongside them.
+const EmbeddedJSONProfile = "jcs-embedded-json"
+
+// EmbeddedJSON selects string values whose content is a JSON document to be
+// canonicalized in place under the EmbeddedJSONProfile.
+type EmbeddedJSON struct {
+	// Pointers are RFC 6901 JSON Pointers to strings that must hold JSON
+	// text. Pointers that do not resolve are ignored; a selected value that
+	// is not a string, or whose content is not strict I-JSON, is an error.
+	Pointers []string
+	// Detect additionally canonicalizes every string value, at any depth and
+	// inside embedded documents, whose content parses as a JSON object or
+	// array. Strings that do not parse are left unchanged.
+	Detect bool
+}
+
+// EmbeddedJSONError locates a failure inside an embedded JSON document. It
+// is the Cause of the *jcserr.Error returned for the failure, which carries
+// the same class.
+type EmbeddedJSONError struct {
+	// Pointer is the RFC 6901 JSON Pointer of the string in the outer
+	// document.
+	Pointer string
+	// Err is the failure in the embedded document; Err.Offset is a byte
+	// offset into the decoded string value.
+	Err *jcserr.Error
+}
+
+// Error implements the error interface.
+func (e *EmbeddedJSONError) Error() string {
+	return fmt.Sprintf("string at %q, embedded byte %d: %s", e.Pointer, e.Err.Offset, e.Err.Message)
+}
+
+// Unwrap returns the failure in the embedded document.
+func (e *EmbeddedJSONError) Unwrap() error {
+	return e.Err
+}
+
+// CanonicalizeEmbeddedJSON parses input with opts, applies e with
+// ApplyEmbeddedJSON, and returns the canonical bytes of the result.
+func CanonicalizeEmbeddedJSON(input []byte, e *EmbeddedJSON, opts *jcstoken.Options) ([]byte, error) {
+	v, err := jcstoken.ParseWithOptions(input, opts)
+	if err != nil {
+		return nil, err //nolint:wrapcheck // EMBED-CANON-001: pass through jcstoken parse errors unchanged.
+	}
+	out, err := ApplyEmbeddedJSON(v, e, opts)
+	if err != nil {
+		return nil, err
+	}
+	return serializeInto(nil, out, opts)
+}
+
+// ApplyEmbeddedJSON returns a copy of v in which every string selected by e
+// is replaced by the canonical form of the JSON text it holds. Embedded
+// documents are parsed strictly with opts and serialized as RFC 8785, or
+// with exact numbers under jcstoken.NumberDecimal. With e.Detect, strings
+// inside embedded documents are canonicalized too. The input tree is never
+// modified; a nil e returns a plain deep copy.
+//
+// EMBED-CANON-001: Embedded JSON is replaced by its canonical form.
+// EMBED-ERROR-001: Embedded failures carry the outer pointer and inner offset.
+func ApplyEmbeddedJSON(v *jcstoken.Value, e *EmbeddedJSON, opts *jcstoken.Options) (*jcstoken.Value, error) {
+	if v == nil {
+		return nil, jcserr.New(jcserr.InternalError, -1, "jcs: nil value")
+	}
+	out := v.Clone()
+	if e == nil {
+		return out, nil
+	}
+	done := make(map[*jcstoken.Value]struct{}, len(e.Pointers))
+	for _, raw := range e.Pointers {
+		ptr, err := jcstoken.ParsePointer(raw)
+		if err != nil {
+			return nil, err //nolint:wrapcheck // EMBED-SELECT-001: preserve pointer failure class unchanged.
+		}
+		target, err := ptr.Resolve(out)
+		if err != nil {
+			continue
+		}
+		if _, seen := done[target]; seen {
+			continue
+		}
+		if err := canonicalizeSelected(target, ptr, e.Detect, opts); err != nil {
+			return nil, err
+		}
+		done[target] = struct{}{}
+	}
+	if e.Detect {
+		detectEmbedded(out, done, opts)
+	}
+	return out, nil
+}
+
+// canonicalizeSelected replaces the string at ptr with the canonical form of
+// its JSON content.
+//
+// EMBED-SELECT-001: Selected strings must hold strict JSON text.
+func canonicalizeSelected(target *jcstoken.Value, ptr jcstoken.Pointer, detect bool, opts *jcstoken.Options) error {
+	if target.Kind != jcstoken.KindString {
+		return jcserr.New(jcserr.InvalidPointer, -1, fmt.Sprintf("jcs: embedded JSON %q is not a string", ptr.String()))
+	}
+	canonical, err := canonicalEmbedded(target.Str, detect, opts)
+	if err != nil {
+		var inner *jcserr.Error
+		if !errors.As(err, &inner) {
+			inner = jcserr.Wrap(jcserr.InternalError, -1, "jcs: embedded JSON", err)
+		}
+		return jcserr.Wrap(inner.Class, -1, "jcs: invalid embedded JSON",
+			&EmbeddedJSONError{Pointer: ptr.String(), Err: inner})
+	}
+	target.Str = canonical
+	return nil
+}
+
+// detectEmbedded canonicalizes, in place, every string value under v that
+// holds a JSON object or array, skipping values in done.
+//
+// EMBED-DETECT-001: Detection rewrites only strings holding objects or arrays.
+func detectEmbedded(v *jcstoken.Value, done map[*jcstoken.Value]struct{}, opts *jcstoken.Options) {
+	if _, skip := done[v]; skip {
+		return
+	}
+	switch v.Kind {
+	case jcstoken.KindString:
+		if !looksLikeContainer(v.Str) {
+			return
+		}
+		if canonical, err := canonicalEmbedded(v.Str, true, opts); err == nil {
+			v.Str = canonical
+		}
+	case jcstoken.KindArray:
+		for i := range v.Elems {
+			detectEmbedded(&v.Elems[i], done, opts)
+		}
+	case jcstoken.KindObject:
+		for i := range v.Members {
+			detectEmbedded(&v.Members[i].Value, done, opts)
+		}
+	}
+}
+
+// canonicalEmbedded parses s strictly and returns its canonical form, after
+// canonicalizing strings embedded in it when detect is set. Under
+// jcstoken.NumberDecimal the embedded document keeps its exact numbers, as
+// the outer document does.
+func canonicalEmbedded(s string, detect bool, opts *jcstoken.Options) (string, error) {
+	inner, err := jcstoken.ParseWithOptions([]byte(s), opts)
+	if err != nil {
+		return "", err //nolint:wrapcheck // EMBED-ERROR-001: the caller attaches the outer pointer.
+	}
+	if detect {
+		detectEmbedded(inner, nil, opts)
+	}
+	serialize := serializeInto
+	if opts != nil && opts.Numbers == jcstoken.NumberDecimal {
+		serialize = serializeDecimal
+	}
+	out, err := serialize(make([]byte, 0, len(s)), inner, opts)
+	if err != nil {
+		return "", err
+	}
+	return string(out), nil
+}
+
+// looksLikeContainer reports whether s, after JSON whitespace, starts an
+// object or array.
+func looksLikeContainer(s string) bool {
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case ' ', '\t', '\n', '\r':
+			continue
+		case '{', '[':
+			return true
+		default:
+			return false
+		}
+	}
+	return false
+}
@@ -0,0 +1,112 @@
+package jcs_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/lattice-substrate/json-canon/jcs"
+	"github.com/lattice-substrate/json-canon/jcserr"
+	"github.com/lattice-substrate/json-canon/jcstoken"
+)
+
+// === EMBED-CANON-001: Embedded JSON is replaced by its canonical form ===
+
+func TestApplyEmbeddedJSON_EMBED_CANON_001(t *testing.T) {
+	in := `{"payload":"{ \"b\": [1.0, 2], \"a\": \"x\" }","other":"{ \"z\": 1 }"}`
+	v, err := jcstoken.Parse([]byte(in))
+	if err != nil {
+		t.Fatal(err)
+	}
+	out, err := jcs.ApplyEmbeddedJSON(v, &jcs.EmbeddedJSON{Pointers: []string{"/payload", "/missing", "/payload"}}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := jcs.Serialize(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `{"other":"{ \"z\": 1 }","payload":"{\"a\":\"x\",\"b\":[1,2]}"}`
+	if string(got) != want {
+		t.Fatalf("got %s want %s", got, want)
+	}
+	if v.Members[0].Value.Str != `{ "b": [1.0, 2], "a": "x" }` {
+		t.Fatal("input tree was modified")
+	}
+	// Scalars are JSON text too.
+	got, err = jcs.CanonicalizeEmbeddedJSON([]byte(`["1E2"," true "]`), &jcs.EmbeddedJSON{Pointers: []string{"/0", "/1"}}, nil)
+	if err != nil || string(got) != `["100","true"]` {
+		t.Fatalf("got %s, %v", got, err)
+	}
+	// Embedded documents keep exact numbers under NumberDecimal.
+	opts := &jcstoken.Options{Numbers: jcstoken.NumberDecimal}
+	dv, err := jcstoken.ParseWithOptions([]byte(`{"p":"[1.10, 12345678901234567.89]"}`), opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dout, err := jcs.ApplyEmbeddedJSON(dv, &jcs.EmbeddedJSON{Pointers: []string{"/p"}}, opts)
+	if err != nil || dout.Members[0].Value.Str != `[1.1,12345678901234567.89]` {
+		t.Fatalf("decimal embedded: %+v, %v", dout, err)
+	}
+}
+
+// === EMBED-SELECT-001: Selected strings must hold strict JSON text ===
+
+func TestApplyEmbeddedJSON_EMBED_SELECT_001(t *testing.T) {
+	cases := []struct {
+		name, in, ptr string
+		class         jcserr.FailureClass
+	}{
+		{"non-string", `{"a":{"b":1}}`, "/a", jcserr.InvalidPointer},
+		{"malformed pointer", `{"a":"{}"}`, "a", jcserr.InvalidPointer},
+		{"not JSON", `{"a":"hello"}`, "/a", jcserr.InvalidGrammar},
+		{"lenient syntax", `{"a":"{\"x\":1,}"}`, "/a", jcserr.InvalidGrammar},
+		{"duplicate key", `{"a":"{\"x\":1,\"x\":2}"}`, "/a", jcserr.DuplicateKey},
+	}
+	for _, tc := range cases {
+		_, err := jcs.CanonicalizeEmbeddedJSON([]byte(tc.in), &jcs.EmbeddedJSON{Pointers: []string{tc.ptr}}, nil)
+		var je *jcserr.Error
+		if !errors.As(err, &je) || je.Class != tc.class {
+			t.Fatalf("%s: expected %s, got %v", tc.name, tc.class, err)
+		}
+	}
+}
+
+// === EMBED-DETECT-001: Detection rewrites only strings holding objects or arrays ===
+
+func TestApplyEmbeddedJSON_EMBED_DETECT_001(t *testing.T) {
+	in := `{"a":"{\"y\":\"[ 2, 1 ]\",\"x\":1}","b":"[1,]","c":" 42 ","d":"{oops","e":["[ true ]"],"{ \"k\": 1 }":0}`
+	got, err := jcs.CanonicalizeEmbeddedJSON([]byte(in), &jcs.EmbeddedJSON{Detect: true}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `{"a":"{\"x\":1,\"y\":\"[2,1]\"}","b":"[1,]","c":" 42 ","d":"{oops","e":["[true]"],"{ \"k\": 1 }":0}`
+	if string(got) != want {
+		t.Fatalf("got %s want %s", got, want)
+	}
+	// A pointer-selected string is still strict under Detect.
+	_, err = jcs.CanonicalizeEmbeddedJSON([]byte(`{"b":"[1,]"}`), &jcs.EmbeddedJSON{Pointers: []string{"/b"}, Detect: true}, nil)
+	if err == nil {
+		t.Fatal("expected failure for invalid selected string")
+	}
+}
+
+// === EMBED-ERROR-001: Embedded failures carry the outer pointer and inner offset ===
+
+func TestApplyEmbeddedJSON_EMBED_ERROR_001(t *testing.T) {
+	_, err := jcs.CanonicalizeEmbeddedJSON([]byte(`{"a":{"b~c":"[1, 01]"}}`), &jcs.EmbeddedJSON{Pointers: []string{"/a/b~0c"}}, nil)
+	var ee *jcs.EmbeddedJSONError
+	if !errors.As(err, &ee) {
+		t.Fatalf("expected EmbeddedJSONError, got %v", err)
+	}
+	if ee.Pointer != "/a/b~0c" || ee.Err.Class != jcserr.InvalidGrammar || ee.Err.Offset != 5 {
+		t.Fatalf("unexpected location: %+v", ee)
+	}
+	var je *jcserr.Error
+	if !errors.As(err, &je) || je.Class != jcserr.InvalidGrammar || je.Offset != -1 {
+		t.Fatalf("unexpected outer error: %v", err)
+	}
+	if msg := err.Error(); !strings.Contains(msg, `"/a/b~0c"`) || !strings.Contains(msg, "embedded byte 5") {
+		t.Fatalf("diagnostic lacks location: %s", msg)
+	}
+}
@@ -31,3 +31,139 @@ func ExampleSerialize() {
 	fmt.Println(string(canonical))
 	// Output: {"a":[3,1],"z":true}
 }
+
+func ExampleLookupScheme() {
+	v, err := jcstoken.Parse([]byte(`{"b":"x\\y","a":[1,2.0]}`))
+	if err != nil {
+		log.Fatal(err)
+	}
+	scheme, ok := jcs.LookupScheme("olpc")
+	if !ok {
+		log.Fatal("unknown scheme")
+	}
+	canonical, err := scheme.Serialize(v)
+	if err != nil {
+		log.Fatal(err)
+	}
+	fmt.Println(string(canonical))
+	// Output: {"a":[1,2],"b":"x\\y"}
+}
+
+func ExampleCanonicalizeSetArrays() {
+	input := []byte(`{"tags":["write","read","write"],"id":7}`)
+	sets := &jcs.SetArrays{Pointers: []string{"/tags"}, Duplicates: jcs.RemoveDuplicates}
+	out, err := jcs.CanonicalizeSetArrays(input, sets, nil)
+	if err != nil {
+		log.Fatal(err)
+	}
+	fmt.Println(jcs.SetArraysProfile, string(out))
+	// Output: jcs-set-arrays {"id":7,"tags":["read","write"]}
+}
+
+func ExampleCanonicalizeDecimal() {
+	input := []byte(`{"amount":12345678901234567.89,"fee":1.10}`)
+	out, err := jcs.CanonicalizeDecimal(input, nil)
+	if err != nil {
+		log.Fatal(err)
+	}
+	fmt.Println(jcs.DecimalProfile, string(out))
+	// Output: jcs-decimal {"amount":12345678901234567.89,"fee":1.1}
+}
+
+func ExampleCanonicalizeEmbeddedJSON() {
+	input := []byte(`{"event":"login","payload":"{ \"user\": \"alice\", \"at\": 1.50 }"}`)
+	out, err := jcs.CanonicalizeEmbeddedJSON(input, &jcs.EmbeddedJSON{Pointers: []string{"/payload"}}, nil)
+	if err != nil {
+		log.Fatal(err)
+	}
+	fmt.Println(jcs.EmbeddedJSONProfile, string(out))
+	// Output: jcs-embedded-json {"event":"login","payload":"{\"at\":1.5,\"user\":\"alice\"}"}
+}
+
+func ExampleDiff() {
+	a, err := jcstoken.Parse([]byte(`{"v":1,"tags":["a","b"],"old":true}`))
+	if err != nil {
+		log.Fatal(err)
+	}
+	b, err := jcstoken.Parse([]byte(`{"tags":["a","c","d"],"v":1.0}`))
+	if err != nil {
+		log.Fatal(err)
+	}
+	patch, err := jcs.Diff(a, b)
+	if err != nil {
+		log.Fatal(err)
+	}
+	out, err := patch.Serialize()
+	if err != nil {
+		log.Fatal(err)
+	}
+	fmt.Println(string(out))
+	// Output: [{"op":"remove","path":"/old"},{"op":"replace","path":"/tags/1","value":"c"},{"op":"add","path":"/tags/2","value":"d"}]
+}
+
+func ExampleFormat() {
+	v, err := jcstoken.Parse([]byte(`{"tags":["b","a"],"id":1.0,"meta":{}}`))
+	if err != nil {
+		log.Fatal(err)
+	}
+	out, err := jcs.Format(v, "  ")
+	if err != nil {
+		log.Fatal(err)
+	}
+	fmt.Print(string(out))
+	// Output:
+	// {
+	//   "id": 1,
+	//   "meta": {},
+	//   "tags": [
+	//     "b",
+	//     "a"
+	//   ]
+	// }
+}
+
+func ExampleApplyPatch() {
+	doc, err := jcstoken.Parse([]byte(`{"name":"svc","replicas":2}`))
+	if err != nil {
+		log.Fatal(err)
+	}
+	ops, err := jcstoken.Parse([]byte(`[{"op":"test","path":"/replicas","value":2},{"op":"replace","path":"/replicas","value":3},{"op":"add","path":"/tags","value":["prod"]}]`))
+	if err != nil {
+		log.Fatal(err)
+	}
+	patch, err := jcs.ParsePatch(ops)
+	if err != nil {
+		log.Fatal(err)
+	}
+	out, err := jcs.ApplyPatch(doc, patch)
+	if err != nil {
+		log.Fatal(err)
+	}
+	canonical, err := jcs.Serialize(out)
+	if err != nil {
+		log.Fatal(err)
+	}
+	fmt.Println(string(canonical))
+	// Output: {"name":"svc","replicas":3,"tags":["prod"]}
+}
+
+func ExampleMergePatch() {
+	target, err := jcstoken.Parse([]byte(`{"title":"Goodbye!","author":{"givenName":"John","familyName":"Doe"}}`))
+	if err != nil {
+		log.Fatal(err)
+	}
+	patch, err := jcstoken.Parse([]byte(`{"title":"Hello!","author":{"familyName":null}}`))
+	if err != nil {
+		log.Fatal(err)
+	}
+	out, err := jcs.MergePatch(target, patch)
+	if err != nil {
+		log.Fatal(err)
+	}
+	canonical, err := jcs.Serialize(out)
+	if err != nil {
+		log.Fatal(err)
+	}
+	fmt.Println(string(canonical))
+	// Output: {"author":{"givenName":"John"},"title":"Hello!"}
+}
@@ -0,0 +1,106 @@
+package jcs
+
+import (
+	"github.com/lattice-substrate/json-canon/jcserr"
+	"github.com/lattice-substrate/json-canon/jcstoken"
+)
+
+// Format returns an indented view of the RFC 8785 canonical form of v for
+// reading: members in canonical UTF-16 order and numbers and strings in
+// their canonical encoding, with each member or element on its own line
+// prefixed by indent once per nesting level, ": " after member names, empty
+// objects and arrays on one line, and a final newline. indent may contain
+// only spaces and tabs.
+//
+// The view only adds insignificant whitespace, so canonicalizing it yields
+// exactly the bytes Serialize returns for v.
+//
+// FMT-VIEW-001: Format lays out the canonical form one member or element
+// per line.
+// FMT-ROUNDTRIP-001: Canonicalizing the Format output yields the canonical
+// bytes.
+func Format(v *jcstoken.Value, indent string) ([]byte, error) {
+	return FormatWithOptions(v, indent, nil)
+}
+
+// FormatWithOptions is like Format but validates the value tree against
+// caller-supplied bounds, as SerializeWithOptions does, with MaxOutputSize
+// bounding the indented view, newline included.
+func FormatWithOptions(v *jcstoken.Value, indent string, opts *jcstoken.Options) ([]byte, error) {
+	if v == nil {
+		return nil, jcserr.New(jcserr.InternalError, -1, "jcs: nil value")
+	}
+	for i := 0; i < len(indent); i++ {
+		if indent[i] != ' ' && indent[i] != '\t' {
+			return nil, jcserr.New(jcserr.InternalError, -1, "jcs: indent may contain only spaces and tabs")
+		}
+	}
+	limits := resolveSerializeLimits(opts)
+	if err := validateValueTree(v, 0, &serializeValidationState{}, limits); err != nil {
+		return nil, err
+	}
+	buf, err := formatValue(nil, v, indent, 0, limits.maxOutputSize)
+	if err != nil {
+		return nil, err
+	}
+	return boundOutput(append(buf, '\n'), limits.maxOutputSize)
+}
+
+// formatValue appends the indented view of v to buf, failing once buf grows
+// past limit bytes.
+func formatValue(buf []byte, v *jcstoken.Value, indent string, depth, limit int) ([]byte, error) {
+	switch {
+	case v.Kind == jcstoken.KindArray && len(v.Elems) > 0:
+		return formatArray(buf, v, indent, depth, limit)
+	case v.Kind == jcstoken.KindObject && len(v.Members) > 0:
+		return formatObject(buf, v, indent, depth, limit)
+	default:
+		return serializeValue(buf, v, limit)
+	}
+}
+
+func formatArray(buf []byte, v *jcstoken.Value, indent string, depth, limit int) ([]byte, error) {
+	buf = append(buf, '[')
+	for i := range v.Elems {
+		if i > 0 {
+			buf = append(buf, ',')
+		}
+		buf = formatNewline(buf, indent, depth+1)
+		var err error
+		buf, err = formatValue(buf, &v.Elems[i], indent, depth+1, limit)
+		if err != nil {
+			return nil, err
+		}
+	}
+	buf = formatNewline(buf, indent, depth)
+	return boundOutput(append(buf, ']'), limit)
+}
+
+// formatObject emits members in the order serializeObject does.
+func formatObject(buf []byte, v *jcstoken.Value, indent string, depth, limit int) ([]byte, error) {
+	sorted := sortMembers(v)
+	buf = append(buf, '{')
+	for i := range sorted {
+		if i > 0 {
+			buf = append(buf, ',')
+		}
+		buf = formatNewline(buf, indent, depth+1)
+		buf = serializeString(buf, sorted[i].member.Key)
+		buf = append(buf, ':', ' ')
+		var err error
+		buf, err = formatValue(buf, &sorted[i].member.Value, indent, depth+1, limit)
+		if err != nil {
+			return nil, err
+		}
+	}
+	buf = formatNewline(buf, indent, depth)
+	return boundOutput(append(buf, '}'), limit)
+}
+
+func formatNewline(buf []byte, indent string, depth int) []byte {
+	buf = append(buf, '\n')
+	for i := 0; i < depth; i++ {
+		buf = append(buf, indent...)
+	}
+	return buf
+}
@@ -0,0 +1,92 @@
+package jcs_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/lattice-substrate/json-canon/jcs"
+	"github.com/lattice-substrate/json-canon/jcserr"
+	"github.com/lattice-substrate/json-canon/jcstoken"
+)
+
+func formatJSON(t *testing.T, in, indent string) string {
+	t.Helper()
+	v, err := jcstoken.Parse([]byte(in))
+	if err != nil {
+		t.Fatalf("parse %q: %v", in, err)
+	}
+	out, err := jcs.Format(v, indent)
+	if err != nil {
+		t.Fatalf("Format(%s): %v", in, err)
+	}
+	return string(out)
+}
+
+// === FMT-VIEW-001: Format lays out the canonical form one member or element per line ===
+
+func TestFormat_FMT_VIEW_001(t *testing.T) {
+	cases := []struct {
+		in, indent, want string
+	}{
+		{`1.50`, "  ", "1.5\n"},
+		{`{}`, "  ", "{}\n"},
+		{`[ ]`, "  ", "[]\n"},
+		{`{"b":[],"a":{}}`, "  ", "{\n  \"a\": {},\n  \"b\": []\n}\n"},
+		{`[1,[2,{"k":null}]]`, "  ", "[\n  1,\n  [\n    2,\n    {\n      \"k\": null\n    }\n  ]\n]\n"},
+		{`{"\ue000":1,"\r":2,"\ud83d\ude00":3}`, "\t", "{\n\t\"\\r\": 2,\n\t\"\U0001F600\": 3,\n\t\"\ue000\": 1\n}\n"},
+		{`["a\u000fb","\/"]`, " ", "[\n \"a\\u000fb\",\n \"/\"\n]\n"},
+		{`[1E21,-0.000001]`, "", "[\n1e+21,\n-0.000001\n]\n"},
+	}
+	for _, tc := range cases {
+		if got := formatJSON(t, tc.in, tc.indent); got != tc.want {
+			t.Errorf("Format(%s, %q) = %q, want %q", tc.in, tc.indent, got, tc.want)
+		}
+	}
+}
+
+// === FMT-ROUNDTRIP-001: Canonicalizing the Format output yields the canonical bytes ===
+
+func TestFormat_FMT_ROUNDTRIP_001(t *testing.T) {
+	inputs := []string{
+		`{"z":[1,2,{"y":"\"{[,:]}\""}],"a":{"b":{"c":[]}},"":0}`,
+		`[-1.0e1,1e-7,123456789012345678901234567890,"\u0000\t\u2028"]`,
+		`{"\ue000":1,"\ud800\udc00":2,"a\\b":[true,false,null]}`,
+		`"plain"`,
+	}
+	for _, in := range inputs {
+		for _, indent := range []string{"", " ", "  ", "\t", " \t"} {
+			got, err := jcs.Canonicalize([]byte(formatJSON(t, in, indent)))
+			if err != nil {
+				t.Fatalf("canonicalize Format(%s, %q): %v", in, indent, err)
+			}
+			if want := canon(t, in); string(got) != want {
+				t.Fatalf("Format(%s, %q) canonicalizes to %s, want %s", in, indent, got, want)
+			}
+		}
+	}
+}
+
+func TestFormatRejectsInvalidIndentAndBounds(t *testing.T) {
+	v, err := jcstoken.Parse([]byte(`[[1]]`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, tc := range []struct {
+		indent string
+		opts   *jcstoken.Options
+		class  jcserr.FailureClass
+	}{
+		{"\n", nil, jcserr.InternalError},
+		{"//", nil, jcserr.InternalError},
+		{"  ", &jcstoken.Options{MaxDepth: 1}, jcserr.BoundExceeded},
+	} {
+		_, err := jcs.FormatWithOptions(v, tc.indent, tc.opts)
+		var je *jcserr.Error
+		if !errors.As(err, &je) || je.Class != tc.class {
+			t.Fatalf("FormatWithOptions(%q) = %v, want %s", tc.indent, err, tc.class)
+		}
+	}
+	if _, err := jcs.Format(nil, ""); err == nil {
+		t.Fatal("Format(nil) succeeded")
+	}
+}
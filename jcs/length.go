@@ -0,0 +1,175 @@
+package jcs
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/lattice-substrate/json-canon/jcserr"
+	"github.com/lattice-substrate/json-canon/jcsfloat"
+	"github.com/lattice-substrate/json-canon/jcstoken"
+)
+
+// CanonicalLength returns len(Serialize(v)), the length in bytes of the RFC
+// 8785 canonical form of v, without building the output. It fails exactly
+// when Serialize does.
+//
+// API-LEN-001: The length equals that of the serialized bytes.
+func CanonicalLength(v *jcstoken.Value) (int, error) {
+	return CanonicalLengthWithOptions(v, nil)
+}
+
+// CanonicalLengthWithOptions is like CanonicalLength but applies
+// caller-supplied bounds as SerializeWithOptions does, failing with
+// BOUND_EXCEEDED when the length exceeds MaxOutputSize.
+func CanonicalLengthWithOptions(v *jcstoken.Value, opts *jcstoken.Options) (int, error) {
+	if v == nil {
+		return 0, jcserr.New(jcserr.InternalError, -1, "jcs: nil value")
+	}
+	return canonicalLength(v, resolveSerializeLimits(opts))
+}
+
+// canonicalLength validates v against limits and returns the length of its
+// RFC 8785 canonical form.
+func canonicalLength(v *jcstoken.Value, limits serializeLimits) (int, error) {
+	if err := validateValueTree(v, 0, &serializeValidationState{}, limits); err != nil {
+		return 0, err
+	}
+	m := outputMeter{limit: limits.maxOutputSize}
+	if err := m.value(v); err != nil {
+		return 0, err
+	}
+	return m.n, nil
+}
+
+// boundOutput returns buf, or fails with BOUND_EXCEEDED once it has grown
+// past limit bytes. Serializers call it as they append each value, so they
+// stop emitting as soon as their output crosses MaxOutputSize.
+//
+// BOUND-OUTPUT-001: Output may not exceed MaxOutputSize.
+func boundOutput(buf []byte, limit int) ([]byte, error) {
+	if len(buf) > limit {
+		return nil, outputBoundError(limit)
+	}
+	return buf, nil
+}
+
+func outputBoundError(limit int) error {
+	return jcserr.New(jcserr.BoundExceeded, -1,
+		fmt.Sprintf("jcs: output size exceeds maximum %d bytes", limit))
+}
+
+// outputMeter totals the length of the RFC 8785 form of a validated value
+// tree without building it, failing as serializeValue does once the total
+// exceeds limit.
+type outputMeter struct {
+	n     int
+	limit int
+}
+
+// add counts k more bytes, failing if the total would exceed m.limit.
+func (m *outputMeter) add(k int) error {
+	if k > m.limit-m.n {
+		return outputBoundError(m.limit)
+	}
+	m.n += k
+	return nil
+}
+
+func (m *outputMeter) value(v *jcstoken.Value) error {
+	switch v.Kind {
+	case jcstoken.KindNull:
+		return m.add(len("null"))
+	case jcstoken.KindBool:
+		return m.add(len(v.Str))
+	case jcstoken.KindNumber:
+		k, err := canonicalNumberLength(v)
+		if err != nil {
+			return err
+		}
+		return m.add(k)
+	case jcstoken.KindString:
+		return m.add(canonicalStringLength(v.Str))
+	case jcstoken.KindArray:
+		return m.array(v)
+	case jcstoken.KindObject:
+		return m.object(v)
+	default:
+		return jcserr.New(jcserr.InternalError, -1, fmt.Sprintf("jcs: unknown value kind %d", v.Kind))
+	}
+}
+
+// array counts the brackets and commas of v, then its elements.
+func (m *outputMeter) array(v *jcstoken.Value) error {
+	if err := m.add(2 + separators(len(v.Elems))); err != nil {
+		return err
+	}
+	for i := range v.Elems {
+		if err := m.value(&v.Elems[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// object counts the braces, commas, and name separators of v, then its
+// members.
+func (m *outputMeter) object(v *jcstoken.Value) error {
+	if err := m.add(2 + separators(len(v.Members))); err != nil {
+		return err
+	}
+	for i := range v.Members {
+		if err := m.add(canonicalStringLength(v.Members[i].Key) + len(":")); err != nil {
+			return err
+		}
+		if err := m.value(&v.Members[i].Value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// separators returns the number of commas between n members or elements.
+func separators(n int) int {
+	return max(n-1, 0)
+}
+
+// canonicalStringLength returns the length serializeString emits for s.
+func canonicalStringLength(s string) int {
+	n := len(`""`) + len(s)
+	for i := 0; i < len(s); i++ {
+		switch b := s[i]; {
+		case b == '"' || b == '\\' || b == '\b' || b == '\t' || b == '\n' || b == '\f' || b == '\r':
+			n++
+		case b < 0x20:
+			n += len(`\u0000`) - 1
+		}
+	}
+	return n
+}
+
+// canonicalNumberLength returns the length serializeNumber emits for v.
+// Integers in the safe range are emitted in full, so only their digits are
+// counted.
+func canonicalNumberLength(v *jcstoken.Value) (int, error) {
+	if v.Num == math.Trunc(v.Num) && math.Abs(v.Num) <= maxSafeInteger {
+		return integerLength(v)
+	}
+	s, err := jcsfloat.FormatDouble(v.Num)
+	if err != nil {
+		return 0, jcserr.Wrap(err.Class, -1, "jcs: number serialization error", err)
+	}
+	return len(s), nil
+}
+
+// integerLength returns the number of characters in the decimal form of
+// the safe integer v.
+func integerLength(v *jcstoken.Value) (int, error) {
+	i, n := int64(v.Num), 1
+	if i < 0 {
+		i, n = -i, 2
+	}
+	for ; i >= 10; i /= 10 {
+		n++
+	}
+	return n, nil
+}
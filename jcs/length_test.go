@@ -0,0 +1,125 @@
+package jcs_test
+
+import (
+	"errors"
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/lattice-substrate/json-canon/jcs"
+	"github.com/lattice-substrate/json-canon/jcserr"
+	"github.com/lattice-substrate/json-canon/jcstoken"
+)
+
+func requireBoundExceeded(t *testing.T, what string, err error) {
+	t.Helper()
+	var je *jcserr.Error
+	if !errors.As(err, &je) || je.Class != jcserr.BoundExceeded {
+		t.Fatalf("%s: expected BOUND_EXCEEDED, got %v", what, err)
+	}
+}
+
+// === API-LEN-001: CanonicalLength equals the length of the serialized bytes ===
+
+func TestCanonicalLength_API_LEN_001(t *testing.T) {
+	for _, in := range []string{
+		`null`, `true`, `false`, `0`, `-1`, `1e20`, `1e21`, `-1.5e-7`, `0.000001`, `123456789012345678`,
+		`9007199254740991`, `-9007199254740993`, `4.5`, `""`, `"\u0000\b\t\n\f\r\u001f\"\\/é😀"`,
+		`[]`, `{}`, `[1,[2,[]],{}]`, `{"b":[1,{"\u0001":"x"}],"a":{"c":null}}`,
+	} {
+		v, err := jcstoken.Parse([]byte(in))
+		if err != nil {
+			t.Fatalf("parse %q: %v", in, err)
+		}
+		n, err := jcs.CanonicalLength(v)
+		if err != nil {
+			t.Fatalf("CanonicalLength(%q): %v", in, err)
+		}
+		if want := len(canon(t, in)); n != want {
+			t.Fatalf("CanonicalLength(%q) = %d, want %d", in, n, want)
+		}
+	}
+
+	for _, v := range []*jcstoken.Value{
+		nil,
+		{Kind: jcstoken.KindNumber, Num: math.NaN()},
+		{Kind: jcstoken.KindObject, Members: []jcstoken.Member{{Key: "a"}, {Key: "a"}}},
+	} {
+		_, want := jcs.Serialize(v)
+		if _, err := jcs.CanonicalLength(v); err == nil || err.Error() != want.Error() {
+			t.Fatalf("CanonicalLength error %v, want %v", err, want)
+		}
+	}
+
+	elems := make([]jcstoken.Value, 1000)
+	for i := range elems {
+		elems[i] = jcstoken.Value{Kind: jcstoken.KindString, Str: strings.Repeat("\n", 100)}
+	}
+	v := &jcstoken.Value{Kind: jcstoken.KindArray, Elems: elems}
+	if allocs := testing.AllocsPerRun(10, func() { _, _ = jcs.CanonicalLength(v) }); allocs != 0 {
+		t.Fatalf("CanonicalLength allocated %v times", allocs)
+	}
+}
+
+// === BOUND-OUTPUT-001: Serializers fail before exceeding MaxOutputSize ===
+
+func TestSerializeWithOptions_BOUND_OUTPUT_001(t *testing.T) {
+	// 1e20 expands to 21 digits: the canonical form is 23 bytes.
+	input := []byte(`[1e20]`)
+	v, err := jcstoken.Parse(input)
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	at, over := &jcstoken.Options{MaxOutputSize: 23}, &jcstoken.Options{MaxOutputSize: 22}
+	if out, err := jcs.SerializeWithOptions(v, at); err != nil || string(out) != "[100000000000000000000]" {
+		t.Fatalf("at the bound: %q %v", out, err)
+	}
+	if n, err := jcs.CanonicalLengthWithOptions(v, at); err != nil || n != 23 {
+		t.Fatalf("CanonicalLengthWithOptions at the bound: %d %v", n, err)
+	}
+	_, err = jcs.SerializeWithOptions(v, over)
+	requireBoundExceeded(t, "SerializeWithOptions", err)
+	_, err = jcs.CanonicalizeWithOptions(input, over)
+	requireBoundExceeded(t, "CanonicalizeWithOptions", err)
+	_, err = jcs.CanonicalLengthWithOptions(v, over)
+	requireBoundExceeded(t, "CanonicalLengthWithOptions", err)
+
+	// Each scheme and Format is bounded by the length of its own output.
+	v, err = jcstoken.Parse([]byte(`{"b":[1,-20],"a":"\u0001\""}`))
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	for _, s := range jcs.Schemes() {
+		out, err := s.Serialize(v)
+		if err != nil {
+			t.Fatalf("%s: %v", s.Name(), err)
+		}
+		if _, err := jcs.SerializeSchemeWithOptions(s, v, &jcstoken.Options{MaxOutputSize: len(out)}); err != nil {
+			t.Fatalf("%s at the bound: %v", s.Name(), err)
+		}
+		_, err = jcs.SerializeSchemeWithOptions(s, v, &jcstoken.Options{MaxOutputSize: len(out) - 1})
+		requireBoundExceeded(t, s.Name(), err)
+	}
+	out, err := jcs.Format(v, "\t")
+	if err != nil {
+		t.Fatalf("Format: %v", err)
+	}
+	if _, err := jcs.FormatWithOptions(v, "\t", &jcstoken.Options{MaxOutputSize: len(out)}); err != nil {
+		t.Fatalf("Format at the bound: %v", err)
+	}
+	_, err = jcs.FormatWithOptions(v, "\t", &jcstoken.Options{MaxOutputSize: len(out) - 1})
+	requireBoundExceeded(t, "FormatWithOptions", err)
+
+	patch, err := jcs.Diff(&jcstoken.Value{Kind: jcstoken.KindNull}, v)
+	if err != nil {
+		t.Fatalf("Diff: %v", err)
+	}
+	if out, err = patch.Serialize(); err != nil {
+		t.Fatalf("Patch.Serialize: %v", err)
+	}
+	if _, err := patch.SerializeWithOptions(&jcstoken.Options{MaxOutputSize: len(out)}); err != nil {
+		t.Fatalf("patch at the bound: %v", err)
+	}
+	_, err = patch.SerializeWithOptions(&jcstoken.Options{MaxOutputSize: len(out) - 1})
+	requireBoundExceeded(t, "Patch.SerializeWithOptions", err)
+}
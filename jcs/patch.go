@@ -0,0 +1,380 @@
+package jcs
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/lattice-substrate/json-canon/jcserr"
+	"github.com/lattice-substrate/json-canon/jcstoken"
+)
+
+// ParsePatch converts an RFC 6902 JSON Patch document into a Patch. The
+// document must be an array of operation objects, each with a string "op"
+// and "path", a "value" member for "add", "replace", and "test", and a string
+// "from" member for "move" and "copy". Other members are ignored. The patch
+// shares no storage with v.
+//
+// PATCH-PARSE-001: Malformed patch documents are rejected as INVALID_PATCH.
+func ParsePatch(v *jcstoken.Value) (Patch, error) {
+	if v == nil {
+		return nil, jcserr.New(jcserr.InternalError, -1, "jcs: nil value")
+	}
+	if v.Kind != jcstoken.KindArray {
+		return nil, jcserr.New(jcserr.InvalidPatch, -1, "jcs: patch document must be an array")
+	}
+	patch := make(Patch, 0, len(v.Elems))
+	for i := range v.Elems {
+		op, err := parseOperation(&v.Elems[i])
+		if err != nil {
+			return nil, operationError(i, err)
+		}
+		patch = append(patch, op)
+	}
+	return patch, nil
+}
+
+// parseOperation converts one operation object.
+func parseOperation(v *jcstoken.Value) (Operation, error) {
+	var op Operation
+	if v.Kind != jcstoken.KindObject {
+		return op, jcserr.New(jcserr.InvalidPatch, -1, "operation must be an object")
+	}
+	present, err := parseMembers(v, &op)
+	if err != nil {
+		return op, err
+	}
+	if !present["op"] || !present["path"] {
+		return op, jcserr.New(jcserr.InvalidPatch, -1, `operation requires "op" and "path"`)
+	}
+	if usesFrom(op.Op) && !present["from"] {
+		return op, jcserr.New(jcserr.InvalidPatch, -1, fmt.Sprintf(`%q operation requires "from"`, op.Op))
+	}
+	if !usesFrom(op.Op) {
+		op.From = ""
+	}
+	if !usesValue(op.Op) {
+		op.Value = nil
+	}
+	return op, checkOperation(&op)
+}
+
+// parseMembers stores the operation members of v in op and reports which
+// of them are present.
+func parseMembers(v *jcstoken.Value, op *Operation) (map[string]bool, error) {
+	present := make(map[string]bool, 4)
+	for i := range v.Members {
+		m := &v.Members[i]
+		var err error
+		switch m.Key {
+		case "op":
+			op.Op, err = memberString(m)
+		case "path":
+			op.Path, err = memberString(m)
+		case "from":
+			op.From, err = memberString(m)
+		case "value":
+			op.Value = m.Value.Clone()
+		default:
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		present[m.Key] = true
+	}
+	return present, nil
+}
+
+// memberString returns the string value of m.
+func memberString(m *jcstoken.Member) (string, error) {
+	if m.Value.Kind != jcstoken.KindString {
+		return "", jcserr.New(jcserr.InvalidPatch, -1, fmt.Sprintf("operation member %q must be a string", m.Key))
+	}
+	return m.Value.Str, nil
+}
+
+// checkOperation validates the op name, the pointer syntax of Path and
+// From, and the presence of Value.
+func checkOperation(op *Operation) error {
+	switch op.Op {
+	case "add", "remove", "replace", "move", "copy", "test":
+	default:
+		return jcserr.New(jcserr.InvalidPatch, -1, fmt.Sprintf("unknown operation %q", op.Op))
+	}
+	if usesValue(op.Op) && op.Value == nil {
+		return jcserr.New(jcserr.InvalidPatch, -1, fmt.Sprintf(`%q operation requires "value"`, op.Op))
+	}
+	if _, err := jcstoken.ParsePointer(op.Path); err != nil {
+		return err //nolint:wrapcheck // PATCH-PARSE-001: pointer errors are already classified.
+	}
+	if usesFrom(op.Op) {
+		if _, err := jcstoken.ParsePointer(op.From); err != nil {
+			return err //nolint:wrapcheck // PATCH-PARSE-001: pointer errors are already classified.
+		}
+	}
+	return nil
+}
+
+func usesValue(op string) bool {
+	return op == "add" || op == "replace" || op == "test"
+}
+
+func usesFrom(op string) bool {
+	return op == "move" || op == "copy"
+}
+
+// operationError prefixes err with the index of the operation that caused
+// it, keeping its failure class.
+func operationError(i int, err error) error {
+	var je *jcserr.Error
+	if !errors.As(err, &je) {
+		return jcserr.Wrap(jcserr.InternalError, -1, fmt.Sprintf("jcs: patch operation %d", i), err)
+	}
+	return jcserr.New(je.Class, -1, fmt.Sprintf("jcs: patch operation %d: %s", i, je.Message))
+}
+
+// ApplyPatch applies p to a copy of v and returns the result, validating v
+// and the result against the default bounds. v is never modified, and no
+// partial result is returned when an operation fails.
+//
+// PATCH-APPLY-001: Operations apply in order with RFC 6902 semantics.
+func ApplyPatch(v *jcstoken.Value, p Patch) (*jcstoken.Value, error) {
+	return ApplyPatchWithOptions(v, p, nil)
+}
+
+// ApplyPatchWithOptions is like ApplyPatch but validates against
+// caller-supplied bounds.
+//
+// A pointer that does not resolve fails with INVALID_POINTER, a "test"
+// operation whose value differs from the target fails with
+// PATCH_TEST_FAILED, and a result that leaves the input domain, such as an
+// object with duplicate keys or a tree beyond the bounds, fails with that
+// domain's class.
+//
+// PATCH-TEST-001: "test" compares values by their canonical serialization.
+// PATCH-DOMAIN-001: Patch results are validated against the input domain.
+func ApplyPatchWithOptions(v *jcstoken.Value, p Patch, opts *jcstoken.Options) (*jcstoken.Value, error) {
+	if v == nil {
+		return nil, jcserr.New(jcserr.InternalError, -1, "jcs: nil value")
+	}
+	limits := resolveSerializeLimits(opts)
+	if err := validateValueTree(v, 0, &serializeValidationState{}, limits); err != nil {
+		return nil, err
+	}
+	doc := v.Clone()
+	for i := range p {
+		if err := applyOperation(doc, &p[i]); err != nil {
+			return nil, operationError(i, err)
+		}
+	}
+	if err := validateValueTree(doc, 0, &serializeValidationState{}, limits); err != nil {
+		return nil, err
+	}
+	return doc, nil
+}
+
+// applyOperation applies op to doc in place.
+func applyOperation(doc *jcstoken.Value, op *Operation) error {
+	if err := checkOperation(op); err != nil {
+		return err
+	}
+	path, _ := jcstoken.ParsePointer(op.Path)
+	switch op.Op {
+	case "add":
+		return addValue(doc, path, op.Value.Clone())
+	case "remove":
+		_, err := removeValue(doc, path)
+		return err
+	case "replace":
+		target, err := path.Resolve(doc)
+		if err != nil {
+			return err //nolint:wrapcheck // PATCH-APPLY-001: pointer errors are already classified.
+		}
+		*target = *op.Value.Clone()
+		return nil
+	case "test":
+		target, err := path.Resolve(doc)
+		if err != nil {
+			return err //nolint:wrapcheck // PATCH-APPLY-001: pointer errors are already classified.
+		}
+		if !equalValues(target, op.Value) {
+			return jcserr.New(jcserr.PatchTestFailed, -1, fmt.Sprintf("value at %q differs", op.Path))
+		}
+		return nil
+	default:
+		from, _ := jcstoken.ParsePointer(op.From)
+		return transferValue(doc, op.Op, from, path)
+	}
+}
+
+// transferValue applies a "move" or "copy" operation from from to path.
+func transferValue(doc *jcstoken.Value, op string, from, path jcstoken.Pointer) error {
+	if op == "copy" {
+		src, err := from.Resolve(doc)
+		if err != nil {
+			return err //nolint:wrapcheck // PATCH-APPLY-001: pointer errors are already classified.
+		}
+		return addValue(doc, path, src.Clone())
+	}
+	if slices.Equal(from, path) {
+		_, err := from.Resolve(doc)
+		return err //nolint:wrapcheck // PATCH-APPLY-001: pointer errors are already classified.
+	}
+	if len(from) < len(path) && slices.Equal(from, path[:len(from)]) {
+		return jcserr.New(jcserr.InvalidPatch, -1, fmt.Sprintf("cannot move %q into its own child", from.String()))
+	}
+	v, err := removeValue(doc, from)
+	if err != nil {
+		return err
+	}
+	return addValue(doc, path, v)
+}
+
+// addValue adds v at path: it replaces the document root or an existing
+// object member, and inserts into an array before the indexed element or,
+// for "-", at the end.
+func addValue(doc *jcstoken.Value, path jcstoken.Pointer, v *jcstoken.Value) error {
+	if len(path) == 0 {
+		*doc = *v
+		return nil
+	}
+	parent, tok, err := resolveParent(doc, path)
+	if err != nil {
+		return err
+	}
+	switch parent.Kind {
+	case jcstoken.KindObject:
+		if i := parent.MemberIndex(tok); i >= 0 {
+			parent.Members[i].Value = *v
+		} else {
+			parent.Members = append(parent.Members, jcstoken.Member{Key: tok, Value: *v})
+		}
+		return nil
+	case jcstoken.KindArray:
+		if tok == "-" {
+			parent.Elems = append(parent.Elems, *v)
+			return nil
+		}
+		i, jerr := jcstoken.ArrayIndex(tok)
+		if jerr == nil && i > len(parent.Elems) {
+			jerr = jcserr.New(jcserr.InvalidPointer, -1,
+				fmt.Sprintf("array index %d out of range (length %d)", i, len(parent.Elems)))
+		}
+		if jerr != nil {
+			return pointerError(path, jerr)
+		}
+		parent.Elems = slices.Insert(parent.Elems, i, *v)
+		return nil
+	default:
+		return pointerError(path, jcserr.New(jcserr.InvalidPointer, -1, "cannot add to scalar value"))
+	}
+}
+
+// removeValue removes the value at path and returns it.
+func removeValue(doc *jcstoken.Value, path jcstoken.Pointer) (*jcstoken.Value, error) {
+	if len(path) == 0 {
+		return nil, jcserr.New(jcserr.InvalidPointer, -1, "cannot remove the document root")
+	}
+	parent, tok, err := resolveParent(doc, path)
+	if err != nil {
+		return nil, err
+	}
+	switch parent.Kind {
+	case jcstoken.KindObject:
+		i := parent.MemberIndex(tok)
+		if i < 0 {
+			return nil, pointerError(path, jcserr.New(jcserr.InvalidPointer, -1, "object member not found"))
+		}
+		v := parent.Members[i].Value
+		parent.Members = slices.Delete(parent.Members, i, i+1)
+		return &v, nil
+	case jcstoken.KindArray:
+		i, jerr := jcstoken.ArrayIndex(tok)
+		if jerr == nil && i >= len(parent.Elems) {
+			jerr = jcserr.New(jcserr.InvalidPointer, -1,
+				fmt.Sprintf("array index %d out of range (length %d)", i, len(parent.Elems)))
+		}
+		if jerr != nil {
+			return nil, pointerError(path, jerr)
+		}
+		v := parent.Elems[i]
+		parent.Elems = slices.Delete(parent.Elems, i, i+1)
+		return &v, nil
+	default:
+		return nil, pointerError(path, jcserr.New(jcserr.InvalidPointer, -1, "cannot descend into scalar value"))
+	}
+}
+
+// resolveParent returns the container of the value at the non-empty path
+// and the final reference token.
+func resolveParent(doc *jcstoken.Value, path jcstoken.Pointer) (*jcstoken.Value, string, error) {
+	parent, err := path[:len(path)-1].Resolve(doc)
+	if err != nil {
+		return nil, "", err //nolint:wrapcheck // PATCH-APPLY-001: pointer errors are already classified.
+	}
+	return parent, path[len(path)-1], nil
+}
+
+// pointerError reports err, which describes the final token of path, as an
+// INVALID_POINTER error naming the whole path.
+func pointerError(path jcstoken.Pointer, err *jcserr.Error) error {
+	return jcserr.New(jcserr.InvalidPointer, -1, fmt.Sprintf("json pointer %q: %s", path.String(), err.Message))
+}
+
+// MergePatch applies the RFC 7396 merge patch patch to a copy of target and
+// returns the result, validating both inputs and the result against the
+// default bounds. An object patch sets or recursively merges its members
+// into target and removes the members it sets to null; any other patch
+// replaces target whole.
+//
+// MERGE-APPLY-001: Merge patches apply with RFC 7396 semantics.
+func MergePatch(target, patch *jcstoken.Value) (*jcstoken.Value, error) {
+	return MergePatchWithOptions(target, patch, nil)
+}
+
+// MergePatchWithOptions is like MergePatch but validates against
+// caller-supplied bounds.
+func MergePatchWithOptions(target, patch *jcstoken.Value, opts *jcstoken.Options) (*jcstoken.Value, error) {
+	if target == nil || patch == nil {
+		return nil, jcserr.New(jcserr.InternalError, -1, "jcs: nil value")
+	}
+	limits := resolveSerializeLimits(opts)
+	for _, v := range []*jcstoken.Value{target, patch} {
+		if err := validateValueTree(v, 0, &serializeValidationState{}, limits); err != nil {
+			return nil, err
+		}
+	}
+	doc := target.Clone()
+	mergeValue(doc, patch)
+	if err := validateValueTree(doc, 0, &serializeValidationState{}, limits); err != nil {
+		return nil, err
+	}
+	return doc, nil
+}
+
+// mergeValue merges patch into target in place.
+func mergeValue(target, patch *jcstoken.Value) {
+	if patch.Kind != jcstoken.KindObject {
+		*target = *patch.Clone()
+		return
+	}
+	if target.Kind != jcstoken.KindObject {
+		*target = jcstoken.Value{Kind: jcstoken.KindObject}
+	}
+	for i := range patch.Members {
+		m := &patch.Members[i]
+		j := target.MemberIndex(m.Key)
+		if m.Value.Kind == jcstoken.KindNull {
+			if j >= 0 {
+				target.Members = slices.Delete(target.Members, j, j+1)
+			}
+			continue
+		}
+		if j < 0 {
+			target.Members = append(target.Members, jcstoken.Member{Key: m.Key})
+			j = len(target.Members) - 1
+		}
+		mergeValue(&target.Members[j].Value, &m.Value)
+	}
+}
@@ -0,0 +1,259 @@
+package jcs_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/lattice-substrate/json-canon/jcs"
+	"github.com/lattice-substrate/json-canon/jcserr"
+	"github.com/lattice-substrate/json-canon/jcstoken"
+)
+
+func parseJSON(t *testing.T, in string) *jcstoken.Value {
+	t.Helper()
+	v, err := jcstoken.Parse([]byte(in))
+	if err != nil {
+		t.Fatalf("parse %q: %v", in, err)
+	}
+	return v
+}
+
+// applyPatch parses doc and patch, applies the patch, and returns the
+// canonical result.
+func applyPatch(t *testing.T, doc, patch string) (string, error) {
+	t.Helper()
+	p, err := jcs.ParsePatch(parseJSON(t, patch))
+	if err != nil {
+		return "", err
+	}
+	out, err := jcs.ApplyPatch(parseJSON(t, doc), p)
+	if err != nil {
+		return "", err
+	}
+	return canonValue(t, out), nil
+}
+
+func requireFailureClass(t *testing.T, err error, class jcserr.FailureClass) {
+	t.Helper()
+	var je *jcserr.Error
+	if !errors.As(err, &je) || je.Class != class {
+		t.Fatalf("expected %s, got %v", class, err)
+	}
+}
+
+// === PATCH-PARSE-001: Malformed patch documents are rejected as INVALID_PATCH ===
+
+func TestParsePatch_PATCH_PARSE_001(t *testing.T) {
+	p, err := jcs.ParsePatch(parseJSON(t,
+		`[{"op":"move","from":"/a","path":"/b","value":1,"x":0},{"path":"/c","op":"test","value":null}]`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(p) != 2 || p[0].Value != nil || p[0].From != "/a" || p[1].Value == nil || p[1].Value.Kind != jcstoken.KindNull {
+		t.Fatalf("unexpected patch %+v", p)
+	}
+	out, err := p.Serialize()
+	if err != nil || string(out) != `[{"from":"/a","op":"move","path":"/b"},{"op":"test","path":"/c","value":null}]` {
+		t.Fatalf("Serialize() = %s, %v", out, err)
+	}
+	for _, patch := range []string{
+		`{}`,
+		`[1]`,
+		`[{"path":"/a"}]`,
+		`[{"op":"add","value":1}]`,
+		`[{"op":"add","path":"/a"}]`,
+		`[{"op":"copy","path":"/a"}]`,
+		`[{"op":"frobnicate","path":"/a"}]`,
+		`[{"op":1,"path":"/a"}]`,
+		`[{"op":"remove","path":["a"]}]`,
+	} {
+		_, err := jcs.ParsePatch(parseJSON(t, patch))
+		requireFailureClass(t, err, jcserr.InvalidPatch)
+	}
+	for _, patch := range []string{
+		`[{"op":"remove","path":"a"}]`,
+		`[{"op":"move","from":"/~2","path":"/a"}]`,
+	} {
+		_, err := jcs.ParsePatch(parseJSON(t, patch))
+		requireFailureClass(t, err, jcserr.InvalidPointer)
+	}
+}
+
+// === PATCH-APPLY-001: Operations apply in order with RFC 6902 semantics ===
+
+func TestApplyPatch_PATCH_APPLY_001(t *testing.T) {
+	// Cases follow RFC 6902 Appendix A.
+	cases := []struct {
+		doc, patch, want string
+	}{
+		{`{"foo":"bar"}`, `[{"op":"add","path":"/baz","value":"qux"}]`, `{"baz":"qux","foo":"bar"}`},
+		{`{"foo":["bar","baz"]}`, `[{"op":"add","path":"/foo/1","value":"qux"}]`, `{"foo":["bar","qux","baz"]}`},
+		{`{"baz":"qux","foo":"bar"}`, `[{"op":"remove","path":"/baz"}]`, `{"foo":"bar"}`},
+		{`{"foo":["bar","qux","baz"]}`, `[{"op":"remove","path":"/foo/1"}]`, `{"foo":["bar","baz"]}`},
+		{`{"baz":"qux","foo":"bar"}`, `[{"op":"replace","path":"/baz","value":"boo"}]`, `{"baz":"boo","foo":"bar"}`},
+		{`{"foo":{"bar":"baz","waldo":"fred"},"qux":{"corge":"grault"}}`,
+			`[{"op":"move","from":"/foo/waldo","path":"/qux/thud"}]`,
+			`{"foo":{"bar":"baz"},"qux":{"corge":"grault","thud":"fred"}}`},
+		{`{"foo":["all","grass","cows","eat"]}`, `[{"op":"move","from":"/foo/1","path":"/foo/3"}]`,
+			`{"foo":["all","cows","eat","grass"]}`},
+		{`{"baz":"qux","foo":["a",2,"c"]}`,
+			`[{"op":"test","path":"/baz","value":"qux"},{"op":"test","path":"/foo/1","value":2}]`,
+			`{"baz":"qux","foo":["a",2,"c"]}`},
+		{`{"foo":"bar"}`, `[{"op":"add","path":"/child","value":{"grandchild":{}}}]`,
+			`{"child":{"grandchild":{}},"foo":"bar"}`},
+		{`{"foo":["bar"]}`, `[{"op":"add","path":"/foo/-","value":["abc","def"]}]`, `{"foo":["bar",["abc","def"]]}`},
+		{`{"/":9,"~1":10}`, `[{"op":"test","path":"/~01","value":10}]`, `{"/":9,"~1":10}`},
+		{`{"a":1}`, `[{"op":"copy","from":"/a","path":"/b"},{"op":"add","path":"","value":[]}]`, `[]`},
+		{`{"a":{"b":1}}`, `[{"op":"move","from":"/a","path":"/a"}]`, `{"a":{"b":1}}`},
+		{`{"a":{"b":1}}`, `[{"op":"move","from":"/a/b","path":"/a"}]`, `{"a":1}`},
+		{`[1,2]`, `[{"op":"add","path":"/2","value":3},{"op":"replace","path":"","value":{"x":0}}]`, `{"x":0}`},
+	}
+	for _, tc := range cases {
+		got, err := applyPatch(t, tc.doc, tc.patch)
+		if err != nil || got != tc.want {
+			t.Errorf("ApplyPatch(%s, %s) = %s, %v, want %s", tc.doc, tc.patch, got, err, tc.want)
+		}
+	}
+	for _, tc := range []struct {
+		doc, patch string
+		class      jcserr.FailureClass
+	}{
+		{`{"foo":"bar"}`, `[{"op":"add","path":"/baz/bat","value":"qux"}]`, jcserr.InvalidPointer},
+		{`{"foo":"bar"}`, `[{"op":"remove","path":"/baz"}]`, jcserr.InvalidPointer},
+		{`{"foo":"bar"}`, `[{"op":"remove","path":""}]`, jcserr.InvalidPointer},
+		{`{"foo":"bar"}`, `[{"op":"replace","path":"/baz","value":1}]`, jcserr.InvalidPointer},
+		{`[1]`, `[{"op":"add","path":"/2","value":3}]`, jcserr.InvalidPointer},
+		{`[1]`, `[{"op":"add","path":"/01","value":3}]`, jcserr.InvalidPointer},
+		{`[1]`, `[{"op":"remove","path":"/-"}]`, jcserr.InvalidPointer},
+		{`1`, `[{"op":"add","path":"/a","value":3}]`, jcserr.InvalidPointer},
+		{`{"a":{"b":1}}`, `[{"op":"move","from":"/a","path":"/a/b/c"}]`, jcserr.InvalidPatch},
+		{`{"a":1}`, `[{"op":"copy","from":"/b","path":"/c"}]`, jcserr.InvalidPointer},
+	} {
+		_, err := applyPatch(t, tc.doc, tc.patch)
+		requireFailureClass(t, err, tc.class)
+	}
+}
+
+func TestApplyPatchIsAtomic(t *testing.T) {
+	doc := parseJSON(t, `{"a":[1]}`)
+	p, err := jcs.ParsePatch(parseJSON(t, `[{"op":"add","path":"/a/-","value":2},{"op":"remove","path":"/b"}]`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = jcs.ApplyPatch(doc, p)
+	requireFailureClass(t, err, jcserr.InvalidPointer)
+	var je *jcserr.Error
+	if errors.As(err, &je) && je.Message != `jcs: patch operation 1: json pointer "/b": object member not found` {
+		t.Fatalf("unexpected message %q", je.Message)
+	}
+	if got := canonValue(t, doc); got != `{"a":[1]}` {
+		t.Fatalf("input modified: %s", got)
+	}
+	// The result shares no storage with the patch values.
+	p, err = jcs.ParsePatch(parseJSON(t, `[{"op":"add","path":"/b","value":[1]}]`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	out, err := jcs.ApplyPatch(doc, p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p[0].Value.Elems[0].Num = 2
+	if got := canonValue(t, out); got != `{"a":[1],"b":[1]}` {
+		t.Fatalf("result changed with patch: %s", got)
+	}
+}
+
+// === PATCH-TEST-001: "test" compares values by their canonical serialization ===
+
+func TestApplyPatch_PATCH_TEST_001(t *testing.T) {
+	// Member order and number spelling do not matter.
+	if _, err := applyPatch(t, `{"a":{"x":1,"y":[1e2]}}`, `[{"op":"test","path":"/a","value":{"y":[100.0],"x":1}}]`); err != nil {
+		t.Fatalf("equal values: %v", err)
+	}
+	for _, tc := range [][2]string{
+		{`{"a":1}`, `[{"op":"test","path":"/a","value":"1"}]`},
+		{`{"a":[1,2]}`, `[{"op":"test","path":"/a","value":[2,1]}]`},
+		{`{"a":{"x":1}}`, `[{"op":"test","path":"/a","value":{"x":1,"y":null}}]`},
+		{`{"a":null}`, `[{"op":"test","path":"/a","value":false}]`},
+	} {
+		_, err := applyPatch(t, tc[0], tc[1])
+		requireFailureClass(t, err, jcserr.PatchTestFailed)
+	}
+	_, err := applyPatch(t, `{}`, `[{"op":"test","path":"/a","value":null}]`)
+	requireFailureClass(t, err, jcserr.InvalidPointer)
+}
+
+// === PATCH-DOMAIN-001: Patch results are validated against the input domain ===
+
+func TestApplyPatch_PATCH_DOMAIN_001(t *testing.T) {
+	doc := parseJSON(t, `{"a":1}`)
+	dup := jcs.Patch{{Op: "add", Path: "/b", Value: &jcstoken.Value{Kind: jcstoken.KindObject, Members: []jcstoken.Member{
+		{Key: "k", Value: jcstoken.Value{Kind: jcstoken.KindNull}},
+		{Key: "k", Value: jcstoken.Value{Kind: jcstoken.KindNull}},
+	}}}}
+	_, err := jcs.ApplyPatch(doc, dup)
+	requireFailureClass(t, err, jcserr.DuplicateKey)
+	lone := jcs.Patch{{Op: "replace", Path: "/a", Value: &jcstoken.Value{Kind: jcstoken.KindString, Str: "\xed\xa0\x80"}}}
+	_, err = jcs.ApplyPatch(doc, lone)
+	if err == nil {
+		t.Fatal("expected error for invalid string")
+	}
+	deep, err := jcs.ParsePatch(parseJSON(t, `[{"op":"add","path":"/b","value":[[1]]}]`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = jcs.ApplyPatchWithOptions(doc, deep, &jcstoken.Options{MaxDepth: 2})
+	requireFailureClass(t, err, jcserr.BoundExceeded)
+	if _, err := jcs.ApplyPatchWithOptions(doc, deep, &jcstoken.Options{MaxDepth: 3}); err != nil {
+		t.Fatalf("within bounds: %v", err)
+	}
+	_, err = jcs.ApplyPatch(doc, jcs.Patch{{Op: "add", Path: "/b"}})
+	requireFailureClass(t, err, jcserr.InvalidPatch)
+	if _, err := jcs.ApplyPatch(nil, nil); err == nil {
+		t.Fatal("expected error for nil value")
+	}
+}
+
+// === MERGE-APPLY-001: Merge patches apply with RFC 7396 semantics ===
+
+func TestMergePatch_MERGE_APPLY_001(t *testing.T) {
+	// Cases follow RFC 7396 Appendix A.
+	cases := [][3]string{
+		{`{"a":"b"}`, `{"a":"c"}`, `{"a":"c"}`},
+		{`{"a":"b"}`, `{"b":"c"}`, `{"a":"b","b":"c"}`},
+		{`{"a":"b"}`, `{"a":null}`, `{}`},
+		{`{"a":"b","b":"c"}`, `{"a":null}`, `{"b":"c"}`},
+		{`{"a":["b"]}`, `{"a":"c"}`, `{"a":"c"}`},
+		{`{"a":"c"}`, `{"a":["b"]}`, `{"a":["b"]}`},
+		{`{"a":{"b":"c"}}`, `{"a":{"b":"d","c":null}}`, `{"a":{"b":"d"}}`},
+		{`{"a":[{"b":"c"}]}`, `{"a":[1]}`, `{"a":[1]}`},
+		{`["a","b"]`, `["c","d"]`, `["c","d"]`},
+		{`{"a":"b"}`, `["c"]`, `["c"]`},
+		{`{"a":"foo"}`, `null`, `null`},
+		{`{"a":"foo"}`, `"bar"`, `"bar"`},
+		{`{"e":null}`, `{"a":1}`, `{"a":1,"e":null}`},
+		{`[1,2]`, `{"a":"b","c":null}`, `{"a":"b"}`},
+		{`{}`, `{"a":{"bb":{"ccc":null}}}`, `{"a":{"bb":{}}}`},
+	}
+	for _, tc := range cases {
+		target := parseJSON(t, tc[0])
+		out, err := jcs.MergePatch(target, parseJSON(t, tc[1]))
+		if err != nil {
+			t.Fatalf("MergePatch(%s, %s): %v", tc[0], tc[1], err)
+		}
+		if got, want := canonValue(t, out), canon(t, tc[2]); got != want {
+			t.Errorf("MergePatch(%s, %s) = %s, want %s", tc[0], tc[1], got, want)
+		}
+		if got := canonValue(t, target); got != canon(t, tc[0]) {
+			t.Errorf("MergePatch modified target %s to %s", tc[0], got)
+		}
+	}
+	_, err := jcs.MergePatchWithOptions(parseJSON(t, `{}`), parseJSON(t, `{"a":{"b":{}}}`), &jcstoken.Options{MaxDepth: 1})
+	requireFailureClass(t, err, jcserr.BoundExceeded)
+	_, err = jcs.MergePatchWithOptions(parseJSON(t, `{"a":{"b":{}}}`), parseJSON(t, `{"a":null}`), &jcstoken.Options{MaxDepth: 1})
+	requireFailureClass(t, err, jcserr.BoundExceeded)
+	if _, err := jcs.MergePatch(nil, parseJSON(t, `{}`)); err == nil {
+		t.Fatal("expected error for nil value")
+	}
+}
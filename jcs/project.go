@@ -0,0 +1,172 @@
+package jcs
+
+import (
+	"fmt"
+
+	"github.com/lattice-substrate/json-canon/jcserr"
+	"github.com/lattice-substrate/json-canon/jcstoken"
+)
+
+// Projection selects which parts of a value tree are emitted by canonical
+// serialization. Signature schemes such as JSF and the W3C Data Integrity JCS
+// cryptosuites canonicalize a document minus its signature or proof member;
+// a Projection expresses that without the caller cloning and mutating the
+// tree.
+//
+// Include is applied first, then exclusions. Pointers use RFC 6901 syntax and
+// refer to positions in the original document; array indexes are therefore
+// original indexes even when earlier elements are dropped.
+type Projection struct {
+	// ExcludeNames removes object members with any of these names at every
+	// depth.
+	ExcludeNames []string
+	// Exclude removes the values at these pointers. Pointers that do not
+	// resolve are ignored; the document root cannot be excluded.
+	Exclude []string
+	// Include, when non-empty, keeps only the values at these pointers and
+	// the containers on the path to them. Every pointer must resolve.
+	Include []string
+}
+
+// CanonicalizeProjected parses input with opts, applies p, and returns the
+// canonical bytes of the projected value. A nil p is equivalent to
+// CanonicalizeWithOptions.
+//
+// PROJ-APPLY-001: Projection precedes canonical emission.
+func CanonicalizeProjected(input []byte, p *Projection, opts *jcstoken.Options) ([]byte, error) {
+	v, err := jcstoken.ParseWithOptions(input, opts)
+	if err != nil {
+		return nil, err //nolint:wrapcheck // PROJ-APPLY-001: pass through jcstoken parse errors unchanged.
+	}
+	return SerializeProjected(v, p, opts)
+}
+
+// SerializeProjected applies p to v and serializes the result canonically.
+// The input tree is never modified.
+func SerializeProjected(v *jcstoken.Value, p *Projection, opts *jcstoken.Options) ([]byte, error) {
+	projected, err := Project(v, p)
+	if err != nil {
+		return nil, err
+	}
+	return serializeInto(nil, projected, opts)
+}
+
+// Project returns a copy of v reduced by p. The result shares no storage with
+// v. A nil or empty p returns a plain deep copy.
+//
+// PROJ-SELECT-001: Include keeps only the selected values and their ancestors.
+// PROJ-EXCLUDE-001: Exclude and ExcludeNames drop members before emission.
+func Project(v *jcstoken.Value, p *Projection) (*jcstoken.Value, error) {
+	if v == nil {
+		return nil, jcserr.New(jcserr.InternalError, -1, "jcs: nil value")
+	}
+	if p == nil {
+		return v.Clone(), nil
+	}
+	pr, err := newProjector(v, p)
+	if err != nil {
+		return nil, err
+	}
+	out := pr.project(v, "", pr.include == nil)
+	return &out, nil
+}
+
+type projector struct {
+	names    map[string]struct{}
+	exclude  map[string]struct{}
+	include  map[string]struct{}
+	ancestor map[string]struct{}
+}
+
+func newProjector(v *jcstoken.Value, p *Projection) (*projector, error) {
+	pr := &projector{
+		names:   make(map[string]struct{}, len(p.ExcludeNames)),
+		exclude: make(map[string]struct{}, len(p.Exclude)),
+	}
+	for _, name := range p.ExcludeNames {
+		pr.names[name] = struct{}{}
+	}
+	for _, s := range p.Exclude {
+		ptr, err := jcstoken.ParsePointer(s)
+		if err != nil {
+			return nil, err //nolint:wrapcheck // PROJ-EXCLUDE-001: preserve pointer failure class unchanged.
+		}
+		if len(ptr) == 0 {
+			return nil, jcserr.New(jcserr.InvalidPointer, -1, "jcs: the document root cannot be excluded")
+		}
+		pr.exclude[ptr.String()] = struct{}{}
+	}
+	if len(p.Include) > 0 {
+		if err := pr.addIncludes(v, p.Include); err != nil {
+			return nil, err
+		}
+	}
+	return pr, nil
+}
+
+func (pr *projector) addIncludes(v *jcstoken.Value, include []string) error {
+	pr.include = make(map[string]struct{}, len(include))
+	pr.ancestor = make(map[string]struct{})
+	for _, s := range include {
+		ptr, err := jcstoken.ParsePointer(s)
+		if err != nil {
+			return err //nolint:wrapcheck // PROJ-SELECT-001: preserve pointer failure class unchanged.
+		}
+		if _, err := ptr.Resolve(v); err != nil {
+			return jcserr.Wrap(jcserr.InvalidPointer, -1, fmt.Sprintf("jcs: include pointer %q does not resolve", s), err)
+		}
+		pr.include[ptr.String()] = struct{}{}
+		for i := 0; i < len(ptr); i++ {
+			pr.ancestor[ptr[:i].String()] = struct{}{}
+		}
+	}
+	return nil
+}
+
+// keep reports whether the value at path is emitted and whether its whole
+// subtree is selected.
+func (pr *projector) keep(path string, selected bool) (bool, bool) {
+	if _, ok := pr.exclude[path]; ok {
+		return false, false
+	}
+	if selected {
+		return true, true
+	}
+	if _, ok := pr.include[path]; ok {
+		return true, true
+	}
+	_, ok := pr.ancestor[path]
+	return ok, false
+}
+
+func (pr *projector) project(v *jcstoken.Value, path string, selected bool) jcstoken.Value {
+	if _, ok := pr.include[path]; ok {
+		selected = true
+	}
+	switch v.Kind {
+	case jcstoken.KindObject:
+		out := jcstoken.Value{Kind: jcstoken.KindObject, Members: make([]jcstoken.Member, 0, len(v.Members))}
+		for i := range v.Members {
+			m := &v.Members[i]
+			if _, drop := pr.names[m.Key]; drop {
+				continue
+			}
+			child := path + jcstoken.Pointer{m.Key}.String()
+			if ok, sel := pr.keep(child, selected); ok {
+				out.Members = append(out.Members, jcstoken.Member{Key: m.Key, Value: pr.project(&m.Value, child, sel)})
+			}
+		}
+		return out
+	case jcstoken.KindArray:
+		out := jcstoken.Value{Kind: jcstoken.KindArray, Elems: make([]jcstoken.Value, 0, len(v.Elems))}
+		for i := range v.Elems {
+			child := fmt.Sprintf("%s/%d", path, i)
+			if ok, sel := pr.keep(child, selected); ok {
+				out.Elems = append(out.Elems, pr.project(&v.Elems[i], child, sel))
+			}
+		}
+		return out
+	default:
+		return *v
+	}
+}
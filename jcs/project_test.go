@@ -0,0 +1,119 @@
+package jcs_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/lattice-substrate/json-canon/jcs"
+	"github.com/lattice-substrate/json-canon/jcserr"
+	"github.com/lattice-substrate/json-canon/jcstoken"
+)
+
+const projectDoc = `{"id":"urn:x","proof":{"type":"DataIntegrityProof","proofValue":"z1"},"items":[{"n":1,"sig":"a"},{"n":2,"sig":"b"},{"n":3}],"meta":{"sig":"c","v":1}}`
+
+func projected(t *testing.T, in string, p *jcs.Projection) string {
+	t.Helper()
+	out, err := jcs.CanonicalizeProjected([]byte(in), p, nil)
+	if err != nil {
+		t.Fatalf("CanonicalizeProjected(%+v): %v", p, err)
+	}
+	return string(out)
+}
+
+func projectErrClass(t *testing.T, in string, p *jcs.Projection) jcserr.FailureClass {
+	t.Helper()
+	_, err := jcs.CanonicalizeProjected([]byte(in), p, nil)
+	var je *jcserr.Error
+	if !errors.As(err, &je) {
+		t.Fatalf("expected *jcserr.Error for %+v, got %T: %v", p, err, err)
+	}
+	return je.Class
+}
+
+// === PROJ-APPLY-001: Projection precedes canonical emission ===
+
+func TestCanonicalizeProjected_PROJ_APPLY_001(t *testing.T) {
+	if got, want := projected(t, projectDoc, nil), canon(t, projectDoc); got != want {
+		t.Fatalf("nil projection: got %s want %s", got, want)
+	}
+	if got, want := projected(t, projectDoc, &jcs.Projection{}), canon(t, projectDoc); got != want {
+		t.Fatalf("empty projection: got %s want %s", got, want)
+	}
+	// Bounds apply to the parsed input before projection.
+	_, err := jcs.CanonicalizeProjected([]byte(projectDoc), &jcs.Projection{Exclude: []string{"/items"}},
+		&jcstoken.Options{MaxDepth: 2})
+	var je *jcserr.Error
+	if !errors.As(err, &je) || je.Class != jcserr.BoundExceeded {
+		t.Fatalf("expected BOUND_EXCEEDED, got %v", err)
+	}
+}
+
+func TestProjectDoesNotModifyInput(t *testing.T) {
+	v, err := jcstoken.Parse([]byte(projectDoc))
+	if err != nil {
+		t.Fatal(err)
+	}
+	before := canon(t, projectDoc)
+	if _, err := jcs.SerializeProjected(v, &jcs.Projection{Exclude: []string{"/proof"}, ExcludeNames: []string{"sig"}}, nil); err != nil {
+		t.Fatal(err)
+	}
+	after, err := jcs.Serialize(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(after) != before {
+		t.Fatalf("input tree modified: %s", after)
+	}
+}
+
+// === PROJ-EXCLUDE-001: Exclusion by pointer and by member name ===
+
+func TestProject_PROJ_EXCLUDE_001(t *testing.T) {
+	cases := []struct {
+		p    jcs.Projection
+		want string
+	}{
+		{jcs.Projection{Exclude: []string{"/proof"}},
+			`{"id":"urn:x","items":[{"n":1,"sig":"a"},{"n":2,"sig":"b"},{"n":3}],"meta":{"sig":"c","v":1}}`},
+		{jcs.Projection{Exclude: []string{"/proof", "/absent"}, ExcludeNames: []string{"sig"}},
+			`{"id":"urn:x","items":[{"n":1},{"n":2},{"n":3}],"meta":{"v":1}}`},
+		// Indexes refer to the original document.
+		{jcs.Projection{Exclude: []string{"/items/0", "/items/1", "/proof", "/meta"}},
+			`{"id":"urn:x","items":[{"n":3}]}`},
+	}
+	for _, tc := range cases {
+		if got := projected(t, projectDoc, &tc.p); got != tc.want {
+			t.Fatalf("%+v:\n got %s\nwant %s", tc.p, got, tc.want)
+		}
+	}
+	for _, bad := range []string{"", "proof", "/~2"} {
+		if c := projectErrClass(t, projectDoc, &jcs.Projection{Exclude: []string{bad}}); c != jcserr.InvalidPointer {
+			t.Fatalf("exclude %q: expected INVALID_POINTER, got %s", bad, c)
+		}
+	}
+}
+
+// === PROJ-SELECT-001: Include keeps selected values and their ancestors ===
+
+func TestProject_PROJ_SELECT_001(t *testing.T) {
+	cases := []struct {
+		p    jcs.Projection
+		want string
+	}{
+		{jcs.Projection{Include: []string{"/id", "/meta/v"}}, `{"id":"urn:x","meta":{"v":1}}`},
+		{jcs.Projection{Include: []string{"/items/2", "/items/0/n"}}, `{"items":[{"n":1},{"n":3}]}`},
+		{jcs.Projection{Include: []string{""}, Exclude: []string{"/items", "/meta"}, ExcludeNames: []string{"proofValue"}},
+			`{"id":"urn:x","proof":{"type":"DataIntegrityProof"}}`},
+		{jcs.Projection{Include: []string{"/meta"}, ExcludeNames: []string{"sig"}}, `{"meta":{"v":1}}`},
+	}
+	for _, tc := range cases {
+		if got := projected(t, projectDoc, &tc.p); got != tc.want {
+			t.Fatalf("%+v:\n got %s\nwant %s", tc.p, got, tc.want)
+		}
+	}
+	for _, bad := range []string{"/absent", "/items/3", "id"} {
+		if c := projectErrClass(t, projectDoc, &jcs.Projection{Include: []string{bad}}); c != jcserr.InvalidPointer {
+			t.Fatalf("include %q: expected INVALID_POINTER, got %s", bad, c)
+		}
+	}
+}
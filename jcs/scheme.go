@@ -0,0 +1,232 @@
+package jcs
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+
+	"github.com/lattice-substrate/json-canon/jcserr"
+	"github.com/lattice-substrate/json-canon/jcstoken"
+)
+
+// Scheme is a canonical JSON serialization of jcstoken.Value trees. Each
+// scheme defines its own key order, string escaping, and number domain; a
+// value outside a scheme's domain fails with SCHEME_DOMAIN.
+//
+// SCHEME-API-001: Every scheme serializes the same parsed Value tree.
+type Scheme interface {
+	// Name returns the stable scheme identifier accepted by LookupScheme.
+	Name() string
+	// Serialize returns the canonical bytes of v under the scheme.
+	Serialize(v *jcstoken.Value) ([]byte, error)
+}
+
+// Built-in schemes.
+var (
+	// RFC8785 is the JSON Canonicalization Scheme and the default scheme.
+	// Its Serialize method is Serialize.
+	RFC8785 Scheme = rfc8785Scheme{}
+	// OLPC is OLPC canonical JSON as used by TUF and in-toto metadata.
+	OLPC Scheme = olpcScheme{}
+	// Matrix is the Matrix specification's canonical JSON for signing.
+	Matrix Scheme = matrixScheme{}
+	// Decimal is the DecimalProfile: RFC 8785 with numbers kept as exact
+	// decimals. Parse with jcstoken.NumberDecimal to keep them.
+	Decimal Scheme = decimalScheme{}
+)
+
+// maxSafeInteger is 2^53-1, the largest integer n such that n and n+1 are
+// both exactly representable in binary64.
+const maxSafeInteger = 1<<53 - 1
+
+// Schemes returns the built-in schemes, default first.
+func Schemes() []Scheme {
+	return []Scheme{RFC8785, OLPC, Matrix, Decimal}
+}
+
+// SerializeSchemeWithOptions is like s.Serialize but validates the value
+// tree against caller-supplied bounds, as SerializeWithOptions does. A Scheme
+// defined outside this package is serialized with its own Serialize method.
+//
+// SCHEME-API-002: Built-in schemes validate against caller-supplied bounds.
+func SerializeSchemeWithOptions(s Scheme, v *jcstoken.Value, opts *jcstoken.Options) ([]byte, error) {
+	if b, ok := s.(boundedScheme); ok {
+		return b.serializeWithOptions(v, opts)
+	}
+	return s.Serialize(v) //nolint:wrapcheck // SCHEME-API-002: pass through the scheme's own errors unchanged.
+}
+
+// boundedScheme is implemented by the built-in schemes, whose Serialize
+// methods call serializeWithOptions with nil options: default value bounds
+// and no output bound.
+type boundedScheme interface {
+	serializeWithOptions(v *jcstoken.Value, opts *jcstoken.Options) ([]byte, error)
+}
+
+// LookupScheme returns the built-in scheme with the given name.
+func LookupScheme(name string) (Scheme, bool) {
+	for _, s := range Schemes() {
+		if s.Name() == name {
+			return s, true
+		}
+	}
+	return nil, false
+}
+
+type rfc8785Scheme struct{}
+
+func (rfc8785Scheme) Name() string { return "rfc8785" }
+
+func (rfc8785Scheme) Serialize(v *jcstoken.Value) ([]byte, error) {
+	return Serialize(v)
+}
+
+func (rfc8785Scheme) serializeWithOptions(v *jcstoken.Value, opts *jcstoken.Options) ([]byte, error) {
+	return serializeInto(nil, v, opts)
+}
+
+// olpcScheme implements OLPC canonical JSON: members sorted by Unicode code
+// point, no insignificant whitespace, strings escaping only '"' and '\', and
+// integers only.
+//
+// SCHEME-OLPC-001: Key order, whitespace, and string escaping.
+// SCHEME-OLPC-002: Numbers are integers.
+type olpcScheme struct{}
+
+func (olpcScheme) Name() string { return "olpc" }
+
+func (s olpcScheme) Serialize(v *jcstoken.Value) ([]byte, error) {
+	return s.serializeWithOptions(v, nil)
+}
+
+func (olpcScheme) serializeWithOptions(v *jcstoken.Value, opts *jcstoken.Options) ([]byte, error) {
+	return serializeScheme(v, "olpc", appendOLPCString, opts)
+}
+
+// matrixScheme implements Matrix canonical JSON: members sorted by Unicode
+// code point, no insignificant whitespace, JSON short escapes, and integers
+// in [-(2^53)+1, 2^53-1].
+//
+// SCHEME-MATRIX-001: Key order, whitespace, and string escaping.
+// SCHEME-MATRIX-002: Numbers are integers in the interoperable range.
+type matrixScheme struct{}
+
+func (matrixScheme) Name() string { return "matrix" }
+
+func (s matrixScheme) Serialize(v *jcstoken.Value) ([]byte, error) {
+	return s.serializeWithOptions(v, nil)
+}
+
+func (matrixScheme) serializeWithOptions(v *jcstoken.Value, opts *jcstoken.Options) ([]byte, error) {
+	// Matrix escapes strings exactly as RFC 8785 does.
+	return serializeScheme(v, "matrix", serializeString, opts)
+}
+
+// serializeScheme validates v as SerializeWithOptions does and emits it with
+// members sorted by code point, integer-only numbers, and the given string
+// encoder.
+func serializeScheme(v *jcstoken.Value, name string, appendString func([]byte, string) []byte, opts *jcstoken.Options) ([]byte, error) {
+	if v == nil {
+		return nil, jcserr.New(jcserr.InternalError, -1, "jcs: nil value")
+	}
+	limits := resolveSerializeLimits(opts)
+	if err := validateValueTree(v, 0, &serializeValidationState{}, limits); err != nil {
+		return nil, err
+	}
+	e := &schemeEncoder{name: name, appendString: appendString, limit: limits.maxOutputSize}
+	return e.appendValue(nil, v)
+}
+
+// schemeEncoder emits a scheme's form of a value tree, failing once the
+// output grows past limit bytes.
+type schemeEncoder struct {
+	name         string
+	appendString func([]byte, string) []byte
+	limit        int
+}
+
+func (e *schemeEncoder) appendValue(buf []byte, v *jcstoken.Value) ([]byte, error) {
+	switch v.Kind {
+	case jcstoken.KindNull:
+		return boundOutput(append(buf, "null"...), e.limit)
+	case jcstoken.KindBool:
+		return boundOutput(append(buf, v.Str...), e.limit)
+	case jcstoken.KindNumber:
+		buf, err := e.appendInteger(buf, v.Num)
+		if err != nil {
+			return nil, err
+		}
+		return boundOutput(buf, e.limit)
+	case jcstoken.KindString:
+		return boundOutput(e.appendString(buf, v.Str), e.limit)
+	case jcstoken.KindArray:
+		buf = append(buf, '[')
+		for i := range v.Elems {
+			if i > 0 {
+				buf = append(buf, ',')
+			}
+			var err error
+			if buf, err = e.appendValue(buf, &v.Elems[i]); err != nil {
+				return nil, err
+			}
+		}
+		return boundOutput(append(buf, ']'), e.limit)
+	case jcstoken.KindObject:
+		return e.appendObject(buf, v)
+	default:
+		return nil, jcserr.New(jcserr.InternalError, -1, fmt.Sprintf("jcs: unknown value kind %d", v.Kind))
+	}
+}
+
+// appendObject emits members in Unicode code point order of their names,
+// which for valid UTF-8 equals bytewise order.
+func (e *schemeEncoder) appendObject(buf []byte, v *jcstoken.Value) ([]byte, error) {
+	order := make([]int, len(v.Members))
+	for i := range order {
+		order[i] = i
+	}
+	sort.Slice(order, func(i, j int) bool {
+		return v.Members[order[i]].Key < v.Members[order[j]].Key
+	})
+	buf = append(buf, '{')
+	for n, i := range order {
+		if n > 0 {
+			buf = append(buf, ',')
+		}
+		buf = e.appendString(buf, v.Members[i].Key)
+		buf = append(buf, ':')
+		var err error
+		if buf, err = e.appendValue(buf, &v.Members[i].Value); err != nil {
+			return nil, err
+		}
+	}
+	return boundOutput(append(buf, '}'), e.limit)
+}
+
+// appendInteger emits f in decimal when it is an integer in the safe range
+// and rejects every other number with SCHEME_DOMAIN.
+func (e *schemeEncoder) appendInteger(buf []byte, f float64) ([]byte, error) {
+	if f != math.Trunc(f) {
+		return nil, jcserr.New(jcserr.SchemeDomain, -1,
+			fmt.Sprintf("jcs: %s canonical JSON does not allow non-integer number %v", e.name, f))
+	}
+	if math.Abs(f) > maxSafeInteger {
+		return nil, jcserr.New(jcserr.SchemeDomain, -1,
+			fmt.Sprintf("jcs: %s canonical JSON integer %v is outside [-(2^53)+1, 2^53-1]", e.name, f))
+	}
+	return strconv.AppendInt(buf, int64(f), 10), nil
+}
+
+// appendOLPCString escapes only '"' and '\'; every other character, including
+// control characters, is emitted as raw UTF-8.
+func appendOLPCString(buf []byte, s string) []byte {
+	buf = append(buf, '"')
+	for i := 0; i < len(s); i++ {
+		if s[i] == '"' || s[i] == '\\' {
+			buf = append(buf, '\\')
+		}
+		buf = append(buf, s[i])
+	}
+	return append(buf, '"')
+}
@@ -0,0 +1,171 @@
+package jcs_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/lattice-substrate/json-canon/jcs"
+	"github.com/lattice-substrate/json-canon/jcserr"
+	"github.com/lattice-substrate/json-canon/jcstoken"
+)
+
+func schemeOutput(t *testing.T, s jcs.Scheme, in string) string {
+	t.Helper()
+	v, err := jcstoken.Parse([]byte(in))
+	if err != nil {
+		t.Fatalf("parse %s: %v", in, err)
+	}
+	out, err := s.Serialize(v)
+	if err != nil {
+		t.Fatalf("%s.Serialize(%s): %v", s.Name(), in, err)
+	}
+	return string(out)
+}
+
+func schemeErrClass(t *testing.T, s jcs.Scheme, v *jcstoken.Value) jcserr.FailureClass {
+	t.Helper()
+	_, err := s.Serialize(v)
+	var je *jcserr.Error
+	if !errors.As(err, &je) {
+		t.Fatalf("%s: expected *jcserr.Error, got %T: %v", s.Name(), err, err)
+	}
+	return je.Class
+}
+
+func requireSchemeDomain(t *testing.T, s jcs.Scheme, inputs ...string) {
+	t.Helper()
+	for _, in := range inputs {
+		v, err := jcstoken.Parse([]byte(in))
+		if err != nil {
+			t.Fatalf("parse %s: %v", in, err)
+		}
+		if got := schemeErrClass(t, s, v); got != jcserr.SchemeDomain {
+			t.Fatalf("%s.Serialize(%s): expected SCHEME_DOMAIN, got %s", s.Name(), in, got)
+		}
+	}
+}
+
+// === SCHEME-API-001: Schemes share the parsed Value tree ===
+
+func TestSchemes_SCHEME_API_001(t *testing.T) {
+	names := []string{}
+	for _, s := range jcs.Schemes() {
+		names = append(names, s.Name())
+		got, ok := jcs.LookupScheme(s.Name())
+		if !ok || got != s {
+			t.Fatalf("LookupScheme(%q) = %v, %v", s.Name(), got, ok)
+		}
+	}
+	if len(names) != 4 || names[0] != "rfc8785" || names[1] != "olpc" || names[2] != "matrix" || names[3] != "decimal" {
+		t.Fatalf("unexpected scheme names %v", names)
+	}
+	if _, ok := jcs.LookupScheme("RFC8785"); ok {
+		t.Fatal("scheme lookup must be case-sensitive")
+	}
+
+	in := `{"z":[1,2.5e1,"é"],"a":{"b":null,"a":true}}`
+	if got, want := schemeOutput(t, jcs.RFC8785, in), canon(t, in); got != want {
+		t.Fatalf("RFC8785 scheme: got %s want %s", got, want)
+	}
+
+	dup := &jcstoken.Value{Kind: jcstoken.KindObject, Members: []jcstoken.Member{
+		{Key: "a", Value: jcstoken.Value{Kind: jcstoken.KindNull}},
+		{Key: "a", Value: jcstoken.Value{Kind: jcstoken.KindNull}},
+	}}
+	surrogate := &jcstoken.Value{Kind: jcstoken.KindString, Str: "\xed\xa0\x80"}
+	for _, s := range jcs.Schemes() {
+		if got := schemeErrClass(t, s, dup); got != jcserr.DuplicateKey {
+			t.Fatalf("%s: expected DUPLICATE_KEY, got %s", s.Name(), got)
+		}
+		if got := schemeErrClass(t, s, surrogate); got != jcserr.InvalidUTF8 && got != jcserr.LoneSurrogate {
+			t.Fatalf("%s: expected string profile failure, got %s", s.Name(), got)
+		}
+		if got := schemeErrClass(t, s, nil); got != jcserr.InternalError {
+			t.Fatalf("%s: expected INTERNAL_ERROR for nil value, got %s", s.Name(), got)
+		}
+	}
+}
+
+// === SCHEME-API-002: Built-in schemes validate against caller-supplied bounds ===
+
+func TestSerializeSchemeWithOptions_SCHEME_API_002(t *testing.T) {
+	wide := &jcstoken.Value{Kind: jcstoken.KindArray, Elems: make([]jcstoken.Value, jcstoken.DefaultMaxArrayElements+1)}
+	for i := range wide.Elems {
+		wide.Elems[i] = jcstoken.Value{Kind: jcstoken.KindNull}
+	}
+	small, err := jcstoken.Parse([]byte(`[[1],[2]]`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	raised := &jcstoken.Options{MaxArrayElements: jcstoken.DefaultMaxArrayElements + 1}
+	for _, s := range jcs.Schemes() {
+		if got := schemeErrClass(t, s, wide); got != jcserr.BoundExceeded {
+			t.Fatalf("%s.Serialize: expected BOUND_EXCEEDED, got %s", s.Name(), got)
+		}
+		if _, err := jcs.SerializeSchemeWithOptions(s, wide, raised); err != nil {
+			t.Fatalf("%s with raised bound: %v", s.Name(), err)
+		}
+		_, err := jcs.SerializeSchemeWithOptions(s, small, &jcstoken.Options{MaxDepth: 1})
+		var je *jcserr.Error
+		if !errors.As(err, &je) || je.Class != jcserr.BoundExceeded {
+			t.Fatalf("%s with lowered depth: expected BOUND_EXCEEDED, got %v", s.Name(), err)
+		}
+		want, err := s.Serialize(small)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got, err := jcs.SerializeSchemeWithOptions(s, small, nil); err != nil || string(got) != string(want) {
+			t.Fatalf("%s with nil options = %s, %v; want %s", s.Name(), got, err, want)
+		}
+	}
+}
+
+// === SCHEME-OLPC-001: OLPC key order, whitespace, and string escaping ===
+
+func TestOLPC_SCHEME_OLPC_001(t *testing.T) {
+	cases := []struct{ in, want string }{
+		{`{ "b" : 1, "a" : [ true, false, null ] }`, `{"a":[true,false,null],"b":1}`},
+		{`["q\"b\\s/", "tab\tnl\n"]`, "[\"q\\\"b\\\\s/\",\"tab\tnl\n\"]"},
+		{`{"😀":1,"￯":2,"a":3}`, "{\"a\":3,\"￯\":2,\"😀\":1}"},
+		{`"\u0001\u007f"`, "\"\x01\x7f\""},
+	}
+	for _, tc := range cases {
+		if got := schemeOutput(t, jcs.OLPC, tc.in); got != tc.want {
+			t.Fatalf("OLPC(%s) = %q, want %q", tc.in, got, tc.want)
+		}
+	}
+}
+
+// === SCHEME-OLPC-002: OLPC numbers are integers ===
+
+func TestOLPC_SCHEME_OLPC_002(t *testing.T) {
+	if got, want := schemeOutput(t, jcs.OLPC, `[0, -7, 1.0, 2e3, 9007199254740991]`), `[0,-7,1,2000,9007199254740991]`; got != want {
+		t.Fatalf("got %s want %s", got, want)
+	}
+	requireSchemeDomain(t, jcs.OLPC, `[1.5]`, `{"a":1e-7}`, `9007199254740992`, `-1e300`)
+}
+
+// === SCHEME-MATRIX-001: Matrix key order, whitespace, and string escaping ===
+
+func TestMatrix_SCHEME_MATRIX_001(t *testing.T) {
+	cases := []struct{ in, want string }{
+		{`{ "b" : 1, "a" : [ true, false, null ] }`, `{"a":[true,false,null],"b":1}`},
+		{`["q\"b\\s\/", "tab\tnl\n\u001f"]`, `["q\"b\\s/","tab\tnl\n\u001f"]`},
+		{`{"😀":1,"￯":2,"a":3}`, "{\"a\":3,\"￯\":2,\"😀\":1}"},
+		{`{"日本":1,"本":2}`, `{"日本":1,"本":2}`},
+	}
+	for _, tc := range cases {
+		if got := schemeOutput(t, jcs.Matrix, tc.in); got != tc.want {
+			t.Fatalf("Matrix(%s) = %q, want %q", tc.in, got, tc.want)
+		}
+	}
+}
+
+// === SCHEME-MATRIX-002: Matrix numbers are integers in [-(2^53)+1, 2^53-1] ===
+
+func TestMatrix_SCHEME_MATRIX_002(t *testing.T) {
+	if got, want := schemeOutput(t, jcs.Matrix, `[-9007199254740991, 9007199254740991, 100.0]`), `[-9007199254740991,9007199254740991,100]`; got != want {
+		t.Fatalf("got %s want %s", got, want)
+	}
+	requireSchemeDomain(t, jcs.Matrix, `[0.5]`, `-9007199254740992`, `{"a":[1e16]}`)
+}
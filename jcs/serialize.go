@@ -4,6 +4,11 @@
 // canonical byte sequence specified by RFC 8785. It depends on jcsfloat for
 // ECMA-262-compliant number serialization and uses UTF-16 code-unit ordering
 // for object property name sorting as required by RFC 8785 §3.2.3.
+//
+// RFC 8785 is the default Scheme; OLPC and Matrix canonical JSON and the
+// exact-decimal jcs-decimal profile are available as alternative schemes over
+// the same Value tree. Format renders the RFC 8785 form indented for reading,
+// differing from the canonical bytes only by insignificant whitespace.
 package jcs
 
 import (
@@ -31,7 +36,8 @@ func Canonicalize(input []byte) ([]byte, error) {
 	return serializeInto(make([]byte, 0, len(input)), v, nil)
 }
 
-// CanonicalizeWithOptions is like Canonicalize but accepts parser options.
+// CanonicalizeWithOptions is like Canonicalize but accepts parser options,
+// which also bound serialization as they do for SerializeWithOptions.
 //
 // API-CANON-002: Options are passed through to ParseWithOptions.
 func CanonicalizeWithOptions(input []byte, opts *jcstoken.Options) ([]byte, error) {
@@ -53,7 +59,8 @@ func Serialize(v *jcstoken.Value) ([]byte, error) {
 }
 
 // SerializeWithOptions is like Serialize but validates the value tree against
-// caller-supplied bounds before canonical emission.
+// caller-supplied bounds before canonical emission. Emission stops with
+// BOUND_EXCEEDED as soon as the output exceeds MaxOutputSize.
 func SerializeWithOptions(v *jcstoken.Value, opts *jcstoken.Options) ([]byte, error) {
 	return serializeInto(nil, v, opts)
 }
@@ -69,29 +76,35 @@ func serializeInto(buf []byte, v *jcstoken.Value, opts *jcstoken.Options) ([]byt
 	}
 
 	var err error
-	buf, err = serializeValue(buf, v)
+	buf, err = serializeValue(buf, v, limits.maxOutputSize)
 	if err != nil {
 		return nil, err
 	}
 	return buf, nil
 }
 
-func serializeValue(buf []byte, v *jcstoken.Value) ([]byte, error) {
+// serializeValue appends the canonical form of v to buf, failing once buf
+// grows past limit bytes.
+func serializeValue(buf []byte, v *jcstoken.Value, limit int) ([]byte, error) {
 	switch v.Kind {
 	case jcstoken.KindNull:
 		// CANON-LIT-001: lowercase literals
-		return append(buf, "null"...), nil
+		return boundOutput(append(buf, "null"...), limit)
 	case jcstoken.KindBool:
 		// CANON-LIT-001: lowercase literals
-		return append(buf, v.Str...), nil
+		return boundOutput(append(buf, v.Str...), limit)
 	case jcstoken.KindNumber:
-		return serializeNumber(buf, v.Num)
+		buf, err := serializeNumber(buf, v.Num)
+		if err != nil {
+			return nil, err
+		}
+		return boundOutput(buf, limit)
 	case jcstoken.KindString:
-		return serializeString(buf, v.Str), nil
+		return boundOutput(serializeString(buf, v.Str), limit)
 	case jcstoken.KindArray:
-		return serializeArray(buf, v)
+		return serializeArray(buf, v, limit)
 	case jcstoken.KindObject:
-		return serializeObject(buf, v)
+		return serializeObject(buf, v, limit)
 	default:
 		return nil, jcserr.New(jcserr.InternalError, -1, fmt.Sprintf("jcs: unknown value kind %d", v.Kind))
 	}
@@ -192,7 +205,7 @@ func utf8SeqLen(b byte) int {
 	}
 }
 
-func serializeArray(buf []byte, v *jcstoken.Value) ([]byte, error) {
+func serializeArray(buf []byte, v *jcstoken.Value, limit int) ([]byte, error) {
 	// CANON-SORT-003: array order preserved
 	buf = append(buf, '[')
 	for i := range v.Elems {
@@ -200,31 +213,19 @@ func serializeArray(buf []byte, v *jcstoken.Value) ([]byte, error) {
 			buf = append(buf, ',')
 		}
 		var err error
-		buf, err = serializeValue(buf, &v.Elems[i])
+		buf, err = serializeValue(buf, &v.Elems[i], limit)
 		if err != nil {
 			return nil, err
 		}
 	}
-	buf = append(buf, ']')
-	return buf, nil
+	return boundOutput(append(buf, ']'), limit)
 }
 
 // serializeObject sorts members by key using UTF-16 code-unit ordering.
 // CANON-SORT-001: UTF-16 code-unit comparison (NOT UTF-8 byte order).
 // CANON-SORT-002: Recursive sorting (nested objects sorted in serializeValue).
-func serializeObject(buf []byte, v *jcstoken.Value) ([]byte, error) {
-	sorted := make([]sortableMember, len(v.Members))
-	for i := range v.Members {
-		sorted[i].member = v.Members[i]
-		// Fast path: ASCII keys need no UTF-16 encoding since byte order
-		// equals UTF-16 code-unit order for U+0000..U+007F.
-		if !isASCII(v.Members[i].Key) {
-			sorted[i].key16 = utf16.Encode([]rune(v.Members[i].Key))
-		}
-	}
-	sort.Slice(sorted, func(i, j int) bool {
-		return compareSortKeys(&sorted[i], &sorted[j]) < 0
-	})
+func serializeObject(buf []byte, v *jcstoken.Value, limit int) ([]byte, error) {
+	sorted := sortMembers(v)
 
 	buf = append(buf, '{')
 	for i := range sorted {
@@ -234,13 +235,29 @@ func serializeObject(buf []byte, v *jcstoken.Value) ([]byte, error) {
 		buf = serializeString(buf, sorted[i].member.Key)
 		buf = append(buf, ':')
 		var err error
-		buf, err = serializeValue(buf, &sorted[i].member.Value)
+		buf, err = serializeValue(buf, &sorted[i].member.Value, limit)
 		if err != nil {
 			return nil, err
 		}
 	}
-	buf = append(buf, '}')
-	return buf, nil
+	return boundOutput(append(buf, '}'), limit)
+}
+
+// sortMembers returns v's members in UTF-16 code-unit order of their names.
+func sortMembers(v *jcstoken.Value) []sortableMember {
+	sorted := make([]sortableMember, len(v.Members))
+	for i := range v.Members {
+		sorted[i].member = v.Members[i]
+		// Fast path: ASCII keys need no UTF-16 encoding since byte order
+		// equals UTF-16 code-unit order for U+0000..U+007F.
+		if !isASCII(v.Members[i].Key) {
+			sorted[i].key16 = utf16.Encode([]rune(v.Members[i].Key))
+		}
+	}
+	sort.Slice(sorted, func(i, j int) bool {
+		return compareSortKeys(&sorted[i], &sorted[j]) < 0
+	})
+	return sorted
 }
 
 type sortableMember struct {
@@ -307,6 +324,9 @@ func compareUTF16Units(ua, ub []uint16) int {
 
 type serializeValidationState struct {
 	values int
+	// exactDecimal defers checks of numbers carrying decimal text to the
+	// Decimal scheme, which emits that text instead of Num.
+	exactDecimal bool
 }
 
 type serializeLimits struct {
@@ -315,8 +335,12 @@ type serializeLimits struct {
 	maxObjectMembers int
 	maxArrayElements int
 	maxStringBytes   int
+	maxOutputSize    int
 }
 
+// resolveSerializeLimits returns the bounds opts sets, with defaults for
+// those it leaves zero. Nil opts leave output unbounded, so the no-options
+// serializers emit whatever the default value bounds admit.
 func resolveSerializeLimits(opts *jcstoken.Options) serializeLimits {
 	if opts == nil {
 		return serializeLimits{
@@ -325,6 +349,7 @@ func resolveSerializeLimits(opts *jcstoken.Options) serializeLimits {
 			maxObjectMembers: jcstoken.DefaultMaxObjectMembers,
 			maxArrayElements: jcstoken.DefaultMaxArrayElements,
 			maxStringBytes:   jcstoken.DefaultMaxStringBytes,
+			maxOutputSize:    math.MaxInt,
 		}
 	}
 	return serializeLimits{
@@ -333,6 +358,7 @@ func resolveSerializeLimits(opts *jcstoken.Options) serializeLimits {
 		maxObjectMembers: resolveSerializeLimit(opts.MaxObjectMembers, jcstoken.DefaultMaxObjectMembers),
 		maxArrayElements: resolveSerializeLimit(opts.MaxArrayElements, jcstoken.DefaultMaxArrayElements),
 		maxStringBytes:   resolveSerializeLimit(opts.MaxStringBytes, jcstoken.DefaultMaxStringBytes),
+		maxOutputSize:    resolveSerializeLimit(opts.MaxOutputSize, jcstoken.DefaultMaxOutputSize),
 	}
 }
 
@@ -365,6 +391,9 @@ func validateValueTree(v *jcstoken.Value, depth int, state *serializeValidationS
 		}
 		return nil
 	case jcstoken.KindNumber:
+		if state.exactDecimal && v.Str != "" {
+			return nil
+		}
 		if math.IsNaN(v.Num) || math.IsInf(v.Num, 0) {
 			return jcserr.New(jcserr.InvalidGrammar, -1, "jcs: number is not finite")
 		}
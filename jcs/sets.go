@@ -0,0 +1,174 @@
+package jcs
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+	"sort"
+
+	"github.com/lattice-substrate/json-canon/jcserr"
+	"github.com/lattice-substrate/json-canon/jcstoken"
+)
+
+// SetArraysProfile names the output of set-array canonicalization. It is an
+// extension, not RFC 8785: documents whose selected arrays differ only in
+// element order (or, with RemoveDuplicates, in repeated elements) produce
+// identical bytes. Consumers that record or compare digests should record
+// this label alongside them.
+const SetArraysProfile = "jcs-set-arrays"
+
+// DuplicatePolicy selects how set-array canonicalization treats elements
+// with identical canonical bytes.
+type DuplicatePolicy int
+
+const (
+	// KeepDuplicates keeps repeated elements, adjacent after sorting.
+	KeepDuplicates DuplicatePolicy = iota
+	// RemoveDuplicates keeps one element of each run of repeated elements.
+	RemoveDuplicates
+	// RejectDuplicates fails with DUPLICATE_ELEMENT on a repeated element.
+	RejectDuplicates
+)
+
+// SetArrays selects arrays to canonicalize as unordered sets under the
+// SetArraysProfile.
+type SetArrays struct {
+	// Pointers are RFC 6901 JSON Pointers to set arrays. Pointers that do
+	// not resolve are ignored.
+	Pointers []string
+	// Paths are JSONPath queries (see jcstoken.Path); every array they
+	// select is a set array.
+	Paths []string
+	// Duplicates is the policy for repeated elements.
+	Duplicates DuplicatePolicy
+}
+
+// CanonicalizeSetArrays parses input with opts, applies s with
+// ApplySetArrays, and returns the canonical bytes of the result. The output
+// belongs to the SetArraysProfile, not RFC 8785, whenever s selects an array.
+func CanonicalizeSetArrays(input []byte, s *SetArrays, opts *jcstoken.Options) ([]byte, error) {
+	v, err := jcstoken.ParseWithOptions(input, opts)
+	if err != nil {
+		return nil, err //nolint:wrapcheck // SET-SORT-001: pass through jcstoken parse errors unchanged.
+	}
+	sorted, err := ApplySetArrays(v, s)
+	if err != nil {
+		return nil, err
+	}
+	return serializeInto(nil, sorted, opts)
+}
+
+// ApplySetArrays returns a copy of v in which every array selected by s is
+// sorted by the bytewise order of its elements' RFC 8785 encodings (numbers
+// parsed with jcstoken.NumberDecimal compare by their exact decimal form),
+// with duplicates handled per s.Duplicates. Selected arrays nested inside other
+// selected arrays are sorted first. A selected value that is not an array is
+// INVALID_POINTER. The input tree is never modified; a nil s returns a plain
+// deep copy.
+//
+// SET-SORT-001: Set arrays are sorted by canonical element bytes.
+// SET-TARGET-001: Selectors address arrays of the input document.
+func ApplySetArrays(v *jcstoken.Value, s *SetArrays) (*jcstoken.Value, error) {
+	if v == nil {
+		return nil, jcserr.New(jcserr.InternalError, -1, "jcs: nil value")
+	}
+	out := v.Clone()
+	if s == nil {
+		return out, nil
+	}
+	targets, err := setTargets(out, s)
+	if err != nil {
+		return nil, err
+	}
+	// Deeper arrays first, so an enclosing set array sorts on final bytes.
+	sort.SliceStable(targets, func(i, j int) bool {
+		return len(targets[i].ptr) > len(targets[j].ptr)
+	})
+	for _, t := range targets {
+		if err := sortSetArray(t.v, t.ptr, s.Duplicates); err != nil {
+			return nil, err
+		}
+	}
+	return out, nil
+}
+
+type setTarget struct {
+	ptr jcstoken.Pointer
+	v   *jcstoken.Value
+}
+
+// setTargets resolves every selector in s against v, returning each selected
+// location once.
+func setTargets(v *jcstoken.Value, s *SetArrays) ([]setTarget, error) {
+	ptrs := make([]jcstoken.Pointer, 0, len(s.Pointers))
+	for _, raw := range s.Pointers {
+		ptr, err := jcstoken.ParsePointer(raw)
+		if err != nil {
+			return nil, err //nolint:wrapcheck // SET-TARGET-001: preserve pointer failure class unchanged.
+		}
+		ptrs = append(ptrs, ptr)
+	}
+	for _, raw := range s.Paths {
+		path, err := jcstoken.ParsePath(raw)
+		if err != nil {
+			return nil, err //nolint:wrapcheck // SET-TARGET-001: preserve JSONPath failure class unchanged.
+		}
+		ptrs = append(ptrs, path.Select(v)...)
+	}
+	seen := make(map[string]struct{}, len(ptrs))
+	targets := make([]setTarget, 0, len(ptrs))
+	for _, ptr := range ptrs {
+		key := ptr.String()
+		if _, dup := seen[key]; dup {
+			continue
+		}
+		seen[key] = struct{}{}
+		target, err := ptr.Resolve(v)
+		if err != nil {
+			continue
+		}
+		if target.Kind != jcstoken.KindArray {
+			return nil, jcserr.New(jcserr.InvalidPointer, -1, fmt.Sprintf("jcs: set array %q is not an array", key))
+		}
+		targets = append(targets, setTarget{ptr: ptr, v: target})
+	}
+	return targets, nil
+}
+
+// sortSetArray sorts a's elements in place by their RFC 8785 bytes, with
+// exact decimal numbers in their jcs-decimal form, and applies the duplicate
+// policy.
+//
+// SET-DUP-001: Repeated elements are kept, removed, or rejected.
+func sortSetArray(a *jcstoken.Value, ptr jcstoken.Pointer, policy DuplicatePolicy) error {
+	type keyed struct {
+		key  []byte
+		elem jcstoken.Value
+	}
+	elems := make([]keyed, len(a.Elems))
+	for i := range a.Elems {
+		// Identical to the RFC 8785 bytes unless exact decimals are present.
+		// The document's output bound applies when it is serialized.
+		key, err := appendDecimalValue(nil, &a.Elems[i], math.MaxInt)
+		if err != nil {
+			return err
+		}
+		elems[i] = keyed{key: key, elem: a.Elems[i]}
+	}
+	sort.SliceStable(elems, func(i, j int) bool {
+		return bytes.Compare(elems[i].key, elems[j].key) < 0
+	})
+	a.Elems = a.Elems[:0]
+	for i := range elems {
+		dup := i > 0 && bytes.Equal(elems[i].key, elems[i-1].key)
+		switch {
+		case dup && policy == RejectDuplicates:
+			return jcserr.New(jcserr.DuplicateElement, -1,
+				fmt.Sprintf("jcs: set array %q contains duplicate elements", ptr.String()))
+		case dup && policy == RemoveDuplicates:
+			continue
+		}
+		a.Elems = append(a.Elems, elems[i].elem)
+	}
+	return nil
+}
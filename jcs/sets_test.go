@@ -0,0 +1,134 @@
+package jcs_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/lattice-substrate/json-canon/jcs"
+	"github.com/lattice-substrate/json-canon/jcserr"
+	"github.com/lattice-substrate/json-canon/jcstoken"
+)
+
+func setCanon(t *testing.T, in string, s *jcs.SetArrays) string {
+	t.Helper()
+	out, err := jcs.CanonicalizeSetArrays([]byte(in), s, nil)
+	if err != nil {
+		t.Fatalf("CanonicalizeSetArrays(%s, %+v): %v", in, s, err)
+	}
+	return string(out)
+}
+
+func setErrClass(t *testing.T, in string, s *jcs.SetArrays) jcserr.FailureClass {
+	t.Helper()
+	_, err := jcs.CanonicalizeSetArrays([]byte(in), s, nil)
+	var je *jcserr.Error
+	if !errors.As(err, &je) {
+		t.Fatalf("expected *jcserr.Error for %+v, got %T: %v", s, err, err)
+	}
+	return je.Class
+}
+
+// === SET-SORT-001: Set arrays are sorted by canonical element bytes ===
+
+func TestApplySetArrays_SET_SORT_001(t *testing.T) {
+	s := &jcs.SetArrays{Pointers: []string{"/tags"}}
+	a := setCanon(t, `{"tags":["write","read","admin"],"order":[2,1]}`, s)
+	b := setCanon(t, `{"order":[2,1],"tags":["admin","write","read"]}`, s)
+	if a != b || a != `{"order":[2,1],"tags":["admin","read","write"]}` {
+		t.Fatalf("set order not canonical: %s vs %s", a, b)
+	}
+	// Elements of mixed kinds sort by their canonical bytes: '"' < '1' < '[' < 'f' < 'n' < '{'.
+	got := setCanon(t, `[{"a":1},null,false,[0],1e1,"x"]`, &jcs.SetArrays{Pointers: []string{""}})
+	if want := `["x",10,[0],false,null,{"a":1}]`; got != want {
+		t.Fatalf("got %s want %s", got, want)
+	}
+	// Nested set arrays are sorted before their enclosing set array.
+	s = &jcs.SetArrays{Paths: []string{`$`, `$[*]`}}
+	if got, want := setCanon(t, `[[3,2],[2,1],[1,3]]`, s), `[[1,2],[1,3],[2,3]]`; got != want {
+		t.Fatalf("nested sets: got %s want %s", got, want)
+	}
+	// Object members inside elements are compared in canonical order.
+	s = &jcs.SetArrays{Pointers: []string{""}}
+	if got, want := setCanon(t, `[{"b":1,"a":2},{"a":1}]`, s), `[{"a":1},{"a":2,"b":1}]`; got != want {
+		t.Fatalf("got %s want %s", got, want)
+	}
+	if got, want := setCanon(t, `[2,1]`, nil), `[2,1]`; got != want {
+		t.Fatalf("nil selection changed output: %s", got)
+	}
+}
+
+func TestApplySetArraysDoesNotModifyInput(t *testing.T) {
+	v, err := jcstoken.Parse([]byte(`{"a":[3,1,2]}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	sorted, err := jcs.ApplySetArrays(v, &jcs.SetArrays{Pointers: []string{"/a"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := canonValue(t, v); got != `{"a":[3,1,2]}` {
+		t.Fatalf("input modified: %s", got)
+	}
+	if got := canonValue(t, sorted); got != `{"a":[1,2,3]}` {
+		t.Fatalf("got %s", got)
+	}
+}
+
+func canonValue(t *testing.T, v *jcstoken.Value) string {
+	t.Helper()
+	out, err := jcs.Serialize(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(out)
+}
+
+// === SET-DUP-001: Repeated elements are kept, removed, or rejected ===
+
+func TestApplySetArrays_SET_DUP_001(t *testing.T) {
+	in := `{"p":["b",1,"a",1.0,"b"]}`
+	cases := []struct {
+		policy jcs.DuplicatePolicy
+		want   string
+	}{
+		{jcs.KeepDuplicates, `{"p":["a","b","b",1,1]}`},
+		{jcs.RemoveDuplicates, `{"p":["a","b",1]}`},
+	}
+	for _, tc := range cases {
+		s := &jcs.SetArrays{Pointers: []string{"/p"}, Duplicates: tc.policy}
+		if got := setCanon(t, in, s); got != tc.want {
+			t.Fatalf("policy %d: got %s want %s", tc.policy, got, tc.want)
+		}
+	}
+	s := &jcs.SetArrays{Pointers: []string{"/p"}, Duplicates: jcs.RejectDuplicates}
+	if got := setErrClass(t, in, s); got != jcserr.DuplicateElement {
+		t.Fatalf("expected DUPLICATE_ELEMENT, got %s", got)
+	}
+	if got := setCanon(t, `{"p":[2,1]}`, s); got != `{"p":[1,2]}` {
+		t.Fatalf("distinct set rejected: %s", got)
+	}
+	// Duplicates are detected on canonical bytes, so member order does not matter.
+	if got := setErrClass(t, `[{"a":1,"b":2},{"b":2,"a":1}]`, &jcs.SetArrays{Pointers: []string{""}, Duplicates: jcs.RejectDuplicates}); got != jcserr.DuplicateElement {
+		t.Fatalf("expected DUPLICATE_ELEMENT, got %s", got)
+	}
+}
+
+// === SET-TARGET-001: Selectors address arrays of the input document ===
+
+func TestApplySetArrays_SET_TARGET_001(t *testing.T) {
+	doc := `{"roles":[{"perms":["w","r"]},{"perms":["x","a"]}],"missing":null}`
+	s := &jcs.SetArrays{Paths: []string{`$.roles[*].perms`}, Pointers: []string{"/absent", "/roles/9"}}
+	if got, want := setCanon(t, doc, s), `{"missing":null,"roles":[{"perms":["r","w"]},{"perms":["a","x"]}]}`; got != want {
+		t.Fatalf("got %s want %s", got, want)
+	}
+	for _, s := range []*jcs.SetArrays{
+		{Pointers: []string{"/missing"}},
+		{Paths: []string{`$.roles[0]`}},
+		{Pointers: []string{"roles"}},
+		{Paths: []string{`$.roles[?@.perms]`}},
+	} {
+		if got := setErrClass(t, doc, s); got != jcserr.InvalidPointer {
+			t.Fatalf("%+v: expected INVALID_POINTER, got %s", s, got)
+		}
+	}
+}
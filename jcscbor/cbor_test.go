@@ -0,0 +1,309 @@
+package jcscbor_test
+
+import (
+	"encoding/hex"
+	"errors"
+	"testing"
+
+	"github.com/lattice-substrate/json-canon/jcs"
+	"github.com/lattice-substrate/json-canon/jcscbor"
+	"github.com/lattice-substrate/json-canon/jcserr"
+	"github.com/lattice-substrate/json-canon/jcstoken"
+)
+
+func mustParse(t *testing.T, in string) *jcstoken.Value {
+	t.Helper()
+	v, err := jcstoken.Parse([]byte(in))
+	if err != nil {
+		t.Fatalf("parse %q: %v", in, err)
+	}
+	return v
+}
+
+func encodeHex(t *testing.T, in string) string {
+	t.Helper()
+	out, err := jcscbor.Encode(mustParse(t, in))
+	if err != nil {
+		t.Fatalf("Encode(%s): %v", in, err)
+	}
+	return hex.EncodeToString(out)
+}
+
+func decodeHex(t *testing.T, h string, opts *jcstoken.Options) (string, error) {
+	t.Helper()
+	data, err := hex.DecodeString(h)
+	if err != nil {
+		t.Fatalf("bad hex %q: %v", h, err)
+	}
+	v, err := jcscbor.DecodeWithOptions(data, opts)
+	if err != nil {
+		return "", err
+	}
+	out, err := jcs.Serialize(v)
+	if err != nil {
+		t.Fatalf("serialize decoded %s: %v", h, err)
+	}
+	return string(out), nil
+}
+
+func assertClass(t *testing.T, err error, want jcserr.FailureClass) {
+	t.Helper()
+	var je *jcserr.Error
+	if !errors.As(err, &je) {
+		t.Fatalf("expected *jcserr.Error, got %T: %v", err, err)
+	}
+	if je.Class != want {
+		t.Fatalf("expected %s, got %s: %v", want, je.Class, err)
+	}
+}
+
+// appendixA holds RFC 8949 Appendix A examples within the JSON domain.
+var appendixA = []struct{ json, cbor string }{
+	{`0`, "00"},
+	{`1`, "01"},
+	{`10`, "0a"},
+	{`23`, "17"},
+	{`24`, "1818"},
+	{`25`, "1819"},
+	{`100`, "1864"},
+	{`1000`, "1903e8"},
+	{`1000000`, "1a000f4240"},
+	{`1000000000000`, "1b000000e8d4a51000"},
+	{`-1`, "20"},
+	{`-10`, "29"},
+	{`-100`, "3863"},
+	{`-1000`, "3903e7"},
+	{`1.1`, "fb3ff199999999999a"},
+	{`1.5`, "f93e00"},
+	{`3.4028234663852886e+38`, "fa7f7fffff"},
+	{`1e+300`, "fb7e37e43c8800759c"},
+	{`5.960464477539063e-8`, "f90001"},
+	{`0.00006103515625`, "f90400"},
+	{`-4.1`, "fbc010666666666666"},
+	{`false`, "f4"},
+	{`true`, "f5"},
+	{`null`, "f6"},
+	{`""`, "60"},
+	{`"a"`, "6161"},
+	{`"IETF"`, "6449455446"},
+	{`"\"\\"`, "62225c"},
+	{`"ü"`, "62c3bc"},
+	{`"水"`, "63e6b0b4"},
+	{`"𐅑"`, "64f0908591"},
+	{`[]`, "80"},
+	{`[1,2,3]`, "83010203"},
+	{`[1,[2,3],[4,5]]`, "8301820203820405"},
+	{`{}`, "a0"},
+	{`{"a":1,"b":[2,3]}`, "a26161016162820203"},
+	{`["a",{"b":"c"}]`, "826161a161626163"},
+	{`{"a":"A","b":"B","c":"C","d":"D","e":"E"}`, "a56161614161626142616361436164614461656145"},
+}
+
+// === CBOR-ENC-001: Shortest arguments and definite lengths ===
+
+func TestEncode_CBOR_ENC_001(t *testing.T) {
+	for _, tc := range appendixA {
+		if got := encodeHex(t, tc.json); got != tc.cbor {
+			t.Errorf("Encode(%s) = %s, want %s", tc.json, got, tc.cbor)
+		}
+	}
+	if got := encodeHex(t, `"abcdefghijklmnopqrstuvwx"`); got[:4] != "7818" {
+		t.Fatalf("24-byte string head = %s, want 7818", got[:4])
+	}
+}
+
+// === CBOR-ENC-002: Map keys sorted by encoded bytes ===
+
+func TestEncode_CBOR_ENC_002(t *testing.T) {
+	// Bytewise order of encoded keys puts shorter text strings first, unlike
+	// the UTF-16 order of RFC 8785.
+	got := encodeHex(t, `{"aa":1,"b":2,"":0,"€":3}`)
+	want := "a4" + "6000" + "616202" + "62616101" + "63e282ac03"
+	if got != want {
+		t.Fatalf("got %s want %s", got, want)
+	}
+	// Member order in the source is irrelevant.
+	if a, b := encodeHex(t, `{"x":{"b":1,"a":2},"y":0}`), encodeHex(t, `{"y":0,"x":{"a":2,"b":1}}`); a != b {
+		t.Fatalf("order-dependent output: %s vs %s", a, b)
+	}
+}
+
+// === CBOR-NUM-001: Integral numbers reduce to CBOR integers ===
+
+func TestEncode_CBOR_NUM_001(t *testing.T) {
+	cases := []struct{ json, cbor string }{
+		{`65504`, "19ffe0"},
+		{`100000`, "1a000186a0"},
+		{`1e19`, "1b8ac7230489e80000"},
+		{`-18446744073709549568`, "3bfffffffffffff7ff"},
+		{`18446744073709551616`, "fa5f800000"},
+		{`9007199254740993`, "1b0020000000000000"},
+	}
+	for _, tc := range cases {
+		if got := encodeHex(t, tc.json); got != tc.cbor {
+			t.Errorf("Encode(%s) = %s, want %s", tc.json, got, tc.cbor)
+		}
+	}
+}
+
+// === CBOR-FLOAT-001: Preferred float serialization ===
+
+func TestEncode_CBOR_FLOAT_001(t *testing.T) {
+	cases := []struct{ json, cbor string }{
+		{`0.5`, "f93800"},
+		{`-0.5`, "f9b800"},
+		{`65504.5`, "fa477fe080"},
+		{`0.1`, "fb3fb999999999999a"},
+		{`1e-7`, "fb3e7ad7f29abcaf48"},
+		{`2.9802322387695312e-8`, "fa33000000"},
+		{`1.401298464324817e-45`, "fa00000001"},
+		{`5e-324`, "fb0000000000000001"},
+	}
+	for _, tc := range cases {
+		if got := encodeHex(t, tc.json); got != tc.cbor {
+			t.Errorf("Encode(%s) = %s, want %s", tc.json, got, tc.cbor)
+		}
+	}
+	_, err := jcscbor.Encode(&jcstoken.Value{Kind: jcstoken.KindNumber, Num: posInf()})
+	assertClass(t, err, jcserr.NumberOverflow)
+}
+
+func posInf() float64 {
+	zero := 0.0
+	return 1 / zero
+}
+
+// === CBOR-DEC-001: Malformed CBOR is INVALID_CBOR ===
+
+func TestDecode_CBOR_DEC_001(t *testing.T) {
+	for _, h := range []string{
+		"",                   // empty input
+		"1a0000",             // truncated argument
+		"1c",                 // reserved additional information
+		"ff",                 // break outside indefinite-length item
+		"1f",                 // indefinite-length integer
+		"0000",               // trailing bytes
+		"f818",               // two-byte simple value below 32
+		"62c3",               // text shorter than declared
+		"98ff01",             // array shorter than declared
+		"a26161016162",       // map missing its last value
+		"9b00000000000000ff", // declared count exceeds remaining input
+	} {
+		_, err := decodeHex(t, h, nil)
+		if err == nil {
+			t.Fatalf("Decode(%s): expected error", h)
+		}
+		assertClass(t, err, jcserr.InvalidCBOR)
+	}
+	_, err := decodeHex(t, "8201f818", nil)
+	var je *jcserr.Error
+	if !errors.As(err, &je) || je.Offset != 2 {
+		t.Fatalf("expected offset 2, got %v", err)
+	}
+}
+
+// === CBOR-DEC-002: Items outside the I-JSON domain are classified ===
+
+func TestDecode_CBOR_DEC_002(t *testing.T) {
+	cases := []struct {
+		cbor string
+		want jcserr.FailureClass
+	}{
+		{"c11a514b67b0", jcserr.UnsupportedCBOR},       // tag 1 (epoch time)
+		{"4401020304", jcserr.UnsupportedCBOR},         // byte string
+		{"a10102", jcserr.UnsupportedCBOR},             // integer map key
+		{"a1f56161", jcserr.UnsupportedCBOR},           // boolean map key
+		{"f7", jcserr.UnsupportedCBOR},                 // undefined
+		{"f0", jcserr.UnsupportedCBOR},                 // simple(16)
+		{"f8ff", jcserr.UnsupportedCBOR},               // simple(255)
+		{"f97e00", jcserr.UnsupportedCBOR},             // NaN
+		{"9f01ff", jcserr.UnsupportedCBOR},             // indefinite-length array
+		{"7f6161ff", jcserr.UnsupportedCBOR},           // indefinite-length text
+		{"1b0020000000000001", jcserr.UnsupportedCBOR}, // 2^53+1 is not a binary64
+		{"1bffffffffffffffff", jcserr.UnsupportedCBOR}, // 2^64-1 rounds to 2^64
+		{"f97c00", jcserr.NumberOverflow},              // +Infinity
+		{"fbfff0000000000000", jcserr.NumberOverflow},  // -Infinity
+		{"f98000", jcserr.NumberNegZero},               // -0.0
+		{"a2616101616102", jcserr.DuplicateKey},        // {"a":1,"a":2}
+		{"61ff", jcserr.InvalidUTF8},                   // invalid UTF-8
+		{"63eda080", jcserr.InvalidUTF8},               // encoded surrogate
+		{"63efb790", jcserr.Noncharacter},              // U+FDD0
+	}
+	for _, tc := range cases {
+		_, err := decodeHex(t, tc.cbor, nil)
+		if err == nil {
+			t.Fatalf("Decode(%s): expected %s", tc.cbor, tc.want)
+		}
+		assertClass(t, err, tc.want)
+	}
+	// Well-formed but non-preferred encodings are accepted.
+	for _, tc := range []struct{ cbor, json string }{
+		{"1800", `0`},
+		{"fb3ff8000000000000", `1.5`},
+		{"f93c00", `1`},
+		{"3bffffffffffffffff", `-18446744073709552000`},
+	} {
+		got, err := decodeHex(t, tc.cbor, nil)
+		if err != nil || got != tc.json {
+			t.Fatalf("Decode(%s) = %q, %v want %q", tc.cbor, got, err, tc.json)
+		}
+	}
+}
+
+// === CBOR-BOUND-001: jcstoken bounds apply to decoding ===
+
+func TestDecode_CBOR_BOUND_001(t *testing.T) {
+	cases := []struct {
+		cbor string
+		opts jcstoken.Options
+	}{
+		{"818101", jcstoken.Options{MaxDepth: 1}},
+		{"83010203", jcstoken.Options{MaxArrayElements: 2}},
+		{"a2616101616202", jcstoken.Options{MaxObjectMembers: 1}},
+		{"6449455446", jcstoken.Options{MaxStringBytes: 3}},
+		{"83010203", jcstoken.Options{MaxValues: 3}},
+		{"83010203", jcstoken.Options{MaxInputSize: 3}},
+		{"9bffffffffffffffff", jcstoken.Options{}},
+	}
+	for _, tc := range cases {
+		_, err := decodeHex(t, tc.cbor, &tc.opts)
+		if err == nil {
+			t.Fatalf("Decode(%s, %+v): expected BOUND_EXCEEDED", tc.cbor, tc.opts)
+		}
+		assertClass(t, err, jcserr.BoundExceeded)
+	}
+}
+
+// === CBOR-RT-001: JSON → CBOR → JSON preserves canonical bytes ===
+
+func TestRoundTrip_CBOR_RT_001(t *testing.T) {
+	for _, tc := range appendixA {
+		got, err := decodeHex(t, tc.cbor, nil)
+		if err != nil {
+			t.Fatalf("Decode(%s): %v", tc.cbor, err)
+		}
+		want, err := jcs.Canonicalize([]byte(tc.json))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != string(want) {
+			t.Errorf("round trip %s: got %s want %s", tc.json, got, want)
+		}
+	}
+	doc := `{"n":[0,-1,1.5,1e21,-1e-7,123456789012,4.35,null,true],"s":"\u0000€😀","o":{"":{}}}`
+	first, err := jcscbor.Encode(mustParse(t, doc))
+	if err != nil {
+		t.Fatal(err)
+	}
+	decoded, err := jcscbor.Decode(first)
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := jcscbor.Encode(decoded)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if hex.EncodeToString(first) != hex.EncodeToString(second) {
+		t.Fatalf("CBOR not stable across round trip:\n%x\n%x", first, second)
+	}
+}
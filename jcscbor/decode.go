@@ -0,0 +1,326 @@
+package jcscbor
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/lattice-substrate/json-canon/jcserr"
+	"github.com/lattice-substrate/json-canon/jcstoken"
+)
+
+// Decode decodes a single CBOR data item into a jcstoken.Value using the
+// default jcstoken bounds. See DecodeWithOptions.
+func Decode(data []byte) (*jcstoken.Value, error) {
+	return DecodeWithOptions(data, nil)
+}
+
+// DecodeWithOptions decodes exactly one CBOR data item into a jcstoken.Value.
+// Any well-formed definite-length encoding is accepted; Encode of the result
+// yields the deterministic form. Error offsets are byte offsets of the
+// offending data item head in data.
+//
+// CBOR-DEC-001: Malformed CBOR is rejected as INVALID_CBOR.
+// CBOR-DEC-002: Items outside the I-JSON domain are rejected with classified errors.
+// CBOR-BOUND-001: jcstoken.Options bounds apply to decoded structure.
+func DecodeWithOptions(data []byte, opts *jcstoken.Options) (*jcstoken.Value, error) {
+	d := newDecoder(data, opts)
+	if len(data) > d.maxInputSize {
+		return nil, jcserr.New(jcserr.BoundExceeded, 0,
+			fmt.Sprintf("input size %d exceeds maximum %d", len(data), d.maxInputSize))
+	}
+	v, err := d.decodeValue()
+	if err != nil {
+		return nil, err
+	}
+	if d.pos != len(data) {
+		return nil, jcserr.New(jcserr.InvalidCBOR, d.pos, "jcscbor: trailing bytes after CBOR data item")
+	}
+	return v, nil
+}
+
+type decoder struct {
+	data   []byte
+	pos    int
+	depth  int
+	values int
+
+	maxInputSize     int
+	maxDepth         int
+	maxValues        int
+	maxObjectMembers int
+	maxArrayElements int
+	maxStringBytes   int
+}
+
+func newDecoder(data []byte, opts *jcstoken.Options) *decoder {
+	if opts == nil {
+		opts = &jcstoken.Options{}
+	}
+	return &decoder{
+		data:             data,
+		maxInputSize:     resolveLimit(opts.MaxInputSize, jcstoken.DefaultMaxInputSize),
+		maxDepth:         resolveLimit(opts.MaxDepth, jcstoken.DefaultMaxDepth),
+		maxValues:        resolveLimit(opts.MaxValues, jcstoken.DefaultMaxValues),
+		maxObjectMembers: resolveLimit(opts.MaxObjectMembers, jcstoken.DefaultMaxObjectMembers),
+		maxArrayElements: resolveLimit(opts.MaxArrayElements, jcstoken.DefaultMaxArrayElements),
+		maxStringBytes:   resolveLimit(opts.MaxStringBytes, jcstoken.DefaultMaxStringBytes),
+	}
+}
+
+func resolveLimit(val, def int) int {
+	if val > 0 {
+		return val
+	}
+	return def
+}
+
+// head is a decoded CBOR initial byte and argument.
+type head struct {
+	offset int
+	major  byte
+	info   byte
+	arg    uint64
+}
+
+// readHead decodes the initial byte and argument at d.pos.
+func (d *decoder) readHead() (head, error) {
+	h := head{offset: d.pos}
+	if d.pos >= len(d.data) {
+		return h, jcserr.New(jcserr.InvalidCBOR, d.pos, "jcscbor: unexpected end of CBOR input")
+	}
+	ib := d.data[d.pos]
+	d.pos++
+	h.major, h.info = ib>>5, ib&0x1f
+	switch {
+	case h.info < 24:
+		h.arg = uint64(h.info)
+		return h, nil
+	case h.info <= 27:
+		n := 1 << (h.info - 24)
+		if len(d.data)-d.pos < n {
+			return h, jcserr.New(jcserr.InvalidCBOR, h.offset, "jcscbor: truncated CBOR argument")
+		}
+		for _, b := range d.data[d.pos : d.pos+n] {
+			h.arg = h.arg<<8 | uint64(b)
+		}
+		d.pos += n
+		return h, nil
+	case h.info == 31 && h.major >= majorBytes && h.major <= majorMap:
+		return h, jcserr.New(jcserr.UnsupportedCBOR, h.offset, "jcscbor: indefinite-length items are not supported")
+	default:
+		return h, jcserr.New(jcserr.InvalidCBOR, h.offset,
+			fmt.Sprintf("jcscbor: reserved or invalid additional information %d for major type %d", h.info, h.major))
+	}
+}
+
+func (d *decoder) decodeValue() (*jcstoken.Value, error) {
+	// BOUND-VALUES-001
+	d.values++
+	if d.values > d.maxValues {
+		return nil, jcserr.New(jcserr.BoundExceeded, d.pos,
+			fmt.Sprintf("value count %d exceeds maximum %d", d.values, d.maxValues))
+	}
+	h, err := d.readHead()
+	if err != nil {
+		return nil, err
+	}
+	switch h.major {
+	case majorUnsigned, majorNegative:
+		return integerValue(h)
+	case majorText:
+		return d.decodeText(h)
+	case majorArray:
+		return d.decodeArray(h)
+	case majorMap:
+		return d.decodeMap(h)
+	case majorSimple:
+		return simpleValue(h)
+	case majorBytes:
+		return nil, jcserr.New(jcserr.UnsupportedCBOR, h.offset, "jcscbor: byte strings have no JSON representation")
+	default:
+		return nil, jcserr.New(jcserr.UnsupportedCBOR, h.offset, fmt.Sprintf("jcscbor: tag %d is not supported", h.arg))
+	}
+}
+
+// integerValue maps a CBOR integer to a binary64 number. Integers that
+// binary64 cannot represent exactly are rejected rather than rounded.
+func integerValue(h head) (*jcstoken.Value, error) {
+	if h.major == majorNegative && h.arg == math.MaxUint64 {
+		return &jcstoken.Value{Kind: jcstoken.KindNumber, Num: -twoTo64}, nil
+	}
+	n := h.arg
+	if h.major == majorNegative {
+		n++
+	}
+	f := float64(n)
+	if f >= twoTo64 || uint64(f) != n {
+		return nil, jcserr.New(jcserr.UnsupportedCBOR, h.offset,
+			"jcscbor: integer is not exactly representable as an IEEE 754 double")
+	}
+	if h.major == majorNegative {
+		f = -f
+	}
+	return &jcstoken.Value{Kind: jcstoken.KindNumber, Num: f}, nil
+}
+
+func (d *decoder) decodeText(h head) (*jcstoken.Value, error) {
+	if h.arg > uint64(len(d.data)-d.pos) {
+		return nil, jcserr.New(jcserr.InvalidCBOR, h.offset, "jcscbor: text string length exceeds remaining input")
+	}
+	if h.arg > uint64(d.maxStringBytes) {
+		return nil, jcserr.New(jcserr.BoundExceeded, h.offset,
+			fmt.Sprintf("string decoded length exceeds maximum %d bytes", d.maxStringBytes))
+	}
+	s := string(d.data[d.pos : d.pos+int(h.arg)])
+	if err := validateText(s, h.offset); err != nil {
+		return nil, err
+	}
+	d.pos += int(h.arg)
+	return &jcstoken.Value{Kind: jcstoken.KindString, Str: s}, nil
+}
+
+func (d *decoder) pushDepth(h head) error {
+	d.depth++
+	if d.depth > d.maxDepth {
+		return jcserr.New(jcserr.BoundExceeded, h.offset,
+			fmt.Sprintf("nesting depth %d exceeds maximum %d", d.depth, d.maxDepth))
+	}
+	return nil
+}
+
+// checkCount rejects declared container sizes that exceed the bound or that
+// cannot fit in the remaining input, before any allocation.
+func (d *decoder) checkCount(h head, count uint64, limit int, what string) error {
+	if h.arg > uint64(limit) {
+		return jcserr.New(jcserr.BoundExceeded, h.offset,
+			fmt.Sprintf("%s count exceeds maximum %d", what, limit))
+	}
+	if count > uint64(len(d.data)-d.pos) {
+		return jcserr.New(jcserr.InvalidCBOR, h.offset,
+			fmt.Sprintf("jcscbor: declared %s count exceeds remaining input", what))
+	}
+	return nil
+}
+
+func (d *decoder) decodeArray(h head) (*jcstoken.Value, error) {
+	if err := d.pushDepth(h); err != nil {
+		return nil, err
+	}
+	defer func() { d.depth-- }()
+	// BOUND-ELEMS-001
+	if err := d.checkCount(h, h.arg, d.maxArrayElements, "array element"); err != nil {
+		return nil, err
+	}
+	v := &jcstoken.Value{Kind: jcstoken.KindArray, Elems: make([]jcstoken.Value, 0, int(h.arg))}
+	for i := uint64(0); i < h.arg; i++ {
+		elem, err := d.decodeValue()
+		if err != nil {
+			return nil, err
+		}
+		v.Elems = append(v.Elems, *elem)
+	}
+	return v, nil
+}
+
+func (d *decoder) decodeMap(h head) (*jcstoken.Value, error) {
+	if err := d.pushDepth(h); err != nil {
+		return nil, err
+	}
+	defer func() { d.depth-- }()
+	// BOUND-MEMBERS-001
+	if err := d.checkCount(h, 2*h.arg, d.maxObjectMembers, "object member"); err != nil {
+		return nil, err
+	}
+	v := &jcstoken.Value{Kind: jcstoken.KindObject, Members: make([]jcstoken.Member, 0, int(h.arg))}
+	seen := make(map[string]int, int(h.arg))
+	for i := uint64(0); i < h.arg; i++ {
+		keyOffset := d.pos
+		key, err := d.decodeKey()
+		if err != nil {
+			return nil, err
+		}
+		if first, dup := seen[key.Str]; dup {
+			return nil, jcserr.New(jcserr.DuplicateKey, keyOffset,
+				fmt.Sprintf("duplicate object key %q (first at byte %d)", key.Str, first))
+		}
+		seen[key.Str] = keyOffset
+		val, err := d.decodeValue()
+		if err != nil {
+			return nil, err
+		}
+		v.Members = append(v.Members, jcstoken.Member{Key: key.Str, Value: *val})
+	}
+	return v, nil
+}
+
+// decodeKey decodes a map key, which must be a text string.
+func (d *decoder) decodeKey() (*jcstoken.Value, error) {
+	h, err := d.readHead()
+	if err != nil {
+		return nil, err
+	}
+	if h.major != majorText {
+		return nil, jcserr.New(jcserr.UnsupportedCBOR, h.offset, "jcscbor: map key is not a text string")
+	}
+	return d.decodeText(h)
+}
+
+// simpleValue maps major type 7 items: false, true, null, and finite
+// floating-point numbers.
+func simpleValue(h head) (*jcstoken.Value, error) {
+	switch h.info {
+	case simpleFalse & 0x1f:
+		return &jcstoken.Value{Kind: jcstoken.KindBool, Str: "false"}, nil
+	case simpleTrue & 0x1f:
+		return &jcstoken.Value{Kind: jcstoken.KindBool, Str: "true"}, nil
+	case simpleNull & 0x1f:
+		return &jcstoken.Value{Kind: jcstoken.KindNull}, nil
+	case headFloat16 & 0x1f:
+		return floatValue(h, float16ToFloat64(uint16(h.arg)))
+	case headFloat32 & 0x1f:
+		return floatValue(h, float64(math.Float32frombits(uint32(h.arg))))
+	case headFloat64 & 0x1f:
+		return floatValue(h, math.Float64frombits(h.arg))
+	case 24:
+		if h.arg < 32 {
+			return nil, jcserr.New(jcserr.InvalidCBOR, h.offset, "jcscbor: two-byte simple value below 32")
+		}
+	}
+	return nil, jcserr.New(jcserr.UnsupportedCBOR, h.offset, fmt.Sprintf("jcscbor: simple value %d is not supported", h.arg))
+}
+
+// floatValue applies the project number profile to a decoded float.
+func floatValue(h head, f float64) (*jcstoken.Value, error) {
+	switch {
+	case math.IsNaN(f):
+		return nil, jcserr.New(jcserr.UnsupportedCBOR, h.offset, "jcscbor: NaN has no JSON representation")
+	case math.IsInf(f, 0):
+		// PROF-OFLOW-001
+		return nil, jcserr.New(jcserr.NumberOverflow, h.offset, "number overflows IEEE 754 double")
+	case f == 0 && math.Signbit(f):
+		// PROF-NEGZ-001
+		return nil, jcserr.New(jcserr.NumberNegZero, h.offset, "negative zero is not allowed")
+	}
+	return &jcstoken.Value{Kind: jcstoken.KindNumber, Num: f}, nil
+}
+
+// float16ToFloat64 widens an IEEE 754 binary16 value exactly.
+func float16ToFloat64(h uint16) float64 {
+	sign := 1.0
+	if h&0x8000 != 0 {
+		sign = -1
+	}
+	exp := int(h>>10) & 0x1f
+	mant := float64(h & 0x3ff)
+	switch exp {
+	case 0:
+		return sign * math.Ldexp(mant, -24)
+	case 0x1f:
+		if mant != 0 {
+			return math.NaN()
+		}
+		return math.Inf(int(sign))
+	default:
+		return sign * math.Ldexp(mant+1024, exp-25)
+	}
+}
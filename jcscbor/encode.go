@@ -0,0 +1,223 @@
+// Package jcscbor transcodes between jcstoken.Value trees and deterministically
+// encoded CBOR (RFC 8949 §4.2).
+//
+// Encode emits core deterministic CBOR: shortest-form arguments, definite
+// lengths, preferred (shortest exact) floating-point encoding, and map keys
+// sorted by the bytewise lexicographic order of their encodings. Because
+// every map key produced from JSON is a text string, that order coincides
+// with the length-first order of RFC 8949 §4.2.3, so output is valid under
+// both rule sets. Integral numbers are reduced to CBOR integers.
+//
+// Decode is strict: it accepts only CBOR data items that map into the I-JSON
+// domain of jcstoken.Value and rejects tags, byte strings, non-text map keys,
+// and other non-JSON items with classified *jcserr.Error values.
+package jcscbor
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+	"sort"
+	"unicode/utf8"
+
+	"github.com/lattice-substrate/json-canon/jcserr"
+	"github.com/lattice-substrate/json-canon/jcstoken"
+)
+
+// CBOR major types.
+const (
+	majorUnsigned = 0
+	majorNegative = 1
+	majorBytes    = 2
+	majorText     = 3
+	majorArray    = 4
+	majorMap      = 5
+	majorTag      = 6
+	majorSimple   = 7
+)
+
+// Simple values and float heads of major type 7.
+const (
+	simpleFalse = 0xf4
+	simpleTrue  = 0xf5
+	simpleNull  = 0xf6
+	headFloat16 = 0xf9
+	headFloat32 = 0xfa
+	headFloat64 = 0xfb
+)
+
+// twoTo64 is 2^64, the exclusive magnitude bound for CBOR integers.
+const twoTo64 = 18446744073709551616.0
+
+// Encode returns the deterministic CBOR encoding of v.
+//
+// CBOR-ENC-001: Arguments use the shortest form and lengths are definite.
+// CBOR-ENC-002: Map keys are sorted by the bytewise order of their encodings.
+func Encode(v *jcstoken.Value) ([]byte, error) {
+	if v == nil {
+		return nil, jcserr.New(jcserr.InternalError, -1, "jcscbor: nil value")
+	}
+	return appendValue(nil, v)
+}
+
+func appendValue(buf []byte, v *jcstoken.Value) ([]byte, error) {
+	switch v.Kind {
+	case jcstoken.KindNull:
+		return append(buf, simpleNull), nil
+	case jcstoken.KindBool:
+		switch v.Str {
+		case "true":
+			return append(buf, simpleTrue), nil
+		case "false":
+			return append(buf, simpleFalse), nil
+		}
+		return nil, jcserr.New(jcserr.InvalidGrammar, -1, fmt.Sprintf("jcscbor: invalid boolean payload %q", v.Str))
+	case jcstoken.KindNumber:
+		return appendNumber(buf, v.Num)
+	case jcstoken.KindString:
+		return appendText(buf, v.Str)
+	case jcstoken.KindArray:
+		buf = appendHead(buf, majorArray, uint64(len(v.Elems)))
+		for i := range v.Elems {
+			var err error
+			if buf, err = appendValue(buf, &v.Elems[i]); err != nil {
+				return nil, err
+			}
+		}
+		return buf, nil
+	case jcstoken.KindObject:
+		return appendMap(buf, v)
+	default:
+		return nil, jcserr.New(jcserr.InternalError, -1, fmt.Sprintf("jcscbor: unknown value kind %d", v.Kind))
+	}
+}
+
+// appendHead appends a data item head with the shortest argument encoding.
+func appendHead(buf []byte, major byte, arg uint64) []byte {
+	mt := major << 5
+	switch {
+	case arg < 24:
+		return append(buf, mt|byte(arg))
+	case arg <= math.MaxUint8:
+		return append(buf, mt|24, byte(arg))
+	case arg <= math.MaxUint16:
+		return append(buf, mt|25, byte(arg>>8), byte(arg))
+	case arg <= math.MaxUint32:
+		return append(buf, mt|26, byte(arg>>24), byte(arg>>16), byte(arg>>8), byte(arg))
+	default:
+		return append(buf, mt|27, byte(arg>>56), byte(arg>>48), byte(arg>>40), byte(arg>>32),
+			byte(arg>>24), byte(arg>>16), byte(arg>>8), byte(arg))
+	}
+}
+
+// appendNumber encodes integral values in (-2^64, 2^64) as CBOR integers and
+// all other values with the shortest float width that preserves them.
+//
+// CBOR-NUM-001: Integral numbers are reduced to major type 0 or 1.
+// CBOR-FLOAT-001: Non-integral numbers use preferred float serialization.
+func appendNumber(buf []byte, f float64) ([]byte, error) {
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		return nil, jcserr.New(jcserr.NumberOverflow, -1, "jcscbor: number is not finite")
+	}
+	if f == math.Trunc(f) && f > -twoTo64 && f < twoTo64 {
+		if f >= 0 {
+			return appendHead(buf, majorUnsigned, uint64(f)), nil
+		}
+		return appendHead(buf, majorNegative, uint64(-f)-1), nil
+	}
+	if h, ok := float16Bits(f); ok {
+		return append(buf, headFloat16, byte(h>>8), byte(h)), nil
+	}
+	if f32 := float32(f); float64(f32) == f {
+		b := math.Float32bits(f32)
+		return append(buf, headFloat32, byte(b>>24), byte(b>>16), byte(b>>8), byte(b)), nil
+	}
+	b := math.Float64bits(f)
+	return append(buf, headFloat64, byte(b>>56), byte(b>>48), byte(b>>40), byte(b>>32),
+		byte(b>>24), byte(b>>16), byte(b>>8), byte(b)), nil
+}
+
+// float16Bits returns the IEEE 754 binary16 encoding of a finite f when f is
+// exactly representable in binary16.
+func float16Bits(f float64) (uint16, bool) {
+	bits := math.Float64bits(f)
+	sign := uint16(bits>>48) & 0x8000
+	exp := int((bits>>52)&0x7ff) - 1023
+	mant := bits & (1<<52 - 1)
+	switch {
+	case f == 0:
+		return sign, true
+	case exp >= -14 && exp <= 15:
+		// Normal binary16: 10 explicit mantissa bits.
+		if mant&(1<<42-1) != 0 {
+			return 0, false
+		}
+		return sign | uint16(exp+15)<<10 | uint16(mant>>42), true
+	case exp >= -24 && exp < -14:
+		// Subnormal binary16: value = m × 2^-24 with m < 2^10.
+		m := (mant | 1<<52) >> uint(52-(exp+24))
+		if m<<uint(52-(exp+24)) != mant|1<<52 {
+			return 0, false
+		}
+		return sign | uint16(m), true
+	default:
+		return 0, false
+	}
+}
+
+func appendText(buf []byte, s string) ([]byte, error) {
+	if err := validateText(s, -1); err != nil {
+		return nil, err
+	}
+	buf = appendHead(buf, majorText, uint64(len(s)))
+	return append(buf, s...), nil
+}
+
+// validateText applies the jcstoken string domain to s.
+func validateText(s string, offset int) *jcserr.Error {
+	if !utf8.ValidString(s) {
+		return jcserr.New(jcserr.InvalidUTF8, offset, "jcscbor: text string is not valid UTF-8")
+	}
+	for _, r := range s {
+		if jcstoken.IsNoncharacter(r) {
+			return jcserr.New(jcserr.Noncharacter, offset, fmt.Sprintf("jcscbor: text string contains noncharacter U+%04X", r))
+		}
+		if r >= 0xD800 && r <= 0xDFFF {
+			return jcserr.New(jcserr.LoneSurrogate, offset, fmt.Sprintf("jcscbor: text string contains surrogate U+%04X", r))
+		}
+	}
+	return nil
+}
+
+type encodedMember struct {
+	key   []byte
+	name  string
+	value *jcstoken.Value
+}
+
+func appendMap(buf []byte, v *jcstoken.Value) ([]byte, error) {
+	members := make([]encodedMember, len(v.Members))
+	for i := range v.Members {
+		key, err := appendText(nil, v.Members[i].Key)
+		if err != nil {
+			return nil, err
+		}
+		members[i] = encodedMember{key: key, name: v.Members[i].Key, value: &v.Members[i].Value}
+	}
+	sort.Slice(members, func(i, j int) bool {
+		return bytes.Compare(members[i].key, members[j].key) < 0
+	})
+	buf = appendHead(buf, majorMap, uint64(len(members)))
+	for i := range members {
+		if i > 0 && bytes.Equal(members[i-1].key, members[i].key) {
+			return nil, jcserr.New(jcserr.DuplicateKey, -1,
+				fmt.Sprintf("jcscbor: duplicate object key %q", members[i].name))
+		}
+		buf = append(buf, members[i].key...)
+		var err error
+		if buf, err = appendValue(buf, members[i].value); err != nil {
+			return nil, err
+		}
+	}
+	return buf, nil
+}
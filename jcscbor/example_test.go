@@ -0,0 +1,35 @@
+package jcscbor_test
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/lattice-substrate/json-canon/jcs"
+	"github.com/lattice-substrate/json-canon/jcscbor"
+	"github.com/lattice-substrate/json-canon/jcstoken"
+)
+
+func ExampleEncode() {
+	v, err := jcstoken.Parse([]byte(`{"b":[1,2.5],"a":true}`))
+	if err != nil {
+		log.Fatal(err)
+	}
+	encoded, err := jcscbor.Encode(v)
+	if err != nil {
+		log.Fatal(err)
+	}
+	fmt.Printf("%x\n", encoded)
+
+	decoded, err := jcscbor.Decode(encoded)
+	if err != nil {
+		log.Fatal(err)
+	}
+	canonical, err := jcs.Serialize(decoded)
+	if err != nil {
+		log.Fatal(err)
+	}
+	fmt.Println(string(canonical))
+	// Output:
+	// a26161f561628201f94100
+	// {"a":true,"b":[1,2.5]}
+}
@@ -0,0 +1,37 @@
+package jcsdi_test
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/lattice-substrate/json-canon/jcsdi"
+	"github.com/lattice-substrate/json-canon/jcstoken"
+)
+
+func ExampleAddProof() {
+	const pub = "z6MkrJVnaZkeFzdQyMZu1cgjg7k1pZZ6pvBQ7XJPt4swbTQ2"
+	key, err := jcsdi.NewMultikey("did:key:"+pub+"#"+pub, "did:key:"+pub, pub,
+		"z3u2en7t5LR2WtQH5PfFqMqwVHBeXouLzo6haApm8XHqvjxq")
+	if err != nil {
+		log.Fatal(err)
+	}
+	doc, err := jcstoken.Parse([]byte(`{"claim":"example"}`))
+	if err != nil {
+		log.Fatal(err)
+	}
+	secured, err := jcsdi.AddProof(doc, jcsdi.ProofOptions{
+		Cryptosuite:        jcsdi.EdDSAJCS2022,
+		VerificationMethod: key.ID,
+		ProofPurpose:       "assertionMethod",
+	}, key)
+	if err != nil {
+		log.Fatal(err)
+	}
+	ring := &jcsdi.KeyRing{}
+	if err := ring.Add(key); err != nil {
+		log.Fatal(err)
+	}
+	fmt.Println(jcsdi.VerifyProof(secured, ring))
+	// Output:
+	// <nil>
+}
@@ -0,0 +1,89 @@
+package jcsdi
+
+import (
+	"fmt"
+
+	"github.com/lattice-substrate/json-canon/jcserr"
+)
+
+const base58Alphabet = "123456789ABCDEFGHJKLMNPQRSTUVWXYZabcdefghijkmnopqrstuvwxyz"
+
+// base58Index maps an ASCII byte to its base58 digit, or -1.
+var base58Index = func() [256]int8 {
+	var idx [256]int8
+	for i := range idx {
+		idx[i] = -1
+	}
+	for i := 0; i < len(base58Alphabet); i++ {
+		idx[base58Alphabet[i]] = int8(i)
+	}
+	return idx
+}()
+
+// encodeMultibase returns the base58-btc multibase ('z' header) encoding of b.
+func encodeMultibase(b []byte) string {
+	zeros := 0
+	for zeros < len(b) && b[zeros] == 0 {
+		zeros++
+	}
+	// log(256)/log(58) < 1.37, so this bounds the digit count.
+	digits := make([]byte, 0, len(b)*137/100+1)
+	for _, c := range b[zeros:] {
+		carry := int(c)
+		for i := range digits {
+			carry += int(digits[i]) << 8
+			digits[i] = byte(carry % 58)
+			carry /= 58
+		}
+		for carry > 0 {
+			digits = append(digits, byte(carry%58))
+			carry /= 58
+		}
+	}
+	out := make([]byte, 0, 1+zeros+len(digits))
+	out = append(out, 'z')
+	for i := 0; i < zeros; i++ {
+		out = append(out, base58Alphabet[0])
+	}
+	for i := len(digits) - 1; i >= 0; i-- {
+		out = append(out, base58Alphabet[digits[i]])
+	}
+	return string(out)
+}
+
+// decodeMultibase decodes a base58-btc multibase string. Other multibase
+// encodings are rejected.
+//
+// DI-KEY-001: Only the 'z' (base58-btc) multibase header is accepted.
+func decodeMultibase(s string) ([]byte, error) {
+	if s == "" || s[0] != 'z' {
+		return nil, jcserr.New(jcserr.InvalidKey, -1, "jcsdi: multibase value must use the base58-btc 'z' header")
+	}
+	s = s[1:]
+	zeros := 0
+	for zeros < len(s) && s[zeros] == base58Alphabet[0] {
+		zeros++
+	}
+	bytes := make([]byte, 0, len(s))
+	for i := zeros; i < len(s); i++ {
+		d := base58Index[s[i]]
+		if d < 0 {
+			return nil, jcserr.New(jcserr.InvalidKey, -1, fmt.Sprintf("jcsdi: invalid base58 character %q", s[i]))
+		}
+		carry := int(d)
+		for j := range bytes {
+			carry += int(bytes[j]) * 58
+			bytes[j] = byte(carry)
+			carry >>= 8
+		}
+		for carry > 0 {
+			bytes = append(bytes, byte(carry))
+			carry >>= 8
+		}
+	}
+	out := make([]byte, zeros, zeros+len(bytes))
+	for i := len(bytes) - 1; i >= 0; i-- {
+		out = append(out, bytes[i])
+	}
+	return out, nil
+}
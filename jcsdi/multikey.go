@@ -0,0 +1,251 @@
+package jcsdi
+
+import (
+	"crypto"
+	"crypto/ecdh"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"fmt"
+	"math/big"
+	"os"
+
+	"github.com/lattice-substrate/json-canon/jcserr"
+	"github.com/lattice-substrate/json-canon/jcstoken"
+)
+
+// Multicodec varint prefixes for Multikey key material.
+var (
+	prefixEd25519Pub  = []byte{0xed, 0x01}
+	prefixEd25519Priv = []byte{0x80, 0x26}
+	prefixP256Pub     = []byte{0x80, 0x24}
+	prefixP256Priv    = []byte{0x86, 0x26}
+	prefixP384Pub     = []byte{0x81, 0x24}
+	prefixP384Priv    = []byte{0x87, 0x26}
+)
+
+// Multikey is a verification method in the W3C Controlled Identifiers
+// Multikey format. Key material is decoded when the key is constructed; a
+// Multikey without SecretKeyMultibase can verify but not sign.
+type Multikey struct {
+	ID                 string
+	Controller         string
+	PublicKeyMultibase string
+	SecretKeyMultibase string
+
+	public  crypto.PublicKey
+	private crypto.Signer
+}
+
+// NewMultikey decodes publicKeyMultibase and, if non-empty,
+// secretKeyMultibase, and checks that both describe the same key pair.
+//
+// DI-KEY-001: Keys are base58-btc multibase with multicodec prefixes.
+func NewMultikey(id, controller, publicKeyMultibase, secretKeyMultibase string) (*Multikey, error) {
+	pub, err := decodePublicKey(publicKeyMultibase)
+	if err != nil {
+		return nil, err
+	}
+	k := &Multikey{
+		ID:                 id,
+		Controller:         controller,
+		PublicKeyMultibase: publicKeyMultibase,
+		SecretKeyMultibase: secretKeyMultibase,
+		public:             pub,
+	}
+	if secretKeyMultibase == "" {
+		return k, nil
+	}
+	priv, err := decodeSecretKey(secretKeyMultibase)
+	if err != nil {
+		return nil, err
+	}
+	if !publicKeysEqual(priv.Public(), pub) {
+		return nil, jcserr.New(jcserr.InvalidKey, -1, "jcsdi: secret key does not match public key")
+	}
+	k.private = priv
+	return k, nil
+}
+
+// ParseMultikey decodes a Multikey JSON document with members "id", "type"
+// ("Multikey"), "controller", "publicKeyMultibase", and optionally
+// "secretKeyMultibase".
+func ParseMultikey(data []byte) (*Multikey, error) {
+	v, err := jcstoken.Parse(data)
+	if err != nil {
+		return nil, err //nolint:wrapcheck // DI-KEY-001: preserve parser failure class unchanged.
+	}
+	if v.Kind != jcstoken.KindObject {
+		return nil, jcserr.New(jcserr.InvalidKey, -1, "jcsdi: multikey document must be an object")
+	}
+	if typ, _ := stringMember(v, "type"); typ != "Multikey" {
+		return nil, jcserr.New(jcserr.InvalidKey, -1, fmt.Sprintf("jcsdi: unsupported key type %q", typ))
+	}
+	id, _ := stringMember(v, "id")
+	if id == "" {
+		return nil, jcserr.New(jcserr.InvalidKey, -1, "jcsdi: multikey document requires an id")
+	}
+	controller, _ := stringMember(v, "controller")
+	pub, _ := stringMember(v, "publicKeyMultibase")
+	sec, _ := stringMember(v, "secretKeyMultibase")
+	return NewMultikey(id, controller, pub, sec)
+}
+
+// ReadMultikeyFile reads and decodes a Multikey JSON document from path.
+// Key resolution is local only; no network lookups are performed.
+func ReadMultikeyFile(path string) (*Multikey, error) {
+	data, err := os.ReadFile(path) //nolint:gosec // REQ:DI-KEY-001 caller-selected key file path.
+	if err != nil {
+		return nil, jcserr.Wrap(jcserr.InvalidKey, -1, fmt.Sprintf("jcsdi: read key file %q", path), err)
+	}
+	return ParseMultikey(data)
+}
+
+// Public returns the decoded public key: ed25519.PublicKey or
+// *ecdsa.PublicKey.
+func (k *Multikey) Public() crypto.PublicKey {
+	return k.public
+}
+
+// CanSign reports whether k holds secret key material.
+func (k *Multikey) CanSign() bool {
+	return k.private != nil
+}
+
+// Resolver maps a verificationMethod identifier to a key.
+type Resolver interface {
+	Resolve(verificationMethod string) (*Multikey, error)
+}
+
+// KeyRing is an in-memory Resolver keyed by Multikey ID.
+type KeyRing struct {
+	keys []*Multikey
+}
+
+// Add registers k. Duplicate IDs are rejected.
+func (r *KeyRing) Add(k *Multikey) error {
+	for _, existing := range r.keys {
+		if existing.ID == k.ID {
+			return jcserr.New(jcserr.InvalidKey, -1, fmt.Sprintf("jcsdi: duplicate key id %q", k.ID))
+		}
+	}
+	r.keys = append(r.keys, k)
+	return nil
+}
+
+// Resolve returns the key whose ID equals verificationMethod.
+func (r *KeyRing) Resolve(verificationMethod string) (*Multikey, error) {
+	for _, k := range r.keys {
+		if k.ID == verificationMethod {
+			return k, nil
+		}
+	}
+	return nil, jcserr.New(jcserr.InvalidKey, -1,
+		fmt.Sprintf("jcsdi: verification method %q not found", verificationMethod))
+}
+
+// LoadKeyRing reads each path with ReadMultikeyFile into a new KeyRing.
+func LoadKeyRing(paths ...string) (*KeyRing, error) {
+	r := &KeyRing{}
+	for _, p := range paths {
+		k, err := ReadMultikeyFile(p)
+		if err != nil {
+			return nil, err
+		}
+		if err := r.Add(k); err != nil {
+			return nil, err
+		}
+	}
+	return r, nil
+}
+
+func decodePublicKey(s string) (crypto.PublicKey, error) {
+	raw, err := decodeMultibase(s)
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case hasPrefix(raw, prefixEd25519Pub, ed25519.PublicKeySize):
+		return ed25519.PublicKey(raw[2:]), nil
+	case hasPrefix(raw, prefixP256Pub, 33):
+		return decompress(elliptic.P256(), raw[2:])
+	case hasPrefix(raw, prefixP384Pub, 49):
+		return decompress(elliptic.P384(), raw[2:])
+	default:
+		return nil, jcserr.New(jcserr.InvalidKey, -1, "jcsdi: unsupported public key multicodec")
+	}
+}
+
+func decodeSecretKey(s string) (crypto.Signer, error) {
+	raw, err := decodeMultibase(s)
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case hasPrefix(raw, prefixEd25519Priv, ed25519.SeedSize):
+		return ed25519.NewKeyFromSeed(raw[2:]), nil
+	case hasPrefix(raw, prefixP256Priv, 32):
+		return ecdsaPrivate(elliptic.P256(), ecdh.P256(), raw[2:])
+	case hasPrefix(raw, prefixP384Priv, 48):
+		return ecdsaPrivate(elliptic.P384(), ecdh.P384(), raw[2:])
+	default:
+		return nil, jcserr.New(jcserr.InvalidKey, -1, "jcsdi: unsupported secret key multicodec")
+	}
+}
+
+func hasPrefix(raw, prefix []byte, keyLen int) bool {
+	return len(raw) == len(prefix)+keyLen && raw[0] == prefix[0] && raw[1] == prefix[1]
+}
+
+func decompress(curve elliptic.Curve, b []byte) (*ecdsa.PublicKey, error) {
+	x, y := elliptic.UnmarshalCompressed(curve, b)
+	if x == nil {
+		return nil, jcserr.New(jcserr.InvalidKey, -1, "jcsdi: invalid compressed public key")
+	}
+	return &ecdsa.PublicKey{Curve: curve, X: x, Y: y}, nil
+}
+
+func ecdsaPrivate(curve elliptic.Curve, ec ecdh.Curve, scalar []byte) (*ecdsa.PrivateKey, error) {
+	k, err := ec.NewPrivateKey(scalar)
+	if err != nil {
+		return nil, jcserr.Wrap(jcserr.InvalidKey, -1, "jcsdi: invalid secret key", err)
+	}
+	point := k.PublicKey().Bytes() // 0x04 || X || Y
+	size := (len(point) - 1) / 2
+	return &ecdsa.PrivateKey{
+		PublicKey: ecdsa.PublicKey{
+			Curve: curve,
+			X:     new(big.Int).SetBytes(point[1 : 1+size]),
+			Y:     new(big.Int).SetBytes(point[1+size:]),
+		},
+		D: new(big.Int).SetBytes(scalar),
+	}, nil
+}
+
+func publicKeysEqual(a, b crypto.PublicKey) bool {
+	type equaler interface{ Equal(crypto.PublicKey) bool }
+	e, ok := a.(equaler)
+	return ok && e.Equal(b)
+}
+
+// EncodePublicKeyMultibase returns the Multikey publicKeyMultibase form of
+// an ed25519.PublicKey or a P-256/P-384 *ecdsa.PublicKey.
+func EncodePublicKeyMultibase(pub crypto.PublicKey) (string, error) {
+	switch k := pub.(type) {
+	case ed25519.PublicKey:
+		return encodeMultibase(append(append([]byte{}, prefixEd25519Pub...), k...)), nil
+	case *ecdsa.PublicKey:
+		var prefix []byte
+		switch k.Curve {
+		case elliptic.P256():
+			prefix = prefixP256Pub
+		case elliptic.P384():
+			prefix = prefixP384Pub
+		default:
+			return "", jcserr.New(jcserr.InvalidKey, -1, "jcsdi: unsupported ecdsa curve")
+		}
+		return encodeMultibase(append(append([]byte{}, prefix...), elliptic.MarshalCompressed(k.Curve, k.X, k.Y)...)), nil
+	default:
+		return "", jcserr.New(jcserr.InvalidKey, -1, fmt.Sprintf("jcsdi: unsupported public key type %T", pub))
+	}
+}
@@ -0,0 +1,393 @@
+// Package jcsdi implements the JCS-based W3C Data Integrity cryptosuites
+// eddsa-jcs-2022 (VC Data Integrity EdDSA Cryptosuites v1.0, §3.3) and
+// ecdsa-jcs-2019 (VC Data Integrity ECDSA Cryptosuites v1.0, §3.3).
+//
+// Both suites canonicalize the proof configuration and the unsecured document
+// with RFC 8785 (this module's jcs package), hash each canonical form, and
+// sign the concatenation proofConfigHash || documentHash. Keys are Multikey
+// verification methods resolved locally through a Resolver; no network or
+// DID resolution is performed. ECDSA signatures use RFC 6979 deterministic
+// nonces, so CreateProof output is a pure function of its inputs.
+package jcsdi
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/asn1"
+	"fmt"
+	"math/big"
+	"regexp"
+
+	"github.com/lattice-substrate/json-canon/jcs"
+	"github.com/lattice-substrate/json-canon/jcserr"
+	"github.com/lattice-substrate/json-canon/jcstoken"
+)
+
+const (
+	// ProofType is the only proof type produced and accepted.
+	ProofType = "DataIntegrityProof"
+	// EdDSAJCS2022 is the Ed25519 JCS cryptosuite identifier.
+	EdDSAJCS2022 = "eddsa-jcs-2022"
+	// ECDSAJCS2019 is the P-256/P-384 JCS cryptosuite identifier.
+	ECDSAJCS2019 = "ecdsa-jcs-2019"
+)
+
+// ProofOptions are the proof options of the Data Integrity create-proof
+// algorithm. Empty optional fields are omitted from the proof.
+type ProofOptions struct {
+	// Cryptosuite is EdDSAJCS2022 or ECDSAJCS2019.
+	Cryptosuite string
+	// VerificationMethod identifies the signing key; it is resolved by ID.
+	VerificationMethod string
+	// ProofPurpose is e.g. "assertionMethod".
+	ProofPurpose string
+	// Created and Expires are optional XML Schema dateTimeStamp values.
+	Created string
+	Expires string
+	// ID, Domain, Challenge, and Nonce are optional proof members.
+	ID        string
+	Domain    string
+	Challenge string
+	Nonce     string
+}
+
+// dateTimeStamp matches the XML Schema 1.1 dateTimeStamp lexical space.
+var dateTimeStamp = regexp.MustCompile(
+	`^-?[0-9]{4,}-[0-9]{2}-[0-9]{2}T[0-9]{2}:[0-9]{2}:[0-9]{2}(\.[0-9]+)?(Z|[+-][0-9]{2}:[0-9]{2})$`)
+
+// CreateProof returns a DataIntegrityProof over doc signed with key. The
+// document's own "proof" member, if any, is not covered by the signature, so
+// the result can be appended to an existing proof set with AddProof.
+//
+// DI-CREATE-001: proofValue = multibase(sign(H(JCS(proofConfig)) || H(JCS(document)))).
+// DI-CREATE-002: Proof options are validated before signing.
+func CreateProof(doc *jcstoken.Value, opts ProofOptions, key *Multikey) (*jcstoken.Value, error) {
+	if doc == nil || doc.Kind != jcstoken.KindObject {
+		return nil, jcserr.New(jcserr.InvalidProof, -1, "jcsdi: document must be a JSON object")
+	}
+	if key == nil || !key.CanSign() {
+		return nil, jcserr.New(jcserr.InvalidKey, -1, "jcsdi: signing requires a key with secret key material")
+	}
+	h, err := suiteHash(opts.Cryptosuite, key.Public())
+	if err != nil {
+		return nil, err
+	}
+	proof, err := proofConfig(opts)
+	if err != nil {
+		return nil, err
+	}
+	if ctx := member(doc, "@context"); ctx != nil {
+		setMember(proof, "@context", *ctx.Clone())
+	}
+	hashData, err := hashData(h, proof, withoutMember(doc, "proof"))
+	if err != nil {
+		return nil, err
+	}
+	sig, err := sign(key.private, h, hashData)
+	if err != nil {
+		return nil, err
+	}
+	setMember(proof, "proofValue", stringValue(encodeMultibase(sig)))
+	return proof, nil
+}
+
+// AddProof returns a copy of doc secured with a new proof. An existing single
+// proof becomes a proof set (array) with the new proof appended.
+func AddProof(doc *jcstoken.Value, opts ProofOptions, key *Multikey) (*jcstoken.Value, error) {
+	proof, err := CreateProof(doc, opts, key)
+	if err != nil {
+		return nil, err
+	}
+	out := doc.Clone()
+	existing := member(out, "proof")
+	switch {
+	case existing == nil:
+		setMember(out, "proof", *proof)
+	case existing.Kind == jcstoken.KindArray:
+		existing.Elems = append(existing.Elems, *proof)
+	default:
+		setMember(out, "proof", jcstoken.Value{Kind: jcstoken.KindArray, Elems: []jcstoken.Value{*existing, *proof}})
+	}
+	return out, nil
+}
+
+func proofConfig(opts ProofOptions) (*jcstoken.Value, error) {
+	if opts.VerificationMethod == "" || opts.ProofPurpose == "" {
+		return nil, jcserr.New(jcserr.InvalidProof, -1, "jcsdi: verificationMethod and proofPurpose are required")
+	}
+	for _, ts := range []string{opts.Created, opts.Expires} {
+		if ts != "" && !dateTimeStamp.MatchString(ts) {
+			return nil, jcserr.New(jcserr.InvalidProof, -1, fmt.Sprintf("jcsdi: %q is not a dateTimeStamp", ts))
+		}
+	}
+	proof := &jcstoken.Value{Kind: jcstoken.KindObject}
+	fields := []struct{ name, value string }{
+		{"type", ProofType},
+		{"cryptosuite", opts.Cryptosuite},
+		{"verificationMethod", opts.VerificationMethod},
+		{"proofPurpose", opts.ProofPurpose},
+		{"created", opts.Created},
+		{"expires", opts.Expires},
+		{"id", opts.ID},
+		{"domain", opts.Domain},
+		{"challenge", opts.Challenge},
+		{"nonce", opts.Nonce},
+	}
+	for _, f := range fields {
+		if f.value != "" {
+			setMember(proof, f.name, stringValue(f.value))
+		}
+	}
+	return proof, nil
+}
+
+// VerifyProof verifies every proof in secured (a single proof object or a
+// proof set array) against the document without its "proof" member. Keys are
+// obtained from resolver by verificationMethod.
+//
+// DI-VERIFY-001: Verification recomputes hashData and checks the signature.
+// DI-VERIFY-002: A proof @context MUST be a prefix of the document @context.
+func VerifyProof(secured *jcstoken.Value, resolver Resolver) error {
+	if secured == nil || secured.Kind != jcstoken.KindObject {
+		return jcserr.New(jcserr.InvalidProof, -1, "jcsdi: secured document must be a JSON object")
+	}
+	proofs := member(secured, "proof")
+	if proofs == nil {
+		return jcserr.New(jcserr.InvalidProof, -1, "jcsdi: document has no proof")
+	}
+	unsecured := withoutMember(secured, "proof")
+	if proofs.Kind != jcstoken.KindArray {
+		return verifyOne(unsecured, proofs, resolver)
+	}
+	if len(proofs.Elems) == 0 {
+		return jcserr.New(jcserr.InvalidProof, -1, "jcsdi: proof set is empty")
+	}
+	for i := range proofs.Elems {
+		if err := verifyOne(unsecured, &proofs.Elems[i], resolver); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func verifyOne(unsecured, proof *jcstoken.Value, resolver Resolver) error {
+	sig, err := proofSignature(proof)
+	if err != nil {
+		return err
+	}
+	options := withoutMember(proof, "proofValue")
+	doc, err := applyProofContext(unsecured, options)
+	if err != nil {
+		return err
+	}
+	vm, _ := stringMember(options, "verificationMethod")
+	key, err := resolver.Resolve(vm)
+	if err != nil {
+		return err //nolint:wrapcheck // DI-VERIFY-001: preserve resolver failure class unchanged.
+	}
+	suiteName, _ := stringMember(options, "cryptosuite")
+	h, err := suiteHash(suiteName, key.Public())
+	if err != nil {
+		return err
+	}
+	data, err := hashData(h, options, doc)
+	if err != nil {
+		return err
+	}
+	if !verifySignature(key.Public(), h, data, sig) {
+		return jcserr.New(jcserr.SignatureInvalid, -1, fmt.Sprintf("jcsdi: %s signature does not verify", suiteName))
+	}
+	return nil
+}
+
+// proofSignature checks the proof's shape and returns its decoded proofValue.
+func proofSignature(proof *jcstoken.Value) ([]byte, error) {
+	if proof.Kind != jcstoken.KindObject {
+		return nil, jcserr.New(jcserr.InvalidProof, -1, "jcsdi: proof must be a JSON object")
+	}
+	if typ, _ := stringMember(proof, "type"); typ != ProofType {
+		return nil, jcserr.New(jcserr.InvalidProof, -1, fmt.Sprintf("jcsdi: unsupported proof type %q", typ))
+	}
+	for _, name := range []string{"created", "expires"} {
+		if ts, ok := stringMember(proof, name); ok && !dateTimeStamp.MatchString(ts) {
+			return nil, jcserr.New(jcserr.InvalidProof, -1, fmt.Sprintf("jcsdi: proof %s %q is not a dateTimeStamp", name, ts))
+		}
+	}
+	proofValue, _ := stringMember(proof, "proofValue")
+	sig, err := decodeMultibase(proofValue)
+	if err != nil {
+		return nil, jcserr.Wrap(jcserr.InvalidProof, -1, "jcsdi: malformed proofValue", err)
+	}
+	return sig, nil
+}
+
+// applyProofContext implements the @context check of the JCS verify-proof
+// algorithm and returns the document to be hashed.
+func applyProofContext(unsecured, options *jcstoken.Value) (*jcstoken.Value, error) {
+	proofCtx := member(options, "@context")
+	if proofCtx == nil {
+		return unsecured, nil
+	}
+	want := contextList(proofCtx)
+	have := contextList(member(unsecured, "@context"))
+	if len(want) > len(have) {
+		return nil, jcserr.New(jcserr.InvalidProof, -1, "jcsdi: proof @context is not a prefix of the document @context")
+	}
+	for i := range want {
+		a, errA := jcs.Serialize(&want[i])
+		b, errB := jcs.Serialize(&have[i])
+		if errA != nil || errB != nil || string(a) != string(b) {
+			return nil, jcserr.New(jcserr.InvalidProof, -1, "jcsdi: proof @context is not a prefix of the document @context")
+		}
+	}
+	doc := unsecured.Clone()
+	setMember(doc, "@context", *proofCtx.Clone())
+	return doc, nil
+}
+
+func contextList(v *jcstoken.Value) []jcstoken.Value {
+	switch {
+	case v == nil:
+		return nil
+	case v.Kind == jcstoken.KindArray:
+		return v.Elems
+	default:
+		return []jcstoken.Value{*v}
+	}
+}
+
+// suiteHash returns the hash function for the cryptosuite and key type.
+//
+// DI-SUITE-001: eddsa-jcs-2022 uses Ed25519 with SHA-256; ecdsa-jcs-2019 uses
+// P-256 with SHA-256 or P-384 with SHA-384.
+func suiteHash(suite string, pub crypto.PublicKey) (crypto.Hash, error) {
+	switch suite {
+	case EdDSAJCS2022:
+		if _, ok := pub.(ed25519.PublicKey); ok {
+			return crypto.SHA256, nil
+		}
+	case ECDSAJCS2019:
+		if k, ok := pub.(*ecdsa.PublicKey); ok {
+			switch k.Curve {
+			case elliptic.P256():
+				return crypto.SHA256, nil
+			case elliptic.P384():
+				return crypto.SHA384, nil
+			}
+		}
+	default:
+		return 0, jcserr.New(jcserr.InvalidProof, -1, fmt.Sprintf("jcsdi: unsupported cryptosuite %q", suite))
+	}
+	return 0, jcserr.New(jcserr.InvalidKey, -1, fmt.Sprintf("jcsdi: key type %T not valid for %s", pub, suite))
+}
+
+// hashData returns H(JCS(config)) || H(JCS(doc)).
+func hashData(h crypto.Hash, config, doc *jcstoken.Value) ([]byte, error) {
+	canonConfig, err := jcs.Serialize(config)
+	if err != nil {
+		return nil, err //nolint:wrapcheck // DI-CREATE-001: preserve jcs failure class unchanged.
+	}
+	canonDoc, err := jcs.Serialize(doc)
+	if err != nil {
+		return nil, err //nolint:wrapcheck // DI-CREATE-001: preserve jcs failure class unchanged.
+	}
+	out := digest(h, canonConfig)
+	return append(out, digest(h, canonDoc)...), nil
+}
+
+func digest(h crypto.Hash, b []byte) []byte {
+	if h == crypto.SHA384 {
+		sum := sha512.Sum384(b)
+		return sum[:]
+	}
+	sum := sha256.Sum256(b)
+	return sum[:]
+}
+
+func sign(priv crypto.Signer, h crypto.Hash, data []byte) ([]byte, error) {
+	switch k := priv.(type) {
+	case ed25519.PrivateKey:
+		return ed25519.Sign(k, data), nil
+	case *ecdsa.PrivateKey:
+		// A nil random source selects RFC 6979 deterministic nonces.
+		der, err := k.Sign(nil, digest(h, data), h)
+		if err != nil {
+			return nil, jcserr.Wrap(jcserr.InternalError, -1, "jcsdi: ecdsa signing failed", err)
+		}
+		var rs struct{ R, S *big.Int }
+		if _, err := asn1.Unmarshal(der, &rs); err != nil {
+			return nil, jcserr.Wrap(jcserr.InternalError, -1, "jcsdi: ecdsa signature encoding", err)
+		}
+		size := (k.Curve.Params().BitSize + 7) / 8
+		sig := make([]byte, 2*size)
+		rs.R.FillBytes(sig[:size])
+		rs.S.FillBytes(sig[size:])
+		return sig, nil
+	default:
+		return nil, jcserr.New(jcserr.InvalidKey, -1, fmt.Sprintf("jcsdi: unsupported private key type %T", priv))
+	}
+}
+
+func verifySignature(pub crypto.PublicKey, h crypto.Hash, data, sig []byte) bool {
+	switch k := pub.(type) {
+	case ed25519.PublicKey:
+		return len(sig) == ed25519.SignatureSize && ed25519.Verify(k, data, sig)
+	case *ecdsa.PublicKey:
+		size := (k.Curve.Params().BitSize + 7) / 8
+		if len(sig) != 2*size {
+			return false
+		}
+		r := new(big.Int).SetBytes(sig[:size])
+		s := new(big.Int).SetBytes(sig[size:])
+		return ecdsa.Verify(k, digest(h, data), r, s)
+	default:
+		return false
+	}
+}
+
+func member(obj *jcstoken.Value, name string) *jcstoken.Value {
+	if obj == nil || obj.Kind != jcstoken.KindObject {
+		return nil
+	}
+	for i := range obj.Members {
+		if obj.Members[i].Key == name {
+			return &obj.Members[i].Value
+		}
+	}
+	return nil
+}
+
+func stringMember(obj *jcstoken.Value, name string) (string, bool) {
+	v := member(obj, name)
+	if v == nil || v.Kind != jcstoken.KindString {
+		return "", false
+	}
+	return v.Str, true
+}
+
+func setMember(obj *jcstoken.Value, name string, v jcstoken.Value) {
+	if existing := member(obj, name); existing != nil {
+		*existing = v
+		return
+	}
+	obj.Members = append(obj.Members, jcstoken.Member{Key: name, Value: v})
+}
+
+// withoutMember returns a deep copy of obj without the named member.
+func withoutMember(obj *jcstoken.Value, name string) *jcstoken.Value {
+	out := &jcstoken.Value{Kind: jcstoken.KindObject, Members: make([]jcstoken.Member, 0, len(obj.Members))}
+	for i := range obj.Members {
+		if obj.Members[i].Key != name {
+			out.Members = append(out.Members, jcstoken.Member{Key: obj.Members[i].Key, Value: *obj.Members[i].Value.Clone()})
+		}
+	}
+	return out
+}
+
+func stringValue(s string) jcstoken.Value {
+	return jcstoken.Value{Kind: jcstoken.KindString, Str: s}
+}
@@ -0,0 +1,352 @@
+package jcsdi_test
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/lattice-substrate/json-canon/jcs"
+	"github.com/lattice-substrate/json-canon/jcsdi"
+	"github.com/lattice-substrate/json-canon/jcserr"
+	"github.com/lattice-substrate/json-canon/jcstoken"
+)
+
+// Example key pairs from the W3C VC Data Integrity EdDSA and ECDSA
+// cryptosuite specifications.
+const (
+	edPub   = "z6MkrJVnaZkeFzdQyMZu1cgjg7k1pZZ6pvBQ7XJPt4swbTQ2"
+	edSec   = "z3u2en7t5LR2WtQH5PfFqMqwVHBeXouLzo6haApm8XHqvjxq"
+	p256Pub = "zDnaepBuvsQ8cpsWrVKw8fbpGpvPeNSjVPTWoq6cRqaYzBKVP"
+	p256Sec = "z42twTcNeSYcnqg1FLuSFs2bsGH3ZqbRHFmvS9XMsYhjxvHN"
+	p384Pub = "z82LkuBieyGShVBhvtE2zoiD6Kma4tJGFtkAhxR5pfkp5QPw4LutoYWhvQCnGjdVn14kujQ"
+	p384Sec = "z2fanyY7zgwNpZGxX5fXXibvScNaUWNprHU9dKx7qpVj7mws9J8LLt4mDB5TyH2GLHWkUc"
+)
+
+const credential = `{"@context":["https://www.w3.org/ns/credentials/v2","https://www.w3.org/ns/credentials/examples/v2"],"id":"urn:uuid:58172aac-d8ba-11ed-83dd-0b3aef56cc33","type":["VerifiableCredential","AlumniCredential"],"name":"Alumni Credential","description":"A minimum viable example of an Alumni Credential.","issuer":"https://vc.example/issuers/5678","validFrom":"2023-01-01T00:00:00Z","credentialSubject":{"id":"did:example:abcdefgh","alumniOf":"The School of Examples"}}`
+
+func mustKey(t *testing.T, pub, sec string) *jcsdi.Multikey {
+	t.Helper()
+	k, err := jcsdi.NewMultikey("did:key:"+pub+"#"+pub, "did:key:"+pub, pub, sec)
+	if err != nil {
+		t.Fatalf("NewMultikey(%s): %v", pub, err)
+	}
+	return k
+}
+
+func mustParse(t *testing.T, in string) *jcstoken.Value {
+	t.Helper()
+	v, err := jcstoken.Parse([]byte(in))
+	if err != nil {
+		t.Fatalf("parse %q: %v", in, err)
+	}
+	return v
+}
+
+func assertClass(t *testing.T, err error, want jcserr.FailureClass) {
+	t.Helper()
+	var je *jcserr.Error
+	if !errors.As(err, &je) {
+		t.Fatalf("expected *jcserr.Error, got %T: %v", err, err)
+	}
+	if je.Class != want {
+		t.Fatalf("expected %s, got %s: %v", want, je.Class, err)
+	}
+}
+
+func options(suite string, k *jcsdi.Multikey) jcsdi.ProofOptions {
+	return jcsdi.ProofOptions{
+		Cryptosuite:        suite,
+		VerificationMethod: k.ID,
+		ProofPurpose:       "assertionMethod",
+		Created:            "2023-02-24T23:36:38Z",
+	}
+}
+
+func ring(t *testing.T, keys ...*jcsdi.Multikey) *jcsdi.KeyRing {
+	t.Helper()
+	r := &jcsdi.KeyRing{}
+	for _, k := range keys {
+		if err := r.Add(k); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return r
+}
+
+func secure(t *testing.T, doc string, suite string, k *jcsdi.Multikey) *jcstoken.Value {
+	t.Helper()
+	out, err := jcsdi.AddProof(mustParse(t, doc), options(suite, k), k)
+	if err != nil {
+		t.Fatalf("AddProof(%s): %v", suite, err)
+	}
+	return out
+}
+
+// === DI-KEY-001: Multikey decoding ===
+
+func TestNewMultikey_DI_KEY_001(t *testing.T) {
+	if _, ok := mustKey(t, edPub, edSec).Public().(ed25519.PublicKey); !ok {
+		t.Fatal("ed25519 key did not decode to ed25519.PublicKey")
+	}
+	for _, tc := range []struct {
+		pub, sec string
+		curve    elliptic.Curve
+	}{{p256Pub, p256Sec, elliptic.P256()}, {p384Pub, p384Sec, elliptic.P384()}} {
+		k, ok := mustKey(t, tc.pub, tc.sec).Public().(*ecdsa.PublicKey)
+		if !ok || k.Curve != tc.curve {
+			t.Fatalf("%s: unexpected public key %T", tc.pub, k)
+		}
+		round, err := jcsdi.EncodePublicKeyMultibase(k)
+		if err != nil || round != tc.pub {
+			t.Fatalf("EncodePublicKeyMultibase: got %q, %v want %q", round, err, tc.pub)
+		}
+	}
+	if k := mustKey(t, edPub, ""); k.CanSign() {
+		t.Fatal("public-only key reports CanSign")
+	}
+	bad := []struct{ pub, sec string }{
+		{"", ""},
+		{"u" + edPub[1:], ""},      // non-base58btc multibase header
+		{"z0OIl", ""},              // characters outside the base58 alphabet
+		{edPub[:len(edPub)-1], ""}, // truncated key material
+		{p256Sec, ""},              // secret key prefix in the public slot
+		{edPub, p256Sec},           // secret from a different key pair
+	}
+	for _, tc := range bad {
+		_, err := jcsdi.NewMultikey("k", "", tc.pub, tc.sec)
+		assertClass(t, err, jcserr.InvalidKey)
+	}
+}
+
+func TestParseMultikey(t *testing.T) {
+	doc := `{"id":"did:key:` + edPub + `#` + edPub + `","type":"Multikey","controller":"did:key:` + edPub +
+		`","publicKeyMultibase":"` + edPub + `","secretKeyMultibase":"` + edSec + `"}`
+	k, err := jcsdi.ParseMultikey([]byte(doc))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !k.CanSign() || k.Controller != "did:key:"+edPub {
+		t.Fatalf("unexpected key: %+v", k)
+	}
+	_, err = jcsdi.ParseMultikey([]byte(`{"id":"k","type":"JsonWebKey","publicKeyMultibase":"` + edPub + `"}`))
+	assertClass(t, err, jcserr.InvalidKey)
+	_, err = jcsdi.ParseMultikey([]byte(`{"type":"Multikey","publicKeyMultibase":"` + edPub + `"}`))
+	assertClass(t, err, jcserr.InvalidKey)
+	_, err = jcsdi.ParseMultikey([]byte(`{"id":"k",}`))
+	assertClass(t, err, jcserr.InvalidGrammar)
+	_, err = jcsdi.ReadMultikeyFile("testdata/does-not-exist.json")
+	assertClass(t, err, jcserr.InvalidKey)
+}
+
+func TestKeyRing(t *testing.T) {
+	k := mustKey(t, edPub, "")
+	r := ring(t, k)
+	assertClass(t, r.Add(k), jcserr.InvalidKey)
+	if got, err := r.Resolve(k.ID); err != nil || got != k {
+		t.Fatalf("Resolve: got %v, %v", got, err)
+	}
+	_, err := r.Resolve("did:example:unknown#key")
+	assertClass(t, err, jcserr.InvalidKey)
+}
+
+// === DI-SUITE-001: Cryptosuite key and hash binding ===
+
+func TestCreateProof_DI_SUITE_001(t *testing.T) {
+	ed := mustKey(t, edPub, edSec)
+	p256 := mustKey(t, p256Pub, p256Sec)
+	_, err := jcsdi.CreateProof(mustParse(t, credential), options(jcsdi.ECDSAJCS2019, ed), ed)
+	assertClass(t, err, jcserr.InvalidKey)
+	_, err = jcsdi.CreateProof(mustParse(t, credential), options(jcsdi.EdDSAJCS2022, p256), p256)
+	assertClass(t, err, jcserr.InvalidKey)
+	_, err = jcsdi.CreateProof(mustParse(t, credential), options("eddsa-rdfc-2022", ed), ed)
+	assertClass(t, err, jcserr.InvalidProof)
+
+	// A verification method that resolves to a key of the wrong type fails
+	// before any signature check.
+	secured := secure(t, credential, jcsdi.EdDSAJCS2022, ed)
+	impostor, err := jcsdi.NewMultikey(ed.ID, "", p256Pub, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertClass(t, jcsdi.VerifyProof(secured, ring(t, impostor)), jcserr.InvalidKey)
+}
+
+// === DI-CREATE-001: proofValue signs proofConfigHash || documentHash ===
+
+func TestCreateProof_DI_CREATE_001(t *testing.T) {
+	ed := mustKey(t, edPub, edSec)
+	proof, err := jcsdi.CreateProof(mustParse(t, credential), options(jcsdi.EdDSAJCS2022, ed), ed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := jcs.Serialize(proof)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Published proofValue for the alumni credential in the EdDSA
+	// cryptosuite specification's eddsa-jcs-2022 example.
+	want := `{"@context":["https://www.w3.org/ns/credentials/v2","https://www.w3.org/ns/credentials/examples/v2"],` +
+		`"created":"2023-02-24T23:36:38Z","cryptosuite":"eddsa-jcs-2022","proofPurpose":"assertionMethod",` +
+		`"proofValue":"z2HnFSSPPBzR36zdDgK8PbEHeXbR56YF24jwMpt3R1eHXQzJDMWS93FCzpvJpwTWd3GAVFuUfjoJdcnTMuVor51aX",` +
+		`"type":"DataIntegrityProof","verificationMethod":"did:key:` + edPub + `#` + edPub + `"}`
+	if string(got) != want {
+		t.Fatalf("proof mismatch:\n got %s\nwant %s", got, want)
+	}
+
+	// ECDSA uses RFC 6979 nonces, so proofs are reproducible.
+	for _, k := range []*jcsdi.Multikey{mustKey(t, p256Pub, p256Sec), mustKey(t, p384Pub, p384Sec)} {
+		a := secure(t, credential, jcsdi.ECDSAJCS2019, k)
+		b := secure(t, credential, jcsdi.ECDSAJCS2019, k)
+		if ca, cb := mustSerialize(t, a), mustSerialize(t, b); ca != cb {
+			t.Fatalf("ecdsa proof not deterministic:\n%s\n%s", ca, cb)
+		}
+	}
+}
+
+func mustSerialize(t *testing.T, v *jcstoken.Value) string {
+	t.Helper()
+	out, err := jcs.Serialize(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(out)
+}
+
+func TestAddProofBuildsProofSet(t *testing.T) {
+	ed := mustKey(t, edPub, edSec)
+	p384 := mustKey(t, p384Pub, p384Sec)
+	once := secure(t, credential, jcsdi.EdDSAJCS2022, ed)
+	twice, err := jcsdi.AddProof(once, options(jcsdi.ECDSAJCS2019, p384), p384)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := jcsdi.VerifyProof(twice, ring(t, ed, p384)); err != nil {
+		t.Fatalf("proof set: %v", err)
+	}
+	if before := mustSerialize(t, once); before == mustSerialize(t, twice) {
+		t.Fatal("AddProof modified its input")
+	}
+}
+
+// === DI-CREATE-002: Proof options are validated ===
+
+func TestCreateProof_DI_CREATE_002(t *testing.T) {
+	ed := mustKey(t, edPub, edSec)
+	doc := mustParse(t, credential)
+	bad := []func(*jcsdi.ProofOptions){
+		func(o *jcsdi.ProofOptions) { o.VerificationMethod = "" },
+		func(o *jcsdi.ProofOptions) { o.ProofPurpose = "" },
+		func(o *jcsdi.ProofOptions) { o.Created = "2023-02-24" },
+		func(o *jcsdi.ProofOptions) { o.Expires = "2023-02-24T23:36:38" },
+	}
+	for i, mutate := range bad {
+		o := options(jcsdi.EdDSAJCS2022, ed)
+		mutate(&o)
+		_, err := jcsdi.CreateProof(doc, o, ed)
+		if err == nil {
+			t.Fatalf("case %d: expected error", i)
+		}
+		assertClass(t, err, jcserr.InvalidProof)
+	}
+	_, err := jcsdi.CreateProof(mustParse(t, `[1]`), options(jcsdi.EdDSAJCS2022, ed), ed)
+	assertClass(t, err, jcserr.InvalidProof)
+	public := mustKey(t, edPub, "")
+	_, err = jcsdi.CreateProof(doc, options(jcsdi.EdDSAJCS2022, public), public)
+	assertClass(t, err, jcserr.InvalidKey)
+}
+
+// === DI-VERIFY-001: Verification recomputes hashData ===
+
+func TestVerifyProof_DI_VERIFY_001(t *testing.T) {
+	keys := []*jcsdi.Multikey{mustKey(t, edPub, edSec), mustKey(t, p256Pub, p256Sec), mustKey(t, p384Pub, p384Sec)}
+	suites := []string{jcsdi.EdDSAJCS2022, jcsdi.ECDSAJCS2019, jcsdi.ECDSAJCS2019}
+	r := ring(t, keys...)
+	for i, k := range keys {
+		secured := secure(t, credential, suites[i], k)
+		if err := jcsdi.VerifyProof(secured, r); err != nil {
+			t.Fatalf("%s: %v", suites[i], err)
+		}
+		// Formatting of the secured document is irrelevant.
+		reparsed := mustParse(t, mustSerialize(t, secured))
+		if err := jcsdi.VerifyProof(reparsed, r); err != nil {
+			t.Fatalf("%s reparsed: %v", suites[i], err)
+		}
+		tampered := mustParse(t, mustSerialize(t, secured))
+		tampered.Members[0].Value = jcstoken.Value{Kind: jcstoken.KindString, Str: "https://www.w3.org/ns/credentials/v2"}
+		assertClass(t, jcsdi.VerifyProof(tampered, r), jcserr.InvalidProof)
+	}
+	secured := secure(t, credential, jcsdi.EdDSAJCS2022, keys[0])
+	name := findMember(t, secured, "name")
+	name.Str = "Forged Credential"
+	assertClass(t, jcsdi.VerifyProof(secured, r), jcserr.SignatureInvalid)
+}
+
+func findMember(t *testing.T, v *jcstoken.Value, name string) *jcstoken.Value {
+	t.Helper()
+	for i := range v.Members {
+		if v.Members[i].Key == name {
+			return &v.Members[i].Value
+		}
+	}
+	t.Fatalf("member %q not found", name)
+	return nil
+}
+
+func TestVerifyProofRejectsMalformedProofs(t *testing.T) {
+	ed := mustKey(t, edPub, edSec)
+	r := ring(t, ed)
+	assertClass(t, jcsdi.VerifyProof(mustParse(t, credential), r), jcserr.InvalidProof)
+	assertClass(t, jcsdi.VerifyProof(mustParse(t, `{"proof":[]}`), r), jcserr.InvalidProof)
+	assertClass(t, jcsdi.VerifyProof(mustParse(t, `{"proof":"z1"}`), r), jcserr.InvalidProof)
+
+	cases := []struct {
+		member, value string
+		want          jcserr.FailureClass
+	}{
+		{"type", "Ed25519Signature2020", jcserr.InvalidProof},
+		{"proofValue", "u" + edPub[1:], jcserr.InvalidProof},
+		{"proofValue", "z2", jcserr.SignatureInvalid},
+		{"cryptosuite", "eddsa-rdfc-2022", jcserr.InvalidProof},
+		{"verificationMethod", "did:example:other#key", jcserr.InvalidKey},
+		{"created", "yesterday", jcserr.InvalidProof},
+		{"proofPurpose", "authentication", jcserr.SignatureInvalid},
+	}
+	for _, tc := range cases {
+		secured := secure(t, credential, jcsdi.EdDSAJCS2022, ed)
+		findMember(t, findMember(t, secured, "proof"), tc.member).Str = tc.value
+		err := jcsdi.VerifyProof(secured, r)
+		if err == nil {
+			t.Fatalf("%s=%q: expected %s", tc.member, tc.value, tc.want)
+		}
+		assertClass(t, err, tc.want)
+	}
+}
+
+// === DI-VERIFY-002: Proof @context must prefix the document @context ===
+
+func TestVerifyProof_DI_VERIFY_002(t *testing.T) {
+	ed := mustKey(t, edPub, edSec)
+	r := ring(t, ed)
+	secured := secure(t, credential, jcsdi.EdDSAJCS2022, ed)
+	// Appending a context to the document keeps the proof context a prefix,
+	// and the signed document is reconstructed with the proof's context.
+	ctx := findMember(t, secured, "@context")
+	ctx.Elems = append(ctx.Elems, jcstoken.Value{Kind: jcstoken.KindString, Str: "https://example.org/extra/v1"})
+	if err := jcsdi.VerifyProof(secured, r); err != nil {
+		t.Fatalf("extended @context: %v", err)
+	}
+	ctx.Elems = ctx.Elems[1:]
+	assertClass(t, jcsdi.VerifyProof(secured, r), jcserr.InvalidProof)
+
+	// Documents without @context produce proofs without @context.
+	plain := secure(t, `{"claim":true}`, jcsdi.EdDSAJCS2022, ed)
+	if out := mustSerialize(t, findMember(t, plain, "proof")); strings.Contains(out, "@context") {
+		t.Fatalf("unexpected proof @context: %s", out)
+	}
+	if err := jcsdi.VerifyProof(plain, r); err != nil {
+		t.Fatal(err)
+	}
+}
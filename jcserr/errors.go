@@ -27,10 +27,38 @@ const (
 	NumberNegZero FailureClass = "NUMBER_NEGZERO"
 	// NumberUnderflow indicates non-zero number underflowing to zero.
 	NumberUnderflow FailureClass = "NUMBER_UNDERFLOW"
+	// NumberInexact indicates an integer whose canonical binary64 form differs from its exact value.
+	NumberInexact FailureClass = "NUMBER_INEXACT"
 	// BoundExceeded indicates explicit configured bounds were exceeded.
 	BoundExceeded FailureClass = "BOUND_EXCEEDED"
 	// NotCanonical indicates input does not match canonical encoding.
 	NotCanonical FailureClass = "NOT_CANONICAL"
+	// InvalidPointer indicates a malformed or unresolvable JSON Pointer.
+	InvalidPointer FailureClass = "INVALID_POINTER"
+	// DigestMismatch indicates a recomputed digest does not match the expected digest.
+	DigestMismatch FailureClass = "DIGEST_MISMATCH"
+	// InvalidKey indicates malformed, mismatched, unsupported, or unresolvable key material.
+	InvalidKey FailureClass = "INVALID_KEY"
+	// InvalidProof indicates a malformed proof or unsupported proof options.
+	InvalidProof FailureClass = "INVALID_PROOF"
+	// SignatureInvalid indicates a well-formed signature that does not verify.
+	SignatureInvalid FailureClass = "SIGNATURE_INVALID"
+	// InvalidCBOR indicates input that is not well-formed CBOR.
+	InvalidCBOR FailureClass = "INVALID_CBOR"
+	// UnsupportedCBOR indicates a well-formed CBOR item with no I-JSON representation.
+	UnsupportedCBOR FailureClass = "UNSUPPORTED_CBOR"
+	// SchemeDomain indicates a value outside the input domain of the selected canonicalization scheme.
+	SchemeDomain FailureClass = "SCHEME_DOMAIN"
+	// DuplicateElement indicates repeated elements in an array canonicalized as a set that rejects duplicates.
+	DuplicateElement FailureClass = "DUPLICATE_ELEMENT"
+	// UnsupportedDecimal indicates a number outside the exact-decimal profile's exponent range.
+	UnsupportedDecimal FailureClass = "UNSUPPORTED_DECIMAL"
+	// InvalidPatch indicates a malformed JSON Patch document or an operation that cannot be applied.
+	InvalidPatch FailureClass = "INVALID_PATCH"
+	// PatchTestFailed indicates a JSON Patch "test" operation whose value does not match.
+	PatchTestFailed FailureClass = "PATCH_TEST_FAILED"
+	// InvalidSalt indicates a redaction salt that is missing or shorter than the required minimum.
+	InvalidSalt FailureClass = "INVALID_SALT"
 	// CLIUsage indicates command-line usage or argument error.
 	CLIUsage FailureClass = "CLI_USAGE"
 	// InternalIO indicates internal stream/file I/O failure.
@@ -20,8 +20,22 @@ func TestFailureClassExitCodes(t *testing.T) {
 		{jcserr.NumberOverflow, 2},
 		{jcserr.NumberNegZero, 2},
 		{jcserr.NumberUnderflow, 2},
+		{jcserr.NumberInexact, 2},
 		{jcserr.BoundExceeded, 2},
 		{jcserr.NotCanonical, 2},
+		{jcserr.InvalidPointer, 2},
+		{jcserr.DigestMismatch, 2},
+		{jcserr.InvalidKey, 2},
+		{jcserr.InvalidProof, 2},
+		{jcserr.SignatureInvalid, 2},
+		{jcserr.InvalidCBOR, 2},
+		{jcserr.UnsupportedCBOR, 2},
+		{jcserr.SchemeDomain, 2},
+		{jcserr.DuplicateElement, 2},
+		{jcserr.UnsupportedDecimal, 2},
+		{jcserr.InvalidPatch, 2},
+		{jcserr.PatchTestFailed, 2},
+		{jcserr.InvalidSalt, 2},
 		{jcserr.CLIUsage, 2},
 		{jcserr.InternalIO, 10},
 		{jcserr.InternalError, 10},
@@ -0,0 +1,125 @@
+package jcsfloat
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/lattice-substrate/json-canon/jcserr"
+)
+
+// MaxDecimalExponent bounds the exponent of a canonical decimal: FormatDecimal
+// accepts values whose scientific-notation exponent lies in
+// [-MaxDecimalExponent, MaxDecimalExponent]. It is the Emax of the General
+// Decimal Arithmetic specification.
+const MaxDecimalExponent = 999_999_999
+
+// maxExponentDigits is the longest exponent, after leading zeros, that is
+// converted before the range check; longer exponents are out of range.
+const maxExponentDigits = 18
+
+// FormatDecimal returns the canonical exact-decimal form of the JSON number
+// token s, without conversion to binary64. The value is written with no
+// leading zeros and no trailing fractional zeros ("1.10" and "11e-1" both
+// become "1.1"), using the ECMA-262 Number::toString layout: plain notation
+// for decimal exponents 1e-7 < |x| < 1e21, otherwise one integer digit and an
+// explicitly signed exponent. Every finite double's FormatDouble output is
+// already in this form, so the two agree whenever s is the shortest
+// round-trip representation of a binary64 value.
+//
+// DEC-CANON-001: Exact decimals have a single canonical form.
+// DEC-RANGE-001: Exponents beyond MaxDecimalExponent are UNSUPPORTED_DECIMAL.
+func FormatDecimal(s string) (string, *jcserr.Error) {
+	t, ok := scanNumberToken(s)
+	if !ok {
+		return "", jcserr.New(jcserr.InvalidGrammar, -1, fmt.Sprintf("invalid number token %q", s))
+	}
+	digits := strings.TrimLeft(t.intPart+t.frac, "0")
+	if digits == "" {
+		if t.negative {
+			return "", jcserr.New(jcserr.NumberNegZero, -1, "negative zero token is not allowed")
+		}
+		return "0", nil
+	}
+	exp, ok := parseExponent(t.exp)
+	// value = 0.digits × 10^n; trimming trailing zeros leaves n unchanged.
+	n := exp + int64(len(digits)) - int64(len(t.frac))
+	if !ok || n-1 > MaxDecimalExponent || n-1 < -MaxDecimalExponent {
+		return "", jcserr.New(jcserr.UnsupportedDecimal, -1,
+			fmt.Sprintf("decimal exponent of %q is outside [-%d, %d]", s, MaxDecimalExponent, MaxDecimalExponent))
+	}
+	return formatECMA(t.negative, strings.TrimRight(digits, "0"), int(n)), nil
+}
+
+// numberToken is a JSON number token split into its parts.
+type numberToken struct {
+	negative bool
+	intPart  string
+	frac     string
+	exp      string // optional sign and digits
+}
+
+// scanNumberToken splits s per the RFC 8259 number grammar and reports
+// whether s is exactly one number token.
+func scanNumberToken(s string) (numberToken, bool) {
+	var t numberToken
+	i := 0
+	if i < len(s) && s[i] == '-' {
+		t.negative = true
+		i++
+	}
+	j := digitsEnd(s, i)
+	if j == i || (s[i] == '0' && j > i+1) {
+		return t, false
+	}
+	t.intPart, i = s[i:j], j
+	if i < len(s) && s[i] == '.' {
+		j = digitsEnd(s, i+1)
+		if j == i+1 {
+			return t, false
+		}
+		t.frac, i = s[i+1:j], j
+	}
+	if i < len(s) && (s[i] == 'e' || s[i] == 'E') {
+		var ok bool
+		if t.exp, i, ok = scanExponent(s, i+1); !ok {
+			return t, false
+		}
+	}
+	return t, i == len(s)
+}
+
+// scanExponent scans the optional sign and digits of an exponent starting
+// at s[i], returning them and the index after the last digit.
+func scanExponent(s string, i int) (string, int, bool) {
+	start := i
+	if i < len(s) && (s[i] == '+' || s[i] == '-') {
+		i++
+	}
+	j := digitsEnd(s, i)
+	return s[start:j], j, j > i
+}
+
+func digitsEnd(s string, i int) int {
+	for i < len(s) && s[i] >= '0' && s[i] <= '9' {
+		i++
+	}
+	return i
+}
+
+// parseExponent converts exponent text to an int64, reporting false when it
+// has more than maxExponentDigits significant digits.
+func parseExponent(s string) (int64, bool) {
+	negative := strings.HasPrefix(s, "-")
+	s = strings.TrimLeft(strings.TrimLeft(s, "+-"), "0")
+	if len(s) > maxExponentDigits {
+		return 0, false
+	}
+	var e int64
+	for i := 0; i < len(s); i++ {
+		e = e*10 + int64(s[i]-'0')
+	}
+	if negative {
+		e = -e
+	}
+	return e, true
+}
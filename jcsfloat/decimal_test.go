@@ -0,0 +1,102 @@
+package jcsfloat_test
+
+import (
+	"math"
+	"strconv"
+	"testing"
+
+	"github.com/lattice-substrate/json-canon/jcserr"
+	"github.com/lattice-substrate/json-canon/jcsfloat"
+)
+
+// === DEC-CANON-001: Exact decimals have a single canonical form ===
+
+func TestFormatDecimal_DEC_CANON_001(t *testing.T) {
+	cases := []struct {
+		in, want string
+	}{
+		{"0", "0"},
+		{"0.000", "0"},
+		{"0e-99999999999999999999", "0"},
+		{"1.10", "1.1"},
+		{"11e-1", "1.1"},
+		{"0.011E+2", "1.1"},
+		{"-1.10", "-1.1"},
+		{"100", "100"},
+		{"1E2", "100"},
+		{"12345678901234567.89", "12345678901234567.89"},
+		{"9007199254740993", "9007199254740993"},
+		{"0.1000000000000000000001", "0.1000000000000000000001"},
+		{"123456789012345678901", "123456789012345678901"},
+		{"1234567890123456789012", "1.234567890123456789012e+21"},
+		{"1e21", "1e+21"},
+		{"1e20", "100000000000000000000"},
+		{"0.000001", "0.000001"},
+		{"0.0000001", "1e-7"},
+		{"0.00000123", "0.00000123"},
+		{"1e400", "1e+400"},
+		{"-2.5e-400", "-2.5e-400"},
+		{"123.456e-2", "1.23456"},
+	}
+	for _, tc := range cases {
+		got, err := jcsfloat.FormatDecimal(tc.in)
+		if err != nil {
+			t.Fatalf("FormatDecimal(%q): %v", tc.in, err)
+		}
+		if got != tc.want {
+			t.Fatalf("FormatDecimal(%q) = %q, want %q", tc.in, got, tc.want)
+		}
+	}
+	for _, in := range []string{"", "-", "01", "1.", ".5", "1e", "1e+", "+1", "1.5x", "000.5e0", "0x10", "Infinity"} {
+		_, err := jcsfloat.FormatDecimal(in)
+		if err == nil || err.Class != jcserr.InvalidGrammar {
+			t.Fatalf("FormatDecimal(%q): expected INVALID_GRAMMAR, got %v", in, err)
+		}
+	}
+	for _, in := range []string{"-0", "-0.000", "-0e5"} {
+		_, err := jcsfloat.FormatDecimal(in)
+		if err == nil || err.Class != jcserr.NumberNegZero {
+			t.Fatalf("FormatDecimal(%q): expected NUMBER_NEGZERO, got %v", in, err)
+		}
+	}
+}
+
+func TestFormatDecimalAgreesWithFormatDouble(t *testing.T) {
+	for _, f := range []float64{5e-324, 1e-7, 1e-6, 0.1, 1.5, 123456789, 1e20, 1e21, 4.35, math.MaxFloat64, -0.3} {
+		want, jerr := jcsfloat.FormatDouble(f)
+		if jerr != nil {
+			t.Fatal(jerr)
+		}
+		for _, token := range []string{want, strconv.FormatFloat(f, 'e', -1, 64)} {
+			got, err := jcsfloat.FormatDecimal(token)
+			if err != nil {
+				t.Fatalf("FormatDecimal(%q): %v", token, err)
+			}
+			if got != want {
+				t.Fatalf("FormatDecimal(%q) = %q, want FormatDouble's %q", token, got, want)
+			}
+		}
+	}
+}
+
+// === DEC-RANGE-001: Exponents beyond MaxDecimalExponent are UNSUPPORTED_DECIMAL ===
+
+func TestFormatDecimal_DEC_RANGE_001(t *testing.T) {
+	for in, want := range map[string]string{
+		"1e999999999":    "1e+999999999",
+		"1e-999999999":   "1e-999999999",
+		"0.1e1000000000": "1e+999999999",
+		"10e-1000000000": "1e-999999999",
+	} {
+		got, err := jcsfloat.FormatDecimal(in)
+		if err != nil || got != want {
+			t.Fatalf("FormatDecimal(%q) = %q, %v; want %q", in, got, err, want)
+		}
+	}
+	for _, in := range []string{"1e1000000000", "1e-1000000000", "10e999999999", "0.1e-999999999", "1e99999999999999999999", "-5e-00000000000000000000001000000000"} {
+		_, err := jcsfloat.FormatDecimal(in)
+		if err == nil || err.Class != jcserr.UnsupportedDecimal {
+			t.Fatalf("FormatDecimal(%q): expected UNSUPPORTED_DECIMAL, got %v", in, err)
+		}
+	}
+}
@@ -15,3 +15,12 @@ func ExampleFormatDouble() {
 	fmt.Println(s)
 	// Output: 3.14
 }
+
+func ExampleFormatFixed() {
+	s, err := jcsfloat.FormatFixed(1.005, 2)
+	if err != nil {
+		log.Fatal(err)
+	}
+	fmt.Println(s)
+	// Output: 1.00
+}
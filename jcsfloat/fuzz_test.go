@@ -9,7 +9,8 @@ import (
 	"github.com/lattice-substrate/json-canon/jcsfloat"
 )
 
-// FuzzFormatDoubleRoundTrip: uint64 bits → format → parse → verify round-trip.
+// FuzzFormatDoubleRoundTrip: uint64 bits → format → parse → verify round-trip,
+// and compare the fixed-width and multiprecision digit generators.
 func FuzzFormatDoubleRoundTrip(f *testing.F) {
 	seeds := []uint64{
 		0x0000000000000000, // +0
@@ -46,6 +47,10 @@ func FuzzFormatDoubleRoundTrip(f *testing.F) {
 		if err != nil {
 			t.Fatalf("FormatDouble(bits=%016x): %v", bits, err)
 		}
+		// ECMA-VEC-004: the fixed-width path matches the multiprecision one.
+		if exact, exactErr := jcsfloat.FormatDoubleExact(fval); exactErr != nil || exact != s {
+			t.Fatalf("bits=%016x FormatDouble=%q FormatDoubleExact=%q (%v)", bits, s, exact, exactErr)
+		}
 
 		parsed, parseErr := strconv.ParseFloat(s, 64)
 		if parseErr != nil {
@@ -0,0 +1,52 @@
+package jcsfloat
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/lattice-substrate/json-canon/jcserr"
+)
+
+// FormatFloat32 formats f as FormatDouble formats float64(f). Widening a
+// float32 to float64 is exact, so the result is the canonical number of the
+// binary64 value equal to f. It is not the shortest decimal that identifies
+// f as a float32: float32(0.1) formats as "0.10000000149011612".
+//
+// NUMTYPE-F32-001: float32 values are formatted by exact widening.
+func FormatFloat32(f float32) (string, *jcserr.Error) {
+	return FormatDouble(float64(f))
+}
+
+// FormatInt64 formats i as a canonical JSON number whose exact value is i.
+// Every i with |i| ≤ 2^53 succeeds. Beyond that, i fails with NUMBER_INEXACT
+// when it has no exact binary64 value, and also when its binary64 value is
+// exact but FormatDouble would emit different digits: 2^60 is
+// 1152921504606846976, while its canonical form is 1152921504606847000.
+//
+// NUMTYPE-INT-001: Integers are emitted only when the output equals them.
+func FormatInt64(i int64) (string, *jcserr.Error) {
+	return formatExactInteger(float64(i), strconv.FormatInt(i, 10))
+}
+
+// FormatUint64 is FormatInt64 for unsigned integers.
+//
+// NUMTYPE-INT-001: Integers are emitted only when the output equals them.
+func FormatUint64(u uint64) (string, *jcserr.Error) {
+	return formatExactInteger(float64(u), strconv.FormatUint(u, 10))
+}
+
+// formatExactInteger returns the canonical form of f, the binary64 value
+// nearest the integer whose decimal text is want. Magnitudes of 64-bit
+// integers stay below 1e21, so the canonical form is plain integer digits
+// and equals want exactly when it denotes the same integer.
+func formatExactInteger(f float64, want string) (string, *jcserr.Error) {
+	got, err := FormatDouble(f)
+	if err != nil {
+		return "", err
+	}
+	if got != want {
+		return "", jcserr.New(jcserr.NumberInexact, -1,
+			fmt.Sprintf("integer %s has no exact canonical binary64 form (nearest is %s)", want, got))
+	}
+	return got, nil
+}
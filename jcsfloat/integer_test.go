@@ -0,0 +1,105 @@
+package jcsfloat_test
+
+import (
+	"math"
+	"strconv"
+	"testing"
+
+	"github.com/lattice-substrate/json-canon/jcserr"
+	"github.com/lattice-substrate/json-canon/jcsfloat"
+)
+
+// === NUMTYPE-F32-001: float32 values are formatted by exact widening ===
+
+func TestFormatFloat32_NUMTYPE_F32_001(t *testing.T) {
+	cases := []struct {
+		f    float32
+		want string
+	}{
+		{0, "0"},
+		{float32(math.Copysign(0, -1)), "0"},
+		{1.5, "1.5"},
+		{0.1, "0.10000000149011612"},
+		{-16777216, "-16777216"},
+		{math.MaxFloat32, "3.4028234663852886e+38"},
+		{math.SmallestNonzeroFloat32, "1.401298464324817e-45"},
+	}
+	for _, tc := range cases {
+		got, err := jcsfloat.FormatFloat32(tc.f)
+		if err != nil || got != tc.want {
+			t.Fatalf("FormatFloat32(%v) = %q, %v; want %q", tc.f, got, err, tc.want)
+		}
+	}
+	for _, f := range []float32{float32(math.NaN()), float32(math.Inf(1))} {
+		if _, err := jcsfloat.FormatFloat32(f); err == nil {
+			t.Fatalf("FormatFloat32(%v): expected error", f)
+		}
+	}
+	// Every float32 widens to the float64 with the same value.
+	for bits := uint32(1); bits < 0x7F800000; bits += 0x10001 {
+		f := math.Float32frombits(bits)
+		got, err := jcsfloat.FormatFloat32(f)
+		want, wantErr := jcsfloat.FormatDouble(float64(f))
+		if err != nil || wantErr != nil || got != want {
+			t.Fatalf("FormatFloat32(bits=%08x) = %q, %v; want %q", bits, got, err, want)
+		}
+		if back, perr := strconv.ParseFloat(got, 32); perr != nil || float32(back) != f {
+			t.Fatalf("FormatFloat32(bits=%08x) = %q does not round-trip", bits, got)
+		}
+	}
+}
+
+// === NUMTYPE-INT-001: Integers are emitted only when the output equals them ===
+
+func TestFormatInt64_NUMTYPE_INT_001(t *testing.T) {
+	exact := []int64{0, 1, -1, 1 << 53, -(1 << 53), 1<<53 - 1, 1e18, -1e18, 1<<53 + 2}
+	for _, i := range exact {
+		got, err := jcsfloat.FormatInt64(i)
+		if want := strconv.FormatInt(i, 10); err != nil || got != want {
+			t.Fatalf("FormatInt64(%d) = %q, %v; want %q", i, got, err, want)
+		}
+	}
+	inexact := []int64{1<<53 + 1, -(1<<53 + 1), 1 << 60, math.MaxInt64, math.MinInt64}
+	for _, i := range inexact {
+		got, err := jcsfloat.FormatInt64(i)
+		if err == nil || err.Class != jcserr.NumberInexact {
+			t.Fatalf("FormatInt64(%d) = %q, %v; want NUMBER_INEXACT", i, got, err)
+		}
+	}
+
+	for _, u := range []uint64{0, 1 << 53, 1e19, 1 << 63} {
+		got, err := jcsfloat.FormatUint64(u)
+		if u == 1<<63 {
+			// 2^63 is exact in binary64 but canonicalizes to 9223372036854776000.
+			if err == nil || err.Class != jcserr.NumberInexact {
+				t.Fatalf("FormatUint64(%d) = %q, %v; want NUMBER_INEXACT", u, got, err)
+			}
+			continue
+		}
+		if want := strconv.FormatUint(u, 10); err != nil || got != want {
+			t.Fatalf("FormatUint64(%d) = %q, %v; want %q", u, got, err, want)
+		}
+	}
+	if got, err := jcsfloat.FormatUint64(math.MaxUint64); err == nil || err.Class != jcserr.NumberInexact {
+		t.Fatalf("FormatUint64(MaxUint64) = %q, %v; want NUMBER_INEXACT", got, err)
+	}
+
+	// Success always means the output is the integer's own decimal text.
+	state := uint64(0x6a09e667f3bcc909)
+	for n := 0; n < 20000; n++ {
+		state += 0x9e3779b97f4a7c15
+		z := state
+		z = (z ^ (z >> 30)) * 0xbf58476d1ce4e5b9
+		z = (z ^ (z >> 27)) * 0x94d049bb133111eb
+		z ^= z >> 31
+		i := int64(z) >> (z % 64)
+		got, err := jcsfloat.FormatInt64(i)
+		want := strconv.FormatInt(i, 10)
+		switch {
+		case err == nil && got != want:
+			t.Fatalf("FormatInt64(%d) = %q", i, got)
+		case err != nil && i >= -(1<<53) && i <= 1<<53:
+			t.Fatalf("FormatInt64(%d): %v", i, err)
+		}
+	}
+}
@@ -6,9 +6,25 @@
 // FormatDouble is validated against large pinned ECMAScript oracle datasets and
 // round-trip fuzzing for finite doubles.
 //
-// The implementation uses math/big.Int for exact multiprecision arithmetic in
-// digit generation, following the Burger-Dybvig algorithm with correct ECMA-262
-// Note 2 (even-digit) tie-breaking.
+// Digit generation for normal doubles uses a fixed-width Schubfach-style
+// algorithm on 64-bit integers. Subnormals take the exact multiprecision
+// path: math/big.Int arithmetic following the Burger-Dybvig algorithm with
+// correct ECMA-262 Note 2 (even-digit) tie-breaking. FormatDoubleExact
+// exposes that path alone as the differential oracle for the fast one.
+//
+// FormatDecimal applies the same output layout to exact decimal number
+// tokens for the jcs-decimal profile.
+//
+// ParseDouble is the inverse direction: it converts a JSON number token to
+// the nearest double, so parsing and formatting share one implementation.
+//
+// FormatFixed, FormatExponential, FormatPrecision, and FormatRadix reproduce
+// the Number.prototype methods toFixed, toExponential, toPrecision, and
+// toString(radix) for reporting output.
+//
+// FormatFloat32, FormatInt64, and FormatUint64 format other Go numeric types;
+// the integer forms refuse values whose canonical number would differ from
+// the integer.
 package jcsfloat
 
 import (
@@ -36,6 +52,19 @@ var digitStatePool = sync.Pool{
 // ECMA-FMT-008: Shortest round-trip representation.
 // ECMA-FMT-009: Even-digit tie-breaking.
 func FormatDouble(f float64) (string, *jcserr.Error) {
+	return formatDouble(f, generateDigits)
+}
+
+// FormatDoubleExact is FormatDouble computed entirely with the multiprecision
+// Burger-Dybvig digit generator. Its output is identical to FormatDouble; it
+// is retained as the reference against which the fixed-width path is checked.
+//
+// ECMA-VEC-004: Reference path for differential validation.
+func FormatDoubleExact(f float64) (string, *jcserr.Error) {
+	return formatDouble(f, generateDigitsExact)
+}
+
+func formatDouble(f float64, generate func(float64) (string, int)) (string, *jcserr.Error) {
 	// ECMA-FMT-001: NaN → error
 	if math.IsNaN(f) {
 		return "", jcserr.New(jcserr.InvalidGrammar, -1, "NaN is not representable in JSON")
@@ -55,7 +84,7 @@ func FormatDouble(f float64) (string, *jcserr.Error) {
 		f = -f
 	}
 
-	digits, n := generateDigits(f)
+	digits, n := generate(f)
 	return formatECMA(negative, digits, n), nil
 }
 
@@ -157,12 +186,22 @@ func appendInt(buf []byte, v int) []byte {
 	return append(buf, tmp[i:]...)
 }
 
-// generateDigits implements the Burger-Dybvig "free-format" shortest-output
+// generateDigits returns the shortest decimal significand and its decimal
+// exponent for a positive finite nonzero double, from shortestDigits when it
+// applies and from generateDigitsExact otherwise.
+func generateDigits(f float64) (string, int) {
+	if digits, n, ok := shortestDigits(f); ok {
+		return digits, n
+	}
+	return generateDigitsExact(f)
+}
+
+// generateDigitsExact implements the Burger-Dybvig "free-format" shortest-output
 // algorithm using exact big.Int arithmetic, producing the shortest decimal
 // significand and its decimal exponent for a positive finite nonzero double.
 //
 // Returns (digits, n) where value = 0.<digits> × 10^n.
-func generateDigits(f float64) (string, int) {
+func generateDigitsExact(f float64) (string, int) {
 	parts := decodeFloatParts(f)
 	state, _ := digitStatePool.Get().(*digitState) //nolint:errcheck // Pool.New always returns *digitState.
 	initScaledState(state, parts)
@@ -4,34 +4,65 @@ import (
 	"math"
 	"testing"
 
+	"github.com/lattice-substrate/json-canon/jcserr"
 	"github.com/lattice-substrate/json-canon/jcsfloat"
 )
 
+var formatDoubleBenchCases = []struct {
+	name string
+	val  float64
+}{
+	{"integer", 42},
+	{"fraction", 3.14159265358979},
+	{"small_fraction", 0.000001},
+	{"exponential_large", 1e20},
+	{"exponential_small", 1e-7},
+	{"subnormal", 5e-324},
+	{"negative_zero", math.Copysign(0, -1)},
+	{"negative", -273.15},
+	{"one", 1},
+	{"max_safe_integer", 9007199254740991},
+}
+
+// BenchmarkFormatDouble measures the fixed-width digit path. Compare with
+// BenchmarkFormatDoubleExact, which formats the same inputs with the
+// multiprecision reference; the "random" case cycles through arbitrary
+// finite bit patterns, where the shortcuts for integers do not apply.
 func BenchmarkFormatDouble(b *testing.B) {
-	cases := []struct {
-		name string
-		val  float64
-	}{
-		{"integer", 42},
-		{"fraction", 3.14159265358979},
-		{"small_fraction", 0.000001},
-		{"exponential_large", 1e20},
-		{"exponential_small", 1e-7},
-		{"subnormal", 5e-324},
-		{"negative_zero", math.Copysign(0, -1)},
-		{"negative", -273.15},
-		{"one", 1},
-		{"max_safe_integer", 9007199254740991},
-	}
+	benchmarkFormat(b, jcsfloat.FormatDouble)
+}
 
-	for _, tc := range cases {
+// BenchmarkFormatDoubleExact measures the multiprecision reference path.
+func BenchmarkFormatDoubleExact(b *testing.B) {
+	benchmarkFormat(b, jcsfloat.FormatDoubleExact)
+}
+
+func benchmarkFormat(b *testing.B, format func(float64) (string, *jcserr.Error)) {
+	b.Helper()
+	for _, tc := range formatDoubleBenchCases {
 		b.Run(tc.name, func(b *testing.B) {
 			b.ReportAllocs()
 			for i := 0; i < b.N; i++ {
-				if _, err := jcsfloat.FormatDouble(tc.val); err != nil {
+				if _, err := format(tc.val); err != nil {
 					b.Fatal(err)
 				}
 			}
 		})
 	}
+
+	random := make([]float64, 0, 1024)
+	next := splitmix64(0x9e3779b97f4a7c15)
+	for len(random) < cap(random) {
+		if bits := next() &^ (1 << 63); bits>>52 != 0x7FF {
+			random = append(random, math.Float64frombits(bits))
+		}
+	}
+	b.Run("random", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			if _, err := format(random[i%len(random)]); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
 }
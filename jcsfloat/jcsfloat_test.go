@@ -302,6 +302,24 @@ func verifyOracle(t *testing.T, path string, expectedRows int, expectedSHA256 st
 		if got != parts[1] {
 			t.Fatalf("line %d bits=%016x got=%q want=%q", rows, bits, got, parts[1])
 		}
+		// ECMA-VEC-004: the multiprecision reference agrees on every line.
+		exact, exactErr := jcsfloat.FormatDoubleExact(math.Float64frombits(bits))
+		if exactErr != nil || exact != parts[1] {
+			t.Fatalf("line %d bits=%016x FormatDoubleExact=%q (%v) want=%q", rows, bits, exact, exactErr, parts[1])
+		}
+		// ECMA-PARSE-001: the oracle string parses back to the same bits
+		// (both zeros format as "0", which parses to +0).
+		parsed, parseErr := jcsfloat.ParseDouble(parts[1])
+		if parseErr != nil {
+			t.Fatalf("line %d unexpected parse error: %v", rows, parseErr)
+		}
+		want := bits
+		if math.Float64frombits(bits) == 0 {
+			want = 0
+		}
+		if math.Float64bits(parsed) != want {
+			t.Fatalf("line %d ParseDouble(%q) = %016x, want %016x", rows, parts[1], math.Float64bits(parsed), want)
+		}
 	}
 	if err := sc.Err(); err != nil {
 		t.Fatalf("scan oracle: %v", err)
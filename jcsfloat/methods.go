@@ -0,0 +1,303 @@
+package jcsfloat
+
+import (
+	"fmt"
+	"math"
+	"math/big"
+	"strings"
+
+	"github.com/lattice-substrate/json-canon/jcserr"
+)
+
+// The Number.prototype formatting methods reproduce JavaScript output for
+// reporting, not JSON emission: unlike FormatDouble they render NaN and
+// ±Infinity as JavaScript does ("NaN", "Infinity", "-Infinity"), and -0 as
+// +0. Arguments outside the ranges where ECMA-262 throws a RangeError fail
+// with BOUND_EXCEEDED.
+
+// Bounds on the digit-count arguments (ECMA-262 §21.1.3).
+const (
+	maxFractionDigits = 100
+	maxPrecision      = 100
+	minRadix          = 2
+	maxRadix          = 36
+)
+
+const radixDigits = "0123456789abcdefghijklmnopqrstuvwxyz"
+
+// FormatFixed formats f as ECMA-262 Number.prototype.toFixed(fractionDigits)
+// does: fixed-point notation with exactly fractionDigits digits after the
+// decimal point, rounding the exact binary value half up. Values with
+// magnitude at least 1e21 use Number::toString. fractionDigits must be in
+// [0, 100].
+//
+// ECMA-OPS-001: Number.prototype.toFixed.
+// ECMA-OPS-005: RangeError arguments are BOUND_EXCEEDED.
+func FormatFixed(f float64, fractionDigits int) (string, *jcserr.Error) {
+	if err := checkDigitArg("fraction digits", fractionDigits, 0, maxFractionDigits); err != nil {
+		return "", err
+	}
+	if s, ok := formatNonFinite(f); ok {
+		return s, nil
+	}
+	sign, x := splitSign(f)
+	if x >= 1e21 {
+		s, err := FormatDouble(x)
+		return sign + s, err
+	}
+	m := roundScaled(x, -fractionDigits).String()
+	if fractionDigits == 0 {
+		return sign + m, nil
+	}
+	if len(m) <= fractionDigits {
+		m = strings.Repeat("0", fractionDigits+1-len(m)) + m
+	}
+	k := len(m) - fractionDigits
+	return sign + m[:k] + "." + m[k:], nil
+}
+
+// FormatExponential formats f as ECMA-262
+// Number.prototype.toExponential(fractionDigits) does: one digit before the
+// decimal point, fractionDigits after it, rounding the exact binary value
+// half up, and an exponent with an explicit sign. A negative fractionDigits
+// stands for undefined and uses the shortest digits that identify f, as
+// FormatDouble does. fractionDigits must be at most 100.
+//
+// ECMA-OPS-002: Number.prototype.toExponential.
+// ECMA-OPS-005: RangeError arguments are BOUND_EXCEEDED.
+func FormatExponential(f float64, fractionDigits int) (string, *jcserr.Error) {
+	if fractionDigits >= 0 {
+		if err := checkDigitArg("fraction digits", fractionDigits, 0, maxFractionDigits); err != nil {
+			return "", err
+		}
+	}
+	if s, ok := formatNonFinite(f); ok {
+		return s, nil
+	}
+	sign, x := splitSign(f)
+	var digits string
+	var e int
+	switch {
+	case x == 0:
+		digits = strings.Repeat("0", max(fractionDigits, 0)+1)
+	case fractionDigits < 0:
+		var n int
+		digits, n = generateDigits(x)
+		e = n - 1
+	default:
+		digits, e = roundedDigits(x, fractionDigits+1)
+	}
+	return sign + appendExponent(digits, e), nil
+}
+
+// FormatPrecision formats f as ECMA-262 Number.prototype.toPrecision(precision)
+// does: precision significant digits, rounding the exact binary value half
+// up, in fixed-point notation unless the decimal exponent is below -6 or at
+// least precision. precision must be in [1, 100].
+//
+// ECMA-OPS-003: Number.prototype.toPrecision.
+// ECMA-OPS-005: RangeError arguments are BOUND_EXCEEDED.
+func FormatPrecision(f float64, precision int) (string, *jcserr.Error) {
+	if err := checkDigitArg("precision", precision, 1, maxPrecision); err != nil {
+		return "", err
+	}
+	if s, ok := formatNonFinite(f); ok {
+		return s, nil
+	}
+	sign, x := splitSign(f)
+	digits := strings.Repeat("0", precision)
+	e := 0
+	if x != 0 {
+		digits, e = roundedDigits(x, precision)
+	}
+	switch {
+	case e < -6 || e >= precision:
+		return sign + appendExponent(digits, e), nil
+	case e == precision-1:
+		return sign + digits, nil
+	case e >= 0:
+		return sign + digits[:e+1] + "." + digits[e+1:], nil
+	default:
+		return sign + "0." + strings.Repeat("0", -(e+1)) + digits, nil
+	}
+}
+
+// FormatRadix formats f as ECMA-262 Number.prototype.toString(radix) does.
+// Radix 10 is Number::toString, identical to FormatDouble for finite f. For
+// other radixes ECMA-262 leaves the digits implementation-approximated;
+// FormatRadix reproduces V8's algorithm, which emits fraction digits until
+// they identify f and rounds the last one half to even. Each floating-point
+// step is rounded explicitly, so the result does not depend on fused
+// multiply-add. radix must be in [2, 36].
+//
+// ECMA-OPS-004: Number.prototype.toString(radix).
+// ECMA-OPS-005: RangeError arguments are BOUND_EXCEEDED.
+// ECMA-OPS-006: Non-decimal radix digits match V8.
+func FormatRadix(f float64, radix int) (string, *jcserr.Error) {
+	if err := checkDigitArg("radix", radix, minRadix, maxRadix); err != nil {
+		return "", err
+	}
+	if s, ok := formatNonFinite(f); ok {
+		return s, nil
+	}
+	if radix == 10 {
+		return FormatDouble(f)
+	}
+	sign, x := splitSign(f)
+	return sign + formatRadixDigits(x, float64(radix)), nil
+}
+
+func checkDigitArg(name string, v, lo, hi int) *jcserr.Error {
+	if v < lo || v > hi {
+		return jcserr.New(jcserr.BoundExceeded, -1, fmt.Sprintf("%s %d outside [%d, %d]", name, v, lo, hi))
+	}
+	return nil
+}
+
+// formatNonFinite returns Number::toString of NaN and ±Infinity.
+func formatNonFinite(f float64) (string, bool) {
+	switch {
+	case math.IsNaN(f):
+		return "NaN", true
+	case math.IsInf(f, 1):
+		return "Infinity", true
+	case math.IsInf(f, -1):
+		return "-Infinity", true
+	}
+	return "", false
+}
+
+// splitSign returns "-" and -f for negative f, and "" and f otherwise; -0
+// is not negative.
+func splitSign(f float64) (string, float64) {
+	if f < 0 {
+		return "-", -f
+	}
+	return "", math.Abs(f)
+}
+
+// appendExponent renders digits × 10^(e - len(digits) + 1) as
+// d[.ddd]e±x (ECMA-262 §21.1.3.2 steps 11-13).
+func appendExponent(digits string, e int) string {
+	buf := make([]byte, 0, len(digits)+8)
+	buf = append(buf, digits[0])
+	if len(digits) > 1 {
+		buf = append(buf, '.')
+		buf = append(buf, digits[1:]...)
+	}
+	buf = append(buf, 'e')
+	if e >= 0 {
+		buf = append(buf, '+')
+	}
+	return string(appendInt(buf, e))
+}
+
+// roundedDigits returns the p significant digits n and the exponent e of
+// the decimal n × 10^(e-p+1) nearest to x > 0, choosing the larger on a tie.
+func roundedDigits(x float64, p int) (string, int) {
+	// x is in [2^(exp-1), 2^exp), so e starts within one of ⌊log10(x)⌋.
+	_, exp := math.Frexp(x)
+	e := flog10pow2(exp - 1)
+	lo, hi := pow10Big(p-1), pow10Big(p)
+	for {
+		n := roundScaled(x, e-p+1)
+		switch {
+		case n.Cmp(hi) >= 0:
+			e++
+		case n.Cmp(lo) < 0:
+			e--
+		default:
+			return n.String(), e
+		}
+	}
+}
+
+// roundScaled returns x / 10^s rounded to an integer, halves rounding up,
+// computed exactly from the binary value of x ≥ 0.
+func roundScaled(x float64, s int) *big.Int {
+	mant, exp := math.Frexp(x)
+	// x = m × 2^(exp-53) with m an integer below 2^53.
+	num := new(big.Int).SetUint64(uint64(math.Ldexp(mant, 53)))
+	den := big.NewInt(1)
+	lshByInt(num, exp-53)
+	lshByInt(den, 53-exp)
+	if s >= 0 {
+		den.Mul(den, pow10Big(s))
+	} else {
+		num.Mul(num, pow10Big(-s))
+	}
+	q, r := num.QuoRem(num, den, new(big.Int))
+	if r.Lsh(r, 1).Cmp(den) >= 0 {
+		q.Add(q, big.NewInt(1))
+	}
+	return q
+}
+
+// formatRadixDigits is V8's DoubleToRadixCString for x ≥ 0.
+func formatRadixDigits(x, radix float64) string {
+	integer := math.Floor(x)
+	fraction := x - integer
+	// Fraction digits are generated only down to the precision of x.
+	delta := float64(0.5 * (math.Nextafter(x, math.Inf(1)) - x))
+	delta = math.Max(math.SmallestNonzeroFloat64, delta)
+	var frac []byte
+	if fraction >= delta {
+		frac, integer = radixFraction(fraction, delta, radix, integer)
+	}
+
+	var rev []byte
+	for integer/radix >= 1<<53 {
+		integer /= radix
+		rev = append(rev, '0')
+	}
+	for {
+		rem := math.Mod(integer, radix)
+		rev = append(rev, radixDigits[int(rem)])
+		integer = (integer - rem) / radix
+		if integer <= 0 {
+			break
+		}
+	}
+	out := make([]byte, 0, len(rev)+len(frac))
+	for i := len(rev) - 1; i >= 0; i-- {
+		out = append(out, rev[i])
+	}
+	return string(append(out, frac...))
+}
+
+// radixFraction generates the fraction digits, prefixed with '.', rounding
+// the last digit half to even; a carry out of the fraction increments
+// integer and drops the fraction.
+func radixFraction(fraction, delta, radix, integer float64) ([]byte, float64) {
+	frac := []byte{'.'}
+	for fraction >= delta {
+		fraction = float64(fraction * radix)
+		delta = float64(delta * radix)
+		digit := int(fraction)
+		frac = append(frac, radixDigits[digit])
+		fraction -= float64(digit)
+		if fraction > 0.5 || (fraction == 0.5 && digit&1 == 1) {
+			if fraction+delta > 1 {
+				if frac = radixCarry(frac, int(radix)); frac == nil {
+					integer++
+				}
+				return frac, integer
+			}
+		}
+	}
+	return frac, integer
+}
+
+// radixCarry adds one unit in the last place of the fraction digits in frac,
+// dropping digits that wrap to zero. It returns nil when the carry reaches
+// the integer part.
+func radixCarry(frac []byte, radix int) []byte {
+	for i := len(frac) - 1; i > 0; i-- {
+		d := strings.IndexByte(radixDigits, frac[i])
+		if d+1 < radix {
+			frac[i] = radixDigits[d+1]
+			return frac[:i+1]
+		}
+	}
+	return nil
+}
@@ -0,0 +1,201 @@
+package jcsfloat_test
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/lattice-substrate/json-canon/jcserr"
+	"github.com/lattice-substrate/json-canon/jcsfloat"
+)
+
+type methodCase struct {
+	v    float64
+	arg  int
+	want string
+}
+
+func runMethodCases(t *testing.T, name string, format func(float64, int) (string, *jcserr.Error), cases []methodCase) {
+	t.Helper()
+	for _, tc := range cases {
+		got, err := format(tc.v, tc.arg)
+		if err != nil {
+			t.Fatalf("%s(%v, %d): %v", name, tc.v, tc.arg, err)
+		}
+		if got != tc.want {
+			t.Fatalf("%s(%v, %d) = %q, want %q", name, tc.v, tc.arg, got, tc.want)
+		}
+	}
+}
+
+// === ECMA-OPS-001: Number.prototype.toFixed ===
+
+func TestFormatFixed_ECMA_OPS_001(t *testing.T) {
+	runMethodCases(t, "FormatFixed", jcsfloat.FormatFixed, []methodCase{
+		{0, 2, "0.00"},
+		{math.Copysign(0, -1), 0, "0"},
+		{1.005, 2, "1.00"}, // binary value is below the half
+		{1.25, 1, "1.3"},   // exact half rounds up
+		{2.5, 0, "3"},
+		{-2.5, 0, "-3"},
+		{-0.0001, 2, "-0.00"},
+		{123.456, 0, "123"},
+		{0.000001, 7, "0.0000010"},
+		{1e20, 2, "100000000000000000000.00"},
+		{1e21, 2, "1e+21"},
+		{-1.5e300, 0, "-1.5e+300"},
+		{0.1, 20, "0.10000000000000000555"},
+		{math.NaN(), 2, "NaN"},
+		{math.Inf(-1), 2, "-Infinity"},
+	})
+}
+
+// === ECMA-OPS-002: Number.prototype.toExponential ===
+
+func TestFormatExponential_ECMA_OPS_002(t *testing.T) {
+	runMethodCases(t, "FormatExponential", jcsfloat.FormatExponential, []methodCase{
+		{0, -1, "0e+0"},
+		{0, 2, "0.00e+0"},
+		{123.456, -1, "1.23456e+2"},
+		{123.456, 2, "1.23e+2"},
+		{-0.00015, 1, "-1.5e-4"},
+		{1.25, 1, "1.3e+0"},
+		{5e-324, -1, "5e-324"},
+		{5e-324, 3, "4.941e-324"},
+		{1.7976931348623157e308, 0, "2e+308"},
+		{99.99, 1, "1.0e+2"},
+		{math.Inf(1), 1, "Infinity"},
+	})
+}
+
+// === ECMA-OPS-003: Number.prototype.toPrecision ===
+
+func TestFormatPrecision_ECMA_OPS_003(t *testing.T) {
+	runMethodCases(t, "FormatPrecision", jcsfloat.FormatPrecision, []methodCase{
+		{0, 3, "0.00"},
+		{0, 1, "0"},
+		{123.456, 3, "123"},
+		{123.456, 2, "1.2e+2"},
+		{123.456, 5, "123.46"},
+		{0.000123, 2, "0.00012"},
+		{0.0000001234, 2, "1.2e-7"},
+		{0.000001, 1, "0.000001"},
+		{99.99, 2, "1.0e+2"},
+		{-1.005, 3, "-1.00"},
+		{1e21, 22, "1000000000000000000000"},
+		{math.NaN(), 5, "NaN"},
+	})
+}
+
+// === ECMA-OPS-004: Number.prototype.toString(radix) ===
+
+func TestFormatRadix_ECMA_OPS_004(t *testing.T) {
+	runMethodCases(t, "FormatRadix", jcsfloat.FormatRadix, []methodCase{
+		{0, 2, "0"},
+		{math.Copysign(0, -1), 16, "0"},
+		{255, 16, "ff"},
+		{-255.5, 16, "-ff.8"},
+		{35, 36, "z"},
+		{0.5, 2, "0.1"},
+		{0.1, 3, "0.0022002200220022002200220022002201"},
+		{1e21, 36, "5v1j4f4ds7c000"},
+		{1e21, 10, "1e+21"},
+		{0.1, 10, "0.1"},
+		{math.Inf(-1), 2, "-Infinity"},
+	})
+}
+
+// === ECMA-OPS-005: RangeError arguments are BOUND_EXCEEDED ===
+
+func TestFormatMethodsRange_ECMA_OPS_005(t *testing.T) {
+	calls := map[string]func() (string, *jcserr.Error){
+		"FormatFixed(-1)":        func() (string, *jcserr.Error) { return jcsfloat.FormatFixed(1, -1) },
+		"FormatFixed(101)":       func() (string, *jcserr.Error) { return jcsfloat.FormatFixed(1, 101) },
+		"FormatExponential(101)": func() (string, *jcserr.Error) { return jcsfloat.FormatExponential(1, 101) },
+		"FormatPrecision(0)":     func() (string, *jcserr.Error) { return jcsfloat.FormatPrecision(1, 0) },
+		"FormatPrecision(101)":   func() (string, *jcserr.Error) { return jcsfloat.FormatPrecision(1, 101) },
+		"FormatRadix(1)":         func() (string, *jcserr.Error) { return jcsfloat.FormatRadix(1, 1) },
+		"FormatRadix(37)":        func() (string, *jcserr.Error) { return jcsfloat.FormatRadix(1, 37) },
+		// The range check precedes the non-finite shortcut, as in ECMA-262.
+		"FormatFixed(NaN, 101)": func() (string, *jcserr.Error) { return jcsfloat.FormatFixed(math.NaN(), 101) },
+	}
+	for name, call := range calls {
+		got, err := call()
+		if err == nil || err.Class != jcserr.BoundExceeded {
+			t.Fatalf("%s = %q, %v; want BOUND_EXCEEDED", name, got, err)
+		}
+	}
+}
+
+// === ECMA-VEC-005 / ECMA-OPS-006: V8 method oracle ===
+
+func TestMethodsOracle(t *testing.T) {
+	verifyMethodsOracle(t, "testdata/golden_methods_vectors.csv", 61440,
+		"ddda7577b62aed4c0612b4478157c1fdeab6e7ec7bf280c8a47edff289bfbfb3")
+}
+
+func verifyMethodsOracle(t *testing.T, path string, expectedRows int, expectedSHA256 string) {
+	t.Helper()
+
+	// #nosec G304 -- oracle fixture path is explicit test input.
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatalf("open oracle: %v", err)
+	}
+	t.Cleanup(func() {
+		if closeErr := f.Close(); closeErr != nil {
+			t.Errorf("close oracle %s: %v", path, closeErr)
+		}
+	})
+
+	methods := map[string]func(float64, int) (string, *jcserr.Error){
+		"toFixed":       jcsfloat.FormatFixed,
+		"toExponential": jcsfloat.FormatExponential,
+		"toPrecision":   jcsfloat.FormatPrecision,
+		"toString":      jcsfloat.FormatRadix,
+	}
+	h := sha256.New()
+	sc := bufio.NewScanner(io.TeeReader(f, h))
+	sc.Buffer(make([]byte, 0, 128*1024), 2*1024*1024)
+	rows := 0
+	for sc.Scan() {
+		rows++
+		parts := strings.Split(sc.Text(), ",")
+		if len(parts) != 4 {
+			t.Fatalf("malformed oracle line %d: %q", rows, sc.Text())
+		}
+		bits, err := strconv.ParseUint(parts[0], 16, 64)
+		if err != nil {
+			t.Fatalf("line %d parse bits: %v", rows, err)
+		}
+		arg := -1 // undefined
+		if parts[2] != "" {
+			if arg, err = strconv.Atoi(parts[2]); err != nil {
+				t.Fatalf("line %d parse argument: %v", rows, err)
+			}
+		}
+		format, ok := methods[parts[1]]
+		if !ok {
+			t.Fatalf("line %d unknown method %q", rows, parts[1])
+		}
+		got, fmtErr := format(math.Float64frombits(bits), arg)
+		if fmtErr != nil || got != parts[3] {
+			t.Fatalf("line %d %s(bits=%016x, %s) = %q (%v), want %q", rows, parts[1], bits, parts[2], got, fmtErr, parts[3])
+		}
+	}
+	if err := sc.Err(); err != nil {
+		t.Fatalf("scan oracle: %v", err)
+	}
+	if rows != expectedRows {
+		t.Fatalf("oracle row count mismatch: got %d want %d", rows, expectedRows)
+	}
+	if gotSHA := fmt.Sprintf("%x", h.Sum(nil)); gotSHA != expectedSHA256 {
+		t.Fatalf("oracle checksum mismatch: got %s want %s", gotSHA, expectedSHA256)
+	}
+}
@@ -0,0 +1,154 @@
+package jcsfloat
+
+import (
+	"fmt"
+	"math"
+	"math/big"
+	"strings"
+
+	"github.com/lattice-substrate/json-canon/jcserr"
+)
+
+// Decimal exponents outside these bounds are decided without arithmetic: with
+// value = 0.d × 10^n, n > maxDoubleDecimalExp exceeds math.MaxFloat64 for any
+// digits d, and n < minDoubleDecimalExp is below half the smallest subnormal.
+const (
+	maxDoubleDecimalExp = 310
+	minDoubleDecimalExp = -324
+)
+
+// fastPathPow10 holds the powers of ten that are exact in binary64.
+var fastPathPow10 = [...]float64{
+	1e0, 1e1, 1e2, 1e3, 1e4, 1e5, 1e6, 1e7, 1e8, 1e9, 1e10,
+	1e11, 1e12, 1e13, 1e14, 1e15, 1e16, 1e17, 1e18, 1e19, 1e20, 1e21, 1e22,
+}
+
+// ParseDouble converts the JSON number token s to the nearest IEEE 754
+// double, rounding ties to even, as ECMA-262 StringToNumber does for the
+// StrDecimalLiteral forms that JSON allows. Every significant digit takes
+// part in rounding; ECMA-262 permits but does not require truncation after
+// the 20th. The conversion uses only exact integer arithmetic and does not
+// depend on strconv.
+//
+// A token that is not exactly one RFC 8259 number is INVALID_GRAMMAR. A
+// lexical negative zero is NUMBER_NEGZERO. A value that rounds to infinity is
+// NUMBER_OVERFLOW and a non-zero value that rounds to zero is
+// NUMBER_UNDERFLOW; for these two the rounded ±Inf or ±0 is returned with
+// the error. Error offsets are -1.
+//
+// ECMA-PARSE-001: Decimal to binary64 conversion is correctly rounded.
+// ECMA-PARSE-002: Range and negative-zero failures are classified directly.
+func ParseDouble(s string) (float64, *jcserr.Error) {
+	t, ok := scanNumberToken(s)
+	if !ok {
+		return 0, jcserr.New(jcserr.InvalidGrammar, -1, fmt.Sprintf("invalid number token %q", s))
+	}
+	sign := 1.0
+	if t.negative {
+		sign = -1
+	}
+	digits := strings.TrimLeft(t.intPart+t.frac, "0")
+	if digits == "" {
+		if t.negative {
+			return math.Copysign(0, -1), jcserr.New(jcserr.NumberNegZero, -1, "negative zero token is not allowed")
+		}
+		return 0, nil
+	}
+	f := sign * parseMagnitude(t, digits)
+	switch {
+	case math.IsInf(f, 0):
+		return f, jcserr.New(jcserr.NumberOverflow, -1, "number overflows IEEE 754 double")
+	case f == 0:
+		return f, jcserr.New(jcserr.NumberUnderflow, -1, "non-zero number underflows to IEEE 754 zero")
+	}
+	return f, nil
+}
+
+// parseMagnitude returns the correctly rounded absolute value of t, whose
+// significant digits without leading zeros are digits.
+func parseMagnitude(t numberToken, digits string) float64 {
+	exp, ok := parseExponent(t.exp)
+	if !ok {
+		if strings.HasPrefix(t.exp, "-") {
+			return 0
+		}
+		return math.Inf(1)
+	}
+	// value = 0.digits × 10^n = digits × 10^(n - len(digits)).
+	n := exp + int64(len(digits)) - int64(len(t.frac))
+	switch {
+	case n > maxDoubleDecimalExp:
+		return math.Inf(1)
+	case n < minDoubleDecimalExp:
+		return 0
+	}
+	trimmed := strings.TrimRight(digits, "0")
+	e10 := int(n) - len(trimmed)
+	if f, ok := fastPathDouble(trimmed, e10); ok {
+		return f
+	}
+	return exactDouble(trimmed, e10)
+}
+
+// fastPathDouble handles digits × 10^e10 when the significand and the power
+// of ten are both exact doubles, so one IEEE 754 operation rounds correctly.
+func fastPathDouble(digits string, e10 int) (float64, bool) {
+	if len(digits) > 15 || e10 < -22 || e10 > 22 {
+		return 0, false
+	}
+	var m uint64
+	for i := 0; i < len(digits); i++ {
+		m = m*10 + uint64(digits[i]-'0')
+	}
+	if e10 < 0 {
+		return float64(m) / fastPathPow10[-e10], true
+	}
+	return float64(m) * fastPathPow10[e10], true
+}
+
+// exactDouble rounds digits × 10^e10 to binary64 by exact division: it finds
+// the scale 2^s at which the quotient q = ⌊digits × 10^e10 × 2^s⌋ has 53
+// significant bits, or s = 1074 for subnormals, and rounds q half to even.
+func exactDouble(digits string, e10 int) float64 {
+	num, ok := new(big.Int).SetString(digits, 10)
+	if !ok {
+		return math.NaN()
+	}
+	den := big.NewInt(1)
+	if e10 >= 0 {
+		num.Mul(num, pow10Big(e10))
+	} else {
+		den = pow10Big(-e10)
+	}
+
+	s := min(53-(num.BitLen()-den.BitLen()), 1074)
+	q, r := scaledQuotient(num, den, s)
+	if q.BitLen() > 53 {
+		s--
+		q, r = scaledQuotient(num, den, s)
+	}
+
+	// Round half to even; q may carry into 2^53, which is still exact.
+	r.Lsh(r, 1)
+	if c := r.Cmp(scaledDenominator(den, s)); c > 0 || (c == 0 && q.Bit(0) == 1) {
+		q.Add(q, big.NewInt(1))
+	}
+	return math.Ldexp(float64(q.Uint64()), -s)
+}
+
+// scaledQuotient returns the quotient and remainder of num × 2^s divided by
+// den, shifting den instead of num when s is negative.
+func scaledQuotient(num, den *big.Int, s int) (*big.Int, *big.Int) {
+	n := new(big.Int).Set(num)
+	if s > 0 {
+		n.Lsh(n, uint(s))
+	}
+	return new(big.Int).QuoRem(n, scaledDenominator(den, s), new(big.Int))
+}
+
+func scaledDenominator(den *big.Int, s int) *big.Int {
+	if s >= 0 {
+		return den
+	}
+	return new(big.Int).Lsh(den, uint(-s))
+}
@@ -0,0 +1,120 @@
+package jcsfloat_test
+
+import (
+	"math"
+	"strconv"
+	"testing"
+
+	"github.com/lattice-substrate/json-canon/jcserr"
+	"github.com/lattice-substrate/json-canon/jcsfloat"
+)
+
+// === ECMA-PARSE-001: Decimal to binary64 conversion is correctly rounded ===
+
+func TestParseDouble_ECMA_PARSE_001(t *testing.T) {
+	cases := []struct {
+		in   string
+		bits uint64
+	}{
+		{"0", 0},
+		{"0.000e-999999999999999999999", 0},
+		{"1", 0x3ff0000000000000},
+		{"0.1", 0x3fb999999999999a},
+		{"-2.5E-3", 0xbf647ae147ae147b},
+		{"5e-324", 0x0000000000000001},
+		{"4.9406564584124654e-324", 0x0000000000000001},
+		// Just above half the smallest subnormal rounds up to it.
+		{"2.4703282292062328e-324", 0x0000000000000001},
+		{"2.2250738585072011e-308", 0x000fffffffffffff},
+		{"2.2250738585072014e-308", 0x0010000000000000},
+		{"1.7976931348623157e308", 0x7fefffffffffffff},
+		// Below the midpoint between MaxFloat64 and 2^1024.
+		{"1.7976931348623158079e308", 0x7fefffffffffffff},
+		{"9007199254740993", 0x4340000000000000}, // tie, even stays
+		{"9007199254740995", 0x4340000000000002}, // tie, rounds to even
+		{"9007199254740993.0000000000000000000000000001", 0x4340000000000001},
+		{"123456789012345678901234567890", 0x45f8ee90ff6c373e},
+		{"1e23", 0x44b52d02c7e14af6},
+		{"8.98846567431158e307", 0x7fe0000000000000},
+		{"0.000001", 0x3eb0c6f7a0b5ed8d},
+		{"1e21", 0x444b1ae4d6e2ef50},
+		{"100000000000000000000000000000000000000000000e-23", 0x444b1ae4d6e2ef50},
+	}
+	for _, tc := range cases {
+		got, err := jcsfloat.ParseDouble(tc.in)
+		if err != nil {
+			t.Fatalf("ParseDouble(%q): %v", tc.in, err)
+		}
+		if math.Float64bits(got) != tc.bits {
+			t.Fatalf("ParseDouble(%q) = %016x, want %016x", tc.in, math.Float64bits(got), tc.bits)
+		}
+	}
+}
+
+func TestParseDoubleAgreesWithStrconv(t *testing.T) {
+	// A fixed splitmix64 sequence keeps the corpus reproducible.
+	state := uint64(0x9e3779b97f4a7c15)
+	next := func() uint64 {
+		state += 0x9e3779b97f4a7c15
+		z := state
+		z = (z ^ (z >> 30)) * 0xbf58476d1ce4e5b9
+		z = (z ^ (z >> 27)) * 0x94d049bb133111eb
+		return z ^ (z >> 31)
+	}
+	for i := 0; i < 20000; i++ {
+		bits := next() &^ (1 << 63)
+		f := math.Float64frombits(bits)
+		if math.IsNaN(f) || math.IsInf(f, 0) || f == 0 {
+			continue
+		}
+		// Shortest, 17-digit, and over-long forms, plus a random decimal.
+		precision := []int{-1, 16, 25}[i%3]
+		inputs := []string{
+			strconv.FormatFloat(f, 'e', precision, 64),
+			strconv.FormatUint(next()%1_000_000_000_000_000_000, 10) + "e" + strconv.Itoa(int(next()%660)-340),
+		}
+		for _, in := range inputs {
+			want, werr := strconv.ParseFloat(in, 64)
+			got, gerr := jcsfloat.ParseDouble(in)
+			if math.Float64bits(got) != math.Float64bits(want) {
+				t.Fatalf("ParseDouble(%q) = %016x, strconv %016x", in, math.Float64bits(got), math.Float64bits(want))
+			}
+			if (werr != nil) != (gerr != nil) && want != 0 {
+				t.Fatalf("ParseDouble(%q): error %v, strconv %v", in, gerr, werr)
+			}
+		}
+	}
+}
+
+// === ECMA-PARSE-002: Range and negative-zero failures are classified directly ===
+
+func TestParseDouble_ECMA_PARSE_002(t *testing.T) {
+	cases := []struct {
+		in    string
+		class jcserr.FailureClass
+		want  float64
+	}{
+		{"1e309", jcserr.NumberOverflow, math.Inf(1)},
+		{"-1.7976931348623159e308", jcserr.NumberOverflow, math.Inf(-1)},
+		{"1e99999999999999999999", jcserr.NumberOverflow, math.Inf(1)},
+		{"1e-400", jcserr.NumberUnderflow, 0},
+		{"-2.4703282292062327e-324", jcserr.NumberUnderflow, math.Copysign(0, -1)},
+		{"1e-99999999999999999999", jcserr.NumberUnderflow, 0},
+		{"-0", jcserr.NumberNegZero, math.Copysign(0, -1)},
+		{"-0.000e5", jcserr.NumberNegZero, math.Copysign(0, -1)},
+	}
+	for _, tc := range cases {
+		got, err := jcsfloat.ParseDouble(tc.in)
+		if err == nil || err.Class != tc.class {
+			t.Fatalf("ParseDouble(%q): expected %s, got %v", tc.in, tc.class, err)
+		}
+		if math.Float64bits(got) != math.Float64bits(tc.want) {
+			t.Fatalf("ParseDouble(%q) = %v, want %v", tc.in, got, tc.want)
+		}
+	}
+	for _, in := range []string{"", "-", "01", "1.", ".5", "+1", "1e", "0x10", "Infinity", "NaN", " 1", "1_000"} {
+		if _, err := jcsfloat.ParseDouble(in); err == nil || err.Class != jcserr.InvalidGrammar {
+			t.Fatalf("ParseDouble(%q): expected INVALID_GRAMMAR, got %v", in, err)
+		}
+	}
+}
@@ -0,0 +1,177 @@
+package jcsfloat
+
+import (
+	"math"
+	"math/big"
+	"math/bits"
+)
+
+// The fixed-width digit generator follows Giulietti's Schubfach algorithm
+// ("The Schubfach way to render doubles", 2020). It brackets v = c × 2^q and
+// its rounding interval with 126-bit approximations of 10^-k and picks the
+// shortest decimal inside the interval, using only 64-bit integer
+// arithmetic. Subnormal inputs are left to the multiprecision path.
+
+// Range of decimal exponents e for which 10^e is tabulated. For normal
+// doubles e = -k with k = ⌊log10(2^q)⌋ or ⌊log10(¾ × 2^q)⌋, q ∈ [-1074, 971].
+const (
+	minWidePow10 = -292
+	maxWidePow10 = 325
+)
+
+const mask63 = 1<<63 - 1
+
+// widePow10 is g = ⌊10^e × 2^(125 - ⌊e × log2(10)⌋)⌋ + 1, a 126-bit value in
+// (2^125, 2^126), stored as g = hi × 2^63 + lo.
+type widePow10 struct {
+	hi, lo uint64
+}
+
+var widePow10Table [maxWidePow10 - minWidePow10 + 1]widePow10
+
+func init() {
+	p := big.NewInt(1)
+	for e := 0; e <= maxWidePow10; e++ {
+		widePow10Table[e-minWidePow10] = newWidePow10(p, e)
+		p.Mul(p, bigTen)
+	}
+	p.SetInt64(10)
+	for e := -1; e >= minWidePow10; e-- {
+		widePow10Table[e-minWidePow10] = newWidePow10(p, e)
+		p.Mul(p, bigTen)
+	}
+}
+
+// newWidePow10 computes the table entry for 10^e, where p = 10^|e|.
+func newWidePow10(p *big.Int, e int) widePow10 {
+	shift := 125 - flog2pow10(e)
+	g := new(big.Int)
+	switch {
+	case e < 0:
+		g.Lsh(big.NewInt(1), uint(shift))
+		g.Quo(g, p)
+	case shift >= 0:
+		g.Lsh(p, uint(shift))
+	default:
+		g.Rsh(p, uint(-shift))
+	}
+	g.Add(g, big.NewInt(1))
+	lo := new(big.Int).And(g, new(big.Int).SetUint64(mask63)).Uint64()
+	return widePow10{hi: g.Rsh(g, 63).Uint64(), lo: lo}
+}
+
+// flog10pow2 returns ⌊q × log10(2)⌋ for |q| ≤ 5456721.
+func flog10pow2(q int) int {
+	return int(int64(q) * 661_971_961_083 >> 41)
+}
+
+// flog10threeQuartersPow2 returns ⌊log10(¾ × 2^q)⌋ for |q| ≤ 5456721.
+func flog10threeQuartersPow2(q int) int {
+	return int((int64(q)*661_971_961_083 - 274_743_187_321) >> 41)
+}
+
+// flog2pow10 returns ⌊e × log2(10)⌋ for |e| ≤ 1233.
+func flog2pow10(e int) int {
+	return int(int64(e) * 913_124_641_741 >> 38)
+}
+
+// roundToOdd returns ⌊g × cp / 2^127⌋ with its lowest bit set when the
+// quotient is inexact, which preserves the comparisons against exact
+// multiples of 4 that shortestDigits needs.
+func roundToOdd(g widePow10, cp uint64) uint64 {
+	x1, _ := bits.Mul64(g.lo, cp)
+	y1, y0 := bits.Mul64(g.hi, cp)
+	z := y0>>1 + x1
+	vbp := y1 + z>>63
+	return vbp | (z&mask63+mask63)>>63
+}
+
+// shortestDigits is the fixed-width counterpart of generateDigitsExact for
+// positive finite normal doubles. It returns ok = false for subnormals.
+//
+// ECMA-VEC-004: Output is identical to the multiprecision reference.
+func shortestDigits(f float64) (digits string, n int, ok bool) {
+	b := math.Float64bits(f)
+	biasedExp := int(b >> 52 & 0x7FF)
+	if biasedExp == 0 {
+		return "", 0, false
+	}
+	c := uint64(1)<<52 | b&(1<<52-1)
+	q := biasedExp - 1075
+
+	// Integers below 2^53 are exact and no shorter decimal rounds to them.
+	if mq := -q; 0 < mq && mq < 53 && c>>mq<<mq == c {
+		digits, n = decimalDigits(c>>mq, 0)
+		return digits, n, true
+	}
+
+	out := c & 1
+	cb := c << 2
+	cbr := cb + 2
+	cbl := cb - 2
+	k := flog10pow2(q)
+	// At a power of two the interval below v is half as wide (ECMA-FMT-008).
+	if c == 1<<52 && biasedExp > 1 {
+		cbl = cb - 1
+		k = flog10threeQuartersPow2(q)
+	}
+	h := q + flog2pow10(-k) + 2
+	g := widePow10Table[-k-minWidePow10]
+	vb := roundToOdd(g, cb<<h)
+	vbl := roundToOdd(g, cbl<<h)
+	vbr := roundToOdd(g, cbr<<h)
+
+	// A shorter decimal in the interval is a multiple of 10 next to s.
+	s := vb >> 2
+	sp10 := s / 10 * 10
+	tp10 := sp10 + 10
+	upin := vbl+out <= sp10<<2
+	wpin := tp10<<2+out <= vbr
+	if upin != wpin {
+		if upin {
+			digits, n = decimalDigits(sp10, k)
+		} else {
+			digits, n = decimalDigits(tp10, k)
+		}
+		return digits, n, true
+	}
+
+	// Otherwise choose between s and s+1, the closer one, or the even one
+	// on a tie (ECMA-FMT-009).
+	t := s + 1
+	uin := vbl+out <= s<<2
+	win := t<<2+out <= vbr
+	if uin != win {
+		if uin {
+			digits, n = decimalDigits(s, k)
+		} else {
+			digits, n = decimalDigits(t, k)
+		}
+		return digits, n, true
+	}
+	cmp := int64(vb - (s+t)<<1)
+	if cmp < 0 || (cmp == 0 && s%2 == 0) {
+		digits, n = decimalDigits(s, k)
+	} else {
+		digits, n = decimalDigits(t, k)
+	}
+	return digits, n, true
+}
+
+// decimalDigits converts the decimal m × 10^e, m > 0, to the digits of m
+// without trailing zeros and the exponent n with value = 0.<digits> × 10^n.
+func decimalDigits(m uint64, e int) (string, int) {
+	var buf [20]byte
+	i := len(buf)
+	for m > 0 {
+		i--
+		buf[i] = byte('0' + m%10)
+		m /= 10
+	}
+	n := len(buf) - i + e
+	end := len(buf)
+	for end > i+1 && buf[end-1] == '0' {
+		end--
+	}
+	return string(buf[i:end]), n
+}
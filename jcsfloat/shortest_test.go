@@ -0,0 +1,103 @@
+package jcsfloat_test
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"math"
+	"strconv"
+	"testing"
+
+	"github.com/lattice-substrate/json-canon/jcsfloat"
+)
+
+// differentialCorpusSHA256 is the SHA-256 of "<hex-bits>,<FormatDouble>\n"
+// over differentialCorpus. Unit tests run on amd64 and arm64, so both
+// architectures must reproduce it bit for bit.
+const differentialCorpusSHA256 = "ae87f821ae0ad371de1954d854bf6dc6fb428037120c3e933a4117b40875ebd3"
+
+// splitmix64 returns a splitmix64 sequence starting after state; a fixed
+// seed keeps each corpus reproducible.
+func splitmix64(state uint64) func() uint64 {
+	return func() uint64 {
+		state += 0x9e3779b97f4a7c15
+		z := state
+		z = (z ^ (z >> 30)) * 0xbf58476d1ce4e5b9
+		z = (z ^ (z >> 27)) * 0x94d049bb133111eb
+		return z ^ (z >> 31)
+	}
+}
+
+// differentialCorpus calls yield with the bit patterns of positive finite
+// nonzero doubles that stress the fixed-width digit path.
+func differentialCorpus(yield func(bits uint64)) {
+	// Each binary exponent at its power of two, its neighbours, and the
+	// largest significand, which exercise the asymmetric interval and the
+	// ends of the power-of-ten table.
+	for e := uint64(0); e < 0x7FF; e++ {
+		for _, m := range []uint64{0, 1, 2, 3, 1<<52 - 1, 1<<52 - 2} {
+			if bits := e<<52 | m; bits != 0 {
+				yield(bits)
+			}
+		}
+	}
+	// Powers of ten and their neighbours, where shorter decimals sit at the
+	// edge of the rounding interval.
+	for p := -323; p <= 308; p++ {
+		bits := math.Float64bits(math.Pow10(p))
+		yield(bits)
+		yield(bits + 1)
+		if bits > 1 {
+			yield(bits - 1)
+		}
+	}
+	// Small and boundary integers take the exact-integer shortcut.
+	for i := uint64(1); i <= 100000; i++ {
+		yield(math.Float64bits(float64(i)))
+	}
+	for _, v := range []float64{1 << 53, 1<<53 + 2, 1<<53 - 1, 1 << 63, 0.5, 0.25, 1.5} {
+		yield(math.Float64bits(v))
+	}
+	next := splitmix64(0x2545f4914f6cdd1d)
+	for i := 0; i < 200000; i++ {
+		bits := next() &^ (1 << 63)
+		if bits>>52 == 0x7FF || bits == 0 {
+			continue
+		}
+		yield(bits)
+	}
+}
+
+// === ECMA-VEC-004: fixed-width digits match the multiprecision reference ===
+
+func TestFormatDoubleMatchesExact_ECMA_VEC_004(t *testing.T) {
+	differentialCorpus(func(bits uint64) {
+		f := math.Float64frombits(bits)
+		got, err := jcsfloat.FormatDouble(f)
+		want, wantErr := jcsfloat.FormatDoubleExact(f)
+		if err != nil || wantErr != nil {
+			t.Fatalf("bits=%016x: errors %v, %v", bits, err, wantErr)
+		}
+		if got != want {
+			t.Fatalf("bits=%016x FormatDouble=%q FormatDoubleExact=%q", bits, got, want)
+		}
+	})
+}
+
+func TestFormatDoubleCorpusDigest_ECMA_VEC_004(t *testing.T) {
+	h := sha256.New()
+	line := make([]byte, 0, 64)
+	differentialCorpus(func(bits uint64) {
+		s, err := jcsfloat.FormatDouble(math.Float64frombits(bits))
+		if err != nil {
+			t.Fatalf("bits=%016x: %v", bits, err)
+		}
+		line = strconv.AppendUint(line[:0], bits, 16)
+		line = append(line, ',')
+		line = append(line, s...)
+		line = append(line, '\n')
+		h.Write(line)
+	})
+	if got := hex.EncodeToString(h.Sum(nil)); got != differentialCorpusSHA256 {
+		t.Fatalf("corpus digest = %s, want %s", got, differentialCorpusSHA256)
+	}
+}
@@ -0,0 +1,123 @@
+#!/usr/bin/env node
+'use strict';
+
+// Provenance: oracle vectors generated via ECMA-262 Number.prototype.toFixed
+// (§21.1.3.3), toExponential (§21.1.3.2), toPrecision (§21.1.3.5), and
+// toString(radix) (§21.1.3.6) as implemented by V8.
+// Output: <16-hex-bits>,<method>,<argument>,<expected-string>; an empty
+// argument is undefined.
+console.error(`oracle-provenance: node=${process.version} v8=${process.versions.v8}`);
+if (typeof process.versions.v8 === 'undefined') {
+  console.error('warning: unable to detect V8 version');
+}
+
+const TARGET_VALUES = 1920;
+const SIGN_BIT = 0x8000000000000000n;
+const MASK64 = 0xffffffffffffffffn;
+
+const dv = new DataView(new ArrayBuffer(8));
+
+function numberFromBits(bits) {
+  dv.setBigUint64(0, bits, false);
+  return dv.getFloat64(0, false);
+}
+
+function bitsFromNumber(x) {
+  dv.setFloat64(0, x, false);
+  return dv.getBigUint64(0, false);
+}
+
+function formatBits(bits) {
+  return bits.toString(16).padStart(16, '0');
+}
+
+const set = new Set();
+
+function addBothSigns(bits) {
+  set.add(bits & MASK64);
+  set.add((bits ^ SIGN_BIT) & MASK64);
+}
+
+function addNumber(x) {
+  addBothSigns(bitsFromNumber(x));
+}
+
+// Non-finite values and zeros.
+addBothSigns(0n);
+addBothSigns(0x7ff0000000000000n);
+set.add(0x7ff8000000000000n);
+
+// Subnormal edges and the smallest normals.
+for (let i = 1n; i <= 16n; i++) {
+  addBothSigns(i);
+  addBothSigns(0x000fffffffffffffn - i + 1n);
+  addBothSigns(0x0010000000000000n + i - 1n);
+}
+addBothSigns(0x7fefffffffffffffn);
+
+// Small integers, halves, and the radix-digit boundaries.
+for (let i = 0; i <= 72; i++) {
+  addNumber(i);
+  addNumber(i + 0.5);
+}
+
+// Powers of two across the exponent range.
+for (let e = -1074; e <= 1023; e += 13) {
+  addNumber(Math.pow(2, e));
+}
+
+// Powers of ten and their neighbours, including the 1e21 and 1e-7 switches.
+for (let e = -30; e <= 30; e++) {
+  const bits = bitsFromNumber(Number(`1e${e}`));
+  addBothSigns(bits - 1n);
+  addBothSigns(bits);
+  addBothSigns(bits + 1n);
+}
+
+// Decimal fractions whose binary value sits just above or below a rounding
+// half, such as 1.005 and 8.345.
+for (let i = 1; i <= 4000; i += 29) {
+  addNumber(i / 1000);
+  addNumber(i / 200);
+}
+
+// Deterministic pseudo-random fill: half over all bit patterns, half with
+// magnitudes in [2^-40, 2^80].
+let state = 0x9e3779b97f4a7c15n;
+function nextRand64() {
+  state ^= state >> 12n;
+  state ^= (state << 25n) & MASK64;
+  state ^= state >> 27n;
+  return (state * 0x2545f4914f6cdd1dn) & MASK64;
+}
+
+let toggle = false;
+while (set.size < TARGET_VALUES) {
+  let bits = nextRand64();
+  if (toggle) {
+    const exp = 1023n - 40n + (nextRand64() % 121n);
+    bits = (bits & 0x800fffffffffffffn) | (exp << 52n);
+  }
+  toggle = !toggle;
+  if (Number.isFinite(numberFromBits(bits))) {
+    set.add(bits);
+  }
+}
+
+const methods = [
+  ['toFixed', [0, 1, 2, 3, 6, 10, 20, 100]],
+  ['toExponential', [undefined, 0, 1, 2, 5, 16, 20, 100]],
+  ['toPrecision', [1, 2, 3, 6, 16, 17, 21, 100]],
+  ['toString', [2, 3, 7, 8, 10, 16, 31, 36]],
+];
+
+const values = Array.from(set).sort((a, b) => (a < b ? -1 : a > b ? 1 : 0));
+for (const bits of values) {
+  const x = numberFromBits(bits);
+  for (const [method, args] of methods) {
+    for (const arg of args) {
+      const out = x[method](arg);
+      process.stdout.write(`${formatBits(bits)},${method},${arg === undefined ? '' : arg},${out}\n`);
+    }
+  }
+}
@@ -0,0 +1,283 @@
+// Package jcsgit integrates RFC 8785 canonicalization with git: it serves
+// the long-running filter process protocol for clean filters and reads the
+// files staged in a repository's index for pre-commit hooks.
+//
+// Reading the index runs the local git executable with fixed arguments and
+// no shell. The package makes no network calls.
+package jcsgit
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+
+	"github.com/lattice-substrate/json-canon/jcserr"
+	"github.com/lattice-substrate/json-canon/jcstoken"
+)
+
+// maxPacketData is the largest payload of one pkt-line: 65520 bytes less
+// the four-byte length header.
+const maxPacketData = 65516
+
+// Filter serves the git long-running filter process protocol, version 2,
+// advertising the clean capability only. Configure it as
+//
+//	[filter "jcs"]
+//		process = jcs-canon git-filter-process
+//		required = true
+//
+// so that git canonicalizes JSON files as they are staged.
+type Filter struct {
+	// Clean returns the cleaned form of the content of the file at
+	// pathname.
+	Clean func(pathname string, content []byte) ([]byte, error)
+	// Report is called with each file that fails to clean, before git is
+	// answered with an error status. It may be nil.
+	Report func(pathname string, err error, content []byte)
+	// MaxContentSize limits the content of one file; larger files fail with
+	// BOUND_EXCEEDED. Zero selects jcstoken.DefaultMaxInputSize.
+	MaxContentSize int
+}
+
+// filterRequest is one command from git.
+type filterRequest struct {
+	command  string
+	pathname string
+	content  []byte
+	err      error
+}
+
+// Serve performs the handshake on r and w and answers requests until git
+// closes r between requests. A file that fails to clean is answered with
+// "status=error" and reported; git decides whether that fails the
+// operation. Serve fails with CLI_USAGE when the peer does not speak the
+// protocol and with INTERNAL_IO when reading or writing fails.
+//
+// GIT-FILTER-001: The filter process canonicalizes each file and answers a
+// failure with an error status.
+func (f *Filter) Serve(r io.Reader, w io.Writer) error {
+	pr := &packetReader{r: bufio.NewReader(r)}
+	pw := &packetWriter{w: bufio.NewWriter(w)}
+	if err := handshake(pr, pw); err != nil {
+		return err
+	}
+	for {
+		req, err := f.readRequest(pr)
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if err := f.answer(pw, req); err != nil {
+			return err
+		}
+	}
+}
+
+// handshake exchanges the welcome, version, and capability lists.
+func handshake(pr *packetReader, pw *packetWriter) error {
+	welcome, err := pr.readList()
+	if err != nil {
+		return unexpectedEOF(err)
+	}
+	if len(welcome) == 0 || welcome[0] != "git-filter-client" || !contains(welcome[1:], "version=2") {
+		return jcserr.New(jcserr.CLIUsage, -1, "filter protocol: expected git-filter-client version=2")
+	}
+	if err := pw.writeList("git-filter-server", "version=2"); err != nil {
+		return err
+	}
+	capabilities, err := pr.readList()
+	if err != nil {
+		return unexpectedEOF(err)
+	}
+	if !contains(capabilities, "capability=clean") {
+		return jcserr.New(jcserr.CLIUsage, -1, "filter protocol: client does not support the clean capability")
+	}
+	return pw.writeList("capability=clean")
+}
+
+// readRequest reads the next command and its content. A content failure is
+// recorded in the request, after the content has been read to its end.
+// readRequest returns io.EOF when git closes the stream between requests.
+func (f *Filter) readRequest(pr *packetReader) (filterRequest, error) {
+	keys, err := pr.readList()
+	if err != nil {
+		return filterRequest{}, err
+	}
+	var req filterRequest
+	for _, kv := range keys {
+		key, value, _ := bytes.Cut([]byte(kv), []byte("="))
+		switch string(key) {
+		case "command":
+			req.command = string(value)
+		case "pathname":
+			req.pathname = string(value)
+		}
+	}
+	limit := f.MaxContentSize
+	if limit <= 0 {
+		limit = jcstoken.DefaultMaxInputSize
+	}
+	for {
+		data, flush, err := pr.read()
+		if err != nil {
+			return filterRequest{}, unexpectedEOF(err)
+		}
+		if flush {
+			return req, nil
+		}
+		if req.err == nil && len(req.content)+len(data) > limit {
+			req.err = jcserr.New(jcserr.BoundExceeded, 0, fmt.Sprintf("input exceeds maximum size %d bytes", limit))
+			req.content = nil
+		}
+		if req.err == nil {
+			req.content = append(req.content, data...)
+		}
+	}
+}
+
+// answer writes the response to req: the status, the cleaned content, and
+// an empty list keeping the status.
+func (f *Filter) answer(pw *packetWriter, req filterRequest) error {
+	var out []byte
+	err := req.err
+	if err == nil && req.command != "clean" {
+		err = jcserr.New(jcserr.CLIUsage, -1, fmt.Sprintf("filter protocol: unsupported command %q", req.command))
+	}
+	if err == nil {
+		out, err = f.Clean(req.pathname, req.content)
+	}
+	if err != nil {
+		if f.Report != nil {
+			f.Report(req.pathname, err, req.content)
+		}
+		return pw.writeList("status=error")
+	}
+	if err := pw.writeList("status=success"); err != nil {
+		return err
+	}
+	if err := pw.writeContent(out); err != nil {
+		return err
+	}
+	return pw.writeList()
+}
+
+func contains(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+// unexpectedEOF reports the end of the stream where a message is required
+// as a protocol failure.
+func unexpectedEOF(err error) error {
+	if errors.Is(err, io.EOF) {
+		return jcserr.New(jcserr.CLIUsage, -1, "filter protocol: unexpected end of stream")
+	}
+	return err
+}
+
+// packetReader reads pkt-lines: a four-digit hexadecimal length that
+// includes itself, then the payload; "0000" is a flush packet.
+type packetReader struct {
+	r *bufio.Reader
+}
+
+// read returns the payload of the next packet, or flush for a flush
+// packet. It returns io.EOF only when the stream ends before a packet.
+func (pr *packetReader) read() ([]byte, bool, error) {
+	var header [4]byte
+	if _, err := io.ReadFull(pr.r, header[:]); err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, false, io.EOF
+		}
+		return nil, false, readError(err)
+	}
+	n, err := strconv.ParseUint(string(header[:]), 16, 16)
+	switch {
+	case err != nil:
+		return nil, false, jcserr.New(jcserr.CLIUsage, -1, fmt.Sprintf("filter protocol: invalid packet length %q", header[:]))
+	case n == 0:
+		return nil, true, nil
+	case n <= 4 || n > maxPacketData+4:
+		return nil, false, jcserr.New(jcserr.CLIUsage, -1, fmt.Sprintf("filter protocol: invalid packet length %d", n))
+	}
+	data := make([]byte, n-4)
+	if _, err := io.ReadFull(pr.r, data); err != nil {
+		return nil, false, readError(err)
+	}
+	return data, false, nil
+}
+
+// readList reads text packets up to a flush packet, without their trailing
+// newlines. It returns io.EOF only when the stream ends before the list.
+func (pr *packetReader) readList() ([]string, error) {
+	var list []string
+	for {
+		data, flush, err := pr.read()
+		if err != nil {
+			if len(list) > 0 {
+				return nil, unexpectedEOF(err)
+			}
+			return nil, err
+		}
+		if flush {
+			return list, nil
+		}
+		list = append(list, string(bytes.TrimSuffix(data, []byte("\n"))))
+	}
+}
+
+func readError(err error) error {
+	if errors.Is(err, io.ErrUnexpectedEOF) {
+		return jcserr.New(jcserr.CLIUsage, -1, "filter protocol: truncated packet")
+	}
+	return jcserr.Wrap(jcserr.InternalIO, -1, "filter protocol: read", err)
+}
+
+// packetWriter writes pkt-lines. Every list and content ends with a flush
+// packet, which also flushes the buffered writer.
+type packetWriter struct {
+	w *bufio.Writer
+}
+
+func (pw *packetWriter) write(data []byte) {
+	// bufio.Writer records the first error; flush reports it.
+	_, _ = fmt.Fprintf(pw.w, "%04x", len(data)+4)
+	_, _ = pw.w.Write(data)
+}
+
+func (pw *packetWriter) flush() error {
+	_, _ = pw.w.WriteString("0000")
+	if err := pw.w.Flush(); err != nil {
+		return jcserr.Wrap(jcserr.InternalIO, -1, "filter protocol: write", err)
+	}
+	return nil
+}
+
+// writeList writes text packets, each ending in a newline, and a flush
+// packet.
+func (pw *packetWriter) writeList(lines ...string) error {
+	for _, line := range lines {
+		pw.write([]byte(line + "\n"))
+	}
+	return pw.flush()
+}
+
+// writeContent writes data in packets of at most maxPacketData bytes and a
+// flush packet.
+func (pw *packetWriter) writeContent(data []byte) error {
+	for len(data) > 0 {
+		n := min(len(data), maxPacketData)
+		pw.write(data[:n])
+		data = data[n:]
+	}
+	return pw.flush()
+}
@@ -0,0 +1,134 @@
+package jcsgit_test
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/lattice-substrate/json-canon/jcs"
+	"github.com/lattice-substrate/json-canon/jcserr"
+	"github.com/lattice-substrate/json-canon/jcsgit"
+)
+
+// pkt encodes text lines as pkt-lines, "" standing for a flush packet.
+func pkt(lines ...string) string {
+	var b strings.Builder
+	for _, line := range lines {
+		if line == "" {
+			b.WriteString("0000")
+			continue
+		}
+		fmt.Fprintf(&b, "%04x%s", len(line)+4, line)
+	}
+	return b.String()
+}
+
+var handshake = pkt("git-filter-client\n", "version=2\n", "", "capability=clean\n", "capability=smudge\n", "")
+
+func clean(t *testing.T, f *jcsgit.Filter, stream string) (string, error) {
+	t.Helper()
+	var out bytes.Buffer
+	err := f.Serve(strings.NewReader(stream), &out)
+	return out.String(), err
+}
+
+func canonicalFilter(reports *[]string) *jcsgit.Filter {
+	return &jcsgit.Filter{
+		Clean: func(_ string, content []byte) ([]byte, error) {
+			return jcs.Canonicalize(content)
+		},
+		Report: func(pathname string, err error, _ []byte) {
+			*reports = append(*reports, pathname+": "+err.Error())
+		},
+	}
+}
+
+// === GIT-FILTER-001: The filter process canonicalizes each file and answers a failure with an error status ===
+
+func TestFilter_GIT_FILTER_001(t *testing.T) {
+	var reports []string
+	f := canonicalFilter(&reports)
+	stream := handshake +
+		pkt("command=clean\n", "pathname=a.json\n", "", `{"b":1,`, `"a":2.0}`, "") +
+		pkt("command=clean\n", "pathname=bad.json\n", "", `{"a":1,"a":2}`, "") +
+		pkt("command=clean\n", "pathname=c.json\n", "", "") +
+		pkt("command=smudge\n", "pathname=d.json\n", "", "[]", "")
+	got, err := clean(t, f, stream)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := pkt("git-filter-server\n", "version=2\n", "", "capability=clean\n", "") +
+		pkt("status=success\n", "", `{"a":2,"b":1}`, "", "") +
+		pkt("status=error\n", "") +
+		pkt("status=error\n", "") +
+		pkt("status=error\n", "")
+	if got != want {
+		t.Fatalf("got  %q\nwant %q", got, want)
+	}
+	if len(reports) != 3 || !strings.HasPrefix(reports[0], "bad.json: jcserr: DUPLICATE_KEY") ||
+		!strings.HasPrefix(reports[1], "c.json: jcserr: INVALID_GRAMMAR") || !strings.HasPrefix(reports[2], "d.json: jcserr: CLI_USAGE") {
+		t.Fatalf("reports: %q", reports)
+	}
+}
+
+func TestFilterSplitsLargeContent(t *testing.T) {
+	var reports []string
+	f := canonicalFilter(&reports)
+	doc := `"` + strings.Repeat("x", 70000) + `"`
+	got, err := clean(t, f, handshake+pkt("command=clean\n", "pathname=big.json\n", "", doc[:60000], doc[60000:], ""))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := pkt("git-filter-server\n", "version=2\n", "", "capability=clean\n", "") +
+		pkt("status=success\n", "", doc[:65516], doc[65516:], "", "")
+	if got != want {
+		t.Fatalf("large content not split into maximal packets (%d bytes)", len(got))
+	}
+}
+
+// === GIT-FILTER-002: Content over the size bound fails with BOUND_EXCEEDED ===
+
+func TestFilter_GIT_FILTER_002(t *testing.T) {
+	var reports []string
+	f := canonicalFilter(&reports)
+	f.MaxContentSize = 8
+	stream := handshake +
+		pkt("command=clean\n", "pathname=big.json\n", "", "[1,2,3,", "4,5]", "") +
+		pkt("command=clean\n", "pathname=small.json\n", "", "[ 1 ]", "")
+	got, err := clean(t, f, stream)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := pkt("git-filter-server\n", "version=2\n", "", "capability=clean\n", "") +
+		pkt("status=error\n", "") +
+		pkt("status=success\n", "", "[1]", "", "")
+	if got != want {
+		t.Fatalf("got  %q\nwant %q", got, want)
+	}
+	if len(reports) != 1 || !strings.HasPrefix(reports[0], "big.json: jcserr: BOUND_EXCEEDED") {
+		t.Fatalf("reports: %q", reports)
+	}
+}
+
+func TestFilterProtocolErrors(t *testing.T) {
+	cases := []string{
+		"",
+		pkt("git-filter-client\n", "version=1\n", ""),
+		pkt("git-filter-client\n", "version=2\n", "", "capability=smudge\n", ""),
+		handshake + "00zz",
+		handshake + "0003",
+		handshake + pkt("command=clean\n"),
+		handshake + pkt("command=clean\n", "pathname=a.json\n", "", "[1]"),
+		handshake + "0010[1]",
+	}
+	for _, stream := range cases {
+		var reports []string
+		_, err := clean(t, canonicalFilter(&reports), stream)
+		var je *jcserr.Error
+		if !errors.As(err, &je) || je.Class != jcserr.CLIUsage {
+			t.Fatalf("stream %q: expected CLI_USAGE, got %v", stream, err)
+		}
+	}
+}
@@ -0,0 +1,95 @@
+package jcsgit
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+
+	"github.com/lattice-substrate/json-canon/jcserr"
+)
+
+// StagedPaths returns the paths of the files added, copied, modified, or
+// renamed in the index of the git repository at dir, relative to the top of
+// its working tree, in git's order. Deleted files are not listed.
+//
+// GIT-STAGED-001: Pre-commit checks read the staged content of the staged
+// files.
+func StagedPaths(ctx context.Context, dir string) ([]string, error) {
+	out, err := runGit(ctx, dir, 0, "diff", "--cached", "--name-only", "-z", "--diff-filter=ACMR")
+	if err != nil {
+		return nil, err
+	}
+	var paths []string
+	for _, p := range strings.Split(string(out), "\x00") {
+		if p != "" {
+			paths = append(paths, p)
+		}
+	}
+	return paths, nil
+}
+
+// ReadStaged returns the content of path, relative to the top of the working
+// tree, in the index of the git repository at dir, which may differ from
+// the file in the working tree. Content over maxSize bytes fails with
+// BOUND_EXCEEDED; a maxSize of zero sets no limit.
+//
+// GIT-STAGED-001: Pre-commit checks read the staged content of the staged
+// files.
+func ReadStaged(ctx context.Context, dir, path string, maxSize int) ([]byte, error) {
+	// ":0:" names stage 0 explicitly, so a path such as "1:x" is not read as
+	// a stage number.
+	return runGit(ctx, dir, maxSize, "cat-file", "blob", ":0:"+path)
+}
+
+// runGit runs git with args in dir and returns its standard output, failing
+// with BOUND_EXCEEDED when limit is positive and the output exceeds it. A
+// missing git executable or a failing git command is a usage error.
+//
+// GIT-EXEC-001: The package runs only the git executable, without a shell.
+func runGit(ctx context.Context, dir string, limit int, args ...string) ([]byte, error) {
+	cmd := exec.CommandContext(ctx, "git", args...)
+	cmd.Dir = dir
+	stdout := &limitedBuffer{limit: limit}
+	var stderr bytes.Buffer
+	cmd.Stdout = stdout
+	cmd.Stderr = &stderr
+	err := cmd.Run()
+	name := "git " + strings.Join(args, " ")
+	var exitErr *exec.ExitError
+	switch {
+	case stdout.exceeded:
+		return nil, jcserr.New(jcserr.BoundExceeded, 0, fmt.Sprintf("input exceeds maximum size %d bytes", limit))
+	case errors.Is(err, exec.ErrNotFound):
+		return nil, jcserr.Wrap(jcserr.CLIUsage, -1, "git executable not found", err)
+	case errors.As(err, &exitErr):
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			err = errors.New(msg)
+		}
+		return nil, jcserr.Wrap(jcserr.CLIUsage, -1, name, err)
+	case err != nil:
+		return nil, jcserr.Wrap(jcserr.InternalIO, -1, name, err)
+	}
+	return stdout.buf.Bytes(), nil
+}
+
+// limitedBuffer collects writes, refusing those past limit when limit is
+// positive. It does not embed bytes.Buffer, whose ReadFrom would bypass the
+// limit.
+type limitedBuffer struct {
+	buf      bytes.Buffer
+	limit    int
+	exceeded bool
+}
+
+var errOutputLimit = errors.New("output limit exceeded")
+
+func (b *limitedBuffer) Write(p []byte) (int, error) {
+	if b.limit > 0 && b.buf.Len()+len(p) > b.limit {
+		b.exceeded = true
+		return 0, errOutputLimit
+	}
+	return b.buf.Write(p) //nolint:wrapcheck // GIT-EXEC-001: bytes.Buffer writes do not fail.
+}
@@ -0,0 +1,95 @@
+package jcsgit_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/lattice-substrate/json-canon/jcserr"
+	"github.com/lattice-substrate/json-canon/jcsgit"
+)
+
+// newRepo initializes a git repository in a temporary directory, isolated
+// from the user's and the system's git configuration.
+func newRepo(t *testing.T) string {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("GIT_CONFIG_NOSYSTEM", "1")
+	dir := t.TempDir()
+	git(t, dir, "init", "-q")
+	return dir
+}
+
+func git(t *testing.T, dir string, args ...string) {
+	t.Helper()
+	cmd := exec.Command("git", append([]string{"-c", "user.name=t", "-c", "user.email=t@example.com"}, args...)...)
+	cmd.Dir = dir
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("git %v: %v\n%s", args, err, out)
+	}
+}
+
+func writeFile(t *testing.T, dir, name, content string) {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// === GIT-STAGED-001: Pre-commit checks read the staged content of the staged files ===
+
+func TestStaged_GIT_STAGED_001(t *testing.T) {
+	dir := newRepo(t)
+	writeFile(t, dir, "gone.json", `1`)
+	writeFile(t, dir, "kept.json", `2`)
+	git(t, dir, "add", ".")
+	git(t, dir, "commit", "-qm", "init")
+
+	writeFile(t, dir, "sub/new.json", `{"b":1,"a":2}`)
+	writeFile(t, dir, "1:odd.json", `[]`)
+	writeFile(t, dir, "kept.json", `3`)
+	git(t, dir, "add", ".")
+	git(t, dir, "rm", "-q", "gone.json")
+	// The working tree differs from the index after staging.
+	writeFile(t, dir, "sub/new.json", `{"a":2,"b":1}`)
+
+	ctx := context.Background()
+	paths, err := jcsgit.StagedPaths(ctx, filepath.Join(dir, "sub"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"1:odd.json", "kept.json", "sub/new.json"}; !reflect.DeepEqual(paths, want) {
+		t.Fatalf("StagedPaths = %q, want %q", paths, want)
+	}
+	for path, want := range map[string]string{"sub/new.json": `{"b":1,"a":2}`, "1:odd.json": `[]`, "kept.json": `3`} {
+		got, err := jcsgit.ReadStaged(ctx, dir, path, 64)
+		if err != nil || string(got) != want {
+			t.Fatalf("ReadStaged(%s) = %q, %v; want %q", path, got, err, want)
+		}
+	}
+	_, err = jcsgit.ReadStaged(ctx, dir, "sub/new.json", 4)
+	requireClass(t, err, jcserr.BoundExceeded)
+	_, err = jcsgit.ReadStaged(ctx, dir, "gone.json", 64)
+	requireClass(t, err, jcserr.CLIUsage)
+	_, err = jcsgit.StagedPaths(ctx, t.TempDir())
+	requireClass(t, err, jcserr.CLIUsage)
+}
+
+func requireClass(t *testing.T, err error, class jcserr.FailureClass) {
+	t.Helper()
+	var je *jcserr.Error
+	if !errors.As(err, &je) || je.Class != class {
+		t.Fatalf("expected %s, got %v", class, err)
+	}
+}
@@ -0,0 +1,32 @@
+package jcsredact_test
+
+import (
+	"bytes"
+	"fmt"
+	"log"
+
+	"github.com/lattice-substrate/json-canon/jcs"
+	"github.com/lattice-substrate/json-canon/jcsredact"
+	"github.com/lattice-substrate/json-canon/jcstoken"
+)
+
+func ExampleRedact() {
+	v, err := jcstoken.Parse([]byte(`{"id":7,"ssn":"123-45-6789"}`))
+	if err != nil {
+		log.Fatal(err)
+	}
+	salt := bytes.Repeat([]byte{0x2a}, jcsredact.MinSaltBytes)
+	res, err := jcsredact.Redact(v, []jcsredact.Target{{Pointer: "/ssn", Salt: salt}})
+	if err != nil {
+		log.Fatal(err)
+	}
+	redacted, err := jcs.Serialize(res.Document)
+	if err != nil {
+		log.Fatal(err)
+	}
+	fmt.Println(string(redacted))
+	fmt.Println(jcsredact.Verify(res.Document, res.Disclosures, res.Digest))
+	// Output:
+	// {"_sd":["7PDxYSllA5k2QT3FOd2o6KmreHd96VoPThI47D2L6tg"],"id":7}
+	// <nil>
+}
@@ -0,0 +1,318 @@
+// Package jcsredact implements salted selective-disclosure redaction over
+// RFC 8785 canonical forms.
+//
+// Redaction replaces selected object members and array elements (addressed by
+// RFC 6901 JSON Pointer) with salted SHA-256 digests in the style of SD-JWT
+// disclosures. Redacted object members are removed and their digests are
+// listed in the reserved "_sd" member of the containing object; redacted array
+// elements are replaced in place by {"...": digest}. Each redaction yields a
+// Disclosure record that is transported separately. A verifier reassembles the
+// document from the redacted form and the disclosures and compares the
+// canonical digest of the result with the digest of the original document.
+//
+// Unlike SD-JWT, digests are computed over the RFC 8785 canonical bytes of the
+// disclosure array rather than over its base64url text, so equal disclosures
+// hash identically regardless of producer formatting. Salts are supplied by the
+// caller; given the same document, pointers, and salts, Redact output is
+// byte-identical across runs.
+package jcsredact
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"sort"
+
+	"github.com/lattice-substrate/json-canon/jcs"
+	"github.com/lattice-substrate/json-canon/jcserr"
+	"github.com/lattice-substrate/json-canon/jcstoken"
+)
+
+const (
+	// SDMember is the reserved object member listing digests of redacted members.
+	SDMember = "_sd"
+	// ElementMember is the sole member name of a redacted array-element placeholder.
+	ElementMember = "..."
+	// MinSaltBytes is the minimum accepted salt length (128 bits).
+	MinSaltBytes = 16
+)
+
+// Target selects one object member or array element to redact.
+type Target struct {
+	// Pointer is an RFC 6901 JSON Pointer into the original document. The
+	// document root cannot be redacted.
+	Pointer string
+	// Salt is caller-supplied entropy bound into the disclosure digest. It
+	// MUST be unique per target and at least MinSaltBytes long; Redact
+	// rejects a salt shared by two targets, since it would link their
+	// disclosures.
+	Salt []byte
+}
+
+// Disclosure is the record that reveals one redacted member or element.
+type Disclosure struct {
+	// Pointer is the location of the redacted value in the original
+	// document. It is informational and not part of the encoded form.
+	Pointer string
+	// Salt is the base64url (unpadded) encoding of the target salt.
+	Salt string
+	// Name is the member name for object-member disclosures.
+	Name string
+	// Element reports whether the disclosure reveals an array element.
+	Element bool
+	// Value is the redacted value.
+	Value jcstoken.Value
+	// Digest is the base64url (unpadded) SHA-256 of the canonical encoding.
+	Digest string
+}
+
+// Result is the output of Redact.
+type Result struct {
+	// Document is the redacted document; the input value is not modified.
+	Document *jcstoken.Value
+	// Disclosures holds one record per target, ordered by Pointer.
+	Disclosures []Disclosure
+	// Digest is the canonical digest of the original document.
+	Digest string
+}
+
+// Digest returns the base64url (unpadded) SHA-256 digest of the RFC 8785
+// canonical form of v.
+//
+// REDACT-DIGEST-001: Digests are computed over canonical bytes only.
+func Digest(v *jcstoken.Value) (string, error) {
+	canonical, err := jcs.Serialize(v)
+	if err != nil {
+		return "", err //nolint:wrapcheck // REDACT-DIGEST-001: preserve jcs failure class unchanged.
+	}
+	return digestBytes(canonical), nil
+}
+
+func digestBytes(b []byte) string {
+	sum := sha256.Sum256(b)
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
+
+// Encode returns the RFC 8785 canonical encoding of the disclosure array:
+// [salt, name, value] for members and [salt, value] for array elements.
+func (d *Disclosure) Encode() ([]byte, error) {
+	out, err := jcs.Serialize(d.array())
+	if err != nil {
+		return nil, err //nolint:wrapcheck // REDACT-DIGEST-001: preserve jcs failure class unchanged.
+	}
+	return out, nil
+}
+
+func (d *Disclosure) array() *jcstoken.Value {
+	elems := []jcstoken.Value{{Kind: jcstoken.KindString, Str: d.Salt}}
+	if !d.Element {
+		elems = append(elems, jcstoken.Value{Kind: jcstoken.KindString, Str: d.Name})
+	}
+	elems = append(elems, d.Value)
+	return &jcstoken.Value{Kind: jcstoken.KindArray, Elems: elems}
+}
+
+// computeDigest returns the digest of the disclosure's canonical encoding.
+// Any Digest already present on d is ignored.
+func (d *Disclosure) computeDigest() (string, error) {
+	enc, err := d.Encode()
+	if err != nil {
+		return "", err
+	}
+	return digestBytes(enc), nil
+}
+
+// DecodeDisclosure parses an encoded disclosure array and recomputes its
+// digest. The returned Disclosure has an empty Pointer.
+func DecodeDisclosure(data []byte) (*Disclosure, error) {
+	v, err := jcstoken.Parse(data)
+	if err != nil {
+		return nil, err //nolint:wrapcheck // REDACT-DIGEST-001: preserve parser failure class unchanged.
+	}
+	if v.Kind != jcstoken.KindArray || len(v.Elems) < 2 || len(v.Elems) > 3 {
+		return nil, jcserr.New(jcserr.InvalidGrammar, -1, "jcsredact: disclosure must be a 2- or 3-element array")
+	}
+	d := &Disclosure{Element: len(v.Elems) == 2}
+	if v.Elems[0].Kind != jcstoken.KindString {
+		return nil, jcserr.New(jcserr.InvalidGrammar, -1, "jcsredact: disclosure salt must be a string")
+	}
+	d.Salt = v.Elems[0].Str
+	if !d.Element {
+		if v.Elems[1].Kind != jcstoken.KindString {
+			return nil, jcserr.New(jcserr.InvalidGrammar, -1, "jcsredact: disclosure name must be a string")
+		}
+		d.Name = v.Elems[1].Str
+	}
+	d.Value = v.Elems[len(v.Elems)-1]
+	if d.Digest, err = d.computeDigest(); err != nil {
+		return nil, err
+	}
+	return d, nil
+}
+
+type plannedTarget struct {
+	ptr  jcstoken.Pointer
+	text string
+	salt []byte
+}
+
+// Redact returns a redacted copy of v together with one Disclosure per target.
+// Targets are applied deepest-first so that a member nested inside another
+// redacted member is itself disclosed separately (recursive disclosure).
+//
+// REDACT-APPLY-001: Redaction output is a pure function of v and targets.
+// REDACT-APPLY-002: Reserved member collisions and invalid targets are rejected.
+func Redact(v *jcstoken.Value, targets []Target) (*Result, error) {
+	if v == nil {
+		return nil, jcserr.New(jcserr.InternalError, -1, "jcsredact: nil value")
+	}
+	plan, err := planTargets(targets)
+	if err != nil {
+		return nil, err
+	}
+	digest, err := Digest(v)
+	if err != nil {
+		return nil, err
+	}
+	doc := v.Clone()
+	disclosures := make([]Disclosure, 0, len(plan))
+	for _, t := range plan {
+		d, err := redactOne(v, doc, t)
+		if err != nil {
+			return nil, err
+		}
+		disclosures = append(disclosures, *d)
+	}
+	sort.Slice(disclosures, func(i, j int) bool {
+		return disclosures[i].Pointer < disclosures[j].Pointer
+	})
+	return &Result{Document: doc, Disclosures: disclosures, Digest: digest}, nil
+}
+
+func planTargets(targets []Target) ([]plannedTarget, error) {
+	plan := make([]plannedTarget, 0, len(targets))
+	seen := make(map[string]struct{}, len(targets))
+	salts := make(map[string]string, len(targets))
+	for _, t := range targets {
+		ptr, err := jcstoken.ParsePointer(t.Pointer)
+		if err != nil {
+			return nil, err //nolint:wrapcheck // REDACT-APPLY-002: preserve pointer failure class unchanged.
+		}
+		if len(ptr) == 0 {
+			return nil, jcserr.New(jcserr.InvalidPointer, -1, "jcsredact: the document root cannot be redacted")
+		}
+		text := ptr.String()
+		if _, dup := seen[text]; dup {
+			return nil, jcserr.New(jcserr.InvalidPointer, -1, fmt.Sprintf("jcsredact: duplicate target %q", text))
+		}
+		seen[text] = struct{}{}
+		if len(t.Salt) < MinSaltBytes {
+			return nil, jcserr.New(jcserr.InvalidSalt, -1,
+				fmt.Sprintf("jcsredact: salt for %q is %d bytes, minimum is %d", text, len(t.Salt), MinSaltBytes))
+		}
+		if other, dup := salts[string(t.Salt)]; dup {
+			return nil, jcserr.New(jcserr.InvalidSalt, -1,
+				fmt.Sprintf("jcsredact: salt for %q is also used for %q", text, other))
+		}
+		salts[string(t.Salt)] = text
+		plan = append(plan, plannedTarget{ptr: ptr, text: text, salt: t.Salt})
+	}
+	sort.SliceStable(plan, func(i, j int) bool {
+		if len(plan[i].ptr) != len(plan[j].ptr) {
+			return len(plan[i].ptr) > len(plan[j].ptr)
+		}
+		return plan[i].text < plan[j].text
+	})
+	return plan, nil
+}
+
+func redactOne(orig, doc *jcstoken.Value, t plannedTarget) (*Disclosure, error) {
+	parentPtr, last := t.ptr[:len(t.ptr)-1], t.ptr[len(t.ptr)-1]
+	origParent, err := parentPtr.Resolve(orig)
+	if err != nil {
+		return nil, err //nolint:wrapcheck // REDACT-APPLY-002: preserve pointer failure class unchanged.
+	}
+	parent, err := parentPtr.Resolve(doc)
+	if err != nil {
+		return nil, err //nolint:wrapcheck // REDACT-APPLY-002: preserve pointer failure class unchanged.
+	}
+	d := &Disclosure{Pointer: t.text, Salt: base64.RawURLEncoding.EncodeToString(t.salt)}
+	switch parent.Kind {
+	case jcstoken.KindObject:
+		if hasMember(origParent, SDMember) {
+			return nil, jcserr.New(jcserr.DuplicateKey, -1,
+				fmt.Sprintf("jcsredact: object at %q already has reserved member %q", parentPtr.String(), SDMember))
+		}
+		return d, redactMember(parent, last, d)
+	case jcstoken.KindArray:
+		return d, redactElement(parent, last, d)
+	default:
+		return nil, jcserr.New(jcserr.InvalidPointer, -1,
+			fmt.Sprintf("jcsredact: target %q does not address an object member or array element", t.text))
+	}
+}
+
+func redactMember(parent *jcstoken.Value, name string, d *Disclosure) error {
+	idx := parent.MemberIndex(name)
+	if idx < 0 {
+		return jcserr.New(jcserr.InvalidPointer, -1, fmt.Sprintf("jcsredact: target %q not found", d.Pointer))
+	}
+	d.Name = name
+	d.Value = parent.Members[idx].Value
+	digest, err := d.computeDigest()
+	if err != nil {
+		return err
+	}
+	d.Digest = digest
+	parent.Members = append(parent.Members[:idx], parent.Members[idx+1:]...)
+	insertSDDigest(parent, digest)
+	return nil
+}
+
+func redactElement(parent *jcstoken.Value, tok string, d *Disclosure) error {
+	idx, perr := jcstoken.ArrayIndex(tok)
+	if perr != nil {
+		return perr
+	}
+	if idx >= len(parent.Elems) {
+		return jcserr.New(jcserr.InvalidPointer, -1, fmt.Sprintf("jcsredact: target %q not found", d.Pointer))
+	}
+	d.Element = true
+	d.Value = parent.Elems[idx]
+	digest, err := d.computeDigest()
+	if err != nil {
+		return err
+	}
+	d.Digest = digest
+	parent.Elems[idx] = placeholder(digest)
+	return nil
+}
+
+func placeholder(digest string) jcstoken.Value {
+	return jcstoken.Value{Kind: jcstoken.KindObject, Members: []jcstoken.Member{{
+		Key:   ElementMember,
+		Value: jcstoken.Value{Kind: jcstoken.KindString, Str: digest},
+	}}}
+}
+
+// insertSDDigest adds digest to the object's "_sd" array, keeping it sorted so
+// that the redacted form is independent of target order.
+func insertSDDigest(obj *jcstoken.Value, digest string) {
+	idx := obj.MemberIndex(SDMember)
+	if idx < 0 {
+		obj.Members = append(obj.Members, jcstoken.Member{
+			Key:   SDMember,
+			Value: jcstoken.Value{Kind: jcstoken.KindArray, Elems: []jcstoken.Value{}},
+		})
+		idx = len(obj.Members) - 1
+	}
+	sd := &obj.Members[idx].Value
+	pos := sort.Search(len(sd.Elems), func(i int) bool { return sd.Elems[i].Str >= digest })
+	sd.Elems = append(sd.Elems, jcstoken.Value{})
+	copy(sd.Elems[pos+1:], sd.Elems[pos:])
+	sd.Elems[pos] = jcstoken.Value{Kind: jcstoken.KindString, Str: digest}
+}
+
+func hasMember(obj *jcstoken.Value, name string) bool {
+	return obj.Kind == jcstoken.KindObject && obj.MemberIndex(name) >= 0
+}
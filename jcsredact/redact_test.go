@@ -0,0 +1,237 @@
+package jcsredact_test
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/lattice-substrate/json-canon/jcs"
+	"github.com/lattice-substrate/json-canon/jcserr"
+	"github.com/lattice-substrate/json-canon/jcsredact"
+	"github.com/lattice-substrate/json-canon/jcstoken"
+)
+
+const sample = `{"name":"Alice","address":{"street":"1 Main St","city":"Springfield"},"emails":["a@example.com","alice@example.org"]}`
+
+func salt(b byte) []byte {
+	return bytes.Repeat([]byte{b}, jcsredact.MinSaltBytes)
+}
+
+func mustParse(t *testing.T, in string) *jcstoken.Value {
+	t.Helper()
+	v, err := jcstoken.Parse([]byte(in))
+	if err != nil {
+		t.Fatalf("parse %q: %v", in, err)
+	}
+	return v
+}
+
+func mustSerialize(t *testing.T, v *jcstoken.Value) string {
+	t.Helper()
+	out, err := jcs.Serialize(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(out)
+}
+
+func assertClass(t *testing.T, err error, want jcserr.FailureClass) {
+	t.Helper()
+	var je *jcserr.Error
+	if !errors.As(err, &je) {
+		t.Fatalf("expected *jcserr.Error, got %T: %v", err, err)
+	}
+	if je.Class != want {
+		t.Fatalf("expected %s, got %s: %v", want, je.Class, err)
+	}
+}
+
+func redactSample(t *testing.T, targets []jcsredact.Target) *jcsredact.Result {
+	t.Helper()
+	res, err := jcsredact.Redact(mustParse(t, sample), targets)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return res
+}
+
+// === REDACT-DIGEST-001: Digests cover canonical bytes only ===
+
+func TestDigest_REDACT_DIGEST_001(t *testing.T) {
+	a, err := jcsredact.Digest(mustParse(t, `{ "b": 1, "a": [true] }`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := jcsredact.Digest(mustParse(t, `{"a":[true],"b":1}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if a != b {
+		t.Fatalf("digest depends on source formatting: %s vs %s", a, b)
+	}
+
+	d := jcsredact.Disclosure{
+		Salt:  "c2FsdA",
+		Name:  "name",
+		Value: jcstoken.Value{Kind: jcstoken.KindString, Str: "Alice"},
+	}
+	enc, err := d.Encode()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(enc) != `["c2FsdA","name","Alice"]` {
+		t.Fatalf("unexpected encoding %s", enc)
+	}
+	decoded, err := jcsredact.DecodeDisclosure([]byte(`[ "c2FsdA", "name", "Alice" ]`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if decoded.Name != "name" || decoded.Element || decoded.Digest == "" {
+		t.Fatalf("unexpected decoded disclosure %+v", decoded)
+	}
+	for _, bad := range []string{`"x"`, `["s"]`, `[1,"v"]`, `["s",2,"v"]`, `["a","b","c","d"]`} {
+		_, err := jcsredact.DecodeDisclosure([]byte(bad))
+		assertClass(t, err, jcserr.InvalidGrammar)
+	}
+}
+
+// === REDACT-APPLY-001: Redaction is deterministic given salts ===
+
+func TestRedact_REDACT_APPLY_001(t *testing.T) {
+	targets := []jcsredact.Target{
+		{Pointer: "/address/street", Salt: salt(1)},
+		{Pointer: "/emails/1", Salt: salt(2)},
+		{Pointer: "/address", Salt: salt(3)},
+	}
+	first := redactSample(t, targets)
+	reversed := []jcsredact.Target{targets[2], targets[1], targets[0]}
+	second := redactSample(t, reversed)
+	if mustSerialize(t, first.Document) != mustSerialize(t, second.Document) {
+		t.Fatal("redacted document depends on target order")
+	}
+	if len(first.Disclosures) != 3 {
+		t.Fatalf("expected 3 disclosures, got %d", len(first.Disclosures))
+	}
+	for i, want := range []string{"/address", "/address/street", "/emails/1"} {
+		if first.Disclosures[i].Pointer != want {
+			t.Fatalf("disclosure %d: got %q want %q", i, first.Disclosures[i].Pointer, want)
+		}
+		if first.Disclosures[i].Digest != second.Disclosures[i].Digest {
+			t.Fatalf("disclosure %d digest not deterministic", i)
+		}
+	}
+
+	doc := first.Document
+	if len(doc.Members) != 3 {
+		t.Fatalf("expected name, emails, _sd; got %s", mustSerialize(t, doc))
+	}
+	emails := doc.Members[1].Value
+	if emails.Elems[1].Kind != jcstoken.KindObject || emails.Elems[1].Members[0].Key != jcsredact.ElementMember {
+		t.Fatalf("array element not replaced by placeholder: %s", mustSerialize(t, &emails))
+	}
+	if first.Disclosures[0].Value.Kind != jcstoken.KindObject {
+		t.Fatal("outer disclosure lost its value")
+	}
+	// The outer disclosure carries the inner member as a digest, not in clear.
+	inner := mustSerialize(t, &first.Disclosures[0].Value)
+	if bytes.Contains([]byte(inner), []byte("Main St")) {
+		t.Fatalf("nested redacted member leaked into outer disclosure: %s", inner)
+	}
+}
+
+func TestRedactDoesNotModifyInput(t *testing.T) {
+	v := mustParse(t, sample)
+	before := mustSerialize(t, v)
+	if _, err := jcsredact.Redact(v, []jcsredact.Target{{Pointer: "/name", Salt: salt(1)}}); err != nil {
+		t.Fatal(err)
+	}
+	if mustSerialize(t, v) != before {
+		t.Fatal("Redact modified its input")
+	}
+}
+
+// === REDACT-APPLY-002: Invalid targets and reserved collisions rejected ===
+
+func TestRedact_REDACT_APPLY_002(t *testing.T) {
+	cases := []struct {
+		name    string
+		doc     string
+		targets []jcsredact.Target
+		class   jcserr.FailureClass
+	}{
+		{"root", sample, []jcsredact.Target{{Pointer: "", Salt: salt(1)}}, jcserr.InvalidPointer},
+		{"missing", sample, []jcsredact.Target{{Pointer: "/nope", Salt: salt(1)}}, jcserr.InvalidPointer},
+		{"bad-syntax", sample, []jcsredact.Target{{Pointer: "name", Salt: salt(1)}}, jcserr.InvalidPointer},
+		{"past-end", sample, []jcsredact.Target{{Pointer: "/emails/-", Salt: salt(1)}}, jcserr.InvalidPointer},
+		{"scalar-parent", sample, []jcsredact.Target{{Pointer: "/name/x", Salt: salt(1)}}, jcserr.InvalidPointer},
+		{"duplicate", sample, []jcsredact.Target{
+			{Pointer: "/name", Salt: salt(1)},
+			{Pointer: "/name", Salt: salt(2)},
+		}, jcserr.InvalidPointer},
+		{"short-salt", sample, []jcsredact.Target{{Pointer: "/name", Salt: []byte("short")}}, jcserr.InvalidSalt},
+		{"shared-salt", sample, []jcsredact.Target{
+			{Pointer: "/name", Salt: salt(1)},
+			{Pointer: "/emails/0", Salt: salt(1)},
+		}, jcserr.InvalidSalt},
+		{"reserved", `{"_sd":[],"a":1}`, []jcsredact.Target{{Pointer: "/a", Salt: salt(1)}}, jcserr.DuplicateKey},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := jcsredact.Redact(mustParse(t, tc.doc), tc.targets)
+			assertClass(t, err, tc.class)
+		})
+	}
+}
+
+// === REDACT-VERIFY-001: Disclosures must be referenced exactly once ===
+
+func TestReassemble_REDACT_VERIFY_001(t *testing.T) {
+	res := redactSample(t, []jcsredact.Target{
+		{Pointer: "/address/street", Salt: salt(1)},
+		{Pointer: "/emails/0", Salt: salt(2)},
+	})
+	full, err := jcsredact.Reassemble(res.Document, res.Disclosures)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := mustSerialize(t, full), mustSerialize(t, mustParse(t, sample)); got != want {
+		t.Fatalf("reassembled mismatch:\n got %s\nwant %s", got, want)
+	}
+
+	partial, err := jcsredact.Reassemble(res.Document, res.Disclosures[:1])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if partial.Members[2].Value.Elems[0].Kind != jcstoken.KindObject {
+		t.Fatalf("undisclosed element not retained: %s", mustSerialize(t, partial))
+	}
+
+	other := redactSample(t, []jcsredact.Target{{Pointer: "/name", Salt: salt(9)}})
+	_, err = jcsredact.Reassemble(res.Document, append(res.Disclosures, other.Disclosures...))
+	assertClass(t, err, jcserr.DigestMismatch)
+
+	dup := []jcsredact.Disclosure{res.Disclosures[0], res.Disclosures[0]}
+	_, err = jcsredact.Reassemble(res.Document, dup)
+	assertClass(t, err, jcserr.DigestMismatch)
+}
+
+// === REDACT-VERIFY-002: Reassembled digest equals original digest ===
+
+func TestVerify_REDACT_VERIFY_002(t *testing.T) {
+	res := redactSample(t, []jcsredact.Target{
+		{Pointer: "/address", Salt: salt(1)},
+		{Pointer: "/address/city", Salt: salt(2)},
+		{Pointer: "/emails/1", Salt: salt(3)},
+	})
+	if err := jcsredact.Verify(res.Document, res.Disclosures, res.Digest); err != nil {
+		t.Fatalf("verify: %v", err)
+	}
+
+	tampered := append([]jcsredact.Disclosure(nil), res.Disclosures...)
+	tampered[2].Value = jcstoken.Value{Kind: jcstoken.KindString, Str: "mallory@example.org"}
+	err := jcsredact.Verify(res.Document, tampered, res.Digest)
+	assertClass(t, err, jcserr.DigestMismatch)
+
+	err = jcsredact.Verify(res.Document, res.Disclosures[:2], res.Digest)
+	assertClass(t, err, jcserr.DigestMismatch)
+}
@@ -0,0 +1,177 @@
+package jcsredact
+
+import (
+	"fmt"
+
+	"github.com/lattice-substrate/json-canon/jcserr"
+	"github.com/lattice-substrate/json-canon/jcstoken"
+)
+
+type disclosureSet struct {
+	byDigest map[string]*Disclosure
+	used     map[string]bool
+	order    []string
+}
+
+func newDisclosureSet(disclosures []Disclosure) (*disclosureSet, error) {
+	set := &disclosureSet{
+		byDigest: make(map[string]*Disclosure, len(disclosures)),
+		used:     make(map[string]bool, len(disclosures)),
+		order:    make([]string, 0, len(disclosures)),
+	}
+	for i := range disclosures {
+		d := &disclosures[i]
+		digest, err := d.computeDigest()
+		if err != nil {
+			return nil, err
+		}
+		if _, dup := set.byDigest[digest]; dup {
+			return nil, jcserr.New(jcserr.DigestMismatch, -1,
+				fmt.Sprintf("jcsredact: duplicate disclosure with digest %s", digest))
+		}
+		set.byDigest[digest] = d
+		set.order = append(set.order, digest)
+	}
+	return set, nil
+}
+
+// take returns the disclosure for digest, marking it used. A disclosure may be
+// consumed at most once and only at a position of the matching kind.
+func (s *disclosureSet) take(digest string, element bool) (*Disclosure, error) {
+	d, ok := s.byDigest[digest]
+	if !ok {
+		return nil, nil
+	}
+	if s.used[digest] {
+		return nil, jcserr.New(jcserr.DigestMismatch, -1,
+			fmt.Sprintf("jcsredact: digest %s is referenced more than once", digest))
+	}
+	if d.Element != element {
+		return nil, jcserr.New(jcserr.DigestMismatch, -1,
+			fmt.Sprintf("jcsredact: disclosure %s used at a position of the wrong kind", digest))
+	}
+	s.used[digest] = true
+	return d, nil
+}
+
+// Reassemble restores every disclosed member and element into a copy of the
+// redacted document. Digests without a matching disclosure stay redacted.
+//
+// REDACT-VERIFY-001: Every supplied disclosure MUST be referenced exactly once
+// by the redacted document; otherwise DIGEST_MISMATCH.
+func Reassemble(redacted *jcstoken.Value, disclosures []Disclosure) (*jcstoken.Value, error) {
+	if redacted == nil {
+		return nil, jcserr.New(jcserr.InternalError, -1, "jcsredact: nil value")
+	}
+	set, err := newDisclosureSet(disclosures)
+	if err != nil {
+		return nil, err
+	}
+	doc := redacted.Clone()
+	if err := set.restore(doc); err != nil {
+		return nil, err
+	}
+	for _, digest := range set.order {
+		if !set.used[digest] {
+			return nil, jcserr.New(jcserr.DigestMismatch, -1,
+				fmt.Sprintf("jcsredact: disclosure %s is not referenced by the document", digest))
+		}
+	}
+	return doc, nil
+}
+
+func (s *disclosureSet) restore(v *jcstoken.Value) error {
+	switch v.Kind {
+	case jcstoken.KindObject:
+		if err := s.restoreMembers(v); err != nil {
+			return err
+		}
+		for i := range v.Members {
+			if err := s.restore(&v.Members[i].Value); err != nil {
+				return err
+			}
+		}
+	case jcstoken.KindArray:
+		for i := range v.Elems {
+			if err := s.restoreElement(&v.Elems[i]); err != nil {
+				return err
+			}
+			if err := s.restore(&v.Elems[i]); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (s *disclosureSet) restoreMembers(obj *jcstoken.Value) error {
+	idx := obj.MemberIndex(SDMember)
+	if idx < 0 {
+		return nil
+	}
+	sd := obj.Members[idx].Value
+	if sd.Kind != jcstoken.KindArray {
+		return jcserr.New(jcserr.InvalidGrammar, -1, "jcsredact: _sd member must be an array of strings")
+	}
+	obj.Members = append(obj.Members[:idx], obj.Members[idx+1:]...)
+	remaining := make([]jcstoken.Value, 0, len(sd.Elems))
+	for _, e := range sd.Elems {
+		if e.Kind != jcstoken.KindString {
+			return jcserr.New(jcserr.InvalidGrammar, -1, "jcsredact: _sd member must be an array of strings")
+		}
+		d, err := s.take(e.Str, false)
+		if err != nil {
+			return err
+		}
+		if d == nil {
+			remaining = append(remaining, e)
+			continue
+		}
+		if obj.MemberIndex(d.Name) >= 0 {
+			return jcserr.New(jcserr.DuplicateKey, -1,
+				fmt.Sprintf("jcsredact: disclosed member %q already present", d.Name))
+		}
+		obj.Members = append(obj.Members, jcstoken.Member{Key: d.Name, Value: *d.Value.Clone()})
+	}
+	if len(remaining) > 0 {
+		obj.Members = append(obj.Members, jcstoken.Member{
+			Key:   SDMember,
+			Value: jcstoken.Value{Kind: jcstoken.KindArray, Elems: remaining},
+		})
+	}
+	return nil
+}
+
+func (s *disclosureSet) restoreElement(elem *jcstoken.Value) error {
+	if elem.Kind != jcstoken.KindObject || len(elem.Members) != 1 ||
+		elem.Members[0].Key != ElementMember || elem.Members[0].Value.Kind != jcstoken.KindString {
+		return nil
+	}
+	d, err := s.take(elem.Members[0].Value.Str, true)
+	if err != nil || d == nil {
+		return err
+	}
+	*elem = *d.Value.Clone()
+	return nil
+}
+
+// Verify reassembles the redacted document with the supplied disclosures and
+// checks that its canonical digest equals want, the digest of the original
+// document as reported by Redact.
+//
+// REDACT-VERIFY-002: Reassembled canonical digest MUST equal the original.
+func Verify(redacted *jcstoken.Value, disclosures []Disclosure, want string) error {
+	doc, err := Reassemble(redacted, disclosures)
+	if err != nil {
+		return err
+	}
+	got, err := Digest(doc)
+	if err != nil {
+		return err
+	}
+	if got != want {
+		return jcserr.New(jcserr.DigestMismatch, -1,
+			fmt.Sprintf("jcsredact: reassembled digest %s does not match expected %s", got, want))
+	}
+	return nil
+}
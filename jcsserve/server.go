@@ -0,0 +1,382 @@
+// Package jcsserve serves RFC 8785 canonicalization, verification, and
+// digests to local clients as an HTTP service on a Unix domain socket or a
+// loopback TCP address.
+//
+// The service only accepts connections; it makes no outbound network calls.
+// Every request is one JSON document in the body of a POST to one of the
+// operation paths. A rejected request is answered with a stable JSON error
+// envelope naming its failure class.
+package jcsserve
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"net/netip"
+	"os"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/lattice-substrate/json-canon/jcs"
+	"github.com/lattice-substrate/json-canon/jcserr"
+	"github.com/lattice-substrate/json-canon/jcstoken"
+)
+
+// Operation paths. Each accepts POST only.
+const (
+	// PathCanonicalize answers with the canonical bytes of the document.
+	PathCanonicalize = "/v1/canonicalize"
+	// PathVerify answers {"canonical":true} when the document is already
+	// canonical and fails with NOT_CANONICAL otherwise.
+	PathVerify = "/v1/verify"
+	// PathDigest answers {"algorithm":"sha-256","digest":hex} for the
+	// SHA-256 digest of the canonical bytes.
+	PathDigest = "/v1/digest"
+)
+
+// ShutdownTimeout bounds how long Serve waits for in-flight requests once
+// its context is canceled.
+const ShutdownTimeout = 30 * time.Second
+
+// readHeaderTimeout bounds how long a client may take to send request
+// headers, so idle clients cannot hold connections open.
+const readHeaderTimeout = 10 * time.Second
+
+// idleTimeout bounds how long a keep-alive connection may wait for its
+// next request.
+const idleTimeout = 60 * time.Second
+
+// DefaultBodyTimeout is the default Config.BodyTimeout.
+const DefaultBodyTimeout = 30 * time.Second
+
+// Config configures a Server.
+type Config struct {
+	// Options bounds every request. MaxInputSize limits the request body,
+	// and the other bounds apply while parsing it. Nil selects the
+	// jcstoken defaults.
+	Options *jcstoken.Options
+	// MaxConcurrent limits the requests processed at once; further
+	// requests wait for a slot. Zero selects runtime.GOMAXPROCS(0).
+	MaxConcurrent int
+	// BodyTimeout bounds how long a request may take to send its body once
+	// it holds a slot, so that slow clients cannot hold slots. Zero selects
+	// DefaultBodyTimeout.
+	BodyTimeout time.Duration
+}
+
+// Server is an http.Handler for the operation paths. Serve also checks the
+// Host header of each request; a caller serving the handler on its own
+// listener is responsible for that check.
+type Server struct {
+	opts        *jcstoken.Options
+	maxInput    int
+	bodyTimeout time.Duration
+	slots       chan struct{}
+}
+
+// operation computes the response body for one request document.
+type operation func(input []byte, opts *jcstoken.Options) ([]byte, error)
+
+// New returns a Server for cfg.
+//
+// SERVE-BOUND-001: Requests are bounded by jcstoken.Options and a
+// concurrency limit.
+func New(cfg Config) *Server {
+	n := cfg.MaxConcurrent
+	if n <= 0 {
+		n = runtime.GOMAXPROCS(0)
+	}
+	maxInput := jcstoken.DefaultMaxInputSize
+	if cfg.Options != nil && cfg.Options.MaxInputSize > 0 {
+		maxInput = cfg.Options.MaxInputSize
+	}
+	bodyTimeout := cfg.BodyTimeout
+	if bodyTimeout <= 0 {
+		bodyTimeout = DefaultBodyTimeout
+	}
+	return &Server{opts: cfg.Options, maxInput: maxInput, bodyTimeout: bodyTimeout, slots: make(chan struct{}, n)}
+}
+
+// ServeHTTP dispatches a request to its operation.
+//
+// SERVE-API-001: canonicalize, verify, and digest follow the CLI semantics.
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	op, status, err := route(r)
+	if err != nil {
+		if status == http.StatusMethodNotAllowed {
+			w.Header().Set("Allow", http.MethodPost)
+		}
+		writeError(w, status, err, nil)
+		return
+	}
+	select {
+	case s.slots <- struct{}{}:
+		defer func() { <-s.slots }()
+	case <-r.Context().Done():
+		return
+	}
+	// Connections that cannot take a deadline, such as test recorders, are
+	// read without one.
+	_ = http.NewResponseController(w).SetReadDeadline(time.Now().Add(s.bodyTimeout))
+	input, err := s.readBody(r)
+	if err != nil {
+		writeError(w, statusOf(err), err, nil)
+		return
+	}
+	body, err := op(input, s.opts)
+	if err != nil {
+		writeError(w, statusOf(err), err, input)
+		return
+	}
+	writeBody(w, http.StatusOK, body)
+}
+
+// route selects the operation for r, or returns the status and error of a
+// request that names none.
+func route(r *http.Request) (operation, int, error) {
+	var op operation
+	switch r.URL.Path {
+	case PathCanonicalize:
+		op = jcs.CanonicalizeWithOptions
+	case PathVerify:
+		op = verify
+	case PathDigest:
+		op = digest
+	default:
+		return nil, http.StatusNotFound, jcserr.New(jcserr.CLIUsage, -1, fmt.Sprintf("unknown operation path %q", r.URL.Path))
+	}
+	if r.Method != http.MethodPost {
+		return nil, http.StatusMethodNotAllowed, jcserr.New(jcserr.CLIUsage, -1, fmt.Sprintf("method %s not allowed; use POST", r.Method))
+	}
+	return op, 0, nil
+}
+
+// readBody reads the request body, rejecting bodies over the input bound
+// before reading past it.
+func (s *Server) readBody(r *http.Request) ([]byte, error) {
+	tooLarge := jcserr.New(jcserr.BoundExceeded, 0, fmt.Sprintf("request body exceeds maximum size %d bytes", s.maxInput))
+	if r.ContentLength > int64(s.maxInput) {
+		return nil, tooLarge
+	}
+	data, err := io.ReadAll(io.LimitReader(r.Body, int64(s.maxInput)+1))
+	if err != nil {
+		return nil, jcserr.Wrap(jcserr.InternalIO, -1, "read request body", err)
+	}
+	if len(data) > s.maxInput {
+		return nil, tooLarge
+	}
+	return data, nil
+}
+
+func verify(input []byte, opts *jcstoken.Options) ([]byte, error) {
+	canonical, err := jcs.CanonicalizeWithOptions(input, opts)
+	if err != nil {
+		return nil, err //nolint:wrapcheck // SERVE-API-001: canonicalization errors are already classified.
+	}
+	if !bytes.Equal(canonical, input) {
+		return nil, jcserr.New(jcserr.NotCanonical, -1, "input is not canonical")
+	}
+	return []byte(`{"canonical":true}`), nil
+}
+
+func digest(input []byte, opts *jcstoken.Options) ([]byte, error) {
+	canonical, err := jcs.CanonicalizeWithOptions(input, opts)
+	if err != nil {
+		return nil, err //nolint:wrapcheck // SERVE-API-001: canonicalization errors are already classified.
+	}
+	sum := sha256.Sum256(canonical)
+	return []byte(`{"algorithm":"sha-256","digest":"` + hex.EncodeToString(sum[:]) + `"}`), nil
+}
+
+// statusOf maps the failure class of err to an HTTP status: 413 for
+// BOUND_EXCEEDED, 500 for internal and unclassified failures, and 422 for
+// every other class, each of which rejects the input.
+func statusOf(err error) int {
+	var je *jcserr.Error
+	if !errors.As(err, &je) {
+		return http.StatusInternalServerError
+	}
+	switch je.Class {
+	case jcserr.BoundExceeded:
+		return http.StatusRequestEntityTooLarge
+	case jcserr.InternalIO, jcserr.InternalError:
+		return http.StatusInternalServerError
+	default:
+		return http.StatusUnprocessableEntity
+	}
+}
+
+// writeError answers with the error envelope of err, locating its offset
+// in input when known.
+//
+// SERVE-ERR-001: Failures are answered with a stable JSON error envelope.
+func writeError(w http.ResponseWriter, status int, err error, input []byte) {
+	body, serErr := jcs.Serialize(Envelope(err, input))
+	if serErr != nil {
+		status = http.StatusInternalServerError
+		body = []byte(`{"error":{"cause":null,"class":"INTERNAL_ERROR","exit_code":10,"message":"error report not serializable","offset":null}}`)
+	}
+	writeBody(w, status, body)
+}
+
+func writeBody(w http.ResponseWriter, status int, body []byte) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Content-Length", strconv.Itoa(len(body)))
+	w.WriteHeader(status)
+	// The client has gone away if the write fails; there is no one to tell.
+	_, _ = w.Write(body)
+}
+
+// Envelope returns the JSON error envelope for err: an object whose "error"
+// member holds the failure class, its CLI exit code, the byte offset or
+// null, the message, and the cause or null, and, when the offset is known,
+// its 1-based line and byte column in input.
+func Envelope(err error, input []byte) *jcstoken.Value {
+	var je *jcserr.Error
+	if !errors.As(err, &je) {
+		je = jcserr.Wrap(jcserr.InternalError, -1, "unclassified error", err)
+	}
+	report := &jcstoken.Value{Kind: jcstoken.KindObject}
+	add := func(key string, v jcstoken.Value) {
+		report.Members = append(report.Members, jcstoken.Member{Key: key, Value: v})
+	}
+	offset, cause := jcstoken.Value{Kind: jcstoken.KindNull}, jcstoken.Value{Kind: jcstoken.KindNull}
+	if je.Offset >= 0 {
+		offset = number(je.Offset)
+	}
+	if je.Cause != nil {
+		cause = text(je.Cause.Error())
+	}
+	add("class", text(string(je.Class)))
+	add("exit_code", number(je.Class.ExitCode()))
+	add("offset", offset)
+	add("message", text(je.Message))
+	add("cause", cause)
+	if input != nil && je.Offset >= 0 && je.Offset <= len(input) {
+		before := input[:je.Offset]
+		add("line", number(bytes.Count(before, []byte{'\n'})+1))
+		add("column", number(je.Offset-bytes.LastIndexByte(before, '\n')))
+	}
+	return &jcstoken.Value{Kind: jcstoken.KindObject, Members: []jcstoken.Member{{Key: "error", Value: *report}}}
+}
+
+func text(s string) jcstoken.Value {
+	return jcstoken.Value{Kind: jcstoken.KindString, Str: strings.ToValidUTF8(s, "\uFFFD")}
+}
+
+func number(n int) jcstoken.Value {
+	return jcstoken.Value{Kind: jcstoken.KindNumber, Num: float64(n)}
+}
+
+// Listen listens on a local address: for network "unix", a new Unix domain
+// socket at path address, accessible to the owner only and removed when the
+// listener is closed; for network "tcp", a literal loopback IP address and
+// port such as "127.0.0.1:8080" or "[::1]:0". Host names are rejected, so
+// no name resolution takes place.
+//
+// SERVE-NET-001: The service listens only on a Unix socket or loopback
+// address.
+func Listen(network, address string) (net.Listener, error) {
+	switch network {
+	case "unix":
+		return listenUnix(address)
+	case "tcp":
+		return listenLoopback(address)
+	default:
+		return nil, jcserr.New(jcserr.CLIUsage, -1, fmt.Sprintf("unsupported network %q", network))
+	}
+}
+
+// listenUnix creates the socket under a umask that leaves it accessible to
+// the owner only, so that it is never open to other users, then sets its
+// mode explicitly for platforms without a umask.
+func listenUnix(path string) (net.Listener, error) {
+	ln, err := listenOwnerOnly(path)
+	if err != nil {
+		return nil, jcserr.Wrap(jcserr.CLIUsage, -1, fmt.Sprintf("listen on socket %q", path), err)
+	}
+	if err := os.Chmod(path, 0o600); err != nil {
+		return nil, jcserr.Wrap(jcserr.InternalIO, -1, fmt.Sprintf("restrict socket %q", path), errors.Join(err, ln.Close()))
+	}
+	return ln, nil
+}
+
+func listenLoopback(address string) (net.Listener, error) {
+	ap, err := netip.ParseAddrPort(address)
+	if err != nil || !ap.Addr().IsLoopback() {
+		return nil, jcserr.New(jcserr.CLIUsage, -1, fmt.Sprintf("listen address %q is not a loopback IP address and port", address))
+	}
+	ln, err := net.Listen("tcp", ap.String())
+	if err != nil {
+		return nil, jcserr.Wrap(jcserr.CLIUsage, -1, fmt.Sprintf("listen on %s", ap), err)
+	}
+	return ln, nil
+}
+
+// checkHost guards next, served on a listener at addr, against DNS
+// rebinding: on a TCP listener a request whose Host header is not
+// 127.0.0.1, [::1], localhost, or the listening address, with the
+// listener's port, is answered 403 with CLI_USAGE. A Unix socket cannot be
+// reached from a web page, so its requests pass unchecked.
+//
+// SERVE-NET-001: A loopback listener answers only requests addressed to it.
+func checkHost(addr net.Addr, next http.Handler) http.Handler {
+	tcp, ok := addr.(*net.TCPAddr)
+	if !ok {
+		return next
+	}
+	port := strconv.Itoa(tcp.Port)
+	allowed := make(map[string]bool)
+	for _, host := range []string{"127.0.0.1", "::1", "localhost", tcp.IP.String()} {
+		allowed[net.JoinHostPort(host, port)] = true
+		if tcp.Port == 80 {
+			allowed[strings.TrimSuffix(net.JoinHostPort(host, port), ":80")] = true
+		}
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !allowed[strings.ToLower(r.Host)] {
+			writeError(w, http.StatusForbidden, jcserr.New(jcserr.CLIUsage, -1, fmt.Sprintf("host %q is not a loopback address of this service", r.Host)), nil)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// Serve answers requests on ln until ctx is canceled, then stops accepting
+// connections and waits up to ShutdownTimeout for in-flight requests to
+// complete. It closes ln.
+//
+// SERVE-SHUTDOWN-001: Shutdown drains in-flight requests.
+func (s *Server) Serve(ctx context.Context, ln net.Listener) error {
+	srv := &http.Server{
+		Handler:           checkHost(ln.Addr(), s),
+		ReadHeaderTimeout: readHeaderTimeout,
+		IdleTimeout:       idleTimeout,
+		ErrorLog:          log.New(io.Discard, "", 0),
+	}
+	done := make(chan error, 1)
+	go func() { done <- srv.Serve(ln) }()
+	select {
+	case err := <-done:
+		return jcserr.Wrap(jcserr.InternalIO, -1, "serve", err)
+	case <-ctx.Done():
+	}
+	shutdownCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), ShutdownTimeout)
+	defer cancel()
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		return jcserr.Wrap(jcserr.InternalIO, -1, "shut down", errors.Join(err, srv.Close()))
+	}
+	if err := <-done; !errors.Is(err, http.ErrServerClosed) {
+		return jcserr.Wrap(jcserr.InternalIO, -1, "serve", err)
+	}
+	return nil
+}
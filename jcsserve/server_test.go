@@ -0,0 +1,358 @@
+package jcsserve_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/lattice-substrate/json-canon/jcserr"
+	"github.com/lattice-substrate/json-canon/jcsserve"
+	"github.com/lattice-substrate/json-canon/jcstoken"
+)
+
+type response struct {
+	status int
+	body   string
+}
+
+func post(t *testing.T, h http.Handler, path, body string) response {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, path, strings.NewReader(body)))
+	if ct := rec.Header().Get("Content-Type"); ct != "application/json" {
+		t.Fatalf("POST %s: Content-Type %q", path, ct)
+	}
+	return response{rec.Code, rec.Body.String()}
+}
+
+// === SERVE-API-001: canonicalize, verify, and digest follow the CLI semantics ===
+
+func TestServer_SERVE_API_001(t *testing.T) {
+	s := jcsserve.New(jcsserve.Config{})
+	cases := []struct {
+		path, body string
+		want       response
+	}{
+		{jcsserve.PathCanonicalize, `{"b":1e2,"a":"é"}`, response{200, `{"a":"é","b":100}`}},
+		{jcsserve.PathVerify, `{"a":"é","b":100}`, response{200, `{"canonical":true}`}},
+		{jcsserve.PathDigest, `{ "a" : 1 }`, response{200, `{"algorithm":"sha-256","digest":"015abd7f5cc57a2dd94b7590f04ad8084273905ee33ec5cebeae62276a97f862"}`}},
+		{jcsserve.PathVerify, `{"b":1,"a":2}`, response{422,
+			`{"error":{"cause":null,"class":"NOT_CANONICAL","exit_code":2,"message":"input is not canonical","offset":null}}`}},
+	}
+	for _, tc := range cases {
+		if got := post(t, s, tc.path, tc.body); got != tc.want {
+			t.Errorf("POST %s %s = %+v, want %+v", tc.path, tc.body, got, tc.want)
+		}
+	}
+	rec := httptest.NewRecorder()
+	s.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, jcsserve.PathDigest, nil))
+	if rec.Code != http.StatusMethodNotAllowed || rec.Header().Get("Allow") != http.MethodPost ||
+		!strings.Contains(rec.Body.String(), `"class":"CLI_USAGE"`) {
+		t.Fatalf("GET: %d %v %s", rec.Code, rec.Header(), rec.Body)
+	}
+	if got := post(t, s, "/v1/format", `{}`); got.status != http.StatusNotFound || !strings.Contains(got.body, `"class":"CLI_USAGE"`) {
+		t.Fatalf("unknown path: %+v", got)
+	}
+}
+
+// === SERVE-ERR-001: Failures are answered with a stable JSON error envelope ===
+
+func TestServer_SERVE_ERR_001(t *testing.T) {
+	s := jcsserve.New(jcsserve.Config{})
+	got := post(t, s, jcsserve.PathCanonicalize, "{\n  \"a\": 1,\n  \"a\": 2\n}")
+	want := `{"error":{"cause":null,"class":"DUPLICATE_KEY","column":3,"exit_code":2,"line":3,"message":"duplicate object key \"a\" (first at byte 4)","offset":14}}`
+	if got.status != http.StatusUnprocessableEntity || got.body != want {
+		t.Fatalf("got %+v, want 422 %s", got, want)
+	}
+	got = post(t, s, jcsserve.PathDigest, "[1,]")
+	if got.status != http.StatusUnprocessableEntity || !strings.HasPrefix(got.body, `{"error":{"cause":null,"class":"INVALID_GRAMMAR","column":4,`) {
+		t.Fatalf("got %+v", got)
+	}
+	// Each failure class maps to its status: 413 for BOUND_EXCEEDED, 422 for
+	// input rejections, and 500 for internal failures.
+	small := jcsserve.New(jcsserve.Config{Options: &jcstoken.Options{MaxInputSize: 2}})
+	if got = post(t, small, jcsserve.PathCanonicalize, "[1]"); got.status != http.StatusRequestEntityTooLarge ||
+		!strings.Contains(got.body, `"class":"BOUND_EXCEEDED"`) {
+		t.Fatalf("bound: %+v", got)
+	}
+	if got = post(t, s, jcsserve.PathVerify, "\"\xff\""); got.status != http.StatusUnprocessableEntity ||
+		!strings.Contains(got.body, `"class":"INVALID_UTF8"`) {
+		t.Fatalf("input rejection: %+v", got)
+	}
+	rec := httptest.NewRecorder()
+	s.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, jcsserve.PathCanonicalize, failingReader{}))
+	if rec.Code != http.StatusInternalServerError || !strings.Contains(rec.Body.String(), `"class":"INTERNAL_IO"`) {
+		t.Fatalf("internal failure: %d %s", rec.Code, rec.Body)
+	}
+
+	env := jcsserve.Envelope(errors.New("boom"), nil)
+	if env.Members[0].Key != "error" || env.Members[0].Value.Members[0].Value.Str != string(jcserr.InternalError) {
+		t.Fatalf("unclassified error envelope: %+v", env)
+	}
+}
+
+type failingReader struct{}
+
+func (failingReader) Read([]byte) (int, error) { return 0, errors.New("connection reset") }
+
+// === SERVE-BOUND-001: Requests are bounded by jcstoken.Options and a concurrency limit ===
+
+func TestServer_SERVE_BOUND_001(t *testing.T) {
+	s := jcsserve.New(jcsserve.Config{Options: &jcstoken.Options{MaxInputSize: 8, MaxDepth: 1}})
+	got := post(t, s, jcsserve.PathCanonicalize, `[1,2,3,4]`)
+	if got.status != http.StatusRequestEntityTooLarge || !strings.Contains(got.body, `"class":"BOUND_EXCEEDED"`) {
+		t.Fatalf("oversized body: %+v", got)
+	}
+	// A body of unknown length is cut off at the bound.
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, jcsserve.PathCanonicalize, strings.NewReader(`[1,2,3,4]`))
+	req.ContentLength = -1
+	s.ServeHTTP(rec, req)
+	if rec.Code != http.StatusRequestEntityTooLarge {
+		t.Fatalf("streamed oversized body: %d %s", rec.Code, rec.Body)
+	}
+	got = post(t, s, jcsserve.PathCanonicalize, `[[1]]`)
+	if got.status != http.StatusRequestEntityTooLarge || !strings.Contains(got.body, `"class":"BOUND_EXCEEDED"`) {
+		t.Fatalf("deep body: %+v", got)
+	}
+	if got = post(t, s, jcsserve.PathCanonicalize, `[1]`); got.status != http.StatusOK {
+		t.Fatalf("within bounds: %+v", got)
+	}
+}
+
+func TestServerConcurrencyLimit(t *testing.T) {
+	s := jcsserve.New(jcsserve.Config{MaxConcurrent: 1})
+	first, release := io.Pipe()
+	done := make(chan response)
+	go func() {
+		rec := httptest.NewRecorder()
+		s.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, jcsserve.PathCanonicalize, first))
+		done <- response{rec.Code, rec.Body.String()}
+	}()
+	// The first request holds the only slot while its body is open.
+	if _, err := release.Write([]byte(`[`)); err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	waiting := make(chan int)
+	go func() {
+		rec := httptest.NewRecorder()
+		s.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, jcsserve.PathCanonicalize, strings.NewReader(`1`)).WithContext(ctx))
+		waiting <- rec.Body.Len()
+	}()
+	cancel()
+	if n := <-waiting; n != 0 {
+		t.Fatalf("request canceled while waiting for a slot was answered (%d bytes)", n)
+	}
+	if _, err := release.Write([]byte(`1]`)); err != nil {
+		t.Fatal(err)
+	}
+	if err := release.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if got := <-done; got != (response{200, `[1]`}) {
+		t.Fatalf("first request: %+v", got)
+	}
+	if got := post(t, s, jcsserve.PathCanonicalize, `2`); got.status != http.StatusOK {
+		t.Fatalf("slot not released: %+v", got)
+	}
+}
+
+func TestServerBodyTimeout(t *testing.T) {
+	ln, err := jcsserve.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	served := make(chan error, 1)
+	s := jcsserve.New(jcsserve.Config{MaxConcurrent: 1, BodyTimeout: 100 * time.Millisecond})
+	go func() { served <- s.Serve(ctx, ln) }()
+	defer func() {
+		cancel()
+		if err := <-served; err != nil {
+			t.Errorf("Serve: %v", err)
+		}
+	}()
+
+	// A client that sends its headers and then stalls holds the only slot
+	// until the body timeout expires.
+	stalled, err := net.Dial("tcp", ln.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer stalled.Close()
+	if _, err := io.WriteString(stalled, "POST "+jcsserve.PathCanonicalize+" HTTP/1.1\r\nHost: "+ln.Addr().String()+"\r\nContent-Length: 10\r\n\r\n["); err != nil {
+		t.Fatal(err)
+	}
+	client := &http.Client{Timeout: 10 * time.Second}
+	resp, err := client.Post("http://"+ln.Addr().String()+jcsserve.PathCanonicalize, "application/json", strings.NewReader(`[1]`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if b, err := io.ReadAll(resp.Body); err != nil || resp.StatusCode != http.StatusOK || string(b) != `[1]` {
+		t.Fatalf("request behind a stalled body: %d %q %v", resp.StatusCode, b, err)
+	}
+}
+
+// === SERVE-NET-001: The service listens only on a Unix socket or loopback address ===
+
+func TestListen_SERVE_NET_001(t *testing.T) {
+	for _, addr := range []string{"0.0.0.0:0", "[::]:0", "192.0.2.1:0", "localhost:0", "127.0.0.1", ""} {
+		if _, err := jcsserve.Listen("tcp", addr); err == nil {
+			t.Fatalf("Listen(tcp, %q) accepted", addr)
+		} else {
+			requireClass(t, err, jcserr.CLIUsage)
+		}
+	}
+	ln, err := jcsserve.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := ln.Close(); err != nil {
+		t.Fatal(err)
+	}
+	_, err = jcsserve.Listen("udp", "127.0.0.1:0")
+	requireClass(t, err, jcserr.CLIUsage)
+	path := filepath.Join(t.TempDir(), "jcs.sock")
+	ln, err = jcsserve.Listen("unix", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	info, err := os.Stat(path)
+	if err != nil || info.Mode().Perm() != 0o600 || info.Mode()&os.ModeSocket == 0 {
+		t.Fatalf("socket %v, %v", info, err)
+	}
+	_, err = jcsserve.Listen("unix", path)
+	requireClass(t, err, jcserr.CLIUsage)
+	if err := ln.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Fatalf("socket not removed: %v", err)
+	}
+}
+
+func TestServeRejectsForeignHost(t *testing.T) {
+	ln, err := jcsserve.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	port := strconv.Itoa(ln.Addr().(*net.TCPAddr).Port)
+	ctx, cancel := context.WithCancel(context.Background())
+	served := make(chan error, 1)
+	go func() { served <- jcsserve.New(jcsserve.Config{}).Serve(ctx, ln) }()
+	defer func() {
+		cancel()
+		if err := <-served; err != nil {
+			t.Errorf("Serve: %v", err)
+		}
+	}()
+
+	cases := []struct {
+		host string
+		want int
+	}{
+		{"127.0.0.1:" + port, http.StatusOK},
+		{"[::1]:" + port, http.StatusOK},
+		{"LocalHost:" + port, http.StatusOK},
+		{"rebind.example:" + port, http.StatusForbidden},
+		{"localhost", http.StatusForbidden},
+		{"127.0.0.1:1", http.StatusForbidden},
+	}
+	for _, tc := range cases {
+		req, err := http.NewRequest(http.MethodPost, "http://"+ln.Addr().String()+jcsserve.PathCanonicalize, strings.NewReader(`[1]`))
+		if err != nil {
+			t.Fatal(err)
+		}
+		req.Host = tc.host
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		b, err := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if err != nil || resp.StatusCode != tc.want {
+			t.Fatalf("Host %q: %d %q %v, want %d", tc.host, resp.StatusCode, b, err, tc.want)
+		}
+		if tc.want == http.StatusForbidden && !strings.Contains(string(b), `"class":"CLI_USAGE"`) {
+			t.Fatalf("Host %q: body %q", tc.host, b)
+		}
+	}
+}
+
+func requireClass(t *testing.T, err error, class jcserr.FailureClass) {
+	t.Helper()
+	var je *jcserr.Error
+	if !errors.As(err, &je) || je.Class != class {
+		t.Fatalf("expected %s, got %v", class, err)
+	}
+}
+
+// === SERVE-SHUTDOWN-001: Shutdown drains in-flight requests ===
+
+func TestServe_SERVE_SHUTDOWN_001(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "jcs.sock")
+	ln, err := jcsserve.Listen("unix", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	served := make(chan error, 1)
+	go func() { served <- jcsserve.New(jcsserve.Config{}).Serve(ctx, ln) }()
+
+	client := &http.Client{Transport: &http.Transport{
+		DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+			return (&net.Dialer{}).DialContext(ctx, "unix", path)
+		},
+	}}
+	body, release := io.Pipe()
+	var wg sync.WaitGroup
+	var got response
+	var reqErr error
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		resp, err := client.Post("http://jcs"+jcsserve.PathCanonicalize, "application/json", body)
+		if err != nil {
+			reqErr = err
+			return
+		}
+		defer resp.Body.Close()
+		b, err := io.ReadAll(resp.Body)
+		got, reqErr = response{resp.StatusCode, string(b)}, err
+	}()
+	// Shut down while the request body is still being sent. The leading
+	// whitespace exceeds the socket buffers, so once it is written the
+	// handler is reading the body.
+	if _, err := release.Write([]byte(strings.Repeat(" ", 4<<20) + `{"b":2,`)); err != nil {
+		t.Fatal(err)
+	}
+	cancel()
+	if _, err := release.Write([]byte(`"a":1}`)); err != nil {
+		t.Fatal(err)
+	}
+	if err := release.Close(); err != nil {
+		t.Fatal(err)
+	}
+	wg.Wait()
+	if reqErr != nil || got != (response{200, `{"a":1,"b":2}`}) {
+		t.Fatalf("in-flight request: %+v, %v", got, reqErr)
+	}
+	if err := <-served; err != nil {
+		t.Fatalf("Serve: %v", err)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Fatalf("socket not removed: %v", err)
+	}
+}
@@ -0,0 +1,11 @@
+//go:build !unix
+
+package jcsserve
+
+import "net"
+
+// listenOwnerOnly listens on a Unix domain socket at path. Platforms without
+// a umask rely on listenUnix setting the mode after creation.
+func listenOwnerOnly(path string) (net.Listener, error) {
+	return net.Listen("unix", path) //nolint:wrapcheck // SERVE-NET-001: listenUnix classifies listen failures.
+}
@@ -0,0 +1,23 @@
+//go:build unix
+
+package jcsserve
+
+import (
+	"net"
+	"sync"
+	"syscall"
+)
+
+// umaskMu serializes the umask changes of concurrent listenOwnerOnly calls.
+var umaskMu sync.Mutex
+
+// listenOwnerOnly listens on a Unix domain socket at path created with mode
+// 0600. The umask is process-wide, so files other goroutines create while
+// the socket is bound are also created without group and other access.
+func listenOwnerOnly(path string) (net.Listener, error) {
+	umaskMu.Lock()
+	defer umaskMu.Unlock()
+	old := syscall.Umask(0o177)
+	defer syscall.Umask(old)
+	return net.Listen("unix", path) //nolint:wrapcheck // SERVE-NET-001: listenUnix classifies listen failures.
+}
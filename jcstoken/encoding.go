@@ -0,0 +1,269 @@
+package jcstoken
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"unicode"
+	"unicode/utf16"
+	"unicode/utf8"
+
+	"github.com/lattice-substrate/json-canon/jcserr"
+)
+
+// Encoding selects the character encoding accepted by ParseEncoded.
+type Encoding int
+
+const (
+	// EncodingUTF8 is strict RFC 8259 UTF-8 without a byte order mark;
+	// ParseEncoded behaves as ParseLenient.
+	EncodingUTF8 Encoding = iota
+	// EncodingAuto detects UTF-8, UTF-16, or UTF-32 from a byte order mark
+	// or, without one, from the pattern of zero bytes in the first four
+	// bytes (RFC 4627 §3), and strips the byte order mark.
+	EncodingAuto
+	// EncodingUTF16LE is little-endian UTF-16; a leading FF FE is stripped.
+	EncodingUTF16LE
+	// EncodingUTF16BE is big-endian UTF-16; a leading FE FF is stripped.
+	EncodingUTF16BE
+	// EncodingUTF32LE is little-endian UTF-32; a leading FF FE 00 00 is stripped.
+	EncodingUTF32LE
+	// EncodingUTF32BE is big-endian UTF-32; a leading 00 00 FE FF is stripped.
+	EncodingUTF32BE
+)
+
+var encodingNames = [...]string{"utf-8", "auto", "utf-16le", "utf-16be", "utf-32le", "utf-32be"}
+
+// String returns the lower-case name of e, as accepted by LookupEncoding.
+func (e Encoding) String() string {
+	if e < 0 || int(e) >= len(encodingNames) {
+		return fmt.Sprintf("Encoding(%d)", int(e))
+	}
+	return encodingNames[e]
+}
+
+// LookupEncoding returns the Encoding named name ("utf-8", "auto",
+// "utf-16le", "utf-16be", "utf-32le", or "utf-32be").
+func LookupEncoding(name string) (Encoding, bool) {
+	for i, n := range encodingNames {
+		if n == name {
+			return Encoding(i), true
+		}
+	}
+	return EncodingUTF8, false
+}
+
+var (
+	bomUTF8    = []byte{0xEF, 0xBB, 0xBF}
+	bomUTF16LE = []byte{0xFF, 0xFE}
+	bomUTF16BE = []byte{0xFE, 0xFF}
+	bomUTF32LE = []byte{0xFF, 0xFE, 0x00, 0x00}
+	bomUTF32BE = []byte{0x00, 0x00, 0xFE, 0xFF}
+)
+
+// DetectEncoding returns the encoding of data and the length of its byte
+// order mark. Without a byte order mark it relies on the first two
+// characters of a JSON text being ASCII (RFC 4627 §3). Input that matches no
+// other pattern is UTF-8.
+//
+// ENC-DETECT-001: Encodings are detected from the BOM or zero-byte pattern.
+func DetectEncoding(data []byte) (Encoding, int) {
+	switch {
+	case bytes.HasPrefix(data, bomUTF8):
+		return EncodingUTF8, len(bomUTF8)
+	case bytes.HasPrefix(data, bomUTF32LE):
+		return EncodingUTF32LE, len(bomUTF32LE)
+	case bytes.HasPrefix(data, bomUTF32BE):
+		return EncodingUTF32BE, len(bomUTF32BE)
+	case bytes.HasPrefix(data, bomUTF16LE):
+		return EncodingUTF16LE, len(bomUTF16LE)
+	case bytes.HasPrefix(data, bomUTF16BE):
+		return EncodingUTF16BE, len(bomUTF16BE)
+	}
+	switch {
+	case len(data) >= 4 && data[0] == 0 && data[1] == 0 && data[2] == 0 && data[3] != 0:
+		return EncodingUTF32BE, 0
+	case len(data) >= 4 && data[0] != 0 && data[1] == 0 && data[2] == 0 && data[3] == 0:
+		return EncodingUTF32LE, 0
+	case len(data) >= 2 && data[0] == 0 && data[1] != 0:
+		return EncodingUTF16BE, 0
+	case len(data) >= 2 && data[0] != 0 && data[1] == 0:
+		return EncodingUTF16LE, 0
+	default:
+		return EncodingUTF8, 0
+	}
+}
+
+// Transcoded is input converted to UTF-8 by Transcode.
+type Transcoded struct {
+	// Data is the UTF-8 text, without a byte order mark.
+	Data []byte
+	// Encoding is the source encoding; never EncodingAuto.
+	Encoding Encoding
+
+	src   []byte
+	start int
+}
+
+// Transcode converts data in encoding enc to UTF-8. Under EncodingUTF8 data
+// is returned unchanged; every other encoding strips its byte order mark.
+// An unpaired UTF-16 surrogate, or a UTF-32 code unit in the surrogate range,
+// fails with LONE_SURROGATE; a truncated code unit or a UTF-32 code unit
+// above U+10FFFF fails with INVALID_UTF8. Error offsets are source offsets.
+// UTF-8 validity of UTF-8 input is left to the parser.
+//
+// ENC-TRANSCODE-001: UTF-16 and UTF-32 input is transcoded to UTF-8.
+// ENC-SURROGATE-001: Unpaired surrogates in the source are LONE_SURROGATE.
+func Transcode(data []byte, enc Encoding) (*Transcoded, error) {
+	t := &Transcoded{Data: data, Encoding: enc, src: data}
+	switch enc {
+	case EncodingUTF8:
+		return t, nil
+	case EncodingAuto:
+		t.Encoding, t.start = DetectEncoding(data)
+	case EncodingUTF16LE, EncodingUTF16BE, EncodingUTF32LE, EncodingUTF32BE:
+		if bom := encodingBOM(enc); bytes.HasPrefix(data, bom) {
+			t.start = len(bom)
+		}
+	default:
+		return nil, jcserr.New(jcserr.InternalError, -1, fmt.Sprintf("unknown input encoding %d", enc))
+	}
+	if t.Encoding == EncodingUTF8 {
+		t.Data = data[t.start:]
+		return t, nil
+	}
+	out := make([]byte, 0, len(data))
+	for i := t.start; i < len(data); {
+		r, size, err := t.decodeRune(i)
+		if err != nil {
+			return nil, err
+		}
+		out = utf8.AppendRune(out, r)
+		i += size
+	}
+	t.Data = out
+	return t, nil
+}
+
+func encodingBOM(enc Encoding) []byte {
+	switch enc {
+	case EncodingUTF16LE:
+		return bomUTF16LE
+	case EncodingUTF16BE:
+		return bomUTF16BE
+	case EncodingUTF32LE:
+		return bomUTF32LE
+	default:
+		return bomUTF32BE
+	}
+}
+
+// decodeRune decodes the code point of the UTF-16 or UTF-32 source at i and
+// returns it with its size in bytes.
+func (t *Transcoded) decodeRune(i int) (rune, int, error) {
+	var order binary.ByteOrder = binary.LittleEndian
+	if t.Encoding == EncodingUTF16BE || t.Encoding == EncodingUTF32BE {
+		order = binary.BigEndian
+	}
+	if t.Encoding == EncodingUTF32LE || t.Encoding == EncodingUTF32BE {
+		return decodeUTF32(t.src, i, order)
+	}
+	return decodeUTF16(t.src, i, order)
+}
+
+func decodeUTF16(src []byte, i int, order binary.ByteOrder) (rune, int, error) {
+	if len(src)-i < 2 {
+		return 0, 0, jcserr.New(jcserr.InvalidUTF8, i, "truncated UTF-16 code unit")
+	}
+	r1 := rune(order.Uint16(src[i:]))
+	switch {
+	case r1 >= 0xDC00 && r1 <= 0xDFFF:
+		return 0, 0, jcserr.New(jcserr.LoneSurrogate, i, fmt.Sprintf("lone low surrogate U+%04X in UTF-16 input", r1))
+	case r1 < 0xD800 || r1 > 0xDBFF:
+		return r1, 2, nil
+	}
+	if len(src)-i >= 4 {
+		if r2 := rune(order.Uint16(src[i+2:])); r2 >= 0xDC00 && r2 <= 0xDFFF {
+			return utf16.DecodeRune(r1, r2), 4, nil
+		}
+	}
+	return 0, 0, jcserr.New(jcserr.LoneSurrogate, i, fmt.Sprintf("lone high surrogate U+%04X in UTF-16 input", r1))
+}
+
+func decodeUTF32(src []byte, i int, order binary.ByteOrder) (rune, int, error) {
+	if len(src)-i < 4 {
+		return 0, 0, jcserr.New(jcserr.InvalidUTF8, i, "truncated UTF-32 code unit")
+	}
+	u := order.Uint32(src[i:])
+	switch {
+	case u >= 0xD800 && u <= 0xDFFF:
+		return 0, 0, jcserr.New(jcserr.LoneSurrogate, i, fmt.Sprintf("surrogate U+%04X in UTF-32 input", u))
+	case u > unicode.MaxRune:
+		return 0, 0, jcserr.New(jcserr.InvalidUTF8, i, fmt.Sprintf("UTF-32 code unit 0x%08X is not a Unicode scalar value", u))
+	}
+	return rune(u), 4, nil
+}
+
+// SourceOffset returns the source byte offset of byte off of t.Data: the
+// start of the source code unit that produced it, or len(source) past the
+// end.
+//
+// ENC-OFFSET-001: Error offsets refer to the original input bytes.
+func (t *Transcoded) SourceOffset(off int) int {
+	if t.Encoding == EncodingUTF8 {
+		return min(off+t.start, len(t.src))
+	}
+	n := 0
+	for i := t.start; i < len(t.src); {
+		r, size, err := t.decodeRune(i)
+		if err != nil {
+			return i
+		}
+		if n += utf8.RuneLen(r); n > off {
+			return i
+		}
+		i += size
+	}
+	return len(t.src)
+}
+
+// remap rewrites the offset of a parser error to the source offset.
+func (t *Transcoded) remap(err error) error {
+	var je *jcserr.Error
+	if errors.As(err, &je) && je.Offset >= 0 {
+		je.Offset = t.SourceOffset(je.Offset)
+	}
+	return err
+}
+
+// ParseEncoded transcodes data from enc to UTF-8 with Transcode and parses
+// it with ParseLenient. It is an explicit opt-in: Parse, ParseWithOptions,
+// and ParseLenient accept only UTF-8 without a byte order mark. MaxInputSize
+// bounds the original input. Error offsets refer to bytes of the original
+// input.
+func ParseEncoded(data []byte, enc Encoding, syntax Syntax, opts *Options) (*Value, error) {
+	if enc == EncodingUTF8 {
+		return ParseLenient(data, syntax, opts)
+	}
+	maxInput := opts.maxInputSize()
+	if len(data) > maxInput {
+		return nil, jcserr.New(jcserr.BoundExceeded, 0,
+			fmt.Sprintf("input size %d exceeds maximum %d", len(data), maxInput))
+	}
+	t, err := Transcode(data, enc)
+	if err != nil {
+		return nil, err
+	}
+	var o Options
+	if opts != nil {
+		o = *opts
+	}
+	// Transcoding may lengthen the text; the size bound was applied above.
+	o.MaxInputSize = max(len(t.Data), maxInput)
+	v, err := ParseLenient(t.Data, syntax, &o)
+	if err != nil {
+		return nil, t.remap(err)
+	}
+	return v, nil
+}
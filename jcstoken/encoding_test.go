@@ -0,0 +1,209 @@
+package jcstoken_test
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"reflect"
+	"testing"
+	"unicode/utf16"
+
+	"github.com/lattice-substrate/json-canon/jcserr"
+	"github.com/lattice-substrate/json-canon/jcstoken"
+)
+
+func encodeUTF16(s string, order binary.AppendByteOrder, bom bool) []byte {
+	var out []byte
+	if bom {
+		out = order.AppendUint16(out, 0xFEFF)
+	}
+	for _, u := range utf16.Encode([]rune(s)) {
+		out = order.AppendUint16(out, u)
+	}
+	return out
+}
+
+func encodeUTF32(s string, order binary.AppendByteOrder, bom bool) []byte {
+	var out []byte
+	if bom {
+		out = order.AppendUint32(out, 0xFEFF)
+	}
+	for _, r := range s {
+		out = order.AppendUint32(out, uint32(r))
+	}
+	return out
+}
+
+func encodedError(t *testing.T, data []byte, enc jcstoken.Encoding) *jcserr.Error {
+	t.Helper()
+	_, err := jcstoken.ParseEncoded(data, enc, jcstoken.SyntaxJSON, nil)
+	var je *jcserr.Error
+	if !errors.As(err, &je) {
+		t.Fatalf("ParseEncoded(% x): expected *jcserr.Error, got %T: %v", data, err, err)
+	}
+	return je
+}
+
+// === ENC-DETECT-001: Encodings are detected from the BOM or zero-byte pattern ===
+
+func TestDetectEncoding_ENC_DETECT_001(t *testing.T) {
+	const doc = `{"a":"é😀"}`
+	cases := []struct {
+		name string
+		data []byte
+		want jcstoken.Encoding
+		bom  int
+	}{
+		{"utf-8", []byte(doc), jcstoken.EncodingUTF8, 0},
+		{"utf-8 bom", append([]byte{0xEF, 0xBB, 0xBF}, doc...), jcstoken.EncodingUTF8, 3},
+		{"utf-16le", encodeUTF16(doc, binary.LittleEndian, false), jcstoken.EncodingUTF16LE, 0},
+		{"utf-16le bom", encodeUTF16(doc, binary.LittleEndian, true), jcstoken.EncodingUTF16LE, 2},
+		{"utf-16be", encodeUTF16(doc, binary.BigEndian, false), jcstoken.EncodingUTF16BE, 0},
+		{"utf-16be bom", encodeUTF16(doc, binary.BigEndian, true), jcstoken.EncodingUTF16BE, 2},
+		{"utf-32le", encodeUTF32(doc, binary.LittleEndian, false), jcstoken.EncodingUTF32LE, 0},
+		{"utf-32le bom", encodeUTF32(doc, binary.LittleEndian, true), jcstoken.EncodingUTF32LE, 4},
+		{"utf-32be", encodeUTF32(doc, binary.BigEndian, false), jcstoken.EncodingUTF32BE, 0},
+		{"utf-32be bom", encodeUTF32(doc, binary.BigEndian, true), jcstoken.EncodingUTF32BE, 4},
+	}
+	want, err := jcstoken.Parse([]byte(doc))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, tc := range cases {
+		enc, bom := jcstoken.DetectEncoding(tc.data)
+		if enc != tc.want || bom != tc.bom {
+			t.Fatalf("%s: DetectEncoding = %s, %d; want %s, %d", tc.name, enc, bom, tc.want, tc.bom)
+		}
+		got, err := jcstoken.ParseEncoded(tc.data, jcstoken.EncodingAuto, jcstoken.SyntaxJSON, nil)
+		if err != nil || !reflect.DeepEqual(got, want) {
+			t.Fatalf("%s: ParseEncoded = %+v, %v", tc.name, got, err)
+		}
+	}
+	// A one-character UTF-16 text is still recognized.
+	if enc, _ := jcstoken.DetectEncoding(encodeUTF16("7", binary.LittleEndian, false)); enc != jcstoken.EncodingUTF16LE {
+		t.Fatalf("single UTF-16LE digit detected as %s", enc)
+	}
+	// The default encoding is strict UTF-8: no BOM and no transcoding.
+	if je := encodedError(t, append([]byte{0xEF, 0xBB, 0xBF}, doc...), jcstoken.EncodingUTF8); je.Class != jcserr.InvalidGrammar {
+		t.Fatalf("expected INVALID_GRAMMAR for BOM under EncodingUTF8, got %s", je.Class)
+	}
+	if je := encodedError(t, encodeUTF16(doc, binary.LittleEndian, true), jcstoken.EncodingUTF8); je.Class != jcserr.InvalidUTF8 {
+		t.Fatalf("expected INVALID_UTF8 for UTF-16 under EncodingUTF8, got %s", je.Class)
+	}
+	for _, name := range []string{"utf-8", "auto", "utf-16le", "utf-16be", "utf-32le", "utf-32be"} {
+		if enc, ok := jcstoken.LookupEncoding(name); !ok || enc.String() != name {
+			t.Fatalf("LookupEncoding(%q) = %s, %v", name, enc, ok)
+		}
+	}
+	if _, ok := jcstoken.LookupEncoding("latin1"); ok {
+		t.Fatal("LookupEncoding accepted latin1")
+	}
+}
+
+// === ENC-TRANSCODE-001: UTF-16 and UTF-32 input is transcoded to UTF-8 ===
+
+func TestTranscode_ENC_TRANSCODE_001(t *testing.T) {
+	const doc = "[\"é中\U0001F600\"]"
+	for _, tc := range []struct {
+		enc  jcstoken.Encoding
+		data []byte
+	}{
+		{jcstoken.EncodingUTF16LE, encodeUTF16(doc, binary.LittleEndian, true)},
+		{jcstoken.EncodingUTF16BE, encodeUTF16(doc, binary.BigEndian, false)},
+		{jcstoken.EncodingUTF32LE, encodeUTF32(doc, binary.LittleEndian, false)},
+		{jcstoken.EncodingUTF32BE, encodeUTF32(doc, binary.BigEndian, true)},
+	} {
+		tr, err := jcstoken.Transcode(tc.data, tc.enc)
+		if err != nil {
+			t.Fatalf("%s: %v", tc.enc, err)
+		}
+		if string(tr.Data) != doc || tr.Encoding != tc.enc {
+			t.Fatalf("%s: Transcode = %q (%s)", tc.enc, tr.Data, tr.Encoding)
+		}
+	}
+	// EncodingUTF8 returns the input unchanged, BOM included.
+	in := []byte("\xEF\xBB\xBF[]")
+	if tr, err := jcstoken.Transcode(in, jcstoken.EncodingUTF8); err != nil || !bytes.Equal(tr.Data, in) {
+		t.Fatalf("EncodingUTF8 changed the input: %q, %v", tr.Data, err)
+	}
+	// Lenient syntax applies to the transcoded text.
+	v, err := jcstoken.ParseEncoded(encodeUTF16("{a: 1, // c\n}", binary.LittleEndian, true), jcstoken.EncodingAuto, jcstoken.SyntaxJSON5, nil)
+	if err != nil || len(v.Members) != 1 || v.Members[0].Key != "a" {
+		t.Fatalf("lenient UTF-16: %+v, %v", v, err)
+	}
+	// MaxInputSize bounds the original input.
+	data := encodeUTF16(`[1,2,3]`, binary.BigEndian, false)
+	if je := func() *jcserr.Error {
+		_, err := jcstoken.ParseEncoded(data, jcstoken.EncodingAuto, jcstoken.SyntaxJSON, &jcstoken.Options{MaxInputSize: len(data) - 1})
+		var je *jcserr.Error
+		errors.As(err, &je)
+		return je
+	}(); je == nil || je.Class != jcserr.BoundExceeded {
+		t.Fatalf("expected BOUND_EXCEEDED, got %v", je)
+	}
+	if _, err := jcstoken.ParseEncoded(data, jcstoken.EncodingAuto, jcstoken.SyntaxJSON, &jcstoken.Options{MaxInputSize: len(data)}); err != nil {
+		t.Fatalf("input at the bound rejected: %v", err)
+	}
+}
+
+// === ENC-SURROGATE-001: Unpaired surrogates in the source are LONE_SURROGATE ===
+
+func TestTranscode_ENC_SURROGATE_001(t *testing.T) {
+	le := binary.LittleEndian
+	prefix := encodeUTF16(`["`, le, false)
+	cases := []struct {
+		name  string
+		data  []byte
+		enc   jcstoken.Encoding
+		class jcserr.FailureClass
+		off   int
+	}{
+		{"lone high", append(le.AppendUint16(append([]byte{}, prefix...), 0xD83D), encodeUTF16(`"]`, le, false)...), jcstoken.EncodingUTF16LE, jcserr.LoneSurrogate, 4},
+		{"lone low", append(le.AppendUint16(append([]byte{}, prefix...), 0xDE00), encodeUTF16(`"]`, le, false)...), jcstoken.EncodingUTF16LE, jcserr.LoneSurrogate, 4},
+		{"high at end", le.AppendUint16(append([]byte{}, prefix...), 0xD83D), jcstoken.EncodingUTF16LE, jcserr.LoneSurrogate, 4},
+		{"utf-32 surrogate", binary.BigEndian.AppendUint32(encodeUTF32(`["`, binary.BigEndian, false), 0xD800), jcstoken.EncodingUTF32BE, jcserr.LoneSurrogate, 8},
+		{"utf-32 above max", binary.BigEndian.AppendUint32(encodeUTF32(`["`, binary.BigEndian, false), 0x110000), jcstoken.EncodingUTF32BE, jcserr.InvalidUTF8, 8},
+		{"truncated utf-16", append(encodeUTF16(`[1]`, le, true), ' '), jcstoken.EncodingAuto, jcserr.InvalidUTF8, 8},
+		{"truncated utf-32", append(encodeUTF32(`[1]`, le, false), 0, 0), jcstoken.EncodingUTF32LE, jcserr.InvalidUTF8, 12},
+	}
+	for _, tc := range cases {
+		je := encodedError(t, tc.data, tc.enc)
+		if je.Class != tc.class || je.Offset != tc.off {
+			t.Fatalf("%s: got %s at %d, want %s at %d", tc.name, je.Class, je.Offset, tc.class, tc.off)
+		}
+	}
+}
+
+// === ENC-OFFSET-001: Error offsets refer to the original input bytes ===
+
+func TestParseEncoded_ENC_OFFSET_001(t *testing.T) {
+	cases := []struct {
+		name string
+		data []byte
+		enc  jcstoken.Encoding
+		off  int
+	}{
+		// "é" is 2 UTF-8 bytes but 2 UTF-16 and 4 UTF-32 bytes; the bad token
+		// "01" starts at character 7.
+		{"utf-16le bom", encodeUTF16(`["é",01]`, binary.LittleEndian, true), jcstoken.EncodingAuto, 2 + 6*2},
+		{"utf-16be", encodeUTF16(`["é",01]`, binary.BigEndian, false), jcstoken.EncodingUTF16BE, 6 * 2},
+		{"utf-32le bom", encodeUTF32(`["é",01]`, binary.LittleEndian, true), jcstoken.EncodingAuto, 4 + 6*4},
+		{"utf-8 bom", []byte("\xEF\xBB\xBF[\"é\",01]"), jcstoken.EncodingAuto, 3 + 7},
+		{"astral", encodeUTF16("[\"\U0001F600\",01]", binary.LittleEndian, false), jcstoken.EncodingUTF16LE, 7 * 2},
+	}
+	for _, tc := range cases {
+		je := encodedError(t, tc.data, tc.enc)
+		if je.Class != jcserr.InvalidGrammar || je.Offset != tc.off {
+			t.Fatalf("%s: got %s at %d, want INVALID_GRAMMAR at %d", tc.name, je.Class, je.Offset, tc.off)
+		}
+	}
+	tr, err := jcstoken.Transcode(encodeUTF16("aé", binary.BigEndian, true), jcstoken.EncodingAuto)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for off, want := range []int{2, 4, 4, 6} {
+		if got := tr.SourceOffset(off); got != want {
+			t.Fatalf("SourceOffset(%d) = %d, want %d", off, got, want)
+		}
+	}
+}
@@ -33,3 +33,17 @@ func ExampleIsNoncharacter() {
 	// true
 	// false
 }
+
+func ExampleParseLenient() {
+	in := []byte("{\n  // retries before giving up\n  \"retries\": 3,\n  \"hosts\": [\"a\", \"b\",],\n}\n")
+	v, err := jcstoken.ParseLenient(in, jcstoken.SyntaxJSONC, nil)
+	if err != nil {
+		log.Fatal(err)
+	}
+	fmt.Println("Members:", len(v.Members))
+	_, err = jcstoken.Parse(in)
+	fmt.Println("Strict error:", err != nil)
+	// Output:
+	// Members: 2
+	// Strict error: true
+}
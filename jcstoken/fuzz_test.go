@@ -2,6 +2,7 @@ package jcstoken_test
 
 import (
 	"bytes"
+	"reflect"
 	"testing"
 
 	"github.com/lattice-substrate/json-canon/jcs"
@@ -50,3 +51,36 @@ func FuzzParseCanonicalRoundTrip(f *testing.F) {
 		}
 	})
 }
+
+// FuzzParseLenientStrictSubset: strict JSON parses identically under every
+// lenient syntax, and lenient parsing never panics.
+func FuzzParseLenientStrictSubset(f *testing.F) {
+	seeds := [][]byte{
+		[]byte(`{"a":"// not a comment","b":[1,2.5e3,-0.5]}`),
+		[]byte(`["\u0027",'\'',"/*"]`),
+		[]byte(`{a:0x10,b:.5,c:+1,}`),
+		[]byte("// c\n[1,/* c */]"),
+	}
+	for _, seed := range seeds {
+		f.Add(seed)
+	}
+
+	f.Fuzz(func(t *testing.T, in []byte) {
+		if len(in) > 1<<20 {
+			return
+		}
+		strict, strictErr := jcstoken.Parse(in)
+		for _, syntax := range []jcstoken.Syntax{jcstoken.SyntaxJSONC, jcstoken.SyntaxJSON5} {
+			v, err := jcstoken.ParseLenient(in, syntax, nil)
+			if strictErr != nil {
+				continue
+			}
+			if err != nil {
+				t.Fatalf("syntax %d rejected strict JSON %q: %v", syntax, in, err)
+			}
+			if !reflect.DeepEqual(v, strict) {
+				t.Fatalf("syntax %d changed strict JSON %q", syntax, in)
+			}
+		}
+	})
+}
@@ -0,0 +1,438 @@
+package jcstoken
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf16"
+	"unicode/utf8"
+
+	"github.com/lattice-substrate/json-canon/jcserr"
+)
+
+// Path is a parsed RFC 9535 JSONPath query restricted to selectors that
+// address locations without evaluating expressions: the root identifier,
+// name, index, and wildcard selectors, and child and descendant segments.
+// Array slices, filter expressions, and function extensions are rejected.
+type Path struct {
+	src  string
+	segs []pathSegment
+}
+
+type pathSegment struct {
+	descendant bool
+	selectors  []pathSelector
+}
+
+type selectorKind int
+
+const (
+	selectName selectorKind = iota
+	selectIndex
+	selectWildcard
+)
+
+type pathSelector struct {
+	kind  selectorKind
+	name  string
+	index int64
+}
+
+// ParsePath parses the string form of a JSONPath query.
+//
+// JPATH-SYNTAX-001: Queries start with '$' and use the RFC 9535 segment and
+// selector grammar; unsupported selectors are INVALID_POINTER.
+func ParsePath(s string) (Path, error) {
+	p := pathParser{src: s}
+	segs, err := p.parse()
+	if err != nil {
+		return Path{}, jcserr.New(jcserr.InvalidPointer, -1, fmt.Sprintf("jsonpath %q: %s", s, err))
+	}
+	return Path{src: s, segs: segs}, nil
+}
+
+// String returns the query text p was parsed from.
+func (p Path) String() string {
+	return p.src
+}
+
+type pathNode struct {
+	v   *Value
+	ptr Pointer
+}
+
+// Select returns the locations within v that p selects, as JSON Pointers in
+// the order RFC 9535 produces them. A location selected more than once is
+// returned once, at its first position.
+//
+// JPATH-EVAL-001: Name, index, wildcard, and descendant selection follow
+// RFC 9535 §2.3 and §2.5.
+func (p Path) Select(v *Value) []Pointer {
+	nodes := []pathNode{{v: v, ptr: Pointer{}}}
+	for _, seg := range p.segs {
+		var next []pathNode
+		for _, n := range nodes {
+			if seg.descendant {
+				next = appendDescendants(next, n, seg.selectors)
+			} else {
+				next = appendSelected(next, n, seg.selectors)
+			}
+		}
+		nodes = distinctNodes(next)
+	}
+	out := make([]Pointer, len(nodes))
+	for i, n := range nodes {
+		out[i] = n.ptr
+	}
+	return out
+}
+
+// appendDescendants applies selectors to n and to every descendant of n,
+// visiting each node before its children and children in document order.
+func appendDescendants(out []pathNode, n pathNode, selectors []pathSelector) []pathNode {
+	out = appendSelected(out, n, selectors)
+	switch n.v.Kind {
+	case KindObject:
+		for i := range n.v.Members {
+			m := &n.v.Members[i]
+			out = appendDescendants(out, pathNode{v: &m.Value, ptr: n.ptr.Append(m.Key)}, selectors)
+		}
+	case KindArray:
+		for i := range n.v.Elems {
+			out = appendDescendants(out, pathNode{v: &n.v.Elems[i], ptr: n.ptr.Append(strconv.Itoa(i))}, selectors)
+		}
+	}
+	return out
+}
+
+func appendSelected(out []pathNode, n pathNode, selectors []pathSelector) []pathNode {
+	for _, sel := range selectors {
+		switch sel.kind {
+		case selectName:
+			out = appendMember(out, n, sel.name)
+		case selectIndex:
+			out = appendElement(out, n, sel.index)
+		default:
+			out = appendChildren(out, n)
+		}
+	}
+	return out
+}
+
+func appendMember(out []pathNode, n pathNode, name string) []pathNode {
+	if n.v.Kind != KindObject {
+		return out
+	}
+	for i := range n.v.Members {
+		if n.v.Members[i].Key == name {
+			return append(out, pathNode{v: &n.v.Members[i].Value, ptr: n.ptr.Append(name)})
+		}
+	}
+	return out
+}
+
+// appendElement selects the element at index, counting from the end when
+// index is negative.
+func appendElement(out []pathNode, n pathNode, index int64) []pathNode {
+	if n.v.Kind != KindArray {
+		return out
+	}
+	if index < 0 {
+		index += int64(len(n.v.Elems))
+	}
+	if index < 0 || index >= int64(len(n.v.Elems)) {
+		return out
+	}
+	i := int(index)
+	return append(out, pathNode{v: &n.v.Elems[i], ptr: n.ptr.Append(strconv.Itoa(i))})
+}
+
+func appendChildren(out []pathNode, n pathNode) []pathNode {
+	switch n.v.Kind {
+	case KindObject:
+		for i := range n.v.Members {
+			m := &n.v.Members[i]
+			out = append(out, pathNode{v: &m.Value, ptr: n.ptr.Append(m.Key)})
+		}
+	case KindArray:
+		for i := range n.v.Elems {
+			out = append(out, pathNode{v: &n.v.Elems[i], ptr: n.ptr.Append(strconv.Itoa(i))})
+		}
+	}
+	return out
+}
+
+// distinctNodes drops repeated locations so that chained descendant segments
+// stay linear in the number of distinct nodes.
+func distinctNodes(nodes []pathNode) []pathNode {
+	seen := make(map[*Value]struct{}, len(nodes))
+	out := nodes[:0]
+	for _, n := range nodes {
+		if _, dup := seen[n.v]; dup {
+			continue
+		}
+		seen[n.v] = struct{}{}
+		out = append(out, n)
+	}
+	return out
+}
+
+type pathParser struct {
+	src string
+	pos int
+}
+
+func (p *pathParser) parse() ([]pathSegment, error) {
+	if !strings.HasPrefix(p.src, "$") {
+		return nil, fmt.Errorf("query must start with '$'")
+	}
+	p.pos = 1
+	var segs []pathSegment
+	for p.pos < len(p.src) {
+		p.skipBlank()
+		if p.pos >= len(p.src) {
+			return nil, fmt.Errorf("trailing blank space")
+		}
+		seg, err := p.segment()
+		if err != nil {
+			return nil, err
+		}
+		segs = append(segs, seg)
+	}
+	return segs, nil
+}
+
+func (p *pathParser) segment() (pathSegment, error) {
+	switch {
+	case strings.HasPrefix(p.src[p.pos:], ".."):
+		p.pos += 2
+		if p.pos < len(p.src) && p.src[p.pos] == '[' {
+			sels, err := p.bracketed()
+			return pathSegment{descendant: true, selectors: sels}, err
+		}
+		sel, err := p.shorthand()
+		return pathSegment{descendant: true, selectors: []pathSelector{sel}}, err
+	case p.src[p.pos] == '.':
+		p.pos++
+		sel, err := p.shorthand()
+		return pathSegment{selectors: []pathSelector{sel}}, err
+	case p.src[p.pos] == '[':
+		sels, err := p.bracketed()
+		return pathSegment{selectors: sels}, err
+	default:
+		return pathSegment{}, fmt.Errorf("unexpected character %q at offset %d", p.src[p.pos], p.pos)
+	}
+}
+
+// shorthand parses the wildcard or member-name-shorthand after '.' or '..'.
+func (p *pathParser) shorthand() (pathSelector, error) {
+	if p.pos < len(p.src) && p.src[p.pos] == '*' {
+		p.pos++
+		return pathSelector{kind: selectWildcard}, nil
+	}
+	start := p.pos
+	for p.pos < len(p.src) {
+		size := nameCharLen(p.src[p.pos:], p.pos > start)
+		if size == 0 {
+			break
+		}
+		p.pos += size
+	}
+	if p.pos == start {
+		return pathSelector{}, fmt.Errorf("expected member name at offset %d", start)
+	}
+	return pathSelector{kind: selectName, name: p.src[start:p.pos]}, nil
+}
+
+// nameCharLen returns the byte length of the name-first (or, when digitOK,
+// name-char) character at the start of s, or 0 if there is none.
+func nameCharLen(s string, digitOK bool) int {
+	r, size := utf8.DecodeRuneInString(s)
+	if r == utf8.RuneError && size == 1 {
+		return 0
+	}
+	if r == '_' || r >= 0x80 || (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (digitOK && isDigit(byte(r))) {
+		return size
+	}
+	return 0
+}
+
+func (p *pathParser) bracketed() ([]pathSelector, error) {
+	p.pos++ // '['
+	var sels []pathSelector
+	for {
+		p.skipBlank()
+		sel, err := p.selector()
+		if err != nil {
+			return nil, err
+		}
+		sels = append(sels, sel)
+		p.skipBlank()
+		if p.pos >= len(p.src) {
+			return nil, fmt.Errorf("unterminated bracketed selection")
+		}
+		switch p.src[p.pos] {
+		case ',':
+			p.pos++
+		case ']':
+			p.pos++
+			return sels, nil
+		default:
+			return nil, fmt.Errorf("unexpected character %q at offset %d", p.src[p.pos], p.pos)
+		}
+	}
+}
+
+func (p *pathParser) selector() (pathSelector, error) {
+	if p.pos >= len(p.src) {
+		return pathSelector{}, fmt.Errorf("unterminated bracketed selection")
+	}
+	switch c := p.src[p.pos]; {
+	case c == '*':
+		p.pos++
+		return pathSelector{kind: selectWildcard}, nil
+	case c == '\'' || c == '"':
+		name, err := p.quoted(c)
+		return pathSelector{kind: selectName, name: name}, err
+	case c == '-' || isDigit(c):
+		return p.index()
+	case c == '?':
+		return pathSelector{}, fmt.Errorf("filter selectors are not supported")
+	case c == ':':
+		return pathSelector{}, fmt.Errorf("array slice selectors are not supported")
+	default:
+		return pathSelector{}, fmt.Errorf("unexpected character %q at offset %d", c, p.pos)
+	}
+}
+
+// maxPathIndex is the largest index magnitude RFC 9535 §2.1 permits (I-JSON
+// exact integers).
+const maxPathIndex = 1<<53 - 1
+
+func (p *pathParser) index() (pathSelector, error) {
+	start := p.pos
+	if p.src[p.pos] == '-' {
+		p.pos++
+	}
+	for p.pos < len(p.src) && isDigit(p.src[p.pos]) {
+		p.pos++
+	}
+	tok := p.src[start:p.pos]
+	if digits := strings.TrimPrefix(tok, "-"); digits == "" || (digits[0] == '0' && tok != "0") {
+		return pathSelector{}, fmt.Errorf("invalid index %q", tok)
+	}
+	if strings.HasPrefix(p.src[p.pos:], ":") {
+		return pathSelector{}, fmt.Errorf("array slice selectors are not supported")
+	}
+	n, err := strconv.ParseInt(tok, 10, 64)
+	if err != nil || n > maxPathIndex || n < -maxPathIndex {
+		return pathSelector{}, fmt.Errorf("index %q out of range", tok)
+	}
+	return pathSelector{kind: selectIndex, index: n}, nil
+}
+
+// quoted parses a single- or double-quoted name selector with the RFC 9535
+// §2.3.1.1 escapes.
+func (p *pathParser) quoted(q byte) (string, error) {
+	p.pos++
+	var b strings.Builder
+	for p.pos < len(p.src) {
+		c := p.src[p.pos]
+		switch {
+		case c == q:
+			p.pos++
+			return b.String(), nil
+		case c == '\\':
+			if err := p.quotedEscape(&b, q); err != nil {
+				return "", err
+			}
+		case c < 0x20:
+			return "", fmt.Errorf("control character in name selector at offset %d", p.pos)
+		default:
+			b.WriteByte(c)
+			p.pos++
+		}
+	}
+	return "", fmt.Errorf("unterminated name selector")
+}
+
+func (p *pathParser) quotedEscape(b *strings.Builder, q byte) error {
+	if p.pos+1 >= len(p.src) {
+		return fmt.Errorf("unterminated escape at offset %d", p.pos)
+	}
+	c := p.src[p.pos+1]
+	p.pos += 2
+	if i := strings.IndexByte(pathEscapes, c); i >= 0 {
+		b.WriteByte(pathEscapeValues[i])
+		return nil
+	}
+	if c == q {
+		b.WriteByte(c)
+		return nil
+	}
+	if c != 'u' {
+		return fmt.Errorf("invalid escape %q at offset %d", "\\"+string(c), p.pos-2)
+	}
+	r, err := p.unicodeEscape()
+	if err != nil {
+		return err
+	}
+	b.WriteRune(r)
+	return nil
+}
+
+// pathEscapes and pathEscapeValues pair the single-character escapes of
+// RFC 9535 §2.3.1.1 with the characters they denote.
+const (
+	pathEscapes      = "bfnrt/\\"
+	pathEscapeValues = "\b\f\n\r\t/\\"
+)
+
+// unicodeEscape decodes the hex digits after "\u", combining a surrogate
+// pair when a high surrogate is followed by "\u" and a low surrogate.
+func (p *pathParser) unicodeEscape() (rune, error) {
+	hi, err := p.hex4()
+	if err != nil {
+		return 0, err
+	}
+	if !utf16.IsSurrogate(hi) {
+		return hi, nil
+	}
+	if hi >= 0xDC00 || !strings.HasPrefix(p.src[p.pos:], "\\u") {
+		return 0, fmt.Errorf("lone surrogate in name selector at offset %d", p.pos)
+	}
+	p.pos += 2
+	lo, err := p.hex4()
+	if err != nil {
+		return 0, err
+	}
+	r := utf16.DecodeRune(hi, lo)
+	if r == utf8.RuneError {
+		return 0, fmt.Errorf("lone surrogate in name selector at offset %d", p.pos)
+	}
+	return r, nil
+}
+
+func (p *pathParser) hex4() (rune, error) {
+	if p.pos+4 > len(p.src) {
+		return 0, fmt.Errorf("truncated unicode escape at offset %d", p.pos)
+	}
+	n, err := strconv.ParseUint(p.src[p.pos:p.pos+4], 16, 32)
+	if err != nil || strings.ContainsAny(p.src[p.pos:p.pos+4], "+-") {
+		return 0, fmt.Errorf("invalid unicode escape at offset %d", p.pos)
+	}
+	p.pos += 4
+	return rune(n), nil
+}
+
+// skipBlank skips RFC 9535 blank space (space, tab, LF, CR).
+func (p *pathParser) skipBlank() {
+	for p.pos < len(p.src) {
+		switch p.src[p.pos] {
+		case ' ', '\t', '\n', '\r':
+			p.pos++
+		default:
+			return
+		}
+	}
+}
@@ -0,0 +1,104 @@
+package jcstoken_test
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/lattice-substrate/json-canon/jcserr"
+	"github.com/lattice-substrate/json-canon/jcstoken"
+)
+
+// storeDoc is the example document of RFC 9535 §1.5.
+const storeDoc = `{"store":{"book":[
+{"category":"reference","author":"Nigel Rees","title":"Sayings of the Century","price":8.95},
+{"category":"fiction","author":"Evelyn Waugh","title":"Sword of Honour","price":12.99},
+{"category":"fiction","author":"Herman Melville","title":"Moby Dick","isbn":"0-553-21311-3","price":8.99},
+{"category":"fiction","author":"J. R. R. Tolkien","title":"The Lord of the Rings","isbn":"0-395-19395-8","price":22.99}],
+"bicycle":{"color":"red","price":399}}}`
+
+func selectPointers(t *testing.T, doc, query string) []string {
+	t.Helper()
+	v, err := jcstoken.Parse([]byte(doc))
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	p, err := jcstoken.ParsePath(query)
+	if err != nil {
+		t.Fatalf("ParsePath(%q): %v", query, err)
+	}
+	out := []string{}
+	for _, ptr := range p.Select(v) {
+		out = append(out, ptr.String())
+	}
+	return out
+}
+
+// === JPATH-SYNTAX-001: RFC 9535 query syntax subset ===
+
+func TestParsePath_JPATH_SYNTAX_001(t *testing.T) {
+	for _, q := range []string{
+		`$`, `$.a`, `$.a.b_1`, `$.*`, `$..a`, `$..*`, `$..[0]`, `$[0]`, `$[-1]`,
+		`$['a']`, `$["a"]`, `$[ 'a' , 1, * ]`, `$ .a [0]`, `$.é`, `$['\'"\b\f\n\r\t\/\\']`,
+		`$['é😀']`, `$["\"'"]`, `$['\uD83D\uDE00']`,
+	} {
+		p, err := jcstoken.ParsePath(q)
+		if err != nil {
+			t.Fatalf("ParsePath(%q): %v", q, err)
+		}
+		if p.String() != q {
+			t.Fatalf("String() = %q, want %q", p.String(), q)
+		}
+	}
+	for _, q := range []string{
+		``, `a`, `$.`, `$..`, `$.1a`, `$[`, `$[]`, `$[01]`, `$[-0]`, `$[1:2]`, `$[:]`, `$[?@.a]`,
+		`$['a`, `$['\q']`, `$['\"']`, `$["\'"]`, `$['\uD800']`, `$['\uZZZZ']`, `$[9007199254740992]`, `$ `, `$.a.`,
+		`$a`, `$[1 2]`, "$['\x01']", `$.length()`,
+	} {
+		_, err := jcstoken.ParsePath(q)
+		var je *jcserr.Error
+		if !errors.As(err, &je) || je.Class != jcserr.InvalidPointer {
+			t.Fatalf("ParsePath(%q): expected INVALID_POINTER, got %v", q, err)
+		}
+	}
+}
+
+// === JPATH-EVAL-001: Selection follows RFC 9535 ===
+
+func TestPathSelect_JPATH_EVAL_001(t *testing.T) {
+	cases := []struct {
+		query string
+		want  []string
+	}{
+		{`$.store.book[*].author`, []string{"/store/book/0/author", "/store/book/1/author", "/store/book/2/author", "/store/book/3/author"}},
+		{`$..author`, []string{"/store/book/0/author", "/store/book/1/author", "/store/book/2/author", "/store/book/3/author"}},
+		{`$.store.*`, []string{"/store/book", "/store/bicycle"}},
+		{`$.store..price`, []string{"/store/book/0/price", "/store/book/1/price", "/store/book/2/price", "/store/book/3/price", "/store/bicycle/price"}},
+		{`$..book[2]`, []string{"/store/book/2"}},
+		{`$..book[-1]`, []string{"/store/book/3"}},
+		{`$..book[0,1]`, []string{"/store/book/0", "/store/book/1"}},
+		{`$["store"]['bicycle'].color`, []string{"/store/bicycle/color"}},
+		{`$`, []string{""}},
+		{`$.store.book[4]`, []string{}},
+		{`$.store.book[-5]`, []string{}},
+		{`$.store.book.title`, []string{}},
+		{`$.store.bicycle[0]`, []string{}},
+		// A location selected twice is reported once.
+		{`$.store.book[0,0,-4]`, []string{"/store/book/0"}},
+	}
+	for _, tc := range cases {
+		if got := selectPointers(t, storeDoc, tc.query); !reflect.DeepEqual(got, tc.want) {
+			t.Fatalf("%s: got %v want %v", tc.query, got, tc.want)
+		}
+	}
+	// Descendant segments visit a node before its children, in document order.
+	got := selectPointers(t, `{"a":{"b":[{"c":1}],"c":2},"c":3}`, `$..c`)
+	want := []string{"/c", "/a/c", "/a/b/0/c"}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("$..c: got %v want %v", got, want)
+	}
+	got = selectPointers(t, `{"a/b":{"~":1}}`, `$['a/b']['~']`)
+	if !reflect.DeepEqual(got, []string{"/a~1b/~0"}) {
+		t.Fatalf("escaped pointer: got %v", got)
+	}
+}
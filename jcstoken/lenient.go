@@ -0,0 +1,524 @@
+package jcstoken
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"sort"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/lattice-substrate/json-canon/jcserr"
+)
+
+// Syntax selects the surface syntax accepted by ParseLenient.
+type Syntax int
+
+const (
+	// SyntaxJSON is strict RFC 8259 JSON; ParseLenient behaves as ParseWithOptions.
+	SyntaxJSON Syntax = iota
+	// SyntaxJSONC is JSON with // and /* */ comments and trailing commas.
+	SyntaxJSONC
+	// SyntaxJSON5 is SyntaxJSONC plus the JSON5 literal forms: unquoted
+	// member names, single-quoted strings, extra string escapes, hexadecimal
+	// numbers, leading '+', leading or trailing decimal points, and the
+	// additional JSON5 whitespace characters.
+	SyntaxJSON5
+)
+
+// ParseLenient parses data written in syntax into the same Value that
+// ParseWithOptions returns for the equivalent strict JSON text. It is an
+// explicit opt-in; Parse and ParseWithOptions never accept lenient syntax.
+//
+// The lenient text is first rewritten to strict JSON and then parsed by the
+// strict parser, so duplicate-key, surrogate, noncharacter, number-profile,
+// and bound checks apply unchanged. MaxInputSize bounds the original input.
+// JSON5 Infinity is rejected as NUMBER_OVERFLOW and NaN as INVALID_GRAMMAR.
+// Error offsets refer to bytes of the original input.
+//
+// LENIENT-SYNTAX-001: Comments and trailing commas are accepted only here.
+// LENIENT-JSON5-001: JSON5 literals are rewritten to their JSON equivalents.
+// LENIENT-PROFILE-001: The strict profile applies to the rewritten value.
+// LENIENT-OFFSET-001: Error offsets refer to the original input.
+func ParseLenient(data []byte, syntax Syntax, opts *Options) (*Value, error) {
+	switch syntax {
+	case SyntaxJSON:
+		return ParseWithOptions(data, opts)
+	case SyntaxJSONC, SyntaxJSON5:
+	default:
+		return nil, jcserr.New(jcserr.InternalError, -1, fmt.Sprintf("unknown input syntax %d", syntax))
+	}
+
+	maxInput := opts.maxInputSize()
+	if len(data) > maxInput {
+		return nil, jcserr.New(jcserr.BoundExceeded, 0,
+			fmt.Sprintf("input size %d exceeds maximum %d", len(data), maxInput))
+	}
+	if !utf8.Valid(data) {
+		return nil, jcserr.New(jcserr.InvalidUTF8, firstInvalidUTF8Offset(data),
+			"input is not valid UTF-8")
+	}
+
+	n := &normalizer{
+		src:            data,
+		json5:          syntax == SyntaxJSON5,
+		maxNumberChars: opts.maxNumberChars(),
+		out:            make([]byte, 0, len(data)),
+	}
+	if err := n.run(); err != nil {
+		return nil, err
+	}
+
+	var strict Options
+	if opts != nil {
+		strict = *opts
+	}
+	// The rewrite may lengthen the text; the size bound was applied above.
+	strict.MaxInputSize = max(len(n.out), maxInput)
+	v, err := ParseWithOptions(n.out, &strict)
+	if err != nil {
+		return nil, n.remap(err)
+	}
+	return v, nil
+}
+
+// offsetSegment maps output offsets from out onward to source offsets from src.
+type offsetSegment struct {
+	out int
+	src int
+}
+
+// normalizer rewrites lenient input into strict JSON, recording where each
+// output byte came from.
+type normalizer struct {
+	src            []byte
+	pos            int
+	json5          bool
+	maxNumberChars int
+	out            []byte
+	segs           []offsetSegment
+}
+
+// mark records that the next output byte originates at source offset src.
+func (n *normalizer) mark(src int) {
+	delta := src - len(n.out)
+	if k := len(n.segs); k > 0 && n.segs[k-1].src-n.segs[k-1].out == delta {
+		return
+	}
+	n.segs = append(n.segs, offsetSegment{out: len(n.out), src: src})
+}
+
+func (n *normalizer) emit(src int, b ...byte) {
+	n.mark(src)
+	n.out = append(n.out, b...)
+}
+
+// copyRaw copies size source bytes unchanged.
+func (n *normalizer) copyRaw(size int) {
+	n.emit(n.pos, n.src[n.pos:n.pos+size]...)
+	n.pos += size
+}
+
+// remap rewrites the offset of a strict-parser error to the source offset.
+func (n *normalizer) remap(err error) error {
+	var je *jcserr.Error
+	if errors.As(err, &je) && je.Offset >= 0 {
+		je.Offset = n.sourceOffset(je.Offset)
+	}
+	return err
+}
+
+func (n *normalizer) sourceOffset(off int) int {
+	i := sort.Search(len(n.segs), func(i int) bool { return n.segs[i].out > off }) - 1
+	if i < 0 {
+		return off
+	}
+	return min(n.segs[i].src+off-n.segs[i].out, len(n.src))
+}
+
+//nolint:cyclop // REQ:LENIENT-SYNTAX-001 one dispatch case per lenient token form.
+func (n *normalizer) run() error {
+	for n.pos < len(n.src) {
+		var err error
+		c := n.src[n.pos]
+		switch {
+		case c == '"' || (n.json5 && c == '\''):
+			err = n.str()
+		case c == '/':
+			err = n.comment()
+		case c == ',':
+			n.comma()
+		case n.json5 && isJSON5NumberStart(c):
+			err = n.number()
+		case n.json5 && n.identStartAt(n.pos) > 0:
+			err = n.identifier()
+		case n.json5 && n.extraSpaceAt(n.pos) > 0:
+			// Extra JSON5 whitespace becomes a single JSON space.
+			n.emit(n.pos, ' ')
+			n.pos += n.extraSpaceAt(n.pos)
+		default:
+			n.copyRaw(1)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// comment replaces a comment with one space. A '/' that does not start a
+// comment is copied for the strict parser to reject.
+func (n *normalizer) comment() error {
+	end, ok := n.commentEnd(n.pos)
+	if !ok {
+		return jcserr.New(jcserr.InvalidGrammar, n.pos, "unterminated block comment")
+	}
+	if end == n.pos {
+		n.copyRaw(1)
+		return nil
+	}
+	n.emit(n.pos, ' ')
+	n.pos = end
+	return nil
+}
+
+// commentEnd returns the offset after the comment starting at i, i itself
+// when no comment starts there, and false for an unterminated block comment.
+func (n *normalizer) commentEnd(i int) (int, bool) {
+	if i+1 >= len(n.src) || n.src[i] != '/' {
+		return i, true
+	}
+	switch n.src[i+1] {
+	case '/':
+		j := i + 2
+		for j < len(n.src) && !n.lineTerminatorAt(j) {
+			j++
+		}
+		return j, true
+	case '*':
+		for j := i + 2; j+1 < len(n.src); j++ {
+			if n.src[j] == '*' && n.src[j+1] == '/' {
+				return j + 2, true
+			}
+		}
+		return i, false
+	default:
+		return i, true
+	}
+}
+
+func (n *normalizer) lineTerminatorAt(i int) bool {
+	c := n.src[i]
+	if c == '\n' || c == '\r' {
+		return true
+	}
+	if !n.json5 || c != 0xE2 {
+		return false
+	}
+	r, _ := utf8.DecodeRune(n.src[i:])
+	return r == '\u2028' || r == '\u2029'
+}
+
+// skipTrivia returns the offset of the next byte after i that is neither
+// whitespace nor part of a comment.
+func (n *normalizer) skipTrivia(i int) int {
+	for i < len(n.src) {
+		switch c := n.src[i]; {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			i++
+		case c == '/':
+			end, ok := n.commentEnd(i)
+			if !ok || end == i {
+				return i
+			}
+			i = end
+		default:
+			size := 0
+			if n.json5 {
+				size = n.extraSpaceAt(i)
+			}
+			if size == 0 {
+				return i
+			}
+			i += size
+		}
+	}
+	return i
+}
+
+// comma drops a comma that follows a value and whose next significant byte
+// closes a container.
+func (n *normalizer) comma() {
+	if !n.afterValue() {
+		n.copyRaw(1)
+		return
+	}
+	if j := n.skipTrivia(n.pos + 1); j < len(n.src) && (n.src[j] == ']' || n.src[j] == '}') {
+		n.pos++
+		return
+	}
+	n.copyRaw(1)
+}
+
+// afterValue reports whether the last significant output byte ends a value.
+func (n *normalizer) afterValue() bool {
+	for i := len(n.out) - 1; i >= 0; i-- {
+		switch n.out[i] {
+		case ' ', '\t', '\n', '\r':
+			continue
+		case '[', '{', ',', ':':
+			return false
+		default:
+			return true
+		}
+	}
+	return false
+}
+
+// extraSpaceAt returns the byte length of a JSON5-only whitespace character
+// at i, or 0.
+func (n *normalizer) extraSpaceAt(i int) int {
+	c := n.src[i]
+	if c == '\v' || c == '\f' {
+		return 1
+	}
+	if c < utf8.RuneSelf {
+		return 0
+	}
+	r, size := utf8.DecodeRune(n.src[i:])
+	if r == '\u2028' || r == '\u2029' || r == '\uFEFF' || unicode.Is(unicode.Zs, r) {
+		return size
+	}
+	return 0
+}
+
+// str copies a string literal. Under JSON5 the literal is rewritten as a
+// double-quoted JSON string with JSON5-only escapes translated.
+func (n *normalizer) str() error {
+	quote := n.src[n.pos]
+	n.emit(n.pos, '"')
+	n.pos++
+	for n.pos < len(n.src) {
+		switch c := n.src[n.pos]; {
+		case c == quote:
+			n.emit(n.pos, '"')
+			n.pos++
+			return nil
+		case c == '\\' && n.json5:
+			if err := n.escape(); err != nil {
+				return err
+			}
+		case c == '\\':
+			// JSON escape: the escaped byte cannot end the string.
+			n.copyRaw(min(2, len(n.src)-n.pos))
+		case c == '"':
+			// Only reachable inside a single-quoted JSON5 string.
+			n.emit(n.pos, '\\', '"')
+			n.pos++
+		default:
+			n.copyRaw(1)
+		}
+	}
+	// Unterminated: the strict parser reports it.
+	return nil
+}
+
+// escape translates one JSON5 escape sequence at n.pos.
+//
+//nolint:gocyclo,cyclop // REQ:LENIENT-JSON5-001 one case per JSON5 escape form.
+func (n *normalizer) escape() error {
+	start := n.pos
+	if start+1 >= len(n.src) {
+		n.copyRaw(1)
+		return nil
+	}
+	e := n.src[start+1]
+	switch {
+	case e == '"' || e == '\\' || e == '/' || e == 'b' || e == 'f' || e == 'n' || e == 'r' || e == 't' || e == 'u':
+		n.copyRaw(2)
+	case e == '\'':
+		n.emit(start, '\'')
+		n.pos += 2
+	case e == 'v':
+		n.emit(start, []byte(`\u000b`)...)
+		n.pos += 2
+	case e == '0' && (start+2 >= len(n.src) || !isDigit(n.src[start+2])):
+		n.emit(start, []byte(`\u0000`)...)
+		n.pos += 2
+	case isDigit(e):
+		return jcserr.New(jcserr.InvalidGrammar, start, "octal escape sequences are not allowed")
+	case e == 'x':
+		return n.hexEscape()
+	case n.lineTerminatorAt(start + 1):
+		// Line continuation: the escape and terminator produce nothing.
+		_, size := utf8.DecodeRune(n.src[start+1:])
+		n.pos += 1 + size
+		if e == '\r' && n.pos < len(n.src) && n.src[n.pos] == '\n' {
+			n.pos++
+		}
+	case e < 0x20:
+		n.emit(start, []byte(fmt.Sprintf(`\u%04x`, e))...)
+		n.pos += 2
+	default:
+		// Any other escaped character stands for itself.
+		_, size := utf8.DecodeRune(n.src[start+1:])
+		n.emit(start+1, n.src[start+1:start+1+size]...)
+		n.pos += 1 + size
+	}
+	return nil
+}
+
+func (n *normalizer) hexEscape() error {
+	start := n.pos
+	if start+3 >= len(n.src) {
+		return jcserr.New(jcserr.InvalidGrammar, start, `\x escape requires two hex digits`)
+	}
+	hi, ok1 := hexVal(n.src[start+2])
+	lo, ok2 := hexVal(n.src[start+3])
+	if !ok1 || !ok2 {
+		return jcserr.New(jcserr.InvalidGrammar, start, `\x escape requires two hex digits`)
+	}
+	n.emit(start, []byte(fmt.Sprintf(`\u00%02x`, hi<<4|lo))...)
+	n.pos += 4
+	return nil
+}
+
+func isJSON5NumberStart(c byte) bool {
+	return c == '+' || c == '-' || c == '.' || isDigit(c)
+}
+
+// number rewrites a JSON5 numeric literal as a JSON number. Literals it
+// cannot interpret are copied for the strict parser to reject.
+//
+//nolint:cyclop // REQ:LENIENT-JSON5-001 one case per JSON5 numeric literal form.
+func (n *normalizer) number() error {
+	start := n.pos
+	bodyStart := start
+	if c := n.src[start]; c == '+' || c == '-' {
+		bodyStart++
+	}
+	end := bodyStart
+	for end < len(n.src) && isNumberBodyByte(n.src, bodyStart, end) {
+		end++
+	}
+	// BOUND-NUMCHARS-001 applies to the source literal before any conversion.
+	if end-start > n.maxNumberChars {
+		return jcserr.New(jcserr.BoundExceeded, start,
+			fmt.Sprintf("number token length %d exceeds maximum %d", end-start, n.maxNumberChars))
+	}
+	body := string(n.src[bodyStart:end])
+	sign := ""
+	if n.src[start] == '-' {
+		sign = "-"
+	}
+	switch {
+	case body == "":
+		n.copyRaw(1)
+		return nil
+	case body == "Infinity":
+		return jcserr.New(jcserr.NumberOverflow, start, "non-finite number Infinity is not allowed")
+	case body == "NaN":
+		return jcserr.New(jcserr.InvalidGrammar, start, "NaN is not a JSON number")
+	case len(body) > 1 && body[0] == '0' && (body[1] == 'x' || body[1] == 'X'):
+		d, ok := new(big.Int).SetString(body[2:], 16)
+		if !ok {
+			return jcserr.New(jcserr.InvalidGrammar, start, fmt.Sprintf("invalid hexadecimal number %q", body))
+		}
+		n.emit(start, []byte(sign+d.Text(10))...)
+	default:
+		n.emit(start, []byte(sign+json5Decimal(body))...)
+	}
+	n.pos = end
+	return nil
+}
+
+// isNumberBodyByte reports whether src[i] continues the numeric literal body
+// starting at start. Signs continue it only directly after a decimal exponent
+// marker.
+func isNumberBodyByte(src []byte, start, i int) bool {
+	c := src[i]
+	switch {
+	case isDigit(c) || c == '.' || ('a' <= c && c <= 'z') || ('A' <= c && c <= 'Z'):
+		return true
+	case c == '+' || c == '-':
+		hex := i-start > 1 && src[start] == '0' && (src[start+1] == 'x' || src[start+1] == 'X')
+		return i > start && (src[i-1] == 'e' || src[i-1] == 'E') && !hex
+	default:
+		return false
+	}
+}
+
+// json5Decimal adds the integer digit a leading decimal point omits and drops
+// a trailing decimal point.
+func json5Decimal(body string) string {
+	if body[0] == '.' && len(body) > 1 && isDigit(body[1]) {
+		body = "0" + body
+	}
+	for i := 0; i < len(body); i++ {
+		if body[i] == '.' && i > 0 && isDigit(body[i-1]) && (i+1 == len(body) || !isDigit(body[i+1])) {
+			return body[:i] + body[i+1:]
+		}
+	}
+	return body
+}
+
+// identStartAt returns the byte length of the ECMAScript identifier start
+// character at i, or 0.
+func (n *normalizer) identStartAt(i int) int {
+	c := n.src[i]
+	if c == '_' || c == '$' || ('a' <= c && c <= 'z') || ('A' <= c && c <= 'Z') {
+		return 1
+	}
+	if c < utf8.RuneSelf {
+		return 0
+	}
+	r, size := utf8.DecodeRune(n.src[i:])
+	if unicode.IsLetter(r) || unicode.Is(unicode.Nl, r) {
+		return size
+	}
+	return 0
+}
+
+func (n *normalizer) identPartAt(i int) int {
+	if size := n.identStartAt(i); size > 0 {
+		return size
+	}
+	if isDigit(n.src[i]) {
+		return 1
+	}
+	r, size := utf8.DecodeRune(n.src[i:])
+	if r >= utf8.RuneSelf && (unicode.In(r, unicode.Mn, unicode.Mc, unicode.Nd, unicode.Pc) || r == '\u200C' || r == '\u200D') {
+		return size
+	}
+	return 0
+}
+
+// identifier quotes an unquoted member name and rejects the non-finite JSON5
+// number literals. Other bare words are copied for the strict parser.
+func (n *normalizer) identifier() error {
+	start := n.pos
+	end := start + n.identStartAt(start)
+	for end < len(n.src) {
+		size := n.identPartAt(end)
+		if size == 0 {
+			break
+		}
+		end += size
+	}
+	name := n.src[start:end]
+	if j := n.skipTrivia(end); j < len(n.src) && n.src[j] == ':' {
+		n.emit(start, '"')
+		n.emit(start, name...)
+		n.emit(end, '"')
+		n.pos = end
+		return nil
+	}
+	switch string(name) {
+	case "Infinity":
+		return jcserr.New(jcserr.NumberOverflow, start, "non-finite number Infinity is not allowed")
+	case "NaN":
+		return jcserr.New(jcserr.InvalidGrammar, start, "NaN is not a JSON number")
+	}
+	n.copyRaw(end - start)
+	return nil
+}
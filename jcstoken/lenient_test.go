@@ -0,0 +1,135 @@
+package jcstoken_test
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/lattice-substrate/json-canon/jcserr"
+	"github.com/lattice-substrate/json-canon/jcstoken"
+)
+
+func lenientError(t *testing.T, in string, syntax jcstoken.Syntax, opts *jcstoken.Options) *jcserr.Error {
+	t.Helper()
+	_, err := jcstoken.ParseLenient([]byte(in), syntax, opts)
+	var je *jcserr.Error
+	if !errors.As(err, &je) {
+		t.Fatalf("ParseLenient(%q): expected *jcserr.Error, got %T: %v", in, err, err)
+	}
+	return je
+}
+
+func assertLenientEquals(t *testing.T, in, strict string, syntax jcstoken.Syntax) {
+	t.Helper()
+	got, err := jcstoken.ParseLenient([]byte(in), syntax, nil)
+	if err != nil {
+		t.Fatalf("ParseLenient(%q): %v", in, err)
+	}
+	want, err := jcstoken.Parse([]byte(strict))
+	if err != nil {
+		t.Fatalf("Parse(%q): %v", strict, err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("ParseLenient(%q) = %+v, want %+v", in, got, want)
+	}
+}
+
+// === LENIENT-SYNTAX-001: Comments and trailing commas are opt-in ===
+
+func TestParseLenient_LENIENT_SYNTAX_001(t *testing.T) {
+	in := "// config\n{\n  \"b\": [1, 2,], /* inline */\n  \"a\": \"//not a comment\",\n}\n"
+	assertLenientEquals(t, in, `{"b":[1,2],"a":"//not a comment"}`, jcstoken.SyntaxJSONC)
+	assertLenientEquals(t, "[1,/*x*/]", "[1]", jcstoken.SyntaxJSONC)
+
+	if _, err := jcstoken.Parse([]byte(in)); err == nil {
+		t.Fatal("strict Parse accepted JSONC input")
+	}
+	if _, err := jcstoken.ParseLenient([]byte(in), jcstoken.SyntaxJSON, nil); err == nil {
+		t.Fatal("SyntaxJSON accepted JSONC input")
+	}
+	for _, in := range []string{`[1,,]`, `[,]`, `{"a":,}`, `{a:1}`, `['x']`, `[0x10]`, `[1] /* open`} {
+		if je := lenientError(t, in, jcstoken.SyntaxJSONC, nil); je.Class != jcserr.InvalidGrammar {
+			t.Fatalf("%s: expected INVALID_GRAMMAR, got %v", in, je)
+		}
+	}
+}
+
+// === LENIENT-JSON5-001: JSON5 literals map to their JSON equivalents ===
+
+func TestParseLenient_LENIENT_JSON5_001(t *testing.T) {
+	cases := []struct{ in, strict string }{
+		{`{unquoted: 1, $x_1: 2, 'single': 3, "double": 4,}`, `{"unquoted":1,"$x_1":2,"single":3,"double":4}`},
+		{`{true: null, émoji: 1}`, `{"true":null,"émoji":1}`},
+		{`['it\'s "quoted"']`, `["it's \"quoted\""]`},
+		{`["\x41\v\0\q"]`, `["A\u000b\u0000q"]`},
+		{"['line \\\ncontinued']", `["line continued"]`},
+		{`[0x1F, -0XfF, +1, .5, 5., 1.e2, +.25e-1]`, `[31,-255,1,0.5,5,1e2,0.025]`},
+		{"\v[\f1\u00a0]\ufeff", `[1]`},
+		{`[true, false, null]`, `[true,false,null]`},
+	}
+	for _, tc := range cases {
+		assertLenientEquals(t, tc.in, tc.strict, jcstoken.SyntaxJSON5)
+	}
+	for _, in := range []string{`["\1"]`, `["\x4"]`, `[0x]`, `[0xG]`, `{a b: 1}`, `[+]`, `[.]`} {
+		if je := lenientError(t, in, jcstoken.SyntaxJSON5, nil); je.Class != jcserr.InvalidGrammar {
+			t.Fatalf("%s: expected INVALID_GRAMMAR, got %v", in, je)
+		}
+	}
+}
+
+// === LENIENT-PROFILE-001: Strict profile checks apply to lenient input ===
+
+func TestParseLenient_LENIENT_PROFILE_001(t *testing.T) {
+	cases := []struct {
+		in   string
+		opts *jcstoken.Options
+		want jcserr.FailureClass
+	}{
+		{`{a: 1, "a": 2}`, nil, jcserr.DuplicateKey},
+		{`{'a': 1, "a": 2}`, nil, jcserr.DuplicateKey},
+		{`[Infinity]`, nil, jcserr.NumberOverflow},
+		{`[-Infinity]`, nil, jcserr.NumberOverflow},
+		{`[NaN]`, nil, jcserr.InvalidGrammar},
+		{`[-0x0]`, nil, jcserr.NumberNegZero},
+		{`[1e400]`, nil, jcserr.NumberOverflow},
+		{`['\uD800']`, nil, jcserr.LoneSurrogate},
+		{`['﷐']`, nil, jcserr.Noncharacter},
+		{`[[[1]]]`, &jcstoken.Options{MaxDepth: 2}, jcserr.BoundExceeded},
+		{`[1, /* padding */]`, &jcstoken.Options{MaxInputSize: 10}, jcserr.BoundExceeded},
+		{`[0x123456789]`, &jcstoken.Options{MaxNumberChars: 8}, jcserr.BoundExceeded},
+	}
+	for _, tc := range cases {
+		if je := lenientError(t, tc.in, jcstoken.SyntaxJSON5, tc.opts); je.Class != tc.want {
+			t.Fatalf("%s: expected %s, got %v", tc.in, tc.want, je)
+		}
+	}
+	// A hexadecimal literal that grows on rewriting is bounded by its source size.
+	if _, err := jcstoken.ParseLenient([]byte(`0xFFFFFFFFFFFFF`), jcstoken.SyntaxJSON5, &jcstoken.Options{MaxInputSize: 15}); err != nil {
+		t.Fatalf("rewritten hexadecimal rejected: %v", err)
+	}
+	je := lenientError(t, "[1,\xff]", jcstoken.SyntaxJSONC, nil)
+	if je.Class != jcserr.InvalidUTF8 || je.Offset != 3 {
+		t.Fatalf("expected INVALID_UTF8 at 3, got %v", je)
+	}
+}
+
+// === LENIENT-OFFSET-001: Error offsets refer to the original input ===
+
+func TestParseLenient_LENIENT_OFFSET_001(t *testing.T) {
+	cases := []struct {
+		in     string
+		syntax jcstoken.Syntax
+		offset int
+	}{
+		{"/* comment */ [1, 2,] x", jcstoken.SyntaxJSONC, 22},
+		{"{\"a\": 1, // c\n \"a\": 2}", jcstoken.SyntaxJSONC, 15},
+		{"{a: 1, /*c*/ a: 2}", jcstoken.SyntaxJSON5, 13},
+		{"[0x10, .5, 'é', 01]", jcstoken.SyntaxJSON5, 18},
+		{"[1, 2] /* open", jcstoken.SyntaxJSONC, 7},
+	}
+	for _, tc := range cases {
+		if je := lenientError(t, tc.in, tc.syntax, nil); je.Offset != tc.offset {
+			t.Fatalf("%q: expected offset %d, got %v", tc.in, tc.offset, je)
+		}
+	}
+}
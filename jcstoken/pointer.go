@@ -0,0 +1,175 @@
+package jcstoken
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/lattice-substrate/json-canon/jcserr"
+)
+
+// Pointer is a parsed RFC 6901 JSON Pointer. Each element is an unescaped
+// reference token; the empty Pointer refers to the whole document.
+type Pointer []string
+
+// ParsePointer parses the string form of an RFC 6901 JSON Pointer.
+//
+// PTR-SYNTAX-001: A non-empty pointer MUST start with '/'.
+// PTR-SYNTAX-002: '~' MUST be followed by '0' or '1'; "~1" decodes to '/' and
+// "~0" decodes to '~', applied in that order.
+func ParsePointer(s string) (Pointer, error) {
+	if s == "" {
+		return Pointer{}, nil
+	}
+	if s[0] != '/' {
+		return nil, jcserr.New(jcserr.InvalidPointer, -1,
+			fmt.Sprintf("json pointer %q must start with '/'", s))
+	}
+	raw := strings.Split(s[1:], "/")
+	p := make(Pointer, len(raw))
+	for i, tok := range raw {
+		decoded, err := unescapePointerToken(tok)
+		if err != nil {
+			return nil, jcserr.New(jcserr.InvalidPointer, -1,
+				fmt.Sprintf("json pointer %q: %s", s, err.Message))
+		}
+		p[i] = decoded
+	}
+	return p, nil
+}
+
+func unescapePointerToken(tok string) (string, *jcserr.Error) {
+	if !strings.Contains(tok, "~") {
+		return tok, nil
+	}
+	var b strings.Builder
+	b.Grow(len(tok))
+	for i := 0; i < len(tok); i++ {
+		if tok[i] != '~' {
+			b.WriteByte(tok[i])
+			continue
+		}
+		if i+1 >= len(tok) || (tok[i+1] != '0' && tok[i+1] != '1') {
+			return "", jcserr.New(jcserr.InvalidPointer, -1, "'~' must be followed by '0' or '1'")
+		}
+		if tok[i+1] == '0' {
+			b.WriteByte('~')
+		} else {
+			b.WriteByte('/')
+		}
+		i++
+	}
+	return b.String(), nil
+}
+
+var pointerEscaper = strings.NewReplacer("~", "~0", "/", "~1")
+
+// String returns the RFC 6901 string form of p.
+func (p Pointer) String() string {
+	var b strings.Builder
+	for _, tok := range p {
+		b.WriteByte('/')
+		b.WriteString(pointerEscaper.Replace(tok))
+	}
+	return b.String()
+}
+
+// Append returns a new Pointer with tok appended, leaving p unchanged.
+func (p Pointer) Append(tok string) Pointer {
+	out := make(Pointer, len(p), len(p)+1)
+	copy(out, p)
+	return append(out, tok)
+}
+
+// Resolve returns the value p refers to within v. The returned value aliases
+// v, so modifications through it are visible in v.
+//
+// PTR-EVAL-001: Evaluation follows RFC 6901 §4 against objects and arrays.
+func (p Pointer) Resolve(v *Value) (*Value, error) {
+	cur := v
+	for i, tok := range p {
+		next, err := cur.child(tok)
+		if err != nil {
+			return nil, jcserr.New(jcserr.InvalidPointer, -1,
+				fmt.Sprintf("json pointer %q: %s", p[:i+1].String(), err.Message))
+		}
+		cur = next
+	}
+	return cur, nil
+}
+
+func (v *Value) child(tok string) (*Value, *jcserr.Error) {
+	switch v.Kind {
+	case KindObject:
+		if i := v.MemberIndex(tok); i >= 0 {
+			return &v.Members[i].Value, nil
+		}
+		return nil, jcserr.New(jcserr.InvalidPointer, -1, "object member not found")
+	case KindArray:
+		idx, err := ArrayIndex(tok)
+		if err != nil {
+			return nil, err
+		}
+		if idx >= len(v.Elems) {
+			return nil, jcserr.New(jcserr.InvalidPointer, -1,
+				fmt.Sprintf("array index %d out of range (length %d)", idx, len(v.Elems)))
+		}
+		return &v.Elems[idx], nil
+	default:
+		return nil, jcserr.New(jcserr.InvalidPointer, -1, "cannot descend into scalar value")
+	}
+}
+
+// maxArrayIndexDigits keeps parsed indexes far below int overflow on every
+// supported platform while exceeding any configurable array bound in practice.
+const maxArrayIndexDigits = 9
+
+// ArrayIndex parses an RFC 6901 array-index reference token. Leading zeros,
+// signs, and the "-" past-the-end token are rejected.
+//
+// PTR-EVAL-002: array-index = %x30 / ( %x31-39 *%x30-39 ).
+func ArrayIndex(tok string) (int, *jcserr.Error) {
+	if tok == "" || (len(tok) > 1 && tok[0] == '0') {
+		return 0, jcserr.New(jcserr.InvalidPointer, -1, fmt.Sprintf("invalid array index %q", tok))
+	}
+	if len(tok) > maxArrayIndexDigits {
+		return 0, jcserr.New(jcserr.InvalidPointer, -1, fmt.Sprintf("array index %q out of range", tok))
+	}
+	n := 0
+	for i := 0; i < len(tok); i++ {
+		if !isDigit(tok[i]) {
+			return 0, jcserr.New(jcserr.InvalidPointer, -1, fmt.Sprintf("invalid array index %q", tok))
+		}
+		n = n*10 + int(tok[i]-'0')
+	}
+	return n, nil
+}
+
+// MemberIndex returns the index in v.Members of the member named key, or -1
+// if v has no such member. It is meaningful only for objects.
+func (v *Value) MemberIndex(key string) int {
+	for i := range v.Members {
+		if v.Members[i].Key == key {
+			return i
+		}
+	}
+	return -1
+}
+
+// Clone returns a deep copy of v that shares no slices with it.
+func (v *Value) Clone() *Value {
+	out := *v
+	if v.Members != nil {
+		out.Members = make([]Member, len(v.Members))
+		for i := range v.Members {
+			out.Members[i].Key = v.Members[i].Key
+			out.Members[i].Value = *v.Members[i].Value.Clone()
+		}
+	}
+	if v.Elems != nil {
+		out.Elems = make([]Value, len(v.Elems))
+		for i := range v.Elems {
+			out.Elems[i] = *v.Elems[i].Clone()
+		}
+	}
+	return &out
+}
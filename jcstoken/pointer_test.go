@@ -0,0 +1,128 @@
+package jcstoken_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/lattice-substrate/json-canon/jcserr"
+	"github.com/lattice-substrate/json-canon/jcstoken"
+)
+
+func assertPointerClass(t *testing.T, err error) {
+	t.Helper()
+	var je *jcserr.Error
+	if !errors.As(err, &je) {
+		t.Fatalf("expected *jcserr.Error, got %T: %v", err, err)
+	}
+	if je.Class != jcserr.InvalidPointer {
+		t.Fatalf("expected INVALID_POINTER, got %s: %v", je.Class, err)
+	}
+}
+
+// === PTR-SYNTAX-001: Non-empty pointer starts with '/' ===
+
+func TestPointer_PTR_SYNTAX_001(t *testing.T) {
+	p, err := jcstoken.ParsePointer("")
+	if err != nil || len(p) != 0 {
+		t.Fatalf("empty pointer: got %v, %v", p, err)
+	}
+	p, err = jcstoken.ParsePointer("/")
+	if err != nil || len(p) != 1 || p[0] != "" {
+		t.Fatalf(`"/" must be one empty token, got %q, %v`, p, err)
+	}
+	_, err = jcstoken.ParsePointer("a/b")
+	assertPointerClass(t, err)
+}
+
+// === PTR-SYNTAX-002: '~' escapes decode in order and round-trip ===
+
+func TestPointer_PTR_SYNTAX_002(t *testing.T) {
+	p, err := jcstoken.ParsePointer("/a~1b/m~0n/~01")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"a/b", "m~n", "~1"}
+	if len(p) != len(want) {
+		t.Fatalf("got %q want %q", p, want)
+	}
+	for i := range want {
+		if p[i] != want[i] {
+			t.Fatalf("token %d: got %q want %q", i, p[i], want[i])
+		}
+	}
+	if got := p.String(); got != "/a~1b/m~0n/~01" {
+		t.Fatalf("String round-trip: got %q", got)
+	}
+	for _, bad := range []string{"/~", "/~2", "/a~"} {
+		_, err := jcstoken.ParsePointer(bad)
+		assertPointerClass(t, err)
+	}
+}
+
+// === PTR-EVAL-001: Evaluation against objects and arrays ===
+
+func TestPointer_PTR_EVAL_001(t *testing.T) {
+	v := mustParse(t, `{"foo":["bar","baz"],"":0,"a/b":1,"m~n":8}`)
+	cases := map[string]string{
+		"/foo/0": "bar",
+		"/foo/1": "baz",
+	}
+	for ptr, want := range cases {
+		p, err := jcstoken.ParsePointer(ptr)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, err := p.Resolve(v)
+		if err != nil {
+			t.Fatalf("%s: %v", ptr, err)
+		}
+		if got.Kind != jcstoken.KindString || got.Str != want {
+			t.Fatalf("%s: got %+v want %q", ptr, got, want)
+		}
+	}
+	for _, ptr := range []string{"/", "/a~1b", "/m~0n"} {
+		p, _ := jcstoken.ParsePointer(ptr)
+		if _, err := p.Resolve(v); err != nil {
+			t.Fatalf("%s: %v", ptr, err)
+		}
+	}
+	for _, ptr := range []string{"/missing", "/foo/2", "/foo/0/x"} {
+		p, _ := jcstoken.ParsePointer(ptr)
+		_, err := p.Resolve(v)
+		assertPointerClass(t, err)
+	}
+}
+
+// === PTR-EVAL-002: Array index grammar ===
+
+func TestPointer_PTR_EVAL_002(t *testing.T) {
+	for tok, want := range map[string]int{"0": 0, "7": 7, "10": 10} {
+		got, err := jcstoken.ArrayIndex(tok)
+		if err != nil || got != want {
+			t.Fatalf("%q: got %d, %v", tok, got, err)
+		}
+	}
+	for _, tok := range []string{"", "-", "01", "+1", "-1", "1a", "1234567890"} {
+		_, err := jcstoken.ArrayIndex(tok)
+		assertPointerClass(t, err)
+	}
+}
+
+func TestValueCloneIsDeep(t *testing.T) {
+	v := mustParse(t, `{"a":[1,{"b":"c"}]}`)
+	c := v.Clone()
+	c.Members[0].Value.Elems[1].Members[0].Value.Str = "changed"
+	if v.Members[0].Value.Elems[1].Members[0].Value.Str != "c" {
+		t.Fatal("Clone shares storage with the original")
+	}
+}
+
+func TestValueMemberIndex(t *testing.T) {
+	v := mustParse(t, `{"a":1,"b":2}`)
+	if got := v.MemberIndex("b"); got != 1 {
+		t.Fatalf("MemberIndex(b) = %d, want 1", got)
+	}
+	if got := v.MemberIndex("z"); got != -1 {
+		t.Fatalf("MemberIndex(z) = %d, want -1", got)
+	}
+}
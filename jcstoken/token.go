@@ -8,16 +8,13 @@
 package jcstoken
 
 import (
-	"errors"
 	"fmt"
-	"math"
-	"strconv"
-	"strings"
 	"unicode"
 	"unicode/utf16"
 	"unicode/utf8"
 
 	"github.com/lattice-substrate/json-canon/jcserr"
+	"github.com/lattice-substrate/json-canon/jcsfloat"
 )
 
 // Limits for denial-of-service protection (BOUND-* requirements).
@@ -29,13 +26,14 @@ const (
 	DefaultMaxArrayElements = 250_000
 	DefaultMaxStringBytes   = 8 * 1024 * 1024
 	DefaultMaxNumberChars   = 4096
+	DefaultMaxOutputSize    = 256 * 1024 * 1024
 )
 
 // Value represents a parsed JSON value.
 type Value struct {
 	Kind    Kind
-	Str     string   // For KindString: decoded Unicode string; for KindBool: "true"/"false"
-	Num     float64  // For KindNumber: IEEE 754 double
+	Str     string   // For KindString: decoded Unicode string; for KindBool: "true"/"false"; for KindNumber under NumberDecimal: canonical decimal text
+	Num     float64  // For KindNumber: IEEE 754 double (nearest value under NumberDecimal)
 	Members []Member // For KindObject: ordered members
 	Elems   []Value  // For KindArray: ordered elements
 }
@@ -73,8 +71,28 @@ type Options struct {
 	MaxArrayElements int
 	MaxStringBytes   int
 	MaxNumberChars   int
+	// MaxOutputSize bounds the bytes a jcs serializer given these Options
+	// emits. The parser ignores it.
+	MaxOutputSize int
+	// Numbers selects how number tokens are interpreted. The zero value is
+	// NumberBinary64.
+	Numbers NumberMode
 }
 
+// NumberMode selects how the parser interprets number tokens.
+type NumberMode int
+
+const (
+	// NumberBinary64 converts numbers to IEEE 754 binary64 under the RFC 8785
+	// profile: overflow, underflow to zero, and lexical -0 are rejected.
+	NumberBinary64 NumberMode = iota
+	// NumberDecimal keeps numbers as exact decimals for the jcs-decimal
+	// profile, which is not RFC 8785. Value.Str holds the canonical text from
+	// jcsfloat.FormatDecimal and Value.Num the nearest binary64, which may be
+	// infinite or zero; only lexical -0 is rejected.
+	NumberDecimal
+)
+
 func resolveOption(val, def int) int {
 	if val > 0 {
 		return val
@@ -124,6 +142,12 @@ func (o *Options) maxNumberChars() int {
 	}
 	return resolveOption(o.MaxNumberChars, DefaultMaxNumberChars)
 }
+func (o *Options) numbers() NumberMode {
+	if o == nil {
+		return NumberBinary64
+	}
+	return o.Numbers
+}
 
 // parser holds the state for parsing.
 type parser struct {
@@ -137,6 +161,7 @@ type parser struct {
 	maxArrayElements int
 	maxStringBytes   int
 	maxNumberChars   int
+	numbers          NumberMode
 }
 
 // Parse parses a complete JSON text under RFC 8785's strict input domain.
@@ -172,6 +197,7 @@ func ParseWithOptions(data []byte, opts *Options) (*Value, error) {
 		maxArrayElements: opts.maxArrayElements(),
 		maxStringBytes:   opts.maxStringBytes(),
 		maxNumberChars:   opts.maxNumberChars(),
+		numbers:          opts.numbers(),
 	}
 
 	p.skipWhitespace()
@@ -770,60 +796,31 @@ func (p *parser) scanExponentPart(numStart int) *jcserr.Error {
 	return nil
 }
 
+// buildNumberValue converts raw with jcsfloat.ParseDouble, which classifies
+// PROF-OFLOW-001, PROF-NEGZ-001, and PROF-UFLOW-001 failures directly.
 func (p *parser) buildNumberValue(start int, raw string) (*Value, error) {
-	f, err := strconv.ParseFloat(raw, 64)
-	if err != nil && !errorsIsRange(err) {
-		return nil, jcserr.New(jcserr.InvalidGrammar, start,
-			fmt.Sprintf("invalid number: %v", err))
-	}
-	// PROF-OFLOW-001
-	if math.IsNaN(f) || math.IsInf(f, 0) {
-		return nil, jcserr.New(jcserr.NumberOverflow, start,
-			"number overflows IEEE 754 double")
-	}
-	// PROF-NEGZ-001: lexical negative zero
-	if strings.HasPrefix(raw, "-") && tokenRepresentsZero(raw) {
-		return nil, jcserr.New(jcserr.NumberNegZero, start,
-			"negative zero token is not allowed")
-	}
-	// PROF-UFLOW-001: non-zero underflows to zero
-	if f == 0 && !tokenRepresentsZero(raw) {
-		return nil, jcserr.New(jcserr.NumberUnderflow, start,
-			"non-zero number underflows to IEEE 754 zero")
-	}
-	return &Value{Kind: KindNumber, Num: f}, nil
-}
-
-func tokenRepresentsZero(raw string) bool {
-	start := 0
-	if len(raw) > 0 && raw[0] == '-' {
-		start = 1
+	if p.numbers == NumberDecimal {
+		return buildDecimalValue(start, raw)
 	}
-	end := len(raw)
-	for i := start; i < len(raw); i++ {
-		if raw[i] == 'e' || raw[i] == 'E' {
-			end = i
-			break
-		}
-	}
-	for i := start; i < end; i++ {
-		if raw[i] >= '1' && raw[i] <= '9' {
-			return false
-		}
+	f, jerr := jcsfloat.ParseDouble(raw)
+	if jerr != nil {
+		return nil, jcserr.New(jerr.Class, start, jerr.Message)
 	}
-	return true
+	return &Value{Kind: KindNumber, Num: f}, nil
 }
 
-func errorsIsRange(err error) bool {
-	if err == nil {
-		return false
-	}
-	var numErr *strconv.NumError
-	ok := errors.As(err, &numErr)
-	if !ok {
-		return false
-	}
-	return errors.Is(numErr.Err, strconv.ErrRange)
+// buildDecimalValue keeps raw as an exact decimal.
+//
+// DEC-PARSE-001: Number tokens are kept exactly under NumberDecimal.
+func buildDecimalValue(start int, raw string) (*Value, error) {
+	dec, jerr := jcsfloat.FormatDecimal(raw)
+	if jerr != nil {
+		return nil, jcserr.New(jerr.Class, start, jerr.Message)
+	}
+	// Out-of-range values become ±Inf or 0 with a range error that does not
+	// apply here; dec stays exact.
+	f, _ := jcsfloat.ParseDouble(raw)
+	return &Value{Kind: KindNumber, Num: f, Str: dec}, nil
 }
 
 func isDigit(b byte) bool {
@@ -501,3 +501,45 @@ func TestParseAllowsDuplicateKeysInDifferentScopes(t *testing.T) {
 		t.Fatalf("unexpected parse result: %+v", v)
 	}
 }
+
+// === DEC-PARSE-001: Number tokens are kept exactly under NumberDecimal ===
+
+func TestParseWithOptions_DEC_PARSE_001(t *testing.T) {
+	opts := &jcstoken.Options{Numbers: jcstoken.NumberDecimal}
+	v, err := jcstoken.ParseWithOptions([]byte(`[1.10,12345678901234567.89,1e400,1e-400,0.0,"1.10"]`), opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"1.1", "12345678901234567.89", "1e+400", "1e-400", "0"}
+	for i, w := range want {
+		if e := v.Elems[i]; e.Kind != jcstoken.KindNumber || e.Str != w {
+			t.Fatalf("element %d: got %+v want decimal %q", i, e, w)
+		}
+	}
+	if v.Elems[0].Num != 1.1 || !math.IsInf(v.Elems[2].Num, 1) || v.Elems[3].Num != 0 {
+		t.Fatalf("unexpected binary64 values: %+v", v.Elems)
+	}
+	if v.Elems[5].Str != "1.10" {
+		t.Fatalf("string changed: %q", v.Elems[5].Str)
+	}
+	if v := mustParse(t, `1.10`); v.Str != "" {
+		t.Fatalf("binary64 mode kept decimal text %q", v.Str)
+	}
+
+	cases := []struct {
+		in     string
+		class  jcserr.FailureClass
+		offset int
+	}{
+		{`[-0.0]`, jcserr.NumberNegZero, 1},
+		{`{"a":1e1000000000}`, jcserr.UnsupportedDecimal, 5},
+		{`[01]`, jcserr.InvalidGrammar, 2},
+	}
+	for _, tc := range cases {
+		_, err := jcstoken.ParseWithOptions([]byte(tc.in), opts)
+		var je *jcserr.Error
+		if !errors.As(err, &je) || je.Class != tc.class || je.Offset != tc.offset {
+			t.Fatalf("%s: expected %s at %d, got %v", tc.in, tc.class, tc.offset, err)
+		}
+	}
+}